@@ -3,6 +3,10 @@ package gemini
 import (
 	"context"
 	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
 
 	"google.golang.org/genai"
 )
@@ -47,6 +51,117 @@ func (a Attachment) validateSource(sentinel error, field string) error {
 	return nil
 }
 
+// checkMIMEType は、申告された MIME type がデータの中身と矛盾しないかを検証します。
+//
+// 判定の根拠はマジックバイトだけです。中身から形式を特定できないデータ（テキストや
+// 未知の形式）は申告を信じて通します。確実に食い違うときだけ止めるのは、送ってから
+// サーバー側のデコード失敗として分かりにくい形で返ってくるのを避けるためで、
+// 申告を推測で上書きするためではありません。生の PCM（isRawAudioMIMEType）は、
+// サンプルの値が偶然ほかの形式のマジックバイトに見えることがあるため確かめません。
+func (a Attachment) checkMIMEType(field string) error {
+	if len(a.Data) == 0 || a.MIMEType == "" || isRawAudioMIMEType(a.MIMEType) {
+		return nil
+	}
+	sniffed := sniffMagic(a.Data)
+	if sniffed == "" || sameMIMEFamily(a.MIMEType, sniffed) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s は %q と申告されていますが、データは %q です",
+		ErrMIMETypeMismatch, field, a.MIMEType, sniffed)
+}
+
+// ValidateFor は、添付が指定した入力の種別（画像・音声・動画・文書）として
+// 送信できるかを検証します。
+//
+// 次の場合にエラーを返します。
+//
+//   - Data と URI を併用している（ErrInvalidAttachment）
+//   - Data があるのに MIME type が無い（ErrInvalidAttachment）
+//   - MIME type がその種別で受け付けられない（ErrUnsupportedMIMEType）
+//   - MIME type の申告がデータの中身と食い違う（ErrMIMETypeMismatch）
+//
+// MIME type を省いた URI 参照はサーバー側の判定に委ねる形なので、検証せずに通します。
+// 送るものが無い添付（IsEmpty）も、生成時に読み飛ばされるため通します。
+func (a Attachment) ValidateFor(modality Modality) error {
+	if a.IsEmpty() {
+		return nil
+	}
+	if err := a.validateSource(ErrInvalidAttachment, "attachment"); err != nil {
+		return err
+	}
+	if a.MIMEType == "" {
+		if len(a.Data) > 0 {
+			return fmt.Errorf("%w: attachment にMIME typeが設定されていません", ErrInvalidAttachment)
+		}
+		return nil
+	}
+	if !slices.Contains(supportedMIMETypes[modality], canonicalMIMEType(a.MIMEType)) {
+		return fmt.Errorf("%w: %q は %s 入力として受け付けられません", ErrUnsupportedMIMEType, a.MIMEType, modality)
+	}
+	return a.checkMIMEType("attachment")
+}
+
+// NewAttachment は、データの中身から MIME type を判定して Attachment を作ります。
+// 判定できない場合は ErrInvalidAttachment を返します。
+func NewAttachment(data []byte) (Attachment, error) {
+	return attachmentFromData(data, "")
+}
+
+// AttachmentFromReader は、r を最後まで読み込み、中身から MIME type を判定して
+// Attachment を作ります。
+//
+// 添付はインラインで送るため全量をメモリへ読み込みます。大きな動画などは
+// UploadFile で File API へ上げて URI で参照してください。
+func AttachmentFromReader(r io.Reader) (Attachment, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("添付データの読み込みに失敗しました: %w", err)
+	}
+	return attachmentFromData(data, "")
+}
+
+// AttachmentFromFile は、ファイルを読み込んで Attachment を作ります。
+//
+// MIME type は中身から判定し、中身から判定できない場合に限って拡張子から推測します。
+// 拡張子は名前を付け替えるだけで変わるため、中身を優先しています。
+func AttachmentFromFile(path string) (Attachment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("添付ファイル %q の読み込みに失敗しました: %w", path, err)
+	}
+	return attachmentFromData(data, path)
+}
+
+// AttachmentFromFS は、fs.FS 上のファイルを読み込んで Attachment を作ります。
+// embed.FS に同梱したサンプル画像などを添付する用途です。MIME type の決め方は
+// AttachmentFromFile と同じです。
+func AttachmentFromFS(fsys fs.FS, name string) (Attachment, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("添付ファイル %q の読み込みに失敗しました: %w", name, err)
+	}
+	return attachmentFromData(data, name)
+}
+
+// attachmentFromData は、マジックバイト → 拡張子 → テキスト判定の順に MIME type を
+// 決めて Attachment を作ります。name が空の場合は拡張子による推測を行いません。
+func attachmentFromData(data []byte, name string) (Attachment, error) {
+	if len(data) == 0 {
+		return Attachment{}, fmt.Errorf("%w: 添付データが空です", ErrInvalidAttachment)
+	}
+	mimeType := sniffMagic(data)
+	if mimeType == "" && name != "" {
+		mimeType = mimeTypeByExtension(name)
+	}
+	if mimeType == "" {
+		mimeType = DetectMIMEType(data)
+	}
+	if mimeType == "" {
+		return Attachment{}, fmt.Errorf("%w: 添付データの MIME type を判定できません", ErrInvalidAttachment)
+	}
+	return Attachment{MIMEType: mimeType, Data: data}, nil
+}
+
 // GenerateWithAttachments は、テキストプロンプトとバイナリ添付からコンテンツを生成します。
 //
 // テキスト 1 つと添付 n 件という、マルチモーダル呼び出しのほとんどを占める形に
@@ -54,6 +169,8 @@ func (a Attachment) validateSource(sentinel error, field string) error {
 //
 // prompt が空でも添付があれば送信します（音声や画像だけを渡して解析させる用途）。
 // 両方が空の場合と、データを持つ添付に MIME type が無い場合はエラーを返します。
+// MIME type の申告がデータの中身と確実に食い違う場合も、送信前に ErrMIMETypeMismatch で
+// 止めます。入力の種別に対応しているかまで確かめたい場合は ValidateFor を使ってください。
 func (c *Client) GenerateWithAttachments(ctx context.Context, modelName string, prompt string, attachments []Attachment, opts GenerateOptions) (*Response, error) {
 	parts, err := attachmentParts(prompt, attachments)
 	if err != nil {
@@ -93,6 +210,9 @@ func attachmentParts(prompt string, attachments []Attachment) ([]*genai.Part, er
 		if attachment.MIMEType == "" {
 			return nil, fmt.Errorf("%w: attachments[%d] にMIME typeが設定されていません", ErrInvalidAttachment, i)
 		}
+		if err := attachment.checkMIMEType(fmt.Sprintf("attachments[%d]", i)); err != nil {
+			return nil, err
+		}
 		parts = append(parts, &genai.Part{
 			InlineData: &genai.Blob{MIMEType: attachment.MIMEType, Data: attachment.Data},
 		})
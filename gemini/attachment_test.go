@@ -1,9 +1,15 @@
 package gemini
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
+	"testing/fstest"
 
 	"google.golang.org/genai"
 )
@@ -265,3 +271,128 @@ func TestResponseAttachmentsCarryMIMETypes(t *testing.T) {
 		t.Errorf("images = %d, audios = %d, want 1 each", len(resp.Images), len(resp.Audios))
 	}
 }
+
+// TestAttachmentPartsRejectsMIMETypeMismatch verifies bytes that clearly contradict the declared
+// type are stopped before sending, instead of surfacing as an opaque server-side decode error.
+func TestAttachmentPartsRejectsMIMETypeMismatch(t *testing.T) {
+	_, err := attachmentParts("prompt", []Attachment{
+		{MIMEType: "image/jpeg", Data: magicSamples["image/png"]},
+	})
+	if !errors.Is(err, ErrMIMETypeMismatch) {
+		t.Fatalf("attachmentParts() error = %v, want ErrMIMETypeMismatch", err)
+	}
+}
+
+// TestAttachmentPartsTrustsUnrecognizedData verifies the mismatch check only fires on a definite
+// signature. Data without magic bytes keeps the caller's declaration.
+func TestAttachmentPartsTrustsUnrecognizedData(t *testing.T) {
+	if _, err := attachmentParts("prompt", []Attachment{{MIMEType: "audio/mpeg", Data: []byte("song")}}); err != nil {
+		t.Fatalf("attachmentParts() error = %v, want nil", err)
+	}
+}
+
+// TestAttachmentPartsAcceptsRawPCM verifies raw PCM is never checked against magic bytes. Its
+// samples are arbitrary, and one starting with 0xFF 0xF0 even carries a valid ADTS header.
+func TestAttachmentPartsAcceptsRawPCM(t *testing.T) {
+	pcm := make([]byte, 4800)
+	copy(pcm, []byte{0xFF, 0xF0, 0x00, 0x00, 0x00, 0x00, 0x00})
+	looksLikeAAC := append(slices.Clone(magicSamples["audio/aac"]), pcm...)
+	for _, mimeType := range []string{"audio/pcm", "audio/L16;rate=24000", "audio/raw", "audio/wav;codec=pcm"} {
+		for _, data := range [][]byte{pcm, looksLikeAAC} {
+			if _, err := attachmentParts("prompt", []Attachment{{MIMEType: mimeType, Data: data}}); err != nil {
+				t.Errorf("attachmentParts(%q, % x...) error = %v, want nil", mimeType, data[:2], err)
+			}
+		}
+	}
+}
+
+func TestAttachmentValidateFor(t *testing.T) {
+	tests := []struct {
+		name       string
+		attachment Attachment
+		modality   Modality
+		want       error
+	}{
+		{"matching image", Attachment{MIMEType: "image/png", Data: magicSamples["image/png"]}, ModalityImage, nil},
+		{"alias spelling", Attachment{MIMEType: "audio/x-wav", Data: magicSamples["audio/wav"]}, ModalityAudio, nil},
+		{"uri without mime", Attachment{URI: "gs://bucket/a"}, ModalityVideo, nil},
+		{"empty", Attachment{}, ModalityImage, nil},
+		{"audio sent as image", Attachment{MIMEType: "audio/mpeg", Data: magicSamples["audio/mpeg"]}, ModalityImage, ErrUnsupportedMIMEType},
+		{"gif is not accepted", Attachment{MIMEType: "image/gif", Data: magicSamples["image/gif"]}, ModalityImage, ErrUnsupportedMIMEType},
+		{"declared jpeg but png", Attachment{MIMEType: "image/jpeg", Data: magicSamples["image/png"]}, ModalityImage, ErrMIMETypeMismatch},
+		{"data without mime", Attachment{Data: []byte("x")}, ModalityImage, ErrInvalidAttachment},
+		{"data and uri", Attachment{MIMEType: "image/png", Data: []byte("x"), URI: "gs://b/a"}, ModalityImage, ErrInvalidAttachment},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.attachment.ValidateFor(tt.modality)
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("ValidateFor() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Errorf("ValidateFor() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestAttachmentFromFSPrefersContentOverExtension verifies the sniffed type wins over a misleading
+// file name, and the extension is only consulted when the bytes say nothing.
+func TestAttachmentFromFSPrefersContentOverExtension(t *testing.T) {
+	fsys := fstest.MapFS{
+		"renamed.jpg": {Data: magicSamples["image/png"]},
+		"voice.mp3":   {Data: []byte{0x00, 0x01, 0x02}},
+		"empty.png":   {Data: nil},
+		"mystery":     {Data: []byte{0x00, 0x01, 0x02}},
+	}
+
+	got, err := AttachmentFromFS(fsys, "renamed.jpg")
+	if err != nil {
+		t.Fatalf("AttachmentFromFS(renamed.jpg) error = %v", err)
+	}
+	if got.MIMEType != "image/png" {
+		t.Errorf("MIMEType = %q, want image/png from the content", got.MIMEType)
+	}
+
+	got, err = AttachmentFromFS(fsys, "voice.mp3")
+	if err != nil {
+		t.Fatalf("AttachmentFromFS(voice.mp3) error = %v", err)
+	}
+	if got.MIMEType != "audio/mpeg" {
+		t.Errorf("MIMEType = %q, want audio/mpeg from the extension", got.MIMEType)
+	}
+
+	for _, name := range []string{"empty.png", "mystery"} {
+		if _, err := AttachmentFromFS(fsys, name); !errors.Is(err, ErrInvalidAttachment) {
+			t.Errorf("AttachmentFromFS(%s) error = %v, want ErrInvalidAttachment", name, err)
+		}
+	}
+	if _, err := AttachmentFromFS(fsys, "missing.png"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("AttachmentFromFS(missing) error = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestAttachmentFromReaderAndFile(t *testing.T) {
+	got, err := AttachmentFromReader(bytes.NewReader(magicSamples["audio/wav"]))
+	if err != nil {
+		t.Fatalf("AttachmentFromReader() error = %v", err)
+	}
+	if got.MIMEType != "audio/wav" || !bytes.Equal(got.Data, magicSamples["audio/wav"]) {
+		t.Errorf("AttachmentFromReader() = %q/%d bytes", got.MIMEType, len(got.Data))
+	}
+
+	path := filepath.Join(t.TempDir(), "doc.pdf")
+	if err := os.WriteFile(path, magicSamples["application/pdf"], 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err = AttachmentFromFile(path)
+	if err != nil {
+		t.Fatalf("AttachmentFromFile() error = %v", err)
+	}
+	if got.MIMEType != "application/pdf" {
+		t.Errorf("AttachmentFromFile() MIMEType = %q, want application/pdf", got.MIMEType)
+	}
+}
@@ -22,6 +22,8 @@ var (
 	_ Generator        = (*Client)(nil)
 	_ Model            = (*Client)(nil)
 	_ VideoGenerator   = (*Client)(nil)
+	_ LiveConnector    = (*Client)(nil)
+	_ MediaDownloader  = (*Client)(nil)
 )
 
 // Client は Gemini SDK をラップしたメイン構造体です。
@@ -29,13 +31,18 @@ type Client struct {
 	modelClient         modelClient
 	fileClient          fileClient
 	videoClient         videoClient
+	liveClient          liveClient
+	media               *mediaDownloader
+	operations          *operationsClient
 	backend             genai.Backend
 	retryOpts           []retry.Option
 	logger              *slog.Logger
 	requestTimeout      time.Duration
 	filePollingInterval time.Duration
+	filePollStrategy    PollStrategy // nil なら filePollingInterval の固定間隔
 	filePollingTimeout  time.Duration
 	asyncCleanupTimeout time.Duration
+	validateVideo       bool
 }
 
 // runWithRetry は共通のリトライ設定を適用して操作を実行します。
@@ -62,17 +69,23 @@ func NewClient(ctx context.Context, cfg Config) (*Client, error) {
 		return nil, fmt.Errorf("gemini: クライアントの作成に失敗しました: %w", err)
 	}
 
+	media := newMediaDownloader(cfg, clientCfg)
 	return &Client{
 		modelClient:         genAIModelClient{models: client.Models},
 		fileClient:          genAIFileClient{files: client.Files},
 		videoClient:         genAIVideoClient{models: client.Models, operations: client.Operations},
+		liveClient:          genAILiveClient{live: client.Live},
+		media:               media,
+		operations:          newOperationsClient(cfg, clientCfg, media.authorized),
 		backend:             clientCfg.Backend,
 		retryOpts:           cfg.buildRetryOptions(),
 		logger:              cfg.getLogger(),
 		requestTimeout:      cfg.RequestTimeout,
 		filePollingInterval: cfg.getFilePollingInterval(),
+		filePollStrategy:    cfg.FilePollStrategy,
 		filePollingTimeout:  cfg.getFilePollingTimeout(),
 		asyncCleanupTimeout: cfg.getAsyncCleanupTimeout(),
+		validateVideo:       cfg.ValidateVideoRequests,
 	}, nil
 }
 
@@ -206,6 +219,43 @@ func applyImageConfig(genConfig *genai.GenerateContentConfig, opts GenerateOptio
 	}
 }
 
+// applySpeechConfig は、音声合成 (TTS) の声の設定を適用します。
+// 声の指定は音声出力でしか意味を持たないため、モダリティが未指定なら AUDIO にします。
+func applySpeechConfig(genConfig *genai.GenerateContentConfig, opts GenerateOptions) {
+	speech := opts.Speech
+	if speech == nil {
+		return
+	}
+	// 声を指定しない（モデルのデフォルトの声の）読み上げでも音声を要求する。
+	if len(genConfig.ResponseModalities) == 0 {
+		genConfig.ResponseModalities = []string{"AUDIO"}
+	}
+	if speech.Voice == "" && len(speech.Speakers) == 0 && speech.LanguageCode == "" {
+		return
+	}
+	genConfig.SpeechConfig = &genai.SpeechConfig{LanguageCode: speech.LanguageCode}
+
+	// Speakers と Voice は排他。API は両方の同時指定を拒否するため、Speakers を優先する。
+	switch {
+	case len(speech.Speakers) > 0:
+		multi := &genai.MultiSpeakerVoiceConfig{}
+		for _, speaker := range speech.Speakers {
+			multi.SpeakerVoiceConfigs = append(multi.SpeakerVoiceConfigs, &genai.SpeakerVoiceConfig{
+				Speaker:     speaker.Speaker,
+				VoiceConfig: prebuiltVoice(speaker.Voice),
+			})
+		}
+		genConfig.SpeechConfig.MultiSpeakerVoiceConfig = multi
+	case speech.Voice != "":
+		genConfig.SpeechConfig.VoiceConfig = prebuiltVoice(speech.Voice)
+	}
+}
+
+// prebuiltVoice はプリセット音声名から genai の声の設定を作ります。
+func prebuiltVoice(name string) *genai.VoiceConfig {
+	return &genai.VoiceConfig{PrebuiltVoiceConfig: &genai.PrebuiltVoiceConfig{VoiceName: name}}
+}
+
 // buildGenerateConfig は GenerateOptions を genai の生成設定へ変換します。
 // Client のメソッドにしないのは、バックエンド判定（vertexAI）以外に Client の状態へ
 // 依存しないためで、クライアント無しでテストできます。
@@ -235,6 +285,7 @@ func buildGenerateConfig(opts GenerateOptions, vertexAI bool) (*genai.GenerateCo
 		}
 	}
 	applyImageConfig(genConfig, opts, vertexAI)
+	applySpeechConfig(genConfig, opts)
 
 	return genConfig, nil
 }
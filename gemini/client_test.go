@@ -292,6 +292,71 @@ func TestBuildGenerateConfig_AudioResponseMIMETypeSetsModalities(t *testing.T) {
 	}
 }
 
+func TestBuildGenerateConfig_AppliesSingleSpeakerVoice(t *testing.T) {
+	got, err := buildGenerateConfig(GenerateOptions{
+		Speech: &SpeechOptions{Voice: "Kore", LanguageCode: "ja-JP"},
+	}, false)
+	if err != nil {
+		t.Fatalf("buildGenerateConfig() unexpected error = %v", err)
+	}
+	if got.SpeechConfig == nil || got.SpeechConfig.LanguageCode != "ja-JP" {
+		t.Fatalf("SpeechConfig = %+v, want language ja-JP", got.SpeechConfig)
+	}
+	if voice := got.SpeechConfig.VoiceConfig; voice == nil || voice.PrebuiltVoiceConfig.VoiceName != "Kore" {
+		t.Fatalf("VoiceConfig = %+v, want Kore", voice)
+	}
+	if len(got.ResponseModalities) != 1 || got.ResponseModalities[0] != "AUDIO" {
+		t.Fatalf("ResponseModalities = %v, want [AUDIO]", got.ResponseModalities)
+	}
+}
+
+// TestBuildGenerateConfig_SpeakersTakePrecedenceOverVoice verifies only one of the mutually exclusive
+// voice settings is sent. The API rejects a request carrying both.
+func TestBuildGenerateConfig_SpeakersTakePrecedenceOverVoice(t *testing.T) {
+	got, err := buildGenerateConfig(GenerateOptions{
+		Speech: &SpeechOptions{
+			Voice:    "Kore",
+			Speakers: []SpeakerVoice{{Speaker: "Joe", Voice: "Puck"}, {Speaker: "Jane", Voice: "Kore"}},
+		},
+	}, false)
+	if err != nil {
+		t.Fatalf("buildGenerateConfig() unexpected error = %v", err)
+	}
+	if got.SpeechConfig.VoiceConfig != nil {
+		t.Errorf("VoiceConfig = %+v, want nil when Speakers is set", got.SpeechConfig.VoiceConfig)
+	}
+	multi := got.SpeechConfig.MultiSpeakerVoiceConfig
+	if multi == nil || len(multi.SpeakerVoiceConfigs) != 2 || multi.SpeakerVoiceConfigs[0].Speaker != "Joe" ||
+		multi.SpeakerVoiceConfigs[0].VoiceConfig.PrebuiltVoiceConfig.VoiceName != "Puck" {
+		t.Fatalf("MultiSpeakerVoiceConfig = %+v", multi)
+	}
+}
+
+// TestBuildGenerateConfig_EmptySpeechRequestsDefaultVoice verifies an empty speech setting still asks
+// for audio, while leaving the voice to the model.
+func TestBuildGenerateConfig_EmptySpeechRequestsDefaultVoice(t *testing.T) {
+	got, err := buildGenerateConfig(GenerateOptions{Speech: &SpeechOptions{}}, false)
+	if err != nil {
+		t.Fatalf("buildGenerateConfig() unexpected error = %v", err)
+	}
+	if got.SpeechConfig != nil {
+		t.Errorf("SpeechConfig = %+v, want nil for the default voice", got.SpeechConfig)
+	}
+	if len(got.ResponseModalities) != 1 || got.ResponseModalities[0] != "AUDIO" {
+		t.Fatalf("ResponseModalities = %v, want [AUDIO]", got.ResponseModalities)
+	}
+}
+
+func TestBuildGenerateConfig_NilSpeechIsNotSent(t *testing.T) {
+	got, err := buildGenerateConfig(GenerateOptions{}, false)
+	if err != nil {
+		t.Fatalf("buildGenerateConfig() unexpected error = %v", err)
+	}
+	if got.SpeechConfig != nil || len(got.ResponseModalities) != 0 {
+		t.Fatalf("SpeechConfig = %+v, modalities = %v; want nothing sent", got.SpeechConfig, got.ResponseModalities)
+	}
+}
+
 func TestGenerateParts_AudioOnlyResponse(t *testing.T) {
 	ctx := context.Background()
 	fake := &fakeModelClient{
@@ -33,6 +33,18 @@ type Config struct {
 	FilePollingInterval time.Duration
 	FilePollingTimeout  time.Duration
 
+	// FilePollStrategy は、File API のアップロード後に処理の完了を確認する間隔の決め方です。
+	// nil の場合は FilePollingInterval の固定間隔です。小さなファイルは1秒足らずで
+	// 使えるようになることが多いため、ExponentialPolling(250*time.Millisecond, 2*time.Second, 2)
+	// のように短く始めると待ちが減ります。最初の確認はアップロード直後に間隔を待たずに行います。
+	FilePollStrategy PollStrategy
+
+	// ValidateVideoRequests を true にすると、StartVideo が送信前に
+	// VideoRequest.ValidateFor でモデルの能力表と照らし、受け付けられない値を
+	// ErrInvalidVideoInput（*VideoFieldError）で返します。能力表が実際の API より古い
+	// 場合に正しいリクエストまで弾かないよう、既定では無効です。
+	ValidateVideoRequests bool
+
 	// RequestTimeout は、生成呼び出し1回（リトライを含む）の上限時間です。
 	// 0 は無制限で、呼び出し側の context の期限にのみ従います。
 	// File API のアップロード待ちとポーリングには適用されません
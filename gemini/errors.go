@@ -3,6 +3,7 @@ package gemini
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"google.golang.org/genai"
 )
@@ -26,6 +27,12 @@ var (
 	// ErrInvalidAttachment は、添付の指定が不正な場合に返されます。
 	// Data と URI の併用、および Data に MIME type が無い場合が該当します。
 	ErrInvalidAttachment = errors.New("gemini: invalid attachment")
+	// ErrMIMETypeMismatch は、添付の MIME type の申告がデータの中身と食い違う場合に
+	// 返されます（PNG のバイト列を image/jpeg として送ろうとした場合など）。
+	ErrMIMETypeMismatch = errors.New("gemini: MIME type does not match attachment data")
+	// ErrUnsupportedMIMEType は、添付の MIME type が入力の種別（画像・音声など）で
+	// 受け付けられないものだった場合に返されます。
+	ErrUnsupportedMIMEType = errors.New("gemini: MIME type is not supported for the modality")
 	// ErrEmptyOperationName は、オペレーション名が空の場合に返されます。
 	ErrEmptyOperationName = errors.New("gemini: operation name is empty")
 	// ErrInvalidVideoInput は、動画生成の入力の組み合わせが API の受け付けない
@@ -38,6 +45,15 @@ var (
 // VideoOperation.Failure に載る形で返されます。
 var ErrVideoGenerationFailed = errors.New("gemini: video generation failed")
 
+// ErrVideoCancelled は、動画生成のオペレーションが取り消されて完了したことを示します
+// （CancelVideo による取り消しなど）。失敗とは区別するため ErrVideoGenerationFailed は
+// 含みません。VideoOperation.Failure に載る形で返されます。
+var ErrVideoCancelled = errors.New("gemini: video generation cancelled")
+
+// ErrUnsupported は、バックエンドがその操作に対応していない場合に返されます
+// （オペレーションの取り消し・削除を受け付けないバックエンドでの CancelVideo / DeleteVideo など）。
+var ErrUnsupported = errors.New("gemini: operation not supported by the backend")
+
 // API との通信は成功したが、レスポンス内容が利用できない場合のセンチネルエラー。
 // いずれもリトライでは解決しないため shouldRetry は false を返します。
 var (
@@ -118,3 +134,82 @@ func newEmptyResponseError() *APIResponseError {
 		Message: "Gemini APIから空のレスポンスが返されました",
 	}
 }
+
+// VideoFailureError は、動画生成のオペレーションが失敗（または取り消し）として完了した
+// 理由です。VideoOperation.Failure に載ります。
+//
+// オペレーションの error（google.rpc.Status）をそのまま構造で持つので、文字列を
+// 解析せずにコードで分岐できます。errors.Is では ErrVideoGenerationFailed（取り消しの
+// 場合は ErrVideoCancelled）と一致します。
+//
+//	if failure, ok := errors.AsType[*gemini.VideoFailureError](err); ok {
+//	    switch failure.Status {
+//	    case "RESOURCE_EXHAUSTED": // クォータ。時間を置いて再投函する
+//	    case "INVALID_ARGUMENT":   // 入力を直す
+//	    }
+//	}
+type VideoFailureError struct {
+	// Code は google.rpc.Code の数値です（3 = INVALID_ARGUMENT、8 = RESOURCE_EXHAUSTED など）。
+	// 返らなかった場合は 0 です。
+	Code int
+	// Status は Code の名前です（"INVALID_ARGUMENT" など）。レスポンスに無い場合は
+	// Code から補います。
+	Status string
+	// Message はバックエンドが返した説明です。
+	Message string
+	// Details は google.rpc.Status の details です（ErrorInfo など。構造は @type による）。
+	Details []map[string]any
+
+	// raw は既知のキーが1つも無かった場合に、元の内容をメッセージへ出すために保持します。
+	raw map[string]any
+}
+
+func (e *VideoFailureError) Error() string {
+	var parts []string
+	if e.Code != 0 {
+		parts = append(parts, fmt.Sprintf("code=%d", e.Code))
+	}
+	if e.Status != "" {
+		parts = append(parts, e.Status)
+	}
+	if e.Message != "" {
+		parts = append(parts, e.Message)
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("%v: %v", e.Unwrap(), e.raw)
+	}
+	return fmt.Sprintf("%v: %s", e.Unwrap(), strings.Join(parts, ": "))
+}
+
+// Unwrap は分類用のセンチネル（ErrVideoCancelled または ErrVideoGenerationFailed）を返します。
+func (e *VideoFailureError) Unwrap() error {
+	if e.Cancelled() {
+		return ErrVideoCancelled
+	}
+	return ErrVideoGenerationFailed
+}
+
+// Cancelled は、オペレーションが取り消されて完了したかを返します。
+func (e *VideoFailureError) Cancelled() bool {
+	return e.Code == 1 || e.Status == "CANCELLED"
+}
+
+// rpcStatusNames は google.rpc.Code の数値と名前の対応です。
+var rpcStatusNames = map[int]string{
+	1:  "CANCELLED",
+	2:  "UNKNOWN",
+	3:  "INVALID_ARGUMENT",
+	4:  "DEADLINE_EXCEEDED",
+	5:  "NOT_FOUND",
+	6:  "ALREADY_EXISTS",
+	7:  "PERMISSION_DENIED",
+	8:  "RESOURCE_EXHAUSTED",
+	9:  "FAILED_PRECONDITION",
+	10: "ABORTED",
+	11: "OUT_OF_RANGE",
+	12: "UNIMPLEMENTED",
+	13: "INTERNAL",
+	14: "UNAVAILABLE",
+	15: "DATA_LOSS",
+	16: "UNAUTHENTICATED",
+}
@@ -131,13 +131,18 @@ func (c *Client) waitForFileActive(ctx context.Context, fileName string) (string
 		return uri, err
 	}
 
-	ticker := time.NewTicker(c.filePollingInterval)
-	defer ticker.Stop()
+	strategy := c.filePollStrategy
+	if strategy == nil {
+		strategy = FixedPolling(c.filePollingInterval)
+	}
+
+	next := time.NewTimer(strategy.Next(1))
+	defer next.Stop()
 
 	timeout := time.NewTimer(c.filePollingTimeout)
 	defer timeout.Stop()
 
-	for {
+	for attempt := 2; ; attempt++ {
 		select {
 		case <-ctx.Done():
 			return "", fmt.Errorf("ファイル %q の待機中にコンテキストがキャンセルされました: %w", fileName, ctx.Err())
@@ -145,10 +150,11 @@ func (c *Client) waitForFileActive(ctx context.Context, fileName string) (string
 		case <-timeout.C:
 			return "", fmt.Errorf("ファイル %q の処理が制限時間（%v）内に完了しませんでした", fileName, c.filePollingTimeout)
 
-		case <-ticker.C:
+		case <-next.C:
 			if uri, finished, err := poll(); finished {
 				return uri, err
 			}
+			next.Reset(strategy.Next(attempt))
 		}
 	}
 }
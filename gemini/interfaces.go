@@ -22,15 +22,34 @@ type Generator interface {
 	GenerateWithAttachments(ctx context.Context, modelName string, prompt string, attachments []Attachment, opts GenerateOptions) (*Response, error)
 }
 
-// VideoGenerator は、動画生成の長時間実行オペレーションを開始し進捗を確認する、
-// 最小のインターフェースです。
+// VideoGenerator は、動画生成の長時間実行オペレーションを開始し、進捗の確認・取り消し・
+// 削除を行う、最小のインターフェースです。
 //
 // 完了までの待ち方（ポーリング間隔・タイムアウト・一時エラーの許容回数）は含めて
-// いません。それはこの2つを呼ぶループ側の方針であり、実装を差し替える理由にならない
+// いません。それはこれらを呼ぶループ側の方針であり、実装を差し替える理由にならない
 // ためです。veo パッケージがこのインターフェースを受け取ってループを組みます。
+// 取り消しや削除に対応しない実装は、CancelVideo / DeleteVideo で ErrUnsupported を返します。
 type VideoGenerator interface {
 	StartVideo(ctx context.Context, modelName string, req VideoRequest) (*VideoOperation, error)
 	PollVideo(ctx context.Context, operationName string) (*VideoOperation, error)
+	CancelVideo(ctx context.Context, operationName string) error
+	DeleteVideo(ctx context.Context, operationName string) error
+}
+
+// LiveConnector は、双方向のライブセッションを開く最小のインターフェースです。
+//
+// live パッケージはこれだけに依存します。再接続（セッションの再開）はこの接続を
+// 張り直すことで行うため、接続の確立そのものを差し替えられる形にしています。
+type LiveConnector interface {
+	ConnectLive(ctx context.Context, modelName string, cfg LiveConfig) (LiveConn, error)
+}
+
+// MediaDownloader は、生成結果の URI が指すメディアを読み出すインターフェースです。
+//
+// veo が URI で返った動画を保存するのに使います。URI の種類ごとの認証の付け方は
+// 実装（*Client）が持つため、利用側は URI を渡すだけで済みます。
+type MediaDownloader interface {
+	DownloadMedia(ctx context.Context, uri string) (*MediaStream, error)
 }
 
 // FileManager は、Gemini API で使用するファイルのアップロードおよび管理を担います。
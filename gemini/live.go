@@ -0,0 +1,320 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// LiveConfig は、ライブセッション（双方向のリアルタイム対話）の設定です。
+//
+// 接続時に1度だけ送られ、セッションの途中では変更できません。
+type LiveConfig struct {
+	// SystemPrompt はセッション全体に効く System instruction です。
+	SystemPrompt string
+	// ResponseAudio が true の場合は音声で、false の場合はテキストで応答します。
+	// ライブモデルは応答のモダリティを1つしか受け付けません。
+	ResponseAudio bool
+	// Voice は音声応答に使うプリセット音声の名前です。空の場合はモデルのデフォルトの声です。
+	Voice string
+	// LanguageCode は音声応答の言語のコード（"ja-JP" など）です。
+	LanguageCode string
+	// Temperature は出力のランダム性です。nil で SDK デフォルトです。
+	Temperature *float32
+	// Functions は、モデルが呼び出せる関数の宣言です。呼び出しは LiveMessage.FunctionCalls で届き、
+	// 結果は LiveConn.SendToolResponses で返します。
+	Functions []FunctionDeclaration
+	// InputTranscription が true の場合は、送った音声の文字起こしも受け取ります。
+	InputTranscription bool
+	// OutputTranscription が true の場合は、音声応答の文字起こしも受け取ります。
+	OutputTranscription bool
+	// Resumption が true の場合、サーバーはセッションを再開するためのハンドルを送ってきます
+	// （LiveMessage.ResumptionHandle）。
+	Resumption bool
+	// ResumptionHandle は、再開するセッションのハンドルです。設定すると Resumption も有効になります。
+	ResumptionHandle string
+}
+
+// FunctionDeclaration は、モデルに呼び出させる関数1つの宣言です。
+type FunctionDeclaration struct {
+	// Name は関数名です。
+	Name string
+	// Description は、モデルがいつ呼び出すべきかを判断するための説明です。
+	Description string
+	// Parameters は引数のスキーマです。引数を取らない場合は nil です。
+	Parameters *Schema
+}
+
+// FunctionCall は、モデルからの関数呼び出しの要求1つです。
+type FunctionCall struct {
+	// ID は呼び出しの識別子です。結果を返すときに FunctionResponse.ID へ設定します。
+	ID string
+	// Name は呼び出す関数名です。
+	Name string
+	// Args は引数です。
+	Args map[string]any
+}
+
+// FunctionResponse は、関数呼び出しの結果1つです。
+type FunctionResponse struct {
+	// ID は対応する FunctionCall.ID です。
+	ID string
+	// Name は呼び出された関数名です。
+	Name string
+	// Response は結果です。エラーを返す場合は "error" キーに入れるのが慣例です。
+	Response map[string]any
+}
+
+// LiveMessage は、ライブセッションでサーバーから届いたメッセージ1件です。
+//
+// 1件のメッセージには応答の断片・割り込み・関数呼び出しなどが同時に載り得るため、
+// genai の入れ子構造を平らにした形で持ちます。該当しないフィールドはゼロ値です。
+type LiveMessage struct {
+	// Text はモデルの応答テキストの断片です。
+	Text string
+	// Audio はモデルの応答音声の断片です。通常は "audio/pcm;rate=24000" の PCM です。
+	Audio []Attachment
+	// InputTranscript は、送った音声の文字起こしの断片です。
+	InputTranscript string
+	// OutputTranscript は、応答音声の文字起こしの断片です。
+	OutputTranscript string
+	// TurnComplete は、モデルの1ターンの応答が終わったことを示します。
+	TurnComplete bool
+	// GenerationComplete は、モデルが生成を終えたことを示します（再生が終わったとは限りません）。
+	GenerationComplete bool
+	// Interrupted は、ユーザーの発話などで応答が中断されたことを示します。
+	// クライアントは再生待ちの応答音声を捨てるべきです。
+	Interrupted bool
+	// FunctionCalls はモデルからの関数呼び出しの要求です。
+	FunctionCalls []FunctionCall
+	// CancelledCallIDs は、取り消された関数呼び出しの ID です。
+	CancelledCallIDs []string
+	// GoAway は、サーバーが間もなく接続を切ることを示します。
+	GoAway bool
+	// GoAwayTimeLeft は、接続が切られるまでの残り時間です。
+	GoAwayTimeLeft time.Duration
+	// ResumptionHandle は、この時点からセッションを再開するためのハンドルです。
+	// サーバーが再開可能と通知した場合だけ設定されます。
+	ResumptionHandle string
+	// Usage はトークン使用量です。
+	Usage *TokenUsage
+}
+
+// LiveConn は、接続済みのライブセッション1本です。
+//
+// 送信系のメソッドは並行して呼び出せます。Receive は1つのゴルーチンから呼び出してください。
+type LiveConn interface {
+	// SendText はテキストを1ターン分送ります。turnComplete が false の場合、
+	// モデルは続きの入力を待ちます。
+	SendText(text string, turnComplete bool) error
+	// SendAudio は音声の断片をリアルタイム入力として送ります。
+	// mimeType は "audio/pcm;rate=16000" のように PCM の形式を示します。
+	SendAudio(chunk []byte, mimeType string) error
+	// SendAudioStreamEnd は、音声入力が（一時的に）途切れたことを伝えます。
+	SendAudioStreamEnd() error
+	// SendToolResponses は関数呼び出しの結果を返します。
+	SendToolResponses(responses []FunctionResponse) error
+	// Receive は次のメッセージを待って返します。Close すると待機中の呼び出しはエラーで戻ります。
+	Receive() (*LiveMessage, error)
+	// Close は接続を閉じます。
+	Close() error
+}
+
+// ConnectLive は、モデルとの双方向のライブセッションを開きます。
+//
+// 接続の確立（セットアップの完了まで）に失敗した場合はエラーを返します。リトライは
+// しません。セッションの再開や割り込みの扱いは live パッケージが受け持ちます。
+//
+// genai の接続処理は ctx をダイヤルに使わないため、ctx のキャンセルで接続の確立を
+// 打ち切ることはできません（ハンドシェイクには genai 既定のタイムアウトが効きます）。
+func (c *Client) ConnectLive(ctx context.Context, modelName string, cfg LiveConfig) (LiveConn, error) {
+	if modelName == "" {
+		return nil, ErrEmptyModelName
+	}
+	session, err := c.liveClient.Connect(ctx, modelName, buildLiveConnectConfig(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("gemini: ライブセッションの接続に失敗しました（モデル: %s）: %w", modelName, err)
+	}
+	return &liveConn{session: session}, nil
+}
+
+// buildLiveConnectConfig は LiveConfig を genai の接続設定へ写します。
+func buildLiveConnectConfig(cfg LiveConfig) *genai.LiveConnectConfig {
+	connectCfg := &genai.LiveConnectConfig{
+		ResponseModalities: []genai.Modality{genai.ModalityText},
+		Temperature:        cfg.Temperature,
+	}
+	if cfg.ResponseAudio {
+		connectCfg.ResponseModalities = []genai.Modality{genai.ModalityAudio}
+	}
+	if cfg.SystemPrompt != "" {
+		connectCfg.SystemInstruction = &genai.Content{
+			Parts: []*genai.Part{{Text: cfg.SystemPrompt}},
+		}
+	}
+	if cfg.Voice != "" || cfg.LanguageCode != "" {
+		// 声の指定が無いときは VoiceConfig を送らず、モデル既定の声にする（applySpeechConfig と同じ）。
+		connectCfg.SpeechConfig = &genai.SpeechConfig{LanguageCode: cfg.LanguageCode}
+		if cfg.Voice != "" {
+			connectCfg.SpeechConfig.VoiceConfig = prebuiltVoice(cfg.Voice)
+		}
+	}
+	if len(cfg.Functions) > 0 {
+		declarations := make([]*genai.FunctionDeclaration, 0, len(cfg.Functions))
+		for _, fn := range cfg.Functions {
+			declarations = append(declarations, &genai.FunctionDeclaration{
+				Name:        fn.Name,
+				Description: fn.Description,
+				Parameters:  fn.Parameters,
+			})
+		}
+		connectCfg.Tools = []*genai.Tool{{FunctionDeclarations: declarations}}
+	}
+	if cfg.InputTranscription {
+		connectCfg.InputAudioTranscription = &genai.AudioTranscriptionConfig{}
+	}
+	if cfg.OutputTranscription {
+		connectCfg.OutputAudioTranscription = &genai.AudioTranscriptionConfig{}
+	}
+	if cfg.Resumption || cfg.ResumptionHandle != "" {
+		connectCfg.SessionResumption = &genai.SessionResumptionConfig{Handle: cfg.ResumptionHandle}
+	}
+	return connectCfg
+}
+
+// liveConn は genai のセッションを LiveConn として包みます。
+//
+// genai のセッションは WebSocket への書き込みを排他しておらず、同時に書くとフレームが
+// 壊れます。音声の送信と関数結果の返信は別のゴルーチンから来るのが普通なので、
+// 書き込みはここで直列化します。
+type liveConn struct {
+	session liveSession
+	writeMu sync.Mutex
+}
+
+func (c *liveConn) SendText(text string, turnComplete bool) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.session.SendClientContent(genai.LiveClientContentInput{
+		Turns:        []*genai.Content{genai.NewContentFromText(text, genai.RoleUser)},
+		TurnComplete: &turnComplete,
+	})
+}
+
+func (c *liveConn) SendAudio(chunk []byte, mimeType string) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.session.SendRealtimeInput(genai.LiveRealtimeInput{
+		Audio: &genai.Blob{MIMEType: mimeType, Data: chunk},
+	})
+}
+
+func (c *liveConn) SendAudioStreamEnd() error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.session.SendRealtimeInput(genai.LiveRealtimeInput{AudioStreamEnd: true})
+}
+
+func (c *liveConn) SendToolResponses(responses []FunctionResponse) error {
+	converted := make([]*genai.FunctionResponse, 0, len(responses))
+	for _, r := range responses {
+		converted = append(converted, &genai.FunctionResponse{ID: r.ID, Name: r.Name, Response: r.Response})
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.session.SendToolResponse(genai.LiveToolResponseInput{FunctionResponses: converted})
+}
+
+// Receive は次のメッセージを返します。セットアップ完了の通知など、利用側に伝える
+// 内容を持たないメッセージは読み飛ばします。
+func (c *liveConn) Receive() (*LiveMessage, error) {
+	for {
+		msg, err := c.session.Receive()
+		if err != nil {
+			return nil, err
+		}
+		if converted := liveMessageFromGenAI(msg); converted != nil {
+			return converted, nil
+		}
+	}
+}
+
+func (c *liveConn) Close() error {
+	return c.session.Close()
+}
+
+// liveMessageFromGenAI は genai のサーバーメッセージを公開型に変換します。
+// 利用側に伝える内容が無い場合は nil を返します。
+func liveMessageFromGenAI(msg *genai.LiveServerMessage) *LiveMessage {
+	if msg == nil {
+		return nil
+	}
+	out := &LiveMessage{}
+	empty := true
+
+	if content := msg.ServerContent; content != nil {
+		if content.ModelTurn != nil {
+			var text strings.Builder
+			for _, part := range content.ModelTurn.Parts {
+				if part == nil || part.Thought {
+					continue
+				}
+				text.WriteString(part.Text)
+				if part.InlineData != nil && len(part.InlineData.Data) > 0 {
+					out.Audio = append(out.Audio, Attachment{MIMEType: part.InlineData.MIMEType, Data: part.InlineData.Data})
+				}
+			}
+			out.Text = text.String()
+		}
+		if content.InputTranscription != nil {
+			out.InputTranscript = content.InputTranscription.Text
+		}
+		if content.OutputTranscription != nil {
+			out.OutputTranscript = content.OutputTranscription.Text
+		}
+		out.TurnComplete = content.TurnComplete
+		out.GenerationComplete = content.GenerationComplete
+		out.Interrupted = content.Interrupted
+		empty = out.Text == "" && len(out.Audio) == 0 && out.InputTranscript == "" && out.OutputTranscript == "" &&
+			!out.TurnComplete && !out.GenerationComplete && !out.Interrupted
+	}
+	if msg.ToolCall != nil {
+		for _, call := range msg.ToolCall.FunctionCalls {
+			if call == nil {
+				continue
+			}
+			out.FunctionCalls = append(out.FunctionCalls, FunctionCall{ID: call.ID, Name: call.Name, Args: call.Args})
+			empty = false
+		}
+	}
+	if msg.ToolCallCancellation != nil && len(msg.ToolCallCancellation.IDs) > 0 {
+		out.CancelledCallIDs = msg.ToolCallCancellation.IDs
+		empty = false
+	}
+	if msg.GoAway != nil {
+		out.GoAway = true
+		out.GoAwayTimeLeft = msg.GoAway.TimeLeft
+		empty = false
+	}
+	if update := msg.SessionResumptionUpdate; update != nil && update.Resumable && update.NewHandle != "" {
+		out.ResumptionHandle = update.NewHandle
+		empty = false
+	}
+	if meta := msg.UsageMetadata; meta != nil {
+		out.Usage = &TokenUsage{
+			PromptTokenCount:     meta.PromptTokenCount,
+			CandidatesTokenCount: meta.ResponseTokenCount,
+			TotalTokenCount:      meta.TotalTokenCount,
+			ThoughtsTokenCount:   meta.ThoughtsTokenCount,
+		}
+		empty = false
+	}
+	if empty {
+		return nil
+	}
+	return out
+}
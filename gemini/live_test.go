@@ -0,0 +1,281 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/genai"
+)
+
+// liveStandIn は Live API の WebSocket エンドポイントの代役です。
+// 受け取ったメッセージを received に流し、replies から来たメッセージをそのまま送り返します。
+type liveStandIn struct {
+	server   *httptest.Server
+	received chan map[string]any
+	replies  chan string
+}
+
+func newLiveStandIn(t *testing.T) *liveStandIn {
+	t.Helper()
+	s := &liveStandIn{
+		received: make(chan map[string]any, 16),
+		replies:  make(chan string, 16),
+	}
+	upgrader := websocket.Upgrader{}
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-goog-api-key") != "test-key" {
+			http.Error(w, "missing api key", http.StatusUnauthorized)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		go func() {
+			for reply := range s.replies {
+				if err := conn.WriteMessage(websocket.TextMessage, []byte(reply)); err != nil {
+					return
+				}
+			}
+		}()
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var msg map[string]any
+			if err := json.Unmarshal(data, &msg); err != nil {
+				t.Errorf("stand-in received invalid JSON: %s", data)
+				return
+			}
+			if _, ok := msg["setup"]; ok {
+				s.replies <- `{"setupComplete":{}}`
+			}
+			s.received <- msg
+		}
+	}))
+	t.Cleanup(func() {
+		s.server.Close()
+		close(s.replies)
+	})
+	return s
+}
+
+// client は代役へ接続する Client を作ります。genai は http(s) のベース URL を wss に
+// 読み替えるため、ws:// を明示して平文で接続させます。
+func (s *liveStandIn) client(t *testing.T) *Client {
+	t.Helper()
+	genaiClient, err := genai.NewClient(context.Background(), &genai.ClientConfig{
+		APIKey:      "test-key",
+		Backend:     genai.BackendGeminiAPI,
+		HTTPOptions: genai.HTTPOptions{BaseURL: "ws" + strings.TrimPrefix(s.server.URL, "http") + "/"},
+	})
+	if err != nil {
+		t.Fatalf("genai.NewClient() error = %v", err)
+	}
+	return &Client{liveClient: genAILiveClient{live: genaiClient.Live}}
+}
+
+func (s *liveStandIn) next(t *testing.T) map[string]any {
+	t.Helper()
+	select {
+	case msg := <-s.received:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("stand-in did not receive a message")
+		return nil
+	}
+}
+
+// field は入れ子の map を "a.b.0.c" の形のパスで辿ります。
+func field(v any, path string) any {
+	for key := range strings.SplitSeq(path, ".") {
+		switch node := v.(type) {
+		case map[string]any:
+			v = node[key]
+		case []any:
+			i := int(key[0] - '0')
+			if i >= len(node) {
+				return nil
+			}
+			v = node[i]
+		default:
+			return nil
+		}
+	}
+	return v
+}
+
+func TestConnectLive_AgainstWebSocketStandIn(t *testing.T) {
+	standIn := newLiveStandIn(t)
+	client := standIn.client(t)
+
+	conn, err := client.ConnectLive(context.Background(), "gemini-live", LiveConfig{
+		SystemPrompt:       "あなたは案内係です。",
+		ResponseAudio:      true,
+		Voice:              "Kore",
+		Functions:          []FunctionDeclaration{{Name: "lookup", Description: "在庫を調べる"}},
+		InputTranscription: true,
+		ResumptionHandle:   "handle-0",
+	})
+	if err != nil {
+		t.Fatalf("ConnectLive() error = %v", err)
+	}
+	defer conn.Close()
+
+	setup := standIn.next(t)
+	checks := map[string]any{
+		"setup.model": "models/gemini-live",
+		"setup.generationConfig.responseModalities.0":                                   "AUDIO",
+		"setup.generationConfig.speechConfig.voiceConfig.prebuiltVoiceConfig.voiceName": "Kore",
+		"setup.systemInstruction.parts.0.text":                                          "あなたは案内係です。",
+		"setup.tools.0.functionDeclarations.0.name":                                     "lookup",
+		"setup.sessionResumption.handle":                                                "handle-0",
+	}
+	for path, want := range checks {
+		if got := field(setup, path); got != want {
+			t.Errorf("%s = %v, want %v", path, got, want)
+		}
+	}
+	if field(setup, "setup.inputAudioTranscription") == nil {
+		t.Error("setup.inputAudioTranscription should be present")
+	}
+
+	if err := conn.SendText("こんにちは", true); err != nil {
+		t.Fatalf("SendText() error = %v", err)
+	}
+	sent := standIn.next(t)
+	if got := field(sent, "clientContent.turns.0.parts.0.text"); got != "こんにちは" {
+		t.Errorf("clientContent text = %v", got)
+	}
+	if got := field(sent, "clientContent.turnComplete"); got != true {
+		t.Errorf("clientContent.turnComplete = %v", got)
+	}
+
+	standIn.replies <- `{"serverContent":{"modelTurn":{"parts":[{"text":"いらっしゃい"},{"inlineData":{"mimeType":"audio/pcm;rate=24000","data":"AAEC"}}]},"outputTranscription":{"text":"いらっしゃい"}}}`
+	standIn.replies <- `{"toolCall":{"functionCalls":[{"id":"call-1","name":"lookup","args":{"item":"傘"}}]}}`
+	standIn.replies <- `{"sessionResumptionUpdate":{"newHandle":"handle-1","resumable":true}}`
+	standIn.replies <- `{"serverContent":{"interrupted":true}}`
+	standIn.replies <- `{"serverContent":{"turnComplete":true},"usageMetadata":{"promptTokenCount":3,"responseTokenCount":4,"totalTokenCount":7}}`
+
+	msg := receiveLive(t, conn)
+	if msg.Text != "いらっしゃい" || msg.OutputTranscript != "いらっしゃい" || len(msg.Audio) != 1 ||
+		msg.Audio[0].MIMEType != "audio/pcm;rate=24000" || string(msg.Audio[0].Data) != "\x00\x01\x02" {
+		t.Errorf("content message = %+v", msg)
+	}
+	msg = receiveLive(t, conn)
+	if len(msg.FunctionCalls) != 1 || msg.FunctionCalls[0].ID != "call-1" || msg.FunctionCalls[0].Args["item"] != "傘" {
+		t.Errorf("tool call message = %+v", msg)
+	}
+	if msg := receiveLive(t, conn); msg.ResumptionHandle != "handle-1" {
+		t.Errorf("resumption message = %+v", msg)
+	}
+	if msg := receiveLive(t, conn); !msg.Interrupted {
+		t.Errorf("interrupted message = %+v", msg)
+	}
+	msg = receiveLive(t, conn)
+	if !msg.TurnComplete || msg.Usage == nil || msg.Usage.CandidatesTokenCount != 4 || msg.Usage.TotalTokenCount != 7 {
+		t.Errorf("turn complete message = %+v (usage %+v)", msg, msg.Usage)
+	}
+
+	if err := conn.SendToolResponses([]FunctionResponse{{ID: "call-1", Name: "lookup", Response: map[string]any{"stock": 3}}}); err != nil {
+		t.Fatalf("SendToolResponses() error = %v", err)
+	}
+	if got := field(standIn.next(t), "toolResponse.functionResponses.0.id"); got != "call-1" {
+		t.Errorf("toolResponse id = %v", got)
+	}
+
+	if err := conn.SendAudio([]byte{1, 2}, "audio/pcm;rate=16000"); err != nil {
+		t.Fatalf("SendAudio() error = %v", err)
+	}
+	if got := field(standIn.next(t), "realtimeInput.audio.mimeType"); got != "audio/pcm;rate=16000" {
+		t.Errorf("realtimeInput.audio.mimeType = %v", got)
+	}
+}
+
+func receiveLive(t *testing.T, conn LiveConn) *LiveMessage {
+	t.Helper()
+	msg, err := conn.Receive()
+	if err != nil {
+		t.Fatalf("Receive() error = %v", err)
+	}
+	return msg
+}
+
+func TestConnectLive_ReceiveFailsAfterClose(t *testing.T) {
+	standIn := newLiveStandIn(t)
+	conn, err := standIn.client(t).ConnectLive(context.Background(), "gemini-live", LiveConfig{})
+	if err != nil {
+		t.Fatalf("ConnectLive() error = %v", err)
+	}
+	if got := field(standIn.next(t), "setup.generationConfig.responseModalities.0"); got != "TEXT" {
+		t.Errorf("responseModalities = %v, want TEXT by default", got)
+	}
+
+	if err := conn.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, err := conn.Receive(); err == nil {
+		t.Fatal("Receive() after Close should fail")
+	}
+}
+
+func TestConnectLive_Errors(t *testing.T) {
+	standIn := newLiveStandIn(t)
+	client := standIn.client(t)
+
+	if _, err := client.ConnectLive(context.Background(), "", LiveConfig{}); !errors.Is(err, ErrEmptyModelName) {
+		t.Errorf("empty model error = %v, want ErrEmptyModelName", err)
+	}
+
+	genaiClient, err := genai.NewClient(context.Background(), &genai.ClientConfig{
+		APIKey:      "wrong-key",
+		Backend:     genai.BackendGeminiAPI,
+		HTTPOptions: genai.HTTPOptions{BaseURL: "ws" + strings.TrimPrefix(standIn.server.URL, "http") + "/"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unauthorized := &Client{liveClient: genAILiveClient{live: genaiClient.Live}}
+	if _, err := unauthorized.ConnectLive(context.Background(), "gemini-live", LiveConfig{}); err == nil {
+		t.Error("ConnectLive() with a rejected handshake should fail")
+	}
+}
+
+func TestLiveMessageFromGenAI_SkipsEmptyMessages(t *testing.T) {
+	tests := []*genai.LiveServerMessage{
+		nil,
+		{SetupComplete: &genai.LiveServerSetupComplete{}},
+		{ServerContent: &genai.LiveServerContent{}},
+		{SessionResumptionUpdate: &genai.LiveServerSessionResumptionUpdate{NewHandle: "h", Resumable: false}},
+	}
+	for i, msg := range tests {
+		if got := liveMessageFromGenAI(msg); got != nil {
+			t.Errorf("case %d: liveMessageFromGenAI() = %+v, want nil", i, got)
+		}
+	}
+}
+
+func TestBuildLiveConnectConfig_LanguageOnlyKeepsDefaultVoice(t *testing.T) {
+	got := buildLiveConnectConfig(LiveConfig{ResponseAudio: true, LanguageCode: "ja-JP"})
+	if got.SpeechConfig == nil || got.SpeechConfig.LanguageCode != "ja-JP" {
+		t.Fatalf("SpeechConfig = %+v, want the language code", got.SpeechConfig)
+	}
+	if got.SpeechConfig.VoiceConfig != nil {
+		t.Errorf("VoiceConfig = %+v, want nil so the model's default voice is used", got.SpeechConfig.VoiceConfig)
+	}
+
+	got = buildLiveConnectConfig(LiveConfig{ResponseAudio: true, Voice: "Kore"})
+	if voice := got.SpeechConfig.VoiceConfig; voice == nil || voice.PrebuiltVoiceConfig.VoiceName != "Kore" {
+		t.Errorf("VoiceConfig = %+v, want Kore", voice)
+	}
+}
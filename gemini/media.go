@@ -0,0 +1,159 @@
+package gemini
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"google.golang.org/genai"
+)
+
+// ErrUnsupportedMediaURI は、DownloadMedia が取得方法を知らない URI が渡された場合に
+// 返されます（Gemini API バックエンドで gs:// を指定した場合など）。
+var ErrUnsupportedMediaURI = errors.New("gemini: unsupported media URI")
+
+const (
+	// filesEndpoint は Gemini API の File API のエンドポイントです。
+	filesEndpoint = "https://generativelanguage.googleapis.com"
+	// storageEndpoint は Cloud Storage の JSON API のエンドポイントです。
+	storageEndpoint = "https://storage.googleapis.com"
+)
+
+// MediaStream は DownloadMedia が返す、読み出し中のメディアです。
+// 読み終えたら Body を Close してください。
+type MediaStream struct {
+	// Body はメディアの中身です。
+	Body io.ReadCloser
+	// MIMEType はサーバーが申告した Content-Type です（パラメータは除きます）。
+	MIMEType string
+	// Size は中身のバイト数です。サーバーが申告しなかった場合は -1 です。
+	Size int64
+}
+
+// DownloadMedia は、生成結果の URI が指すメディアを認証付きで読み出します。
+//
+// 中身は読みながら届くので、長い動画でも全体をメモリに載せずにファイルへ書き出せます。
+// 扱える URI は次のとおりです。
+//
+//   - Gemini API の File API の URI（https://generativelanguage.googleapis.com/...、
+//     または "files/xxx"）: API キーを付けて取得します。Veo の結果はこの形で返ります。
+//   - gs:// （Vertex AI のみ）: Application Default Credentials で Cloud Storage から
+//     取得します。OutputGCSURI を指定した Veo の結果はこの形で返ります。
+//   - それ以外の https:// : 署名付き URL などとして、認証情報を付けずに取得します。
+func (c *Client) DownloadMedia(ctx context.Context, uri string) (*MediaStream, error) {
+	if c.media == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedMediaURI, uri)
+	}
+	return c.media.download(ctx, uri)
+}
+
+// mediaDownloader は DownloadMedia の HTTP 部分です。エンドポイントを差し替えられる
+// ようにしてあるのは、テストでローカルのサーバーへ向けるためです。
+type mediaDownloader struct {
+	vertex          bool
+	apiKey          string
+	httpClient      *http.Client
+	filesEndpoint   string
+	storageEndpoint string
+
+	// authorized は Cloud Storage 用の認証付きクライアントを返します。ADC の検出は
+	// 時間がかかり失敗もしうるため、gs:// を初めて取得するときまで遅らせます。
+	authorized func() (*http.Client, error)
+}
+
+// newMediaDownloader は、Config と genai に渡した設定から mediaDownloader を作ります。
+func newMediaDownloader(cfg Config, clientCfg *genai.ClientConfig) *mediaDownloader {
+	d := &mediaDownloader{
+		vertex:          clientCfg.Backend == genai.BackendVertexAI,
+		apiKey:          cfg.APIKey,
+		httpClient:      cmp.Or(cfg.HTTPClient, http.DefaultClient),
+		filesEndpoint:   filesEndpoint,
+		storageEndpoint: storageEndpoint,
+	}
+	if !d.vertex {
+		return d
+	}
+	if clientCfg.HTTPClient != nil {
+		// toClientConfig が認証を付け直した複製をそのまま使う。
+		authorized := clientCfg.HTTPClient
+		d.authorized = func() (*http.Client, error) { return authorized, nil }
+		return d
+	}
+	d.authorized = sync.OnceValues(func() (*http.Client, error) {
+		cc := &genai.ClientConfig{HTTPClient: &http.Client{}}
+		if err := cc.UseDefaultCredentials(); err != nil {
+			return nil, fmt.Errorf("gemini: Cloud Storage の認証情報を取得できません: %w", err)
+		}
+		return cc.HTTPClient, nil
+	})
+	return d
+}
+
+func (d *mediaDownloader) download(ctx context.Context, uri string) (*MediaStream, error) {
+	req, client, err := d.request(ctx, strings.TrimSpace(uri))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("gemini: メディア %s の取得に失敗しました: %w", uri, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("gemini: メディア %s の取得に失敗しました: HTTP %d: %s",
+			uri, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	mimeType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		mimeType = ""
+	}
+	return &MediaStream{Body: resp.Body, MIMEType: mimeType, Size: resp.ContentLength}, nil
+}
+
+// request は URI の種類に応じてリクエストと、それを送るクライアントを決めます。
+func (d *mediaDownloader) request(ctx context.Context, uri string) (*http.Request, *http.Client, error) {
+	switch {
+	case strings.HasPrefix(uri, "gs://"):
+		if !d.vertex || d.authorized == nil {
+			return nil, nil, fmt.Errorf("%w: gs:// は Vertex AI バックエンドでのみ取得できます: %s", ErrUnsupportedMediaURI, uri)
+		}
+		bucket, object, ok := strings.Cut(strings.TrimPrefix(uri, "gs://"), "/")
+		if bucket == "" || !ok || object == "" {
+			return nil, nil, fmt.Errorf("%w: %s", ErrUnsupportedMediaURI, uri)
+		}
+		client, err := d.authorized()
+		if err != nil {
+			return nil, nil, err
+		}
+		target := d.storageEndpoint + "/download/storage/v1/b/" + url.PathEscape(bucket) +
+			"/o/" + url.PathEscape(object) + "?alt=media"
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+		return req, client, err
+
+	case strings.HasPrefix(uri, "files/"):
+		return d.request(ctx, d.filesEndpoint+"/v1beta/"+uri+":download?alt=media")
+
+	case strings.HasPrefix(uri, "https://"), strings.HasPrefix(uri, "http://"):
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %s: %w", ErrUnsupportedMediaURI, uri, err)
+		}
+		// API キーは File API のホストにだけ送る。任意の URL へ送ると鍵が漏れる。
+		if files, err := url.Parse(d.filesEndpoint); err == nil && req.URL.Host == files.Host && d.apiKey != "" {
+			req.Header.Set("x-goog-api-key", d.apiKey)
+		}
+		return req, d.httpClient, nil
+
+	default:
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnsupportedMediaURI, uri)
+	}
+}
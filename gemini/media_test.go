@@ -0,0 +1,123 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// mediaServer は、受け取ったリクエストを記録して固定の動画を返すサーバーです。
+func mediaServer(t *testing.T) (*httptest.Server, *[]*http.Request) {
+	t.Helper()
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		if strings.Contains(r.URL.Path, "missing") {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "video/mp4; codecs=avc1")
+		_, _ = io.WriteString(w, "video-bytes")
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func readStream(t *testing.T, stream *MediaStream) string {
+	t.Helper()
+	defer stream.Body.Close()
+	data, err := io.ReadAll(stream.Body)
+	if err != nil {
+		t.Fatalf("reading stream: %v", err)
+	}
+	return string(data)
+}
+
+func TestDownloadMedia_GeminiAPIFiles(t *testing.T) {
+	files, filesRequests := mediaServer(t)
+	other, otherRequests := mediaServer(t)
+	client := &Client{media: &mediaDownloader{
+		apiKey:        "test-key",
+		httpClient:    http.DefaultClient,
+		filesEndpoint: files.URL,
+	}}
+	ctx := context.Background()
+
+	stream, err := client.DownloadMedia(ctx, "files/abc123")
+	if err != nil {
+		t.Fatalf("DownloadMedia(files/...) error = %v", err)
+	}
+	if got := readStream(t, stream); got != "video-bytes" {
+		t.Errorf("body = %q", got)
+	}
+	if stream.MIMEType != "video/mp4" || stream.Size != int64(len("video-bytes")) {
+		t.Errorf("stream = %+v, want the parsed content type and size", stream)
+	}
+	req := (*filesRequests)[0]
+	if req.URL.Path != "/v1beta/files/abc123:download" || req.URL.Query().Get("alt") != "media" {
+		t.Errorf("request URL = %s", req.URL)
+	}
+	if req.Header.Get("x-goog-api-key") != "test-key" {
+		t.Error("the API key should be sent to the File API host")
+	}
+
+	stream, err = client.DownloadMedia(ctx, other.URL+"/signed/video.mp4")
+	if err != nil {
+		t.Fatalf("DownloadMedia(other host) error = %v", err)
+	}
+	readStream(t, stream)
+	if (*otherRequests)[0].Header.Get("x-goog-api-key") != "" {
+		t.Error("the API key must not be sent to other hosts")
+	}
+
+	if _, err := client.DownloadMedia(ctx, "gs://bucket/video.mp4"); !errors.Is(err, ErrUnsupportedMediaURI) {
+		t.Errorf("gs:// on the Gemini API error = %v, want ErrUnsupportedMediaURI", err)
+	}
+	if _, err := client.DownloadMedia(ctx, "ftp://example.com/v.mp4"); !errors.Is(err, ErrUnsupportedMediaURI) {
+		t.Errorf("ftp:// error = %v, want ErrUnsupportedMediaURI", err)
+	}
+}
+
+func TestDownloadMedia_CloudStorageOnVertex(t *testing.T) {
+	storage, requests := mediaServer(t)
+	authorizedCalls := 0
+	client := &Client{media: &mediaDownloader{
+		vertex:          true,
+		httpClient:      http.DefaultClient,
+		storageEndpoint: storage.URL,
+		authorized: func() (*http.Client, error) {
+			authorizedCalls++
+			return storage.Client(), nil
+		},
+	}}
+
+	stream, err := client.DownloadMedia(context.Background(), "gs://my-bucket/renders/shot 1.mp4")
+	if err != nil {
+		t.Fatalf("DownloadMedia(gs://) error = %v", err)
+	}
+	readStream(t, stream)
+	if got := (*requests)[0].URL.EscapedPath(); got != "/download/storage/v1/b/my-bucket/o/renders%2Fshot%201.mp4" {
+		t.Errorf("request path = %s, want the object name escaped as one segment", got)
+	}
+	if authorizedCalls != 1 {
+		t.Errorf("authorized client calls = %d, want 1", authorizedCalls)
+	}
+
+	if _, err := client.DownloadMedia(context.Background(), "gs://bucket-only"); !errors.Is(err, ErrUnsupportedMediaURI) {
+		t.Errorf("gs:// without an object error = %v, want ErrUnsupportedMediaURI", err)
+	}
+}
+
+func TestDownloadMedia_ReportsHTTPErrors(t *testing.T) {
+	server, _ := mediaServer(t)
+	client := &Client{media: &mediaDownloader{httpClient: http.DefaultClient, filesEndpoint: server.URL}}
+
+	_, err := client.DownloadMedia(context.Background(), server.URL+"/missing.mp4")
+	if err == nil || !strings.Contains(err.Error(), "HTTP 404") {
+		t.Fatalf("DownloadMedia() error = %v, want the HTTP status", err)
+	}
+}
@@ -0,0 +1,320 @@
+package gemini
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Modality は、添付を受け取る入力の種別です。
+//
+// モデルが受け付ける MIME type は入力の種別ごとに決まっていて、画像として送った
+// 音声ファイルはサーバー側で分かりにくいエラーになります。ValidateFor に渡して、
+// 送信前にその差を検出するために使います。
+type Modality string
+
+// 添付の入力種別です。
+const (
+	// ModalityImage は画像入力です。
+	ModalityImage Modality = "image"
+	// ModalityAudio は音声入力です。
+	ModalityAudio Modality = "audio"
+	// ModalityVideo は動画入力です。
+	ModalityVideo Modality = "video"
+	// ModalityDocument は PDF やテキストなどの文書入力です。
+	ModalityDocument Modality = "document"
+)
+
+// supportedMIMETypes は、入力の種別ごとに Gemini が受け付ける MIME type です。
+// 値は canonicalMIMEType で正規化した表記で持ちます。
+var supportedMIMETypes = map[Modality][]string{
+	ModalityImage: {"image/png", "image/jpeg", "image/webp", "image/heic", "image/heif"},
+	ModalityAudio: {"audio/wav", "audio/mpeg", "audio/aiff", "audio/aac", "audio/ogg", "audio/flac", "audio/mp4"},
+	ModalityVideo: {
+		"video/mp4", "video/mpeg", "video/quicktime", "video/avi", "video/x-flv",
+		"video/webm", "video/wmv", "video/3gpp",
+	},
+	ModalityDocument: {"application/pdf", "text/plain", "text/html", "text/css", "text/csv", "text/xml", "text/markdown", "application/json"},
+}
+
+// SupportedMIMETypes は、指定した入力の種別で受け付ける MIME type の一覧を返します。
+// 未知の種別には nil を返します。返り値は複製なので、変更しても構いません。
+func SupportedMIMETypes(modality Modality) []string {
+	return slices.Clone(supportedMIMETypes[modality])
+}
+
+// mimeAliases は、同じ形式を指す別表記を正規の表記へ寄せる対応表です。
+//
+// 同じ WAV でも audio/wav・audio/x-wav・audio/wave と書き方が揺れるため、
+// 表記の違いだけで「対応外」や「中身と不一致」と判定しないよう比較の前に寄せます。
+var mimeAliases = map[string]string{
+	"image/jpg":         "image/jpeg",
+	"image/pjpeg":       "image/jpeg",
+	"audio/mp3":         "audio/mpeg",
+	"audio/mpeg3":       "audio/mpeg",
+	"audio/x-mpeg-3":    "audio/mpeg",
+	"audio/x-wav":       "audio/wav",
+	"audio/wave":        "audio/wav",
+	"audio/vnd.wave":    "audio/wav",
+	"audio/x-aiff":      "audio/aiff",
+	"audio/x-flac":      "audio/flac",
+	"audio/x-aac":       "audio/aac",
+	"audio/m4a":         "audio/mp4",
+	"audio/x-m4a":       "audio/mp4",
+	"video/mov":         "video/quicktime",
+	"video/x-msvideo":   "video/avi",
+	"video/msvideo":     "video/avi",
+	"video/x-ms-wmv":    "video/wmv",
+	"video/mpg":         "video/mpeg",
+	"application/x-pdf": "application/pdf",
+}
+
+// canonicalMIMEType は、パラメータ（"; charset=..." など）と大文字小文字の違いを
+// 取り除き、別表記を正規の表記へ寄せます。
+func canonicalMIMEType(mimeType string) string {
+	mediaType, _, _ := strings.Cut(mimeType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	if canonical, ok := mimeAliases[mediaType]; ok {
+		return canonical
+	}
+	return mediaType
+}
+
+// mimeContainerGroups は、コンテナ形式が同じで、先頭バイトだけでは区別できない
+// MIME type をまとめたものです。
+//
+// 例えば M4A 音声と MP4 動画はどちらも ISO BMFF（ftyp ボックス）で始まり、
+// 先頭バイトからは「MP4 コンテナである」ことしか分かりません。同じグループ内の
+// 申告は中身と矛盾しないものとして扱います。
+var mimeContainerGroups = map[string]string{
+	"video/mp4":        "isobmff",
+	"audio/mp4":        "isobmff",
+	"video/quicktime":  "isobmff",
+	"video/3gpp":       "isobmff",
+	"image/heic":       "heif",
+	"image/heif":       "heif",
+	"audio/ogg":        "ogg",
+	"video/ogg":        "ogg",
+	"application/ogg":  "ogg",
+	"video/webm":       "matroska",
+	"audio/webm":       "matroska",
+	"video/x-matroska": "matroska",
+}
+
+// sameMIMEFamily は、2つの MIME type が同じ形式（または区別できないコンテナ）を
+// 指しているかを判定します。
+func sameMIMEFamily(a, b string) bool {
+	a, b = canonicalMIMEType(a), canonicalMIMEType(b)
+	if a == b {
+		return true
+	}
+	groupA, okA := mimeContainerGroups[a]
+	groupB, okB := mimeContainerGroups[b]
+	return okA && okB && groupA == groupB
+}
+
+// DetectMIMEType は、データの先頭バイトから MIME type を判定します。
+// 判定できない場合は空文字列を返します。
+//
+// 画像・音声・動画・PDF はマジックバイトで判定し、それ以外は net/http の
+// DetectContentType（テキストや HTML の判定）にフォールバックします。
+func DetectMIMEType(data []byte) string {
+	if mimeType := sniffMagic(data); mimeType != "" {
+		return mimeType
+	}
+	if len(data) == 0 {
+		return ""
+	}
+	detected := http.DetectContentType(data)
+	if detected == "application/octet-stream" {
+		return ""
+	}
+	return canonicalMIMEType(detected)
+}
+
+// sniffMagic は、マジックバイトという確実な手掛かりがある形式だけを判定します。
+//
+// 申告との不一致の検出にはこちらだけを使います。DetectContentType のテキスト判定は
+// 「制御文字を含まない」程度の弱い推測で、短いバイナリ断片もテキスト扱いになるため、
+// それを根拠に呼び出し側の申告を否定すると誤検出になります。
+func sniffMagic(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return "image/png"
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return "image/jpeg"
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return "image/gif"
+	case bytes.HasPrefix(data, []byte("%PDF-")):
+		return "application/pdf"
+	case bytes.HasPrefix(data, []byte("ID3")):
+		return "audio/mpeg"
+	case bytes.HasPrefix(data, []byte("fLaC")):
+		return "audio/flac"
+	case bytes.HasPrefix(data, []byte("OggS")):
+		return "audio/ogg"
+	case bytes.HasPrefix(data, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return "video/webm"
+	case bytes.HasPrefix(data, []byte("FLV")):
+		return "video/x-flv"
+	case bytes.HasPrefix(data, []byte{0x00, 0x00, 0x01, 0xBA}), bytes.HasPrefix(data, []byte{0x00, 0x00, 0x01, 0xB3}):
+		return "video/mpeg"
+	case bytes.HasPrefix(data, []byte{0x30, 0x26, 0xB2, 0x75, 0x8E, 0x66, 0xCF, 0x11}):
+		return "video/wmv"
+	}
+	if mimeType := sniffChunked(data); mimeType != "" {
+		return mimeType
+	}
+	if mimeType := sniffISOBMFF(data); mimeType != "" {
+		return mimeType
+	}
+	return sniffMPEGAudioFrame(data)
+}
+
+// sniffChunked は、RIFF / FORM のチャンク形式（WAV・WebP・AVI・AIFF）を判定します。
+// 先頭4バイトは共通で、形式は8バイト目からの識別子で決まります。
+func sniffChunked(data []byte) string {
+	if len(data) < 12 {
+		return ""
+	}
+	container, form := string(data[:4]), string(data[8:12])
+	switch {
+	case container == "RIFF" && form == "WAVE":
+		return "audio/wav"
+	case container == "RIFF" && form == "WEBP":
+		return "image/webp"
+	case container == "RIFF" && form == "AVI ":
+		return "video/avi"
+	case container == "FORM" && (form == "AIFF" || form == "AIFC"):
+		return "audio/aiff"
+	}
+	return ""
+}
+
+// sniffISOBMFF は、ftyp ボックスで始まる ISO BMFF 系の形式を major brand で判定します。
+// HEIC 画像・M4A 音声・MOV・MP4 はいずれもこの形で、コンテナだけでは区別できません。
+func sniffISOBMFF(data []byte) string {
+	if len(data) < 12 || string(data[4:8]) != "ftyp" {
+		return ""
+	}
+	brand := string(data[8:12])
+	switch brand {
+	case "heic", "heix", "hevc", "hevx", "heim", "heis":
+		return "image/heic"
+	case "mif1", "msf1", "heif":
+		return "image/heif"
+	case "avif", "avis":
+		return "image/avif"
+	case "M4A ", "M4B ", "M4P ":
+		return "audio/mp4"
+	case "qt  ":
+		return "video/quicktime"
+	}
+	if strings.HasPrefix(brand, "3gp") || strings.HasPrefix(brand, "3g2") {
+		return "video/3gpp"
+	}
+	return "video/mp4"
+}
+
+// sniffMPEGAudioFrame は、ID3 タグの無い MP3 と ADTS 形式の AAC をフレームヘッダから
+// 判定します。どちらも先頭がフレーム同期のビット列で、レイヤ番号が 0 なら AAC です。
+//
+// 同期のビット列だけでは、0xFFF0 のような値で始まる生の PCM なども当たってしまうため、
+// ヘッダの他のフィールド（MP3 はバージョン・レイヤ・ビットレート・サンプリング周波数の
+// 番号、AAC はサンプリング周波数の番号とフレーム長）が有効な値のときだけ判定します。
+func sniffMPEGAudioFrame(data []byte) string {
+	if len(data) < 4 || data[0] != 0xFF || data[1]&0xE0 != 0xE0 {
+		return ""
+	}
+	if layer := (data[1] >> 1) & 0x03; layer == 0 {
+		return sniffADTSFrame(data)
+	}
+	version := (data[1] >> 3) & 0x03   // 1 は予約値
+	bitrateIndex := data[2] >> 4       // 0 は自由形式、15 は不正値
+	rateIndex := (data[2] >> 2) & 0x03 // 3 は予約値
+	if version == 1 || bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+		return ""
+	}
+	return "audio/mpeg"
+}
+
+// sniffADTSFrame は、ADTS のフレームヘッダ（7 バイト）の同期・サンプリング周波数の
+// 番号・フレーム長を確かめて AAC と判定します。
+func sniffADTSFrame(data []byte) string {
+	if len(data) < 7 || data[1]&0xF0 != 0xF0 {
+		return ""
+	}
+	rateIndex := (data[2] >> 2) & 0x0F // 13 以上は予約値
+	frameLength := int(data[3]&0x03)<<11 | int(data[4])<<3 | int(data[5])>>5
+	if rateIndex >= 13 || frameLength < 7 {
+		return ""
+	}
+	return "audio/aac"
+}
+
+// isRawAudioMIMEType は、ヘッダを持たない生の PCM を示す MIME type かを判定します
+// （"audio/pcm"・"audio/L16;rate=24000"・codec=pcm のパラメータ付きなど）。
+// 生の PCM はどんなバイト列でもありうるため、中身から形式を判定できません。
+func isRawAudioMIMEType(mimeType string) bool {
+	mediaType, params, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		mediaType = canonicalMIMEType(mimeType)
+	}
+	switch strings.ToLower(mediaType) {
+	case "audio/pcm", "audio/l16", "audio/l24", "audio/l8", "audio/raw", "audio/x-raw":
+		return true
+	}
+	return strings.EqualFold(params["codec"], "pcm")
+}
+
+// extensionMIMETypes は、拡張子から MIME type を引くための対応表です。
+//
+// mime.TypeByExtension は OS の mime.types に依存し、音声・動画の拡張子を知らない
+// 環境があるため、添付でよく使う形式はここで固定します。
+var extensionMIMETypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".webp": "image/webp",
+	".heic": "image/heic",
+	".heif": "image/heif",
+	".gif":  "image/gif",
+	".wav":  "audio/wav",
+	".mp3":  "audio/mpeg",
+	".aif":  "audio/aiff",
+	".aiff": "audio/aiff",
+	".aac":  "audio/aac",
+	".ogg":  "audio/ogg",
+	".flac": "audio/flac",
+	".m4a":  "audio/mp4",
+	".mp4":  "video/mp4",
+	".mpeg": "video/mpeg",
+	".mpg":  "video/mpeg",
+	".mov":  "video/quicktime",
+	".avi":  "video/avi",
+	".flv":  "video/x-flv",
+	".webm": "video/webm",
+	".wmv":  "video/wmv",
+	".3gp":  "video/3gpp",
+	".pdf":  "application/pdf",
+	".txt":  "text/plain",
+	".md":   "text/markdown",
+	".csv":  "text/csv",
+	".json": "application/json",
+}
+
+// mimeTypeByExtension は、ファイル名の拡張子から MIME type を推測します。
+// 推測できない場合は空文字列を返します。
+func mimeTypeByExtension(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext == "" {
+		return ""
+	}
+	if mimeType, ok := extensionMIMETypes[ext]; ok {
+		return mimeType
+	}
+	return canonicalMIMEType(mime.TypeByExtension(ext))
+}
@@ -0,0 +1,132 @@
+package gemini
+
+import "testing"
+
+// magicSamples は形式ごとの先頭バイトの最小例です。実ファイルを同梱せずに
+// マジックバイト判定を検証するため、判定に必要な先頭部分だけを持ちます。
+var magicSamples = map[string][]byte{
+	"image/png":       []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"),
+	"image/jpeg":      {0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10, 'J', 'F', 'I', 'F'},
+	"image/gif":       []byte("GIF89a\x01\x00\x01\x00"),
+	"image/webp":      []byte("RIFF\x24\x00\x00\x00WEBPVP8 "),
+	"image/heic":      []byte("\x00\x00\x00\x18ftypheic\x00\x00\x00\x00"),
+	"image/heif":      []byte("\x00\x00\x00\x18ftypmif1\x00\x00\x00\x00"),
+	"application/pdf": []byte("%PDF-1.7\n"),
+	"audio/mpeg":      []byte("ID3\x04\x00\x00\x00\x00\x00\x00"),
+	"audio/wav":       []byte("RIFF\x24\x00\x00\x00WAVEfmt "),
+	"audio/flac":      []byte("fLaC\x00\x00\x00\x22"),
+	"audio/ogg":       []byte("OggS\x00\x02\x00\x00"),
+	"audio/aiff":      []byte("FORM\x00\x00\x00\x00AIFFCOMM"),
+	"audio/aac":       {0xFF, 0xF1, 0x50, 0x80, 0x02, 0x1F, 0xFC},
+	"audio/mp4":       []byte("\x00\x00\x00\x20ftypM4A \x00\x00\x00\x00"),
+	"video/mp4":       []byte("\x00\x00\x00\x20ftypisom\x00\x00\x02\x00"),
+	"video/quicktime": []byte("\x00\x00\x00\x14ftypqt  \x00\x00\x00\x00"),
+	"video/webm":      {0x1A, 0x45, 0xDF, 0xA3, 0x9F, 0x42, 0x86},
+	"video/avi":       []byte("RIFF\x24\x00\x00\x00AVI LIST"),
+}
+
+func TestDetectMIMETypeRecognizesMagicBytes(t *testing.T) {
+	for want, data := range magicSamples {
+		t.Run(want, func(t *testing.T) {
+			if got := DetectMIMEType(data); got != want {
+				t.Errorf("DetectMIMEType() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+// TestDetectMIMETypeDetectsMP3WithoutID3 verifies a bare MPEG frame is still recognized. Many
+// encoders omit the ID3 tag, and those files would otherwise fall through to "unknown".
+func TestDetectMIMETypeDetectsMP3WithoutID3(t *testing.T) {
+	if got := DetectMIMEType([]byte{0xFF, 0xFB, 0x90, 0x64}); got != "audio/mpeg" {
+		t.Errorf("DetectMIMEType() = %q, want audio/mpeg", got)
+	}
+}
+
+// TestDetectMIMETypeRejectsBareFrameSync verifies that data matching only the frame sync bits
+// is not reported as MP3 or AAC. Raw PCM often starts with such sample values.
+func TestDetectMIMETypeRejectsBareFrameSync(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"ADTS sync without a frame length": {0xFF, 0xF0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+		"MPEG sync with a bad bitrate":     {0xFF, 0xFB, 0xF0, 0x00},
+		"reserved MPEG version":            {0xFF, 0xEB, 0x90, 0x00},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := sniffMagic(data); got != "" {
+				t.Errorf("sniffMagic() = %q, want empty", got)
+			}
+		})
+	}
+}
+
+func TestDetectMIMETypeFallsBackToText(t *testing.T) {
+	if got := DetectMIMEType([]byte("hello, world")); got != "text/plain" {
+		t.Errorf("DetectMIMEType() = %q, want text/plain (parameters stripped)", got)
+	}
+}
+
+func TestDetectMIMETypeReturnsEmptyForUnknown(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"nil":    nil,
+		"binary": {0x00, 0x01, 0x02, 0x03, 0xFE},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := DetectMIMEType(data); got != "" {
+				t.Errorf("DetectMIMEType() = %q, want empty", got)
+			}
+		})
+	}
+}
+
+// TestSameMIMEFamilyAcceptsAliasesAndSharedContainers verifies spelling variants and containers the
+// header cannot tell apart are not reported as mismatches. An M4A file sniffs as a generic MP4
+// container, and rejecting "audio/mp4" for it would be a false positive.
+func TestSameMIMEFamilyAcceptsAliasesAndSharedContainers(t *testing.T) {
+	tests := []struct {
+		declared, sniffed string
+		want              bool
+	}{
+		{"audio/x-wav", "audio/wav", true},
+		{"audio/mp3", "audio/mpeg", true},
+		{"IMAGE/JPG", "image/jpeg", true},
+		{"audio/mp4", "video/mp4", true},
+		{"image/heif", "image/heic", true},
+		{"text/plain; charset=utf-8", "text/plain", true},
+		{"image/jpeg", "image/png", false},
+		{"audio/mpeg", "audio/wav", false},
+	}
+	for _, tt := range tests {
+		if got := sameMIMEFamily(tt.declared, tt.sniffed); got != tt.want {
+			t.Errorf("sameMIMEFamily(%q, %q) = %v, want %v", tt.declared, tt.sniffed, got, tt.want)
+		}
+	}
+}
+
+func TestMIMETypeByExtension(t *testing.T) {
+	tests := map[string]string{
+		"voice.MP3":        "audio/mpeg",
+		"dir/photo.heic":   "image/heic",
+		"clip.mov":         "video/quicktime",
+		"no-extension":     "",
+		"archive.unknown1": "",
+	}
+	for name, want := range tests {
+		if got := mimeTypeByExtension(name); got != want {
+			t.Errorf("mimeTypeByExtension(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestSupportedMIMETypesReturnsCopy(t *testing.T) {
+	got := SupportedMIMETypes(ModalityImage)
+	if len(got) == 0 {
+		t.Fatal("SupportedMIMETypes(ModalityImage) is empty")
+	}
+	got[0] = "mutated"
+	if SupportedMIMETypes(ModalityImage)[0] == "mutated" {
+		t.Error("SupportedMIMETypes() must not expose the internal table")
+	}
+	if SupportedMIMETypes("hologram") != nil {
+		t.Error("SupportedMIMETypes(unknown) should be nil")
+	}
+}
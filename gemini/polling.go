@@ -0,0 +1,80 @@
+package gemini
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// PollStrategy は、状態確認を繰り返す待ちループが次の確認までどれだけ待つかを決めます。
+//
+// File API のアップロード待ち（Config.FilePollStrategy）と、veo の完了待ち
+// （veo.WithPollStrategy）で共通に使います。数秒で終わる処理と10分かかる処理とでは
+// 適した待ち方が違うため、固定間隔だけでなく、間隔を伸ばしていく方式も選べます。
+//
+// 実装は並行に呼び出されても安全である必要があります（1つの戦略を複数の待ちが共有します）。
+type PollStrategy interface {
+	// Next は、attempt 回目（1始まり）の確認で未完了だった後、次の確認までの待ち時間を返します。
+	Next(attempt int) time.Duration
+}
+
+// PollStrategyFunc は関数を PollStrategy として使うためのアダプタです。
+type PollStrategyFunc func(attempt int) time.Duration
+
+// Next は f(attempt) を返します。
+func (f PollStrategyFunc) Next(attempt int) time.Duration {
+	return f(attempt)
+}
+
+// FixedPolling は毎回同じ間隔で確認する戦略を返します。
+// interval がゼロ以下の場合は PollingInterval を使います。
+func FixedPolling(interval time.Duration) PollStrategy {
+	interval = orDefault(interval, PollingInterval)
+	return PollStrategyFunc(func(int) time.Duration { return interval })
+}
+
+// ExponentialPolling は、initial から始めて確認のたびに間隔を multiplier 倍に伸ばし、
+// maxInterval で頭打ちにする戦略を返します。
+//
+// すぐ終わることもあれば長くかかることもある処理向けです。序盤は細かく確認して
+// 早く終わった場合の待ちを減らし、長引いたら問い合わせの回数を抑えます。
+// initial がゼロ以下なら PollingInterval、maxInterval が initial 未満なら initial、
+// multiplier が 1 以下なら 2 を使います。
+func ExponentialPolling(initial, maxInterval time.Duration, multiplier float64) PollStrategy {
+	initial = orDefault(initial, PollingInterval)
+	maxInterval = max(maxInterval, initial)
+	if multiplier <= 1 || math.IsNaN(multiplier) {
+		multiplier = 2
+	}
+	return PollStrategyFunc(func(attempt int) time.Duration {
+		d := float64(initial) * math.Pow(multiplier, float64(max(attempt, 1)-1))
+		if d >= float64(maxInterval) {
+			return maxInterval
+		}
+		return time.Duration(d)
+	})
+}
+
+// JitteredPolling は、base の間隔を ±fraction の範囲でランダムに揺らす戦略を返します。
+//
+// 同時に投函した多数のジョブの確認が同じ瞬間に重なり、レート制限に当たるのを
+// 避けるためのものです。fraction は 0〜1 に丸めます（0 なら揺らしません）。
+// base が nil の場合は FixedPolling(PollingInterval) を使います。
+func JitteredPolling(base PollStrategy, fraction float64) PollStrategy {
+	if base == nil {
+		base = FixedPolling(PollingInterval)
+	}
+	if math.IsNaN(fraction) {
+		fraction = 0
+	}
+	fraction = min(max(fraction, 0), 1)
+	return PollStrategyFunc(func(attempt int) time.Duration {
+		d := base.Next(attempt)
+		if fraction == 0 || d <= 0 {
+			return d
+		}
+		// [1-fraction, 1+fraction) の係数を掛ける。
+		factor := 1 + fraction*(2*rand.Float64()-1)
+		return time.Duration(float64(d) * factor)
+	})
+}
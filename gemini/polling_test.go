@@ -0,0 +1,85 @@
+package gemini
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+func TestFixedPolling(t *testing.T) {
+	if got := FixedPolling(3 * time.Second).Next(7); got != 3*time.Second {
+		t.Errorf("Next() = %v, want 3s", got)
+	}
+	if got := FixedPolling(0).Next(1); got != PollingInterval {
+		t.Errorf("Next() with zero interval = %v, want PollingInterval", got)
+	}
+}
+
+func TestExponentialPolling(t *testing.T) {
+	strategy := ExponentialPolling(time.Second, 10*time.Second, 2)
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, w := range want {
+		if got := strategy.Next(i + 1); got != w {
+			t.Errorf("Next(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+	// 非常に大きな試行回数でも上限で止まり、オーバーフローしないこと。
+	if got := strategy.Next(10_000); got != 10*time.Second {
+		t.Errorf("Next(10000) = %v, want the cap", got)
+	}
+
+	fallback := ExponentialPolling(time.Second, 0, 0.5)
+	if got := fallback.Next(3); got != time.Second {
+		t.Errorf("cap below initial: Next(3) = %v, want initial", got)
+	}
+}
+
+func TestJitteredPolling(t *testing.T) {
+	strategy := JitteredPolling(FixedPolling(10*time.Second), 0.2)
+	varied := false
+	for range 200 {
+		got := strategy.Next(1)
+		if got < 8*time.Second || got > 12*time.Second {
+			t.Fatalf("Next() = %v, want within ±20%% of 10s", got)
+		}
+		varied = varied || got != 10*time.Second
+	}
+	if !varied {
+		t.Error("jittered delays never varied")
+	}
+	if got := JitteredPolling(FixedPolling(time.Second), 0).Next(1); got != time.Second {
+		t.Errorf("zero jitter Next() = %v, want unchanged", got)
+	}
+	if got := JitteredPolling(FixedPolling(time.Second), 5).Next(1); got < 0 || got > 2*time.Second {
+		t.Errorf("fraction should be clamped to 1, got %v", got)
+	}
+}
+
+func TestWaitForFileActive_UsesPollStrategy(t *testing.T) {
+	fake := &fakeFileClient{
+		getFiles: []*genai.File{
+			{Name: "test-file", State: genai.FileStateProcessing},
+			{Name: "test-file", State: genai.FileStateProcessing},
+			{Name: "test-file", URI: "https://example.com/file", State: genai.FileStateActive},
+		},
+	}
+	var attempts []int
+	client := &Client{
+		fileClient:          fake,
+		filePollingInterval: time.Hour, // 戦略が優先されること
+		filePollStrategy: PollStrategyFunc(func(attempt int) time.Duration {
+			attempts = append(attempts, attempt)
+			return time.Millisecond
+		}),
+		filePollingTimeout: time.Second,
+	}
+
+	if _, err := client.waitForFileActive(context.Background(), "test-file"); err != nil {
+		t.Fatalf("waitForFileActive() error = %v", err)
+	}
+	if len(attempts) != 2 || attempts[0] != 1 || attempts[1] != 2 {
+		t.Errorf("strategy attempts = %v, want [1 2]", attempts)
+	}
+}
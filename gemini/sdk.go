@@ -61,3 +61,30 @@ func (c genAIFileClient) Get(ctx context.Context, name string, config *genai.Get
 func (c genAIFileClient) Delete(ctx context.Context, name string, config *genai.DeleteFileConfig) (*genai.DeleteFileResponse, error) {
 	return c.files.Delete(ctx, name, config)
 }
+
+// liveClient はライブセッションの接続に使う genai の呼び出し面です。
+type liveClient interface {
+	Connect(ctx context.Context, model string, config *genai.LiveConnectConfig) (liveSession, error)
+}
+
+// liveSession は接続済みのライブセッションです。*genai.Session が満たします。
+type liveSession interface {
+	SendClientContent(input genai.LiveClientContentInput) error
+	SendRealtimeInput(input genai.LiveRealtimeInput) error
+	SendToolResponse(input genai.LiveToolResponseInput) error
+	Receive() (*genai.LiveServerMessage, error)
+	Close() error
+}
+
+type genAILiveClient struct {
+	live *genai.Live
+}
+
+func (c genAILiveClient) Connect(ctx context.Context, model string, config *genai.LiveConnectConfig) (liveSession, error) {
+	session, err := c.live.Connect(ctx, model, config)
+	if err != nil {
+		// nil の *genai.Session をインターフェースに包むと非 nil になるため、明示的に nil を返す。
+		return nil, err
+	}
+	return session, nil
+}
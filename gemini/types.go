@@ -84,6 +84,12 @@ type GenerateOptions struct {
 	Seed             *int64
 	PersonGeneration PersonGeneration
 
+	// --- 音声合成 (TTS) 特有のパラメータ ---
+
+	// Speech は音声合成の声の設定です。nil でなければレスポンスモダリティは AUDIO になります。
+	// 声も言語も無い空の設定は声の設定を送らず、モデルのデフォルトの声で読み上げます。
+	Speech *SpeechOptions
+
 	SafetySettings   []*genai.SafetySetting
 	ResponseMIMEType string
 	// ResponseSchema は構造化出力のスキーマです。ResponseMIMEType "application/json" と
@@ -99,6 +105,29 @@ type GenerateOptions struct {
 	ResponseJSONSchema any
 }
 
+// SpeechOptions は音声合成（TTS）の声の設定です。
+//
+// 単一話者なら Voice を、複数話者の対話なら Speakers を指定します。
+// Voice と Speakers は排他的な指定方法です。両方を設定した場合は Speakers を優先し、
+// Voice は送信しません（API が両方の同時指定を受け付けないため）。
+type SpeechOptions struct {
+	// Voice は単一話者で使うプリセット音声の名前です（"Kore"、"Puck" など）。
+	Voice string
+	// Speakers は、プロンプト中の話者名と声の対応です。
+	Speakers []SpeakerVoice
+	// LanguageCode は読み上げる言語のコード（"ja-JP" など）です。
+	// 空の場合はモデルがテキストから判定します。
+	LanguageCode string
+}
+
+// SpeakerVoice は、複数話者の音声合成における話者1人分の声の割り当てです。
+type SpeakerVoice struct {
+	// Speaker はプロンプト中の話者名です。台本の "Speaker: セリフ" の表記と一致させます。
+	Speaker string
+	// Voice はその話者に割り当てるプリセット音声の名前です。
+	Voice string
+}
+
 // Ptr は任意の値へのポインタを返すヘルパーです。
 // GenerateOptions のポインタ型フィールドにリテラルを設定する際に使用します。
 func Ptr[T any](v T) *T { return &v }
@@ -3,6 +3,8 @@ package gemini
 import (
 	"context"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
 
 	"google.golang.org/genai"
@@ -110,7 +112,11 @@ type VideoOperation struct {
 	// FilteredReasons は除外された理由です。
 	FilteredReasons []string
 	// Failure は生成が失敗した場合の理由です。成功時は nil です。
+	// 中身は *VideoFailureError で、取り消されて完了した場合は ErrVideoCancelled を含みます。
 	Failure error
+	// ProgressPercent は、オペレーションのメタデータに含まれていた進捗（0〜100）です。
+	// バックエンドやモデルによっては返らないため、その場合は nil です。
+	ProgressPercent *float64
 }
 
 // StartVideo は動画生成の長時間実行オペレーションを開始し、その時点の状態を返します。
@@ -118,6 +124,9 @@ type VideoOperation struct {
 // この呼び出しはオペレーションの投函までで、完了は待ちません。完了を待つには
 // 返された Name を PollVideo に渡してポーリングするか、veo パッケージを使ってください。
 //
+// Config.ValidateVideoRequests が有効な場合は、モデルの能力表に照らして受け付けられない
+// 値を送信前に弾きます（VideoRequest.ValidateFor 参照）。
+//
 // 投函自体は Config のリトライ設定に従って再送されます（レート制限や一時的な
 // サーバーエラーで1本分の生成が落ちるのを防ぐため）。ポーリング側は逆にリトライを
 // 挟みません（PollVideo のコメント参照）。
@@ -125,6 +134,11 @@ func (c *Client) StartVideo(ctx context.Context, modelName string, req VideoRequ
 	if modelName == "" {
 		return nil, ErrEmptyModelName
 	}
+	if c.validateVideo {
+		if err := req.ValidateFor(modelName, c); err != nil {
+			return nil, err
+		}
+	}
 	source, err := req.buildSource()
 	if err != nil {
 		return nil, err
@@ -281,9 +295,10 @@ func videoOperationFrom(op *genai.GenerateVideosOperation) (*VideoOperation, err
 	}
 
 	result := &VideoOperation{
-		Name:    op.Name,
-		Done:    op.Done,
-		Failure: videoOperationFailure(op.Error),
+		Name:            op.Name,
+		Done:            op.Done,
+		Failure:         videoOperationFailure(op.Error),
+		ProgressPercent: videoOperationProgress(op.Metadata),
 	}
 	if op.Response == nil {
 		return result, nil
@@ -304,27 +319,70 @@ func videoOperationFrom(op *genai.GenerateVideosOperation) (*VideoOperation, err
 	return result, nil
 }
 
-// videoOperationFailure は、オペレーションのエラー情報を error へ変換します。
+// videoOperationProgress は、オペレーションのメタデータから進捗の割合を拾います。
+//
+// メタデータの型はサービスごとに異なり genai も map[string]any のまま公開しているため、
+// 進捗を表すことが分かっているキーだけを見ます。数値は JSON 由来の float64 のほか、
+// 文字列で返る場合もあります。
+func videoOperationProgress(metadata map[string]any) *float64 {
+	for _, key := range []string{"progressPercent", "progressPercentage", "progress_percent"} {
+		var percent float64
+		switch v := metadata[key].(type) {
+		case float64:
+			percent = v
+		case int:
+			percent = float64(v)
+		case string:
+			parsed, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(v), "%"), 64)
+			if err != nil {
+				continue
+			}
+			percent = parsed
+		default:
+			continue
+		}
+		if math.IsNaN(percent) || percent < 0 || percent > 100 {
+			continue
+		}
+		return &percent
+	}
+	return nil
+}
+
+// videoOperationFailure は、オペレーションのエラー情報を *VideoFailureError へ変換します。
 //
 // genai はこのフィールドを map[string]any のまま公開しているため（google.rpc.Status
-// 相当の任意構造）、既知のキーだけ拾って読める文字列に整えます。
+// 相当の任意構造）、既知のキーだけ拾います。code は JSON 由来の float64 のほか、
+// 数値の文字列で返る場合も受け付けます。
 func videoOperationFailure(raw map[string]any) error {
 	if len(raw) == 0 {
 		return nil
 	}
 
-	var parts []string
-	if code, ok := raw["code"]; ok {
-		parts = append(parts, fmt.Sprintf("code=%v", code))
-	}
-	if status, ok := raw["status"].(string); ok && status != "" {
-		parts = append(parts, status)
+	failure := &VideoFailureError{raw: raw}
+	switch code := raw["code"].(type) {
+	case float64:
+		failure.Code = int(code)
+	case int:
+		failure.Code = code
+	case int32:
+		failure.Code = int(code)
+	case int64:
+		failure.Code = int(code)
+	case string:
+		failure.Code, _ = strconv.Atoi(code)
 	}
-	if message, ok := raw["message"].(string); ok && message != "" {
-		parts = append(parts, message)
+	failure.Status, _ = raw["status"].(string)
+	if failure.Status == "" {
+		failure.Status = rpcStatusNames[failure.Code]
 	}
-	if len(parts) == 0 {
-		return fmt.Errorf("%w: %v", ErrVideoGenerationFailed, raw)
+	failure.Message, _ = raw["message"].(string)
+	if details, ok := raw["details"].([]any); ok {
+		for _, detail := range details {
+			if m, ok := detail.(map[string]any); ok {
+				failure.Details = append(failure.Details, m)
+			}
+		}
 	}
-	return fmt.Errorf("%w: %s", ErrVideoGenerationFailed, strings.Join(parts, ": "))
+	return failure
 }
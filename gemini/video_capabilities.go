@@ -0,0 +1,325 @@
+package gemini
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// VideoCapabilities は、動画生成モデル1つが1つのバックエンドで受け付ける値の一覧です。
+//
+// 一覧系のフィールドは空なら検査しません（値を把握していない項目で誤って弾かないため）。
+// 真偽値のフィールドは false が「使えない」です。
+type VideoCapabilities struct {
+	// DurationsSec は DurationSec に指定できる秒数です。
+	DurationsSec []int
+	// AspectRatios は AspectRatio に指定できる縦横比です。
+	AspectRatios []string
+	// Resolutions は Resolution に指定できる解像度です。
+	Resolutions []string
+	// MaxVideos は NumberOfVideos の上限です。0 は検査しません。
+	MaxVideos int
+	// Audio は GenerateAudio を指定できるかです。
+	Audio bool
+	// ImageInput は Image（image-to-video）を指定できるかです。
+	ImageInput bool
+	// LastFrame は LastFrame（first/last frame 補間）を指定できるかです。
+	LastFrame bool
+	// Extension は Video（継続生成）を指定できるかです。
+	Extension bool
+	// MaxReferences は References の枚数の上限です。0 は参照画像を使えないことを表します。
+	MaxReferences int
+	// ReferenceTypes は References の Type に指定できる種別です。空なら検査しません。
+	ReferenceTypes []VideoReferenceType
+	// OutputGCSURI は OutputGCSURI を指定できるかです。
+	OutputGCSURI bool
+}
+
+// union は、a と b のどちらかで受け付けられる値をすべて受け付ける一覧を返します。
+// バックエンドが分からない場合の検証に使います。
+func (a VideoCapabilities) union(b VideoCapabilities) VideoCapabilities {
+	return VideoCapabilities{
+		DurationsSec:   unionOrUnchecked(a.DurationsSec, b.DurationsSec),
+		AspectRatios:   unionOrUnchecked(a.AspectRatios, b.AspectRatios),
+		Resolutions:    unionOrUnchecked(a.Resolutions, b.Resolutions),
+		MaxVideos:      maxOrUnchecked(a.MaxVideos, b.MaxVideos),
+		Audio:          a.Audio || b.Audio,
+		ImageInput:     a.ImageInput || b.ImageInput,
+		LastFrame:      a.LastFrame || b.LastFrame,
+		Extension:      a.Extension || b.Extension,
+		MaxReferences:  max(a.MaxReferences, b.MaxReferences),
+		ReferenceTypes: unionOrUnchecked(a.ReferenceTypes, b.ReferenceTypes),
+		OutputGCSURI:   a.OutputGCSURI || b.OutputGCSURI,
+	}
+}
+
+// unionOrUnchecked は2つの一覧の和を返します。どちらかが空（検査しない）なら空を返します。
+func unionOrUnchecked[T comparable](a, b []T) []T {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+	out := slices.Clone(a)
+	for _, v := range b {
+		if !slices.Contains(out, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func maxOrUnchecked(a, b int) int {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return max(a, b)
+}
+
+// videoModel は、モデル1つのバックエンドごとの受け付ける値です。
+type videoModel struct {
+	vertex    VideoCapabilities
+	geminiAPI VideoCapabilities
+}
+
+// videoModels はモデル名の接頭辞ごとの能力表です。
+//
+// 値は公開ドキュメントに基づく執筆時点のもので、プレビューのモデルは予告なく変わります。
+// 変わった場合や新しいモデルを使う場合は RegisterVideoCapabilities で上書きしてください。
+var videoModels = struct {
+	mu       sync.RWMutex
+	byPrefix map[string]videoModel
+}{byPrefix: map[string]videoModel{
+	"veo-2.0": {
+		vertex: VideoCapabilities{
+			DurationsSec: []int{5, 6, 7, 8},
+			AspectRatios: []string{"16:9", "9:16"},
+			Resolutions:  []string{"720p"},
+			MaxVideos:    4,
+			ImageInput:   true,
+			LastFrame:    true,
+			Extension:    true,
+			OutputGCSURI: true,
+		},
+		geminiAPI: VideoCapabilities{
+			DurationsSec: []int{5, 6, 7, 8},
+			AspectRatios: []string{"16:9", "9:16"},
+			Resolutions:  []string{"720p"},
+			MaxVideos:    2,
+			ImageInput:   true,
+		},
+	},
+	"veo-3.0": {
+		vertex: VideoCapabilities{
+			DurationsSec: []int{4, 6, 8},
+			AspectRatios: []string{"16:9", "9:16"},
+			Resolutions:  []string{"720p", "1080p"},
+			MaxVideos:    4,
+			Audio:        true,
+			ImageInput:   true,
+			OutputGCSURI: true,
+		},
+		geminiAPI: VideoCapabilities{
+			DurationsSec: []int{4, 6, 8},
+			AspectRatios: []string{"16:9", "9:16"},
+			Resolutions:  []string{"720p", "1080p"},
+			MaxVideos:    1,
+			ImageInput:   true,
+		},
+	},
+	"veo-3.1": {
+		vertex: VideoCapabilities{
+			DurationsSec:   []int{4, 6, 8},
+			AspectRatios:   []string{"16:9", "9:16"},
+			Resolutions:    []string{"720p", "1080p"},
+			MaxVideos:      4,
+			Audio:          true,
+			ImageInput:     true,
+			LastFrame:      true,
+			Extension:      true,
+			MaxReferences:  3,
+			ReferenceTypes: []VideoReferenceType{VideoReferenceAsset},
+			OutputGCSURI:   true,
+		},
+		geminiAPI: VideoCapabilities{
+			DurationsSec:   []int{4, 6, 8},
+			AspectRatios:   []string{"16:9", "9:16"},
+			Resolutions:    []string{"720p", "1080p"},
+			MaxVideos:      1,
+			ImageInput:     true,
+			LastFrame:      true,
+			Extension:      true,
+			MaxReferences:  3,
+			ReferenceTypes: []VideoReferenceType{VideoReferenceAsset},
+		},
+	},
+}}
+
+// RegisterVideoCapabilities は、modelPrefix で始まるモデルの能力表を登録します。
+// 既に登録がある接頭辞は置き換えます。モデル名に複数の接頭辞が当てはまる場合は
+// 最も長いものを使うため、"veo-3.1-fast" のように既存より細かい単位でも登録できます。
+func RegisterVideoCapabilities(modelPrefix string, vertex, geminiAPI VideoCapabilities) {
+	videoModels.mu.Lock()
+	defer videoModels.mu.Unlock()
+	videoModels.byPrefix[modelPrefix] = videoModel{vertex: vertex, geminiAPI: geminiAPI}
+}
+
+// LookupVideoCapabilities は、モデルがバックエンドで受け付ける値の一覧を返します。
+// 能力表に無いモデルの場合は false を返します。
+func LookupVideoCapabilities(modelName string, vertexAI bool) (VideoCapabilities, bool) {
+	model, ok := lookupVideoModel(modelName)
+	if !ok {
+		return VideoCapabilities{}, false
+	}
+	if vertexAI {
+		return model.vertex, true
+	}
+	return model.geminiAPI, true
+}
+
+func lookupVideoModel(modelName string) (videoModel, bool) {
+	name := modelName[strings.LastIndex(modelName, "/")+1:] // "models/..." や "publishers/google/models/..." を除く
+	videoModels.mu.RLock()
+	defer videoModels.mu.RUnlock()
+	var (
+		found videoModel
+		best  = -1
+	)
+	for prefix, model := range videoModels.byPrefix {
+		if strings.HasPrefix(name, prefix) && len(prefix) > best {
+			found, best = model, len(prefix)
+		}
+	}
+	return found, best >= 0
+}
+
+// VideoFieldError は、VideoRequest のフィールド1つがモデルに受け付けられない値だった
+// ことを表します。errors.Is で ErrInvalidVideoInput と比較できます。
+type VideoFieldError struct {
+	// Model は検証に使ったモデル名です。
+	Model string
+	// Field は不正だったフィールド名です（"DurationSec"、"References[1].Type" など）。
+	Field string
+	// Value は指定された値です。
+	Value any
+	// Allowed は指定できる値です。指定自体ができない場合は空です。
+	Allowed []string
+}
+
+func (e *VideoFieldError) Error() string {
+	if len(e.Allowed) == 0 {
+		return fmt.Sprintf("gemini: %s はモデル %s では指定できません（指定値: %v）", e.Field, e.Model, e.Value)
+	}
+	return fmt.Sprintf("gemini: %s=%v はモデル %s では使えません（使える値: %s）",
+		e.Field, e.Value, e.Model, strings.Join(e.Allowed, ", "))
+}
+
+func (e *VideoFieldError) Unwrap() error {
+	return ErrInvalidVideoInput
+}
+
+// Validate は、リクエストがモデルに受け付けられるかを送信前に確かめます。
+//
+// 入力の組み合わせ（StartVideo が常に行う検査）に加えて、DurationSec / AspectRatio /
+// Resolution / NumberOfVideos / GenerateAudio / 入力の種類 / References の枚数と種別を
+// 能力表と照らします。バックエンドを問わない検証のため、どちらかのバックエンドで
+// 受け付けられる値は通します。バックエンドごとの違いまで確かめるには ValidateFor を
+// 使ってください。
+//
+// 不正なフィールドはすべて *VideoFieldError として errors.Join でまとめて返します。
+// 能力表に無いモデルは、入力の組み合わせだけを検査します。
+func (r VideoRequest) Validate(modelName string) error {
+	if err := r.validateCombination(); err != nil {
+		return err
+	}
+	model, ok := lookupVideoModel(modelName)
+	if !ok {
+		return nil
+	}
+	return r.validateAgainst(modelName, model.vertex.union(model.geminiAPI))
+}
+
+// ValidateFor は、backend のバックエンドで使う前提で Validate と同じ検証を行います。
+// *Client をそのまま渡せます。backend が nil の場合は Validate と同じです。
+func (r VideoRequest) ValidateFor(modelName string, backend BackendInspector) error {
+	if backend == nil {
+		return r.Validate(modelName)
+	}
+	if err := r.validateCombination(); err != nil {
+		return err
+	}
+	caps, ok := LookupVideoCapabilities(modelName, backend.IsVertexAI())
+	if !ok {
+		return nil
+	}
+	return r.validateAgainst(modelName, caps)
+}
+
+// validateCombination は StartVideo が送信前に必ず行う検査（入力の組み合わせと Seed）です。
+func (r VideoRequest) validateCombination() error {
+	if _, err := r.buildSource(); err != nil {
+		return err
+	}
+	_, err := r.buildConfig()
+	return err
+}
+
+func (r VideoRequest) validateAgainst(modelName string, caps VideoCapabilities) error {
+	var errs []error
+	reject := func(field string, value any, allowed []string) {
+		errs = append(errs, &VideoFieldError{Model: modelName, Field: field, Value: value, Allowed: allowed})
+	}
+
+	if r.DurationSec > 0 && len(caps.DurationsSec) > 0 && !slices.Contains(caps.DurationsSec, r.DurationSec) {
+		allowed := make([]string, len(caps.DurationsSec))
+		for i, d := range caps.DurationsSec {
+			allowed[i] = strconv.Itoa(d)
+		}
+		reject("DurationSec", r.DurationSec, allowed)
+	}
+	if r.AspectRatio != "" && len(caps.AspectRatios) > 0 && !slices.Contains(caps.AspectRatios, r.AspectRatio) {
+		reject("AspectRatio", r.AspectRatio, caps.AspectRatios)
+	}
+	if r.Resolution != "" && len(caps.Resolutions) > 0 && !slices.Contains(caps.Resolutions, r.Resolution) {
+		reject("Resolution", r.Resolution, caps.Resolutions)
+	}
+	if r.NumberOfVideos > 0 && caps.MaxVideos > 0 && r.NumberOfVideos > caps.MaxVideos {
+		reject("NumberOfVideos", r.NumberOfVideos, []string{"1〜" + strconv.Itoa(caps.MaxVideos)})
+	}
+	if r.GenerateAudio != nil && !caps.Audio {
+		reject("GenerateAudio", *r.GenerateAudio, nil)
+	}
+	if r.OutputGCSURI != "" && !caps.OutputGCSURI {
+		reject("OutputGCSURI", r.OutputGCSURI, nil)
+	}
+	if r.Image != nil && !r.Image.IsEmpty() && !caps.ImageInput {
+		reject("Image", "(image)", nil)
+	}
+	if r.LastFrame != nil && !r.LastFrame.IsEmpty() && !caps.LastFrame {
+		reject("LastFrame", "(image)", nil)
+	}
+	if r.Video != nil && !r.Video.IsEmpty() && !caps.Extension {
+		reject("Video", "(video)", nil)
+	}
+	if n := len(r.References); n > 0 {
+		switch {
+		case caps.MaxReferences == 0:
+			reject("References", fmt.Sprintf("%d 枚", n), nil)
+		case n > caps.MaxReferences:
+			reject("References", fmt.Sprintf("%d 枚", n), []string{"1〜" + strconv.Itoa(caps.MaxReferences) + " 枚"})
+		}
+		if len(caps.ReferenceTypes) > 0 {
+			allowed := make([]string, len(caps.ReferenceTypes))
+			for i, t := range caps.ReferenceTypes {
+				allowed[i] = string(t)
+			}
+			for i, ref := range r.References {
+				if ref.Type != "" && !slices.Contains(caps.ReferenceTypes, ref.Type) {
+					reject(fmt.Sprintf("References[%d].Type", i), ref.Type, allowed)
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,150 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+type backendStub bool
+
+func (b backendStub) IsVertexAI() bool { return bool(b) }
+
+func TestVideoRequestValidate_ReportsEveryField(t *testing.T) {
+	audio := true
+	req := VideoRequest{
+		Prompt:         "p",
+		DurationSec:    10,
+		AspectRatio:    "1:1",
+		Resolution:     "4k",
+		NumberOfVideos: 5,
+		GenerateAudio:  &audio,
+	}
+	err := req.Validate("veo-2.0-generate-001")
+	if !errors.Is(err, ErrInvalidVideoInput) {
+		t.Fatalf("Validate() error = %v, want ErrInvalidVideoInput", err)
+	}
+	var fields []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		fieldErr, ok := errors.AsType[*VideoFieldError](e)
+		if !ok {
+			t.Fatalf("error %v is not a *VideoFieldError", e)
+		}
+		fields = append(fields, fieldErr.Field)
+	}
+	want := []string{"DurationSec", "AspectRatio", "Resolution", "NumberOfVideos", "GenerateAudio"}
+	if len(fields) != len(want) {
+		t.Fatalf("fields = %v, want %v", fields, want)
+	}
+	for i := range want {
+		if fields[i] != want[i] {
+			t.Errorf("fields[%d] = %s, want %s", i, fields[i], want[i])
+		}
+	}
+}
+
+func TestVideoRequestValidate_InputsAndReferences(t *testing.T) {
+	refs := func(n int, typ VideoReferenceType) []VideoReference {
+		out := make([]VideoReference, n)
+		for i := range out {
+			out[i] = VideoReference{Image: Attachment{URI: "gs://bucket/ref.png"}, Type: typ}
+		}
+		return out
+	}
+	tests := []struct {
+		name  string
+		model string
+		req   VideoRequest
+		field string
+	}{
+		{"extension on veo-3.0", "veo-3.0-generate-001", VideoRequest{Video: &Attachment{URI: "gs://bucket/v.mp4"}}, "Video"},
+		{"references on veo-3.0", "veo-3.0-generate-001", VideoRequest{Prompt: "p", References: refs(1, "")}, "References"},
+		{"too many references", "veo-3.1-generate-preview", VideoRequest{Prompt: "p", References: refs(4, VideoReferenceAsset)}, "References"},
+		{"style reference on veo-3.1", "veo-3.1-generate-preview", VideoRequest{Prompt: "p", References: refs(1, VideoReferenceStyle)}, "References[0].Type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate(tt.model)
+			fieldErr, ok := errors.AsType[*VideoFieldError](err)
+			if !ok || fieldErr.Field != tt.field {
+				t.Fatalf("Validate() error = %v, want a field error for %s", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestVideoRequestValidate_PassesValidAndUnknown(t *testing.T) {
+	req := VideoRequest{Prompt: "p", DurationSec: 8, AspectRatio: "16:9", Resolution: "1080p"}
+	if err := req.Validate("models/veo-3.1-fast-generate-preview"); err != nil {
+		t.Errorf("Validate(valid) error = %v", err)
+	}
+	if err := (VideoRequest{Prompt: "p", DurationSec: 30}).Validate("veo-9-experimental"); err != nil {
+		t.Errorf("Validate(unknown model) error = %v, want no capability checks", err)
+	}
+	if err := (VideoRequest{}).Validate("veo-3.1-generate-preview"); !errors.Is(err, ErrEmptyPrompt) {
+		t.Errorf("Validate(empty) error = %v, want the combination check to run", err)
+	}
+}
+
+func TestVideoRequestValidateFor_BackendDifferences(t *testing.T) {
+	audio := false
+	req := VideoRequest{Prompt: "p", NumberOfVideos: 4, GenerateAudio: &audio, OutputGCSURI: "gs://bucket/out/"}
+
+	if err := req.ValidateFor("veo-3.0-generate-001", backendStub(true)); err != nil {
+		t.Errorf("ValidateFor(Vertex AI) error = %v", err)
+	}
+	if err := req.Validate("veo-3.0-generate-001"); err != nil {
+		t.Errorf("Validate() error = %v, want values accepted by either backend to pass", err)
+	}
+	err := req.ValidateFor("veo-3.0-generate-001", backendStub(false))
+	for _, field := range []string{"NumberOfVideos", "GenerateAudio", "OutputGCSURI"} {
+		found := false
+		for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+			if fieldErr, ok := errors.AsType[*VideoFieldError](e); ok && fieldErr.Field == field {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("ValidateFor(Gemini API) error = %v, want a field error for %s", err, field)
+		}
+	}
+}
+
+func TestRegisterVideoCapabilities_LongestPrefixWins(t *testing.T) {
+	RegisterVideoCapabilities("veo-test-narrow", VideoCapabilities{DurationsSec: []int{2}}, VideoCapabilities{DurationsSec: []int{2}})
+	RegisterVideoCapabilities("veo-test", VideoCapabilities{DurationsSec: []int{9}}, VideoCapabilities{DurationsSec: []int{9}})
+	t.Cleanup(func() {
+		videoModels.mu.Lock()
+		defer videoModels.mu.Unlock()
+		delete(videoModels.byPrefix, "veo-test-narrow")
+		delete(videoModels.byPrefix, "veo-test")
+	})
+
+	caps, ok := LookupVideoCapabilities("veo-test-narrow-001", true)
+	if !ok || len(caps.DurationsSec) != 1 || caps.DurationsSec[0] != 2 {
+		t.Errorf("LookupVideoCapabilities() = %+v, %v; want the longest prefix", caps, ok)
+	}
+}
+
+func TestStartVideoEnforcesCapabilitiesWhenEnabled(t *testing.T) {
+	video := &fakeVideoClient{}
+	client := newVideoTestClient(video)
+	client.backend = genai.BackendVertexAI
+	req := VideoRequest{Prompt: "p", DurationSec: 10}
+
+	if _, err := client.StartVideo(context.Background(), "veo-3.1-generate-001", req); err != nil {
+		t.Fatalf("StartVideo() without enforcement error = %v", err)
+	}
+
+	client.validateVideo = true
+	video.calls = 0
+	_, err := client.StartVideo(context.Background(), "veo-3.1-generate-001", req)
+	if fieldErr, ok := errors.AsType[*VideoFieldError](err); !ok || fieldErr.Field != "DurationSec" {
+		t.Fatalf("StartVideo() error = %v, want a DurationSec field error", err)
+	}
+	if video.calls != 0 {
+		t.Errorf("SDK calls = %d, want the request rejected before sending", video.calls)
+	}
+}
@@ -0,0 +1,132 @@
+package gemini
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// CancelVideo は、投函済みの動画生成オペレーションの取り消しを要求します。
+//
+// 長時間実行オペレーションの cancel エンドポイント（{name}:cancel）を呼びます。
+// 取り消しは非同期で、受け付けられてもすぐに止まるとは限りません。止まったオペレーションは
+// PollVideo で Done になり、Failure が ErrVideoCancelled を含みます。既に完了していた場合は
+// 生成結果がそのまま残ります。
+//
+// バックエンドやモデルが取り消しに対応していない場合は ErrUnsupported を返します。
+func (c *Client) CancelVideo(ctx context.Context, operationName string) error {
+	if strings.TrimSpace(operationName) == "" {
+		return ErrEmptyOperationName
+	}
+	if c.operations == nil {
+		return fmt.Errorf("%w: オペレーション %q は取り消せません", ErrUnsupported, operationName)
+	}
+	return c.operations.call(ctx, http.MethodPost, operationName, ":cancel", "取り消し")
+}
+
+// DeleteVideo は、動画生成オペレーションの記録をサーバーから削除します。
+//
+// 長時間実行オペレーションの delete エンドポイント（DELETE {name}）を呼びます。
+// 削除した後は PollVideo で結果を取り出せなくなるため、動画を取り出し終えてから呼んでください。
+// 実行中のオペレーションを削除しても生成は止まりません（止めるには CancelVideo を使います）。
+// 生成された動画のファイル（File API のファイルや OutputGCSURI のオブジェクト）は消えません。
+//
+// バックエンドやモデルが削除に対応していない場合は ErrUnsupported を返します。
+func (c *Client) DeleteVideo(ctx context.Context, operationName string) error {
+	if strings.TrimSpace(operationName) == "" {
+		return ErrEmptyOperationName
+	}
+	if c.operations == nil {
+		return fmt.Errorf("%w: オペレーション %q は削除できません", ErrUnsupported, operationName)
+	}
+	return c.operations.call(ctx, http.MethodDelete, operationName, "", "削除")
+}
+
+// operationsClient は、genai SDK が持たない長時間実行オペレーションの API を直接呼ぶ
+// HTTP 部分です。エンドポイントを差し替えられるようにしてあるのは、テストでローカルの
+// サーバーへ向けるためです。
+type operationsClient struct {
+	vertex         bool
+	apiKey         string
+	httpClient     *http.Client
+	geminiEndpoint string
+	vertexEndpoint string
+
+	// authorized は Vertex AI 用の認証付きクライアントを返します。mediaDownloader と
+	// 同じものを共有し、ADC の検出を1回で済ませます。
+	authorized func() (*http.Client, error)
+}
+
+// newOperationsClient は、Config と genai に渡した設定から operationsClient を作ります。
+func newOperationsClient(cfg Config, clientCfg *genai.ClientConfig, authorized func() (*http.Client, error)) *operationsClient {
+	return &operationsClient{
+		vertex:         clientCfg.Backend == genai.BackendVertexAI,
+		apiKey:         cfg.APIKey,
+		httpClient:     cmp.Or(cfg.HTTPClient, http.DefaultClient),
+		geminiEndpoint: filesEndpoint,
+		vertexEndpoint: vertexEndpoint(cfg.LocationID),
+		authorized:     authorized,
+	}
+}
+
+// vertexEndpoint はロケーションごとの Vertex AI のエンドポイントです。
+func vertexEndpoint(location string) string {
+	if location == "" || location == "global" {
+		return "https://aiplatform.googleapis.com"
+	}
+	return "https://" + location + "-aiplatform.googleapis.com"
+}
+
+// call は、オペレーション name の suffix のエンドポイントへ method のリクエストを送ります。
+// action はエラーに載せる操作の名前です。
+func (d *operationsClient) call(ctx context.Context, method, name, suffix, action string) error {
+	target, client := d.geminiEndpoint+"/v1beta/"+name+suffix, d.httpClient
+	if d.vertex {
+		if d.authorized == nil {
+			return fmt.Errorf("%w: オペレーション %q の%sはできません", ErrUnsupported, name, action)
+		}
+		authorized, err := d.authorized()
+		if err != nil {
+			return err
+		}
+		target, client = d.vertexEndpoint+"/v1/"+name+suffix, authorized
+	}
+
+	var body io.Reader
+	if method == http.MethodPost {
+		body = strings.NewReader("{}")
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return fmt.Errorf("gemini: オペレーション %q の%sに失敗しました: %w", name, action, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if !d.vertex && d.apiKey != "" {
+		req.Header.Set("x-goog-api-key", d.apiKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("gemini: オペレーション %q の%sに失敗しました: %w", name, action, err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	detail := strings.TrimSpace(string(respBody))
+	// 対応していない操作は 501 UNIMPLEMENTED（ルートごと無い場合は 405）で返る。
+	if resp.StatusCode == http.StatusNotImplemented || resp.StatusCode == http.StatusMethodNotAllowed ||
+		strings.Contains(detail, "UNIMPLEMENTED") {
+		return fmt.Errorf("%w: オペレーション %q の%s: HTTP %d: %s", ErrUnsupported, name, action, resp.StatusCode, detail)
+	}
+	return fmt.Errorf("gemini: オペレーション %q の%sに失敗しました: HTTP %d: %s", name, action, resp.StatusCode, detail)
+}
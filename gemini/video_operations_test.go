@@ -0,0 +1,111 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// cancelServer は、受け取ったオペレーションへのリクエストを記録して status を返すサーバーです。
+func cancelServer(t *testing.T, status int, body string) (*httptest.Server, *[]*http.Request) {
+	t.Helper()
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestCancelVideo_GeminiAPI(t *testing.T) {
+	server, requests := cancelServer(t, http.StatusOK, "{}")
+	client := &Client{operations: &operationsClient{apiKey: "test-key", httpClient: http.DefaultClient, geminiEndpoint: server.URL}}
+
+	if err := client.CancelVideo(context.Background(), "models/veo-3.1-generate-preview/operations/abc"); err != nil {
+		t.Fatalf("CancelVideo() error = %v", err)
+	}
+	req := (*requests)[0]
+	if req.Method != http.MethodPost || req.URL.Path != "/v1beta/models/veo-3.1-generate-preview/operations/abc:cancel" {
+		t.Errorf("request = %s %s", req.Method, req.URL.Path)
+	}
+	if req.Header.Get("x-goog-api-key") != "test-key" {
+		t.Error("the API key should be sent with the cancel request")
+	}
+}
+
+func TestCancelVideo_VertexAI(t *testing.T) {
+	server, requests := cancelServer(t, http.StatusOK, "{}")
+	client := &Client{operations: &operationsClient{
+		vertex:         true,
+		vertexEndpoint: server.URL,
+		authorized:     func() (*http.Client, error) { return server.Client(), nil },
+	}}
+
+	name := "projects/p/locations/us-central1/publishers/google/models/veo-3.0-generate-001/operations/xyz"
+	if err := client.CancelVideo(context.Background(), name); err != nil {
+		t.Fatalf("CancelVideo() error = %v", err)
+	}
+	if got := (*requests)[0].URL.Path; got != "/v1/"+name+":cancel" {
+		t.Errorf("request path = %s", got)
+	}
+}
+
+func TestCancelVideo_Unsupported(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+	}{
+		{"not implemented", http.StatusNotImplemented, `{"error":{"status":"UNIMPLEMENTED"}}`, ErrUnsupported},
+		{"unimplemented status in body", http.StatusBadRequest, `{"error":{"status":"UNIMPLEMENTED"}}`, ErrUnsupported},
+		{"other failure", http.StatusNotFound, `{"error":{"status":"NOT_FOUND"}}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := cancelServer(t, tt.status, tt.body)
+			client := &Client{operations: &operationsClient{httpClient: http.DefaultClient, geminiEndpoint: server.URL}}
+
+			err := client.CancelVideo(context.Background(), "operations/abc")
+			if err == nil {
+				t.Fatal("CancelVideo() error = nil")
+			}
+			if got := errors.Is(err, ErrUnsupported); got != (tt.want != nil) {
+				t.Errorf("CancelVideo() error = %v, ErrUnsupported = %v", err, got)
+			}
+		})
+	}
+
+	if err := (&Client{}).CancelVideo(context.Background(), ""); !errors.Is(err, ErrEmptyOperationName) {
+		t.Errorf("CancelVideo(\"\") error = %v, want ErrEmptyOperationName", err)
+	}
+}
+
+func TestDeleteVideo(t *testing.T) {
+	server, requests := cancelServer(t, http.StatusOK, "{}")
+	client := &Client{operations: &operationsClient{apiKey: "test-key", httpClient: http.DefaultClient, geminiEndpoint: server.URL}}
+
+	if err := client.DeleteVideo(context.Background(), "models/veo-3.1-generate-preview/operations/abc"); err != nil {
+		t.Fatalf("DeleteVideo() error = %v", err)
+	}
+	req := (*requests)[0]
+	if req.Method != http.MethodDelete || req.URL.Path != "/v1beta/models/veo-3.1-generate-preview/operations/abc" {
+		t.Errorf("request = %s %s", req.Method, req.URL.Path)
+	}
+	if req.Header.Get("x-goog-api-key") != "test-key" {
+		t.Error("the API key should be sent with the delete request")
+	}
+
+	unsupported, _ := cancelServer(t, http.StatusNotImplemented, `{"error":{"status":"UNIMPLEMENTED"}}`)
+	client = &Client{operations: &operationsClient{httpClient: http.DefaultClient, geminiEndpoint: unsupported.URL}}
+	if err := client.DeleteVideo(context.Background(), "operations/abc"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("DeleteVideo() error = %v, want ErrUnsupported", err)
+	}
+	if err := (&Client{}).DeleteVideo(context.Background(), " "); !errors.Is(err, ErrEmptyOperationName) {
+		t.Errorf("DeleteVideo(blank) error = %v, want ErrEmptyOperationName", err)
+	}
+}
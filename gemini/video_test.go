@@ -338,6 +338,87 @@ func TestPollVideoMapsOperationFailure(t *testing.T) {
 			t.Errorf("failure = %q, want it to contain %q", op.Failure.Error(), want)
 		}
 	}
+	failure, ok := errors.AsType[*VideoFailureError](op.Failure)
+	if !ok || failure.Code != 3 || failure.Status != "INVALID_ARGUMENT" || failure.Cancelled() {
+		t.Errorf("failure = %#v, want the code and status as fields", op.Failure)
+	}
+}
+
+// TestVideoOperationFailureFields は、status が無い場合にコードから名前を補うことと、
+// details を構造のまま残すことを検証します。
+func TestVideoOperationFailureFields(t *testing.T) {
+	err := videoOperationFailure(map[string]any{
+		"code":    float64(8),
+		"message": "Quota exceeded",
+		"details": []any{map[string]any{"@type": "type.googleapis.com/google.rpc.ErrorInfo", "reason": "RATE_LIMIT_EXCEEDED"}},
+	})
+	failure, ok := errors.AsType[*VideoFailureError](err)
+	if !ok {
+		t.Fatalf("failure = %v, want *VideoFailureError", err)
+	}
+	if failure.Status != "RESOURCE_EXHAUSTED" || failure.Message != "Quota exceeded" {
+		t.Errorf("failure = %+v, want the status derived from the code", failure)
+	}
+	if len(failure.Details) != 1 || failure.Details[0]["reason"] != "RATE_LIMIT_EXCEEDED" {
+		t.Errorf("details = %v", failure.Details)
+	}
+
+	unknown := videoOperationFailure(map[string]any{"unexpected": true})
+	if !errors.Is(unknown, ErrVideoGenerationFailed) || !contains(unknown.Error(), "unexpected") {
+		t.Errorf("failure without known keys = %v, want the raw content in the message", unknown)
+	}
+	if videoOperationFailure(nil) != nil {
+		t.Error("empty error map should not be a failure")
+	}
+}
+
+// TestPollVideoMapsCancelledOperation は、取り消されて完了したオペレーションが
+// 失敗ではなく ErrVideoCancelled として区別されることを検証します。
+func TestPollVideoMapsCancelledOperation(t *testing.T) {
+	video := &fakeVideoClient{
+		pollOp: &genai.GenerateVideosOperation{
+			Name:  "operations/abc",
+			Done:  true,
+			Error: map[string]any{"code": float64(1), "message": "Operation cancelled"},
+		},
+	}
+	client := newVideoTestClient(video)
+
+	op, err := client.PollVideo(context.Background(), "operations/abc")
+	if err != nil {
+		t.Fatalf("PollVideo() error = %v", err)
+	}
+	if !errors.Is(op.Failure, ErrVideoCancelled) || errors.Is(op.Failure, ErrVideoGenerationFailed) {
+		t.Fatalf("failure = %v, want ErrVideoCancelled only", op.Failure)
+	}
+}
+
+func TestPollVideoReadsProgressFromMetadata(t *testing.T) {
+	tests := []struct {
+		metadata map[string]any
+		want     float64
+		ok       bool
+	}{
+		{metadata: map[string]any{"progressPercent": float64(42)}, want: 42, ok: true},
+		{metadata: map[string]any{"progressPercentage": "37.5%"}, want: 37.5, ok: true},
+		{metadata: map[string]any{"progressPercent": "unknown"}},
+		{metadata: map[string]any{"progressPercent": float64(140)}},
+		{metadata: map[string]any{"createTime": "2026-01-01T00:00:00Z"}},
+		{},
+	}
+	for _, tt := range tests {
+		video := &fakeVideoClient{pollOp: &genai.GenerateVideosOperation{Name: "operations/abc", Metadata: tt.metadata}}
+		op, err := newVideoTestClient(video).PollVideo(context.Background(), "operations/abc")
+		if err != nil {
+			t.Fatalf("PollVideo() error = %v", err)
+		}
+		switch {
+		case !tt.ok && op.ProgressPercent != nil:
+			t.Errorf("metadata %v: progress = %v, want nil", tt.metadata, *op.ProgressPercent)
+		case tt.ok && (op.ProgressPercent == nil || *op.ProgressPercent != tt.want):
+			t.Errorf("metadata %v: progress = %v, want %v", tt.metadata, op.ProgressPercent, tt.want)
+		}
+	}
 }
 
 func TestPollVideoRequiresOperationName(t *testing.T) {
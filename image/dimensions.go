@@ -0,0 +1,121 @@
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// decodeDimensions は、画像ヘッダから幅と高さを読み取ります。
+//
+// 標準ライブラリの image.DecodeConfig は WebP を扱えず（golang.org/x/image が必要）、
+// 生成モデルの出力には WebP も含まれるため、寸法に必要なヘッダだけを自前で読みます。
+// 画素のデコードはしないので、大きな画像でもコストはヘッダの走査分だけです。
+// 未対応の形式や壊れたヘッダでは ok=false を返します。
+func decodeDimensions(data []byte) (width, height int, ok bool) {
+	switch {
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return pngDimensions(data)
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8}):
+		return jpegDimensions(data)
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return gifDimensions(data)
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return webpDimensions(data)
+	}
+	return 0, 0, false
+}
+
+// pngDimensions は IHDR チャンクから寸法を読みます。IHDR は仕様上必ず先頭チャンクです。
+func pngDimensions(data []byte) (int, int, bool) {
+	if len(data) < 24 || string(data[12:16]) != "IHDR" {
+		return 0, 0, false
+	}
+	return int(binary.BigEndian.Uint32(data[16:20])), int(binary.BigEndian.Uint32(data[20:24])), true
+}
+
+// gifDimensions は論理スクリーン記述子から寸法を読みます。
+func gifDimensions(data []byte) (int, int, bool) {
+	if len(data) < 10 {
+		return 0, 0, false
+	}
+	return int(binary.LittleEndian.Uint16(data[6:8])), int(binary.LittleEndian.Uint16(data[8:10])), true
+}
+
+// jpegDimensions は、マーカーを順に読み飛ばして最初の SOF（フレームヘッダ）から
+// 寸法を読みます。EXIF などのアプリケーションセグメントが先に来ることがあるため、
+// 固定位置では読めません。
+func jpegDimensions(data []byte) (int, int, bool) {
+	i := 2
+	for i+4 <= len(data) {
+		if data[i] != 0xFF {
+			return 0, 0, false
+		}
+		marker := data[i+1]
+		// 0xFF の連続はフィルバイトなので読み飛ばす。
+		if marker == 0xFF {
+			i++
+			continue
+		}
+		// 長さを持たないマーカー（RSTn / SOI / TEM）。
+		if (marker >= 0xD0 && marker <= 0xD8) || marker == 0x01 {
+			i += 2
+			continue
+		}
+		if marker == 0xD9 || marker == 0xDA {
+			// EOI / SOS まで SOF が無ければ寸法は得られない。
+			return 0, 0, false
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		if length < 2 {
+			return 0, 0, false
+		}
+		if isJPEGFrameMarker(marker) {
+			// セグメント: 長さ(2) 精度(1) 高さ(2) 幅(2)
+			if i+9 > len(data) {
+				return 0, 0, false
+			}
+			height := int(binary.BigEndian.Uint16(data[i+5 : i+7]))
+			width := int(binary.BigEndian.Uint16(data[i+7 : i+9]))
+			return width, height, true
+		}
+		i += 2 + length
+	}
+	return 0, 0, false
+}
+
+// isJPEGFrameMarker は、マーカーが SOF0〜SOF15 のフレームヘッダかを判定します。
+// 同じ範囲にある DHT (C4)・JPG (C8)・DAC (CC) はフレームヘッダではありません。
+func isJPEGFrameMarker(marker byte) bool {
+	return marker >= 0xC0 && marker <= 0xCF && marker != 0xC4 && marker != 0xC8 && marker != 0xCC
+}
+
+// webpDimensions は、先頭チャンクの種類（非可逆 VP8 / 可逆 VP8L / 拡張 VP8X）に
+// 応じて寸法を読みます。
+func webpDimensions(data []byte) (int, int, bool) {
+	if len(data) < 30 {
+		return 0, 0, false
+	}
+	switch string(data[12:16]) {
+	case "VP8 ":
+		// フレームタグ(3) の後に開始コード 9D 01 2A、続いて 14 ビットの幅と高さ。
+		if !bytes.Equal(data[23:26], []byte{0x9D, 0x01, 0x2A}) {
+			return 0, 0, false
+		}
+		width := int(binary.LittleEndian.Uint16(data[26:28]) & 0x3FFF)
+		height := int(binary.LittleEndian.Uint16(data[28:30]) & 0x3FFF)
+		return width, height, true
+	case "VP8L":
+		// シグネチャ 0x2F の後に 14 ビットずつの (幅-1) と (高さ-1)。
+		if data[20] != 0x2F {
+			return 0, 0, false
+		}
+		bits := binary.LittleEndian.Uint32(data[21:25])
+		return int(bits&0x3FFF) + 1, int((bits>>14)&0x3FFF) + 1, true
+	case "VP8X":
+		// フラグ(4) の後に 24 ビットずつの (幅-1) と (高さ-1)。
+		width := int(uint32(data[24])|uint32(data[25])<<8|uint32(data[26])<<16) + 1
+		height := int(uint32(data[27])|uint32(data[28])<<8|uint32(data[29])<<16) + 1
+		return width, height, true
+	}
+	return 0, 0, false
+}
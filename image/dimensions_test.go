@@ -0,0 +1,97 @@
+package image
+
+import (
+	"bytes"
+	stdimage "image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// encodeSample は標準ライブラリのエンコーダで実際の画像を作ります。手書きの
+// ヘッダではなく本物のエンコーダ出力で検証するためです。
+func encodeSample(t *testing.T, format string, width, height int) []byte {
+	t.Helper()
+	img := stdimage.NewRGBA(stdimage.Rect(0, 0, width, height))
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "png":
+		err = png.Encode(&buf, img)
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, nil)
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatalf("encode %s: %v", format, err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeDimensionsStandardFormats(t *testing.T) {
+	for _, format := range []string{"png", "jpeg", "gif"} {
+		t.Run(format, func(t *testing.T) {
+			width, height, ok := decodeDimensions(encodeSample(t, format, 37, 21))
+			if !ok || width != 37 || height != 21 {
+				t.Errorf("decodeDimensions() = %d x %d, %v; want 37 x 21", width, height, ok)
+			}
+		})
+	}
+}
+
+// TestDecodeDimensionsJPEGSkipsAppSegments verifies an EXIF/APP segment before the frame header does
+// not throw off the marker walk. Camera and model outputs routinely carry one.
+func TestDecodeDimensionsJPEGSkipsAppSegments(t *testing.T) {
+	plain := encodeSample(t, "jpeg", 64, 48)
+	app1 := []byte{0xFF, 0xE1, 0x00, 0x08, 'E', 'x', 'i', 'f', 0x00, 0x00}
+	withApp := append(append(append([]byte{}, plain[:2]...), app1...), plain[2:]...)
+
+	width, height, ok := decodeDimensions(withApp)
+	if !ok || width != 64 || height != 48 {
+		t.Errorf("decodeDimensions() = %d x %d, %v; want 64 x 48", width, height, ok)
+	}
+}
+
+func TestDecodeDimensionsWebP(t *testing.T) {
+	riff := func(chunk string, payload []byte) []byte {
+		data := []byte("RIFF\x00\x00\x00\x00WEBP" + chunk + "\x00\x00\x00\x00")
+		return append(data, payload...)
+	}
+	tests := map[string]struct {
+		data          []byte
+		width, height int
+	}{
+		// 非可逆: フレームタグ(3) + 開始コード + 幅 640 / 高さ 480
+		"lossy": {riff("VP8 ", []byte{0, 0, 0, 0x9D, 0x01, 0x2A, 0x80, 0x02, 0xE0, 0x01}), 640, 480},
+		// 可逆: 0x2F + (幅-1=99)|(高さ-1=49)<<14
+		"lossless": {riff("VP8L", []byte{0x2F, 0x63, 0x40, 0x0C, 0x00, 0, 0, 0, 0, 0}), 100, 50},
+		// 拡張: フラグ(4) + (幅-1=1023) + (高さ-1=767)
+		"extended": {riff("VP8X", []byte{0, 0, 0, 0, 0xFF, 0x03, 0x00, 0xFF, 0x02, 0x00}), 1024, 768},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			width, height, ok := decodeDimensions(tt.data)
+			if !ok || width != tt.width || height != tt.height {
+				t.Errorf("decodeDimensions() = %d x %d, %v; want %d x %d", width, height, ok, tt.width, tt.height)
+			}
+		})
+	}
+}
+
+func TestDecodeDimensionsRejectsUnknownOrTruncated(t *testing.T) {
+	sample := encodeSample(t, "png", 8, 8)
+	for name, data := range map[string][]byte{
+		"empty":              nil,
+		"not an image":       []byte("hello"),
+		"truncated png":      sample[:20],
+		"jpeg without frame": {0xFF, 0xD8, 0xFF, 0xD9},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, _, ok := decodeDimensions(data); ok {
+				t.Error("decodeDimensions() ok = true, want false")
+			}
+		})
+	}
+}
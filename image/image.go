@@ -0,0 +1,269 @@
+// Package image は、Gemini の画像生成モデル（Nano Banana など）による画像の生成と
+// 編集を扱うクライアントを提供します。
+//
+// gemini パッケージが持つのは「プロンプトと添付を送ってバイト列を受け取る」1往復で、
+// 画像としての扱い——元画像・マスク・参照画像の並べ方、複数バリエーションの並行生成と
+// シードの割り当て、MIME type と寸法の付与、画像が返らなかった場合の分類——を
+// このパッケージが受け持ちます。
+//
+// 依存は gemini.Generator の注入だけで、genai SDK には触れません。
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/shouni/go-gemini-client/gemini"
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultConcurrency は、バリエーションを並行して生成する既定の上限数です。
+const DefaultConcurrency = 4
+
+// maskInstruction は、マスク画像を添付したときにプロンプトへ付け足す説明です。
+// Gemini の画像モデルはマスク専用の入力を持たないため、2枚目の画像がマスクで
+// あることを言葉で伝えます。モデルへの指示なので英語で書いています。
+const maskInstruction = "The second image is an edit mask: modify only the white areas of the first image and keep the black areas unchanged."
+
+// 入力・結果に関するセンチネルエラーです。
+var (
+	// ErrGeneratorRequired は、New に nil の生成クライアントが渡された場合に返されます。
+	ErrGeneratorRequired = errors.New("image: generator is required")
+	// ErrMissingSource は、Edit に編集元の画像が無い場合に返されます。
+	ErrMissingSource = errors.New("image: source image is required for editing")
+	// ErrNoImage は、呼び出しは成功したのに画像が1枚も返らなかった場合を示します。
+	// 詳細は errors.AsType[*NoImageError] でモデルが返したテキストを参照してください。
+	ErrNoImage = errors.New("image: model returned no image")
+)
+
+// NoImageError は、モデルが画像を返さずテキストだけを返したことを示します。
+//
+// 画像モデルは、プロンプトが曖昧なときや生成を断るときに画像の代わりに説明文を
+// 返します。空のスライスとして成功扱いにすると呼び出し側が気付けないため、
+// その説明文を持たせたエラーにしています。errors.Is で ErrNoImage と比較できます。
+//
+//	if noImage, ok := errors.AsType[*image.NoImageError](err); ok {
+//	    slog.Warn("no image", "text", noImage.Text)
+//	}
+type NoImageError struct {
+	// Variation は、画像が返らなかったバリエーションの番号（0 始まり）です。
+	Variation int
+	// Text はモデルが画像の代わりに返したテキストです。空の場合もあります。
+	Text string
+}
+
+func (e *NoImageError) Error() string {
+	if e.Text == "" {
+		return fmt.Sprintf("%v（バリエーション %d）", ErrNoImage, e.Variation)
+	}
+	return fmt.Sprintf("%v（バリエーション %d）: モデルの応答: %s", ErrNoImage, e.Variation, e.Text)
+}
+
+// Unwrap は ErrNoImage を返し、errors.Is による判定を可能にします。
+func (e *NoImageError) Unwrap() error { return ErrNoImage }
+
+// Client は画像の生成と編集を扱うクライアントです。
+type Client struct {
+	generator   gemini.Generator
+	concurrency int
+}
+
+// New は、生成クライアントを注入して Client を初期化します。
+//
+// generator には *gemini.Client をそのまま渡せます。
+//
+//	gc, err := gemini.NewClient(ctx, cfg)
+//	ic, err := image.New(gc, image.WithConcurrency(2))
+func New(generator gemini.Generator, opts ...Option) (*Client, error) {
+	if generator == nil {
+		return nil, ErrGeneratorRequired
+	}
+	c := &Client{
+		generator:   generator,
+		concurrency: DefaultConcurrency,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Generate はプロンプト（と任意の参照画像）から画像を生成します。
+//
+// Count が 2 以上の場合は本数分の呼び出しを並行して行い、結果をバリエーションの
+// 順に並べて返します。1本でも失敗した場合は残りを打ち切ってエラーを返します。
+// 画像が返らなかった場合は *NoImageError（ErrNoImage）です。
+func (c *Client) Generate(ctx context.Context, modelName string, req Request) (*Result, error) {
+	if strings.TrimSpace(req.Prompt) == "" {
+		return nil, gemini.ErrEmptyPrompt
+	}
+	refs, err := validateImages("References", req.References)
+	if err != nil {
+		return nil, err
+	}
+	return c.run(ctx, modelName, req, req.Prompt, refs)
+}
+
+// Edit は元画像をプロンプトに従って編集します。
+//
+// 添付は「元画像 → マスク → 参照画像」の順に並べます。Mask を指定した場合は、
+// 2枚目がマスクであることをプロンプトに付け足します（maskInstruction 参照）。
+// バリエーションと失敗の扱いは Generate と同じです。
+func (c *Client) Edit(ctx context.Context, modelName string, req EditRequest) (*Result, error) {
+	if strings.TrimSpace(req.Prompt) == "" {
+		return nil, gemini.ErrEmptyPrompt
+	}
+	if req.Source.IsEmpty() {
+		return nil, ErrMissingSource
+	}
+
+	inputs := []gemini.Attachment{req.Source}
+	prompt := req.Prompt
+	if req.Mask != nil && !req.Mask.IsEmpty() {
+		inputs = append(inputs, *req.Mask)
+		prompt = req.Prompt + "\n\n" + maskInstruction
+	}
+	if _, err := validateImages("Source/Mask", inputs); err != nil {
+		return nil, err
+	}
+	refs, err := validateImages("References", req.References)
+	if err != nil {
+		return nil, err
+	}
+	return c.run(ctx, modelName, req.Request, prompt, append(inputs, refs...))
+}
+
+// validateImages は、添付がすべて画像入力として送信できるかを検証し、空の要素を
+// 取り除いた列を返します。field はエラーでどの入力かを示すためのラベルです。
+func validateImages(field string, attachments []gemini.Attachment) ([]gemini.Attachment, error) {
+	valid := make([]gemini.Attachment, 0, len(attachments))
+	for i, attachment := range attachments {
+		if attachment.IsEmpty() {
+			continue
+		}
+		if err := attachment.ValidateFor(gemini.ModalityImage); err != nil {
+			return nil, fmt.Errorf("%s[%d]: %w", field, i, err)
+		}
+		valid = append(valid, attachment)
+	}
+	return valid, nil
+}
+
+// run は Count 本のバリエーションを並行して生成し、結果を順に並べます。
+func (c *Client) run(ctx context.Context, modelName string, req Request, prompt string, attachments []gemini.Attachment) (*Result, error) {
+	count := max(req.Count, 1)
+	variations := make([]*gemini.Response, count)
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(c.concurrency)
+	for i := range count {
+		g.Go(func() error {
+			opts := generateOptions(req, variationSeed(req.Seed, i))
+			resp, err := c.generator.GenerateWithAttachments(gctx, modelName, prompt, attachments, opts)
+			if err != nil {
+				return fmt.Errorf("画像生成に失敗しました（モデル: %s, バリエーション: %d）: %w", modelName, i, err)
+			}
+			if resp == nil {
+				return fmt.Errorf("画像生成に失敗しました（モデル: %s, バリエーション: %d）: %w", modelName, i, gemini.ErrEmptyResponse)
+			}
+			variations[i] = resp
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	var texts []string
+	for i, resp := range variations {
+		images := imagesFrom(resp, i, variationSeed(req.Seed, i))
+		if len(images) == 0 {
+			return nil, &NoImageError{Variation: i, Text: strings.TrimSpace(resp.Text)}
+		}
+		result.Images = append(result.Images, images...)
+		if text := strings.TrimSpace(resp.Text); text != "" {
+			texts = append(texts, text)
+		}
+		result.Usage = addUsage(result.Usage, resp.Usage)
+	}
+	result.Text = strings.Join(texts, "\n")
+	return result, nil
+}
+
+// generateOptions は、画像生成の設定を gemini の生成オプションへ写します。
+func generateOptions(req Request, seed *int64) gemini.GenerateOptions {
+	return gemini.GenerateOptions{
+		AspectRatio:      req.AspectRatio,
+		ImageSize:        req.ImageSize,
+		PersonGeneration: req.PersonGeneration,
+		Seed:             seed,
+	}
+}
+
+// variationSeed は i 本目のバリエーションに使うシードを返します。
+//
+// 基準値に番号を足すだけにしているのは、同じ Request を再送すれば同じ組の画像が
+// 得られる（再現性がある）ようにするためです。int32 の上限を超える分は下限側へ
+// 折り返します（API のシードは int32 のため）。基準値自体が範囲外の場合は
+// 折り返さず、gemini 側の ErrInvalidSeed に委ねます。
+func variationSeed(base *int64, i int) *int64 {
+	if base == nil {
+		return nil
+	}
+	seed := *base
+	if i == 0 || seed > math.MaxInt32 || seed < math.MinInt32 {
+		return &seed
+	}
+	seed += int64(i)
+	if seed > math.MaxInt32 {
+		seed -= 1 << 32
+	}
+	return &seed
+}
+
+// imagesFrom は、レスポンスの添付から画像だけを取り出し、寸法を付けて返します。
+func imagesFrom(resp *gemini.Response, variation int, seed *int64) []Image {
+	var images []Image
+	for _, attachment := range resp.Attachments {
+		if len(attachment.Data) == 0 {
+			continue
+		}
+		mimeType := attachment.MIMEType
+		if mimeType == "" {
+			mimeType = gemini.DetectMIMEType(attachment.Data)
+		}
+		if !strings.HasPrefix(mimeType, "image/") {
+			continue
+		}
+		width, height, _ := decodeDimensions(attachment.Data)
+		images = append(images, Image{
+			MIMEType:  mimeType,
+			Data:      attachment.Data,
+			Width:     width,
+			Height:    height,
+			Seed:      seed,
+			Variation: variation,
+		})
+	}
+	return images
+}
+
+// addUsage はトークン使用量を合算します。どちらかが nil の場合はもう一方を返します。
+func addUsage(total, usage *gemini.TokenUsage) *gemini.TokenUsage {
+	if usage == nil {
+		return total
+	}
+	if total == nil {
+		sum := *usage
+		return &sum
+	}
+	total.PromptTokenCount += usage.PromptTokenCount
+	total.CandidatesTokenCount += usage.CandidatesTokenCount
+	total.TotalTokenCount += usage.TotalTokenCount
+	total.ThoughtsTokenCount += usage.ThoughtsTokenCount
+	return total
+}
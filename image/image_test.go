@@ -0,0 +1,249 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shouni/go-gemini-client/gemini"
+)
+
+// fakeGenerator は gemini.Generator のテストダブルです。バリエーションは並行して
+// 呼ばれるため、記録はロックで守ります。
+type fakeGenerator struct {
+	mu    sync.Mutex
+	calls []fakeCall
+	// respond は呼び出しごとの応答を決めます。nil の場合は 1x1 の PNG を返します。
+	respond func(call fakeCall) (*gemini.Response, error)
+
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+	delay       time.Duration
+}
+
+type fakeCall struct {
+	model       string
+	prompt      string
+	attachments []gemini.Attachment
+	opts        gemini.GenerateOptions
+}
+
+func (f *fakeGenerator) GenerateWithAttachments(ctx context.Context, modelName string, prompt string, attachments []gemini.Attachment, opts gemini.GenerateOptions) (*gemini.Response, error) {
+	current := f.inFlight.Add(1)
+	defer f.inFlight.Add(-1)
+	for {
+		seen := f.maxInFlight.Load()
+		if current <= seen || f.maxInFlight.CompareAndSwap(seen, current) {
+			break
+		}
+	}
+	if f.delay > 0 {
+		select {
+		case <-time.After(f.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	call := fakeCall{model: modelName, prompt: prompt, attachments: attachments, opts: opts}
+	f.mu.Lock()
+	f.calls = append(f.calls, call)
+	f.mu.Unlock()
+
+	if f.respond != nil {
+		return f.respond(call)
+	}
+	return &gemini.Response{
+		Attachments: []gemini.Attachment{{MIMEType: "image/png", Data: pngHeader(1, 1)}},
+		Usage:       &gemini.TokenUsage{TotalTokenCount: 10},
+	}, nil
+}
+
+// pngHeader は寸法の読み取りに足りるだけの PNG ヘッダを作ります。
+func pngHeader(width, height byte) []byte {
+	return []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00" + string(width) + "\x00\x00\x00" + string(height))
+}
+
+func newTestClient(t *testing.T, generator gemini.Generator, opts ...Option) *Client {
+	t.Helper()
+	c, err := New(generator, opts...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return c
+}
+
+func TestNewRequiresGenerator(t *testing.T) {
+	if _, err := New(nil); !errors.Is(err, ErrGeneratorRequired) {
+		t.Fatalf("New(nil) error = %v, want ErrGeneratorRequired", err)
+	}
+}
+
+func TestGenerateReturnsTypedImages(t *testing.T) {
+	fake := &fakeGenerator{}
+	client := newTestClient(t, fake)
+
+	got, err := client.Generate(context.Background(), "gemini-image", Request{Prompt: "a cat", AspectRatio: "1:1"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	img, ok := got.First()
+	if !ok {
+		t.Fatal("Generate() returned no image")
+	}
+	if img.MIMEType != "image/png" || img.Width != 1 || img.Height != 1 {
+		t.Errorf("image = %s %dx%d, want image/png 1x1", img.MIMEType, img.Width, img.Height)
+	}
+	if got.Usage == nil || got.Usage.TotalTokenCount != 10 {
+		t.Errorf("Usage = %+v, want the response usage", got.Usage)
+	}
+	if fake.calls[0].opts.AspectRatio != "1:1" {
+		t.Errorf("AspectRatio = %q, want it forwarded", fake.calls[0].opts.AspectRatio)
+	}
+}
+
+// TestGenerateVariationsUseDerivedSeedsInOrder verifies each variation gets base+i as its seed and
+// the result is ordered by variation regardless of completion order, so re-running the same request
+// reproduces the same set.
+func TestGenerateVariationsUseDerivedSeedsInOrder(t *testing.T) {
+	fake := &fakeGenerator{respond: func(call fakeCall) (*gemini.Response, error) {
+		// シードが大きいほど早く返し、完了順を入力順と逆にする。
+		time.Sleep(time.Duration(110-*call.opts.Seed) * time.Millisecond)
+		return &gemini.Response{Attachments: []gemini.Attachment{{MIMEType: "image/png", Data: pngHeader(byte(*call.opts.Seed-100+1), 1)}}}, nil
+	}}
+	client := newTestClient(t, fake)
+
+	got, err := client.Generate(context.Background(), "gemini-image", Request{Prompt: "a cat", Count: 3, Seed: gemini.Ptr[int64](100)})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(got.Images) != 3 {
+		t.Fatalf("images = %d, want 3", len(got.Images))
+	}
+	for i, img := range got.Images {
+		if img.Variation != i || img.Seed == nil || *img.Seed != int64(100+i) || img.Width != i+1 {
+			t.Errorf("Images[%d] = variation %d seed %v width %d", i, img.Variation, img.Seed, img.Width)
+		}
+	}
+}
+
+func TestVariationSeedWrapsWithinInt32(t *testing.T) {
+	if got := *variationSeed(gemini.Ptr[int64](2147483647), 1); got != -2147483648 {
+		t.Errorf("variationSeed(MaxInt32, 1) = %d, want MinInt32", got)
+	}
+	if variationSeed(nil, 3) != nil {
+		t.Error("variationSeed(nil) should stay nil so each call is random")
+	}
+}
+
+func TestGenerateRespectsConcurrencyLimit(t *testing.T) {
+	fake := &fakeGenerator{delay: 20 * time.Millisecond}
+	client := newTestClient(t, fake, WithConcurrency(2))
+
+	if _, err := client.Generate(context.Background(), "gemini-image", Request{Prompt: "a cat", Count: 6}); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if got := fake.maxInFlight.Load(); got > 2 {
+		t.Errorf("max in-flight calls = %d, want at most 2", got)
+	}
+	if len(fake.calls) != 6 {
+		t.Errorf("calls = %d, want 6", len(fake.calls))
+	}
+}
+
+// TestGenerateReportsTextOnlyResponse verifies a text-only reply becomes a typed error carrying the
+// model's explanation, instead of an empty success the caller cannot tell apart from a real result.
+func TestGenerateReportsTextOnlyResponse(t *testing.T) {
+	fake := &fakeGenerator{respond: func(fakeCall) (*gemini.Response, error) {
+		return &gemini.Response{Text: "I can't draw that."}, nil
+	}}
+	client := newTestClient(t, fake)
+
+	_, err := client.Generate(context.Background(), "gemini-image", Request{Prompt: "a cat"})
+	if !errors.Is(err, ErrNoImage) {
+		t.Fatalf("Generate() error = %v, want ErrNoImage", err)
+	}
+	noImage, ok := errors.AsType[*NoImageError](err)
+	if !ok || noImage.Text != "I can't draw that." {
+		t.Errorf("NoImageError = %+v, want the model text", noImage)
+	}
+}
+
+func TestGeneratePropagatesGeneratorError(t *testing.T) {
+	sentinel := errors.New("quota exceeded")
+	fake := &fakeGenerator{respond: func(fakeCall) (*gemini.Response, error) { return nil, sentinel }}
+	client := newTestClient(t, fake)
+
+	if _, err := client.Generate(context.Background(), "gemini-image", Request{Prompt: "a cat", Count: 2}); !errors.Is(err, sentinel) {
+		t.Fatalf("Generate() error = %v, want the generator error", err)
+	}
+}
+
+func TestGenerateValidatesInput(t *testing.T) {
+	client := newTestClient(t, &fakeGenerator{})
+
+	if _, err := client.Generate(context.Background(), "m", Request{Prompt: " "}); !errors.Is(err, gemini.ErrEmptyPrompt) {
+		t.Errorf("empty prompt error = %v, want ErrEmptyPrompt", err)
+	}
+	_, err := client.Generate(context.Background(), "m", Request{
+		Prompt:     "a cat",
+		References: []gemini.Attachment{{MIMEType: "audio/mpeg", Data: []byte("ID3...")}},
+	})
+	if !errors.Is(err, gemini.ErrUnsupportedMIMEType) {
+		t.Errorf("audio reference error = %v, want ErrUnsupportedMIMEType", err)
+	}
+}
+
+// TestEditOrdersSourceMaskThenReferences verifies the attachment order the mask instruction relies
+// on: the instruction says "the second image is the mask", so the order is part of the contract.
+func TestEditOrdersSourceMaskThenReferences(t *testing.T) {
+	fake := &fakeGenerator{}
+	client := newTestClient(t, fake)
+
+	source := gemini.Attachment{MIMEType: "image/png", Data: pngHeader(4, 4)}
+	mask := gemini.Attachment{MIMEType: "image/png", Data: pngHeader(4, 4)}
+	ref := gemini.Attachment{URI: "gs://bucket/style.png", MIMEType: "image/png"}
+	_, err := client.Edit(context.Background(), "gemini-image", EditRequest{
+		Request: Request{Prompt: "make it blue", References: []gemini.Attachment{ref}},
+		Source:  source,
+		Mask:    &mask,
+	})
+	if err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+
+	call := fake.calls[0]
+	if len(call.attachments) != 3 || call.attachments[2].URI != ref.URI {
+		t.Fatalf("attachments = %+v, want source, mask, reference", call.attachments)
+	}
+	if !strings.HasPrefix(call.prompt, "make it blue") || !strings.Contains(call.prompt, maskInstruction) {
+		t.Errorf("prompt = %q, want the caller prompt followed by the mask instruction", call.prompt)
+	}
+}
+
+func TestEditWithoutMaskKeepsPrompt(t *testing.T) {
+	fake := &fakeGenerator{}
+	client := newTestClient(t, fake)
+
+	_, err := client.Edit(context.Background(), "gemini-image", EditRequest{
+		Request: Request{Prompt: "make it blue"},
+		Source:  gemini.Attachment{MIMEType: "image/png", Data: pngHeader(4, 4)},
+	})
+	if err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+	if call := fake.calls[0]; call.prompt != "make it blue" || len(call.attachments) != 1 {
+		t.Errorf("call = %q with %d attachments, want the prompt unchanged and only the source", call.prompt, len(call.attachments))
+	}
+}
+
+func TestEditRequiresSource(t *testing.T) {
+	client := newTestClient(t, &fakeGenerator{})
+	if _, err := client.Edit(context.Background(), "m", EditRequest{Request: Request{Prompt: "x"}}); !errors.Is(err, ErrMissingSource) {
+		t.Fatalf("Edit() error = %v, want ErrMissingSource", err)
+	}
+}
@@ -0,0 +1,15 @@
+package image
+
+// Option は Client の設定を適用する関数型です。
+// 不正な値（ゼロ以下）は「指定なし」として無視し、既定値のままにします。
+type Option func(*Client)
+
+// WithConcurrency は、バリエーションを並行して生成する呼び出しの上限数を設定します。
+// Request.Count がこれを超える場合、残りは空きを待って順に呼び出されます。
+func WithConcurrency(n int) Option {
+	return func(c *Client) {
+		if n > 0 {
+			c.concurrency = n
+		}
+	}
+}
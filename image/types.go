@@ -0,0 +1,80 @@
+package image
+
+import "github.com/shouni/go-gemini-client/gemini"
+
+// Request は画像生成1回分の入力です。
+type Request struct {
+	// Prompt は生成指示です。必須です。
+	Prompt string
+	// References は被写体や画風の参照画像です。プロンプトの後に、この順で添付されます。
+	References []gemini.Attachment
+
+	// AspectRatio は "1:1" / "16:9" などの縦横比です。空は API のデフォルトに委ねます。
+	AspectRatio string
+	// ImageSize は "1K" / "2K" などの出力サイズです。空は API のデフォルトに委ねます。
+	ImageSize string
+	// PersonGeneration は人物生成の許可設定です。Vertex AI でのみ送信されます。
+	PersonGeneration gemini.PersonGeneration
+
+	// Seed は生成の再現性を確保するための乱数シードです。nil は毎回ランダムです。
+	// Count が 2 以上の場合、i 本目（0 始まり）には Seed+i を使います。
+	Seed *int64
+	// Count は生成するバリエーションの数です。0 以下は 1 として扱います。
+	// 1回の呼び出しで複数枚を返さないモデルがあるため、本数分の呼び出しを並行して行います。
+	Count int
+}
+
+// EditRequest は既存画像の編集1回分の入力です。
+//
+// 出力の設定（縦横比・Seed・Count など）と参照画像は Request と共通なので、
+// 埋め込みで持ちます。Prompt には「どう編集するか」を書きます。
+type EditRequest struct {
+	Request
+	// Source は編集する元画像です。必須です。
+	Source gemini.Attachment
+	// Mask は編集範囲を示すマスク画像です（白が編集範囲、黒が保持範囲）。
+	// nil の場合は画像全体がプロンプトに従って編集されます。
+	Mask *gemini.Attachment
+}
+
+// Image は生成された画像1枚です。
+type Image struct {
+	// MIMEType は "image/png" のような画像の種別です。
+	MIMEType string
+	// Data は画像のバイト列です。
+	Data []byte
+	// Width と Height は画像ヘッダから読み取った寸法（ピクセル）です。
+	// 形式が未対応、またはヘッダが壊れていて読み取れない場合は 0 です。
+	Width  int
+	Height int
+	// Seed はこの画像の生成に使ったシードです。Request.Seed が nil の場合は nil です。
+	Seed *int64
+	// Variation は、この画像が何本目のバリエーションから得られたか（0 始まり）です。
+	Variation int
+}
+
+// Attachment は画像を添付として返します。生成結果を Edit の Source や
+// 次の生成の References にそのまま渡すための変換です。
+func (i Image) Attachment() gemini.Attachment {
+	return gemini.Attachment{MIMEType: i.MIMEType, Data: i.Data}
+}
+
+// Result は画像生成・編集の結果です。
+type Result struct {
+	// Images は生成された画像です。バリエーションの順（Variation の昇順）に並びます。
+	Images []Image
+	// Text は画像と一緒に返された説明文です。バリエーションごとに改行で連結します。
+	Text string
+	// Usage は全バリエーションのトークン使用量の合計です。
+	// どの呼び出しも使用量を返さなかった場合は nil です。
+	Usage *gemini.TokenUsage
+}
+
+// First は最初の画像を返します。1枚だけ要求する通常の使い方で、添字アクセスと
+// 境界チェックを毎回書かずに済むようにしています。画像が無い場合は false を返します。
+func (r *Result) First() (Image, bool) {
+	if r == nil || len(r.Images) == 0 {
+		return Image{}, false
+	}
+	return r.Images[0], true
+}
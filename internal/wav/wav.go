@@ -0,0 +1,131 @@
+// Package wav は、リニア PCM と WAV（RIFF）コンテナの相互変換を提供します。
+//
+// 音声合成・ライブセッション・楽曲生成はいずれも生の PCM か WAV を返し、
+// 再生可能な形に包む処理と、ヘッダから形式を読み取る処理が共通して必要になります。
+// パッケージごとに書き写すとヘッダのバイト配置の誤りがどこか1か所にだけ残るため、
+// ここに集約しています。外部へ公開する理由は無いので internal に置いています。
+package wav
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalid は、データが解釈できる WAV ではない場合に返されます。
+var ErrInvalid = errors.New("wav: invalid WAV data")
+
+// headerSize は、Encode が書き出す標準的な 44 バイトのヘッダ長です。
+const headerSize = 44
+
+// Format は PCM の形式です。
+type Format struct {
+	// SampleRate はサンプリング周波数（Hz）です。
+	SampleRate int
+	// Channels はチャンネル数です（1 = モノラル、2 = ステレオ）。
+	Channels int
+	// BitsPerSample は1サンプルあたりのビット数です（通常 16）。
+	BitsPerSample int
+}
+
+// blockAlign は、全チャンネル分の1サンプルのバイト数です。
+func (f Format) blockAlign() int {
+	return f.Channels * f.BitsPerSample / 8
+}
+
+// Valid は、ヘッダを書くのに必要な値がすべて正かを返します。
+func (f Format) Valid() bool {
+	return f.SampleRate > 0 && f.Channels > 0 && f.BitsPerSample > 0 && f.BitsPerSample%8 == 0
+}
+
+// Duration は、PCM のバイト数から再生時間を求めます。
+func (f Format) Duration(pcmBytes int) time.Duration {
+	if !f.Valid() {
+		return 0
+	}
+	frames := pcmBytes / f.blockAlign()
+	return time.Duration(frames) * time.Second / time.Duration(f.SampleRate)
+}
+
+// Offset は、再生位置 d に対応する PCM のバイト位置を返します。
+// 位置はフレーム境界に切り捨てるので、そこで切り出してもサンプルが途中で割れません。
+func (f Format) Offset(d time.Duration) int {
+	if !f.Valid() || d <= 0 {
+		return 0
+	}
+	frames := int64(d) * int64(f.SampleRate) / int64(time.Second)
+	return int(frames) * f.blockAlign()
+}
+
+// Encode は、リトルエンディアンの PCM を WAV で包みます。
+func Encode(pcm []byte, format Format) ([]byte, error) {
+	if !format.Valid() {
+		return nil, fmt.Errorf("%w: 形式が不正です（%+v）", ErrInvalid, format)
+	}
+	// 最後のサンプルが途中で切れていると再生ソフトによってはノイズになるため、
+	// フレーム境界に切り詰める。
+	pcm = pcm[:len(pcm)-len(pcm)%format.blockAlign()]
+
+	out := make([]byte, headerSize, headerSize+len(pcm))
+	copy(out[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(out[4:8], uint32(36+len(pcm)))
+	copy(out[8:12], "WAVE")
+	copy(out[12:16], "fmt ")
+	binary.LittleEndian.PutUint32(out[16:20], 16)
+	binary.LittleEndian.PutUint16(out[20:22], 1) // リニア PCM
+	binary.LittleEndian.PutUint16(out[22:24], uint16(format.Channels))
+	binary.LittleEndian.PutUint32(out[24:28], uint32(format.SampleRate))
+	binary.LittleEndian.PutUint32(out[28:32], uint32(format.SampleRate*format.blockAlign()))
+	binary.LittleEndian.PutUint16(out[32:34], uint16(format.blockAlign()))
+	binary.LittleEndian.PutUint16(out[34:36], uint16(format.BitsPerSample))
+	copy(out[36:40], "data")
+	binary.LittleEndian.PutUint32(out[40:44], uint32(len(pcm)))
+	return append(out, pcm...), nil
+}
+
+// Decode は WAV を読み、形式と PCM 本体を返します。
+//
+// チャンクは順に走査するので、fmt と data の間に LIST などの付加チャンクが
+// 挟まっていても読めます。返す PCM は data の部分スライスで、複製しません。
+func Decode(data []byte) (Format, []byte, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return Format{}, nil, fmt.Errorf("%w: RIFF/WAVE ヘッダがありません", ErrInvalid)
+	}
+
+	var format Format
+	haveFormat := false
+	for offset := 12; offset+8 <= len(data); {
+		id := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		body := offset + 8
+		if size < 0 || body+size > len(data) {
+			// 生成途中で切れたストリームなど、data チャンクの長さが実体より長いことがある。
+			// data に限っては手元にある分を本体として扱う。
+			if id == "data" && haveFormat {
+				return format, data[body:], nil
+			}
+			return Format{}, nil, fmt.Errorf("%w: チャンク %q が途中で切れています", ErrInvalid, id)
+		}
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return Format{}, nil, fmt.Errorf("%w: fmt チャンクが短すぎます", ErrInvalid)
+			}
+			format = Format{
+				Channels:      int(binary.LittleEndian.Uint16(data[body+2 : body+4])),
+				SampleRate:    int(binary.LittleEndian.Uint32(data[body+4 : body+8])),
+				BitsPerSample: int(binary.LittleEndian.Uint16(data[body+14 : body+16])),
+			}
+			haveFormat = true
+		case "data":
+			if !haveFormat {
+				return Format{}, nil, fmt.Errorf("%w: data チャンクが fmt チャンクより前にあります", ErrInvalid)
+			}
+			return format, data[body : body+size], nil
+		}
+		// チャンクは偶数バイト境界に揃えられる。
+		offset = body + size + size%2
+	}
+	return Format{}, nil, fmt.Errorf("%w: data チャンクがありません", ErrInvalid)
+}
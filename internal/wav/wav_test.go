@@ -0,0 +1,94 @@
+package wav
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	format := Format{SampleRate: 24000, Channels: 1, BitsPerSample: 16}
+	pcm := bytes.Repeat([]byte{0x01, 0x02}, 24000) // 1 秒分
+
+	encoded, err := Encode(pcm, format)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if len(encoded) != headerSize+len(pcm) {
+		t.Fatalf("len = %d, want header + pcm", len(encoded))
+	}
+
+	gotFormat, gotPCM, err := Decode(encoded)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if gotFormat != format {
+		t.Errorf("format = %+v, want %+v", gotFormat, format)
+	}
+	if !bytes.Equal(gotPCM, pcm) {
+		t.Error("PCM did not survive the round trip")
+	}
+	if got := gotFormat.Duration(len(gotPCM)); got != time.Second {
+		t.Errorf("Duration = %v, want 1s", got)
+	}
+}
+
+func TestFormatOffsetAlignsToFrames(t *testing.T) {
+	stereo := Format{SampleRate: 48000, Channels: 2, BitsPerSample: 16}
+	if got := stereo.Offset(time.Second); got != 192000 {
+		t.Errorf("Offset(1s) = %d, want 192000", got)
+	}
+	// 1/3 ミリ秒は 16 フレームちょうどにならないので、フレーム境界へ切り捨てる。
+	if got := stereo.Offset(time.Millisecond / 3); got%4 != 0 || got != 15*4 {
+		t.Errorf("Offset(1/3ms) = %d, want 60 (frame aligned)", got)
+	}
+	if got := stereo.Offset(-time.Second); got != 0 {
+		t.Errorf("Offset(negative) = %d, want 0", got)
+	}
+}
+
+// TestEncodeTrimsPartialFrame verifies a trailing half sample is dropped so the data chunk length
+// is always a whole number of frames.
+func TestEncodeTrimsPartialFrame(t *testing.T) {
+	encoded, err := Encode([]byte{1, 2, 3}, Format{SampleRate: 8000, Channels: 1, BitsPerSample: 16})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if len(encoded) != headerSize+2 {
+		t.Errorf("len = %d, want header + 2 bytes", len(encoded))
+	}
+}
+
+// TestDecodeSkipsExtraChunks verifies a LIST chunk between fmt and data does not hide the audio.
+// Encoders that write metadata put it there.
+func TestDecodeSkipsExtraChunks(t *testing.T) {
+	encoded, _ := Encode([]byte{9, 9, 9, 9}, Format{SampleRate: 44100, Channels: 2, BitsPerSample: 16})
+	list := []byte("LIST\x03\x00\x00\x00abc\x00") // 奇数長 + パディング
+	withList := append(append(append([]byte{}, encoded[:36]...), list...), encoded[36:]...)
+
+	format, pcm, err := Decode(withList)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if format.Channels != 2 || !bytes.Equal(pcm, []byte{9, 9, 9, 9}) {
+		t.Errorf("Decode() = %+v / %v", format, pcm)
+	}
+}
+
+func TestDecodeRejectsInvalid(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"empty":   nil,
+		"not wav": []byte("RIFF\x00\x00\x00\x00WEBPVP8 "),
+		"no data": []byte("RIFF\x00\x00\x00\x00WAVEfmt \x10\x00\x00\x00\x01\x00\x01\x00\x40\x1f\x00\x00\x80\x3e\x00\x00\x02\x00\x10\x00"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := Decode(data); !errors.Is(err, ErrInvalid) {
+				t.Errorf("Decode() error = %v, want ErrInvalid", err)
+			}
+		})
+	}
+	if _, err := Encode(nil, Format{}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Encode(zero format) error = %v, want ErrInvalid", err)
+	}
+}
@@ -0,0 +1,370 @@
+// Package kana は、漢字かな交じりの文を、ひらがなかカタカナの読みに書き換える変換器を
+// 提供します。
+//
+// Lyria は漢字の読みを取り違えることがあるため、lyria.WithReadingConverter に渡して、
+// 日本語の歌詞をモデルに渡す前に読みへ書き換えるのに使います。
+//
+// 変換は外部の辞書ファイルや cgo に頼らない、同梱の辞書による最長一致の分かち書きです。
+// 辞書に無い漢字は1字ずつの音読み・訓読みで推し、固有名詞などは利用者辞書
+// （WithUserDictionary）で補います。"[Verse]" や "【サビ】" のようなタグの中身と、
+// 句読点・記号・英数字はそのまま残します。
+package kana
+
+import (
+	"bufio"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// builtinDictionary は同梱の辞書です（dict/words.tsv と dict/kanji.tsv）。
+//
+//go:embed dict
+var builtinDictionary embed.FS
+
+// ErrInvalidEntry は、辞書の項目の表記が空か、読みが仮名でない場合に返されます。
+var ErrInvalidEntry = errors.New("kana: invalid dictionary entry")
+
+// Script は読みを書く文字です。
+type Script int
+
+const (
+	// Hiragana は読みをひらがなで書きます。元からカタカナで書かれた語はそのまま残します。
+	Hiragana Script = iota
+	// Katakana は、読みと元からひらがなで書かれた部分をカタカナで書きます。
+	Katakana
+)
+
+// TokenKind は Token の由来です。
+type TokenKind int
+
+const (
+	// TokenText は、仮名・句読点・英数字など、書き換えずに残す文字列です。
+	TokenText TokenKind = iota
+	// TokenTag は "[Verse]" や "【サビ】" のようなタグです。中身も含めてそのまま残します。
+	TokenTag
+	// TokenWord は辞書（利用者辞書を含む）で読んだ語です。
+	TokenWord
+	// TokenKanji は、辞書に無いため1字ずつの読みで推した漢字です。読みが誤っている
+	// ことがあるため、気になる語は利用者辞書に加えてください。
+	TokenKanji
+	// TokenUnknown は読みの分からない漢字です。Reading は Surface と同じです。
+	TokenUnknown
+)
+
+// Token は、Tokenize で分けた文字列の1区切りです。
+type Token struct {
+	Surface string
+	Reading string
+	Kind    TokenKind
+}
+
+// kanjiReading は、1字の漢字の音読みと訓読み（送り仮名を除いた語幹）です。
+type kanjiReading struct {
+	on, kun string
+}
+
+// dictionary は同梱の辞書を読み込んだものです。
+type dictionary struct {
+	words  map[string]string
+	maxLen int // words の表記の最大の文字数
+	kanji  map[rune]kanjiReading
+}
+
+// builtin は同梱の辞書です。同梱の辞書はテストで検証しているため、読み込みに失敗した
+// 場合は panic します。
+var builtin = sync.OnceValue(func() *dictionary {
+	d, err := loadBuiltin()
+	if err != nil {
+		panic(err)
+	}
+	return d
+})
+
+func loadBuiltin() (*dictionary, error) {
+	wordsFile, err := builtinDictionary.Open("dict/words.tsv")
+	if err != nil {
+		return nil, fmt.Errorf("kana: failed to open builtin dictionary: %w", err)
+	}
+	defer wordsFile.Close()
+	words, err := LoadDictionary(wordsFile)
+	if err != nil {
+		return nil, fmt.Errorf("kana: builtin dictionary: %w", err)
+	}
+
+	data, err := builtinDictionary.ReadFile("dict/kanji.tsv")
+	if err != nil {
+		return nil, fmt.Errorf("kana: failed to open builtin kanji readings: %w", err)
+	}
+	kanji := make(map[rune]kanjiReading)
+	for n, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		r, size := utf8.DecodeRuneInString(fields[0])
+		if len(fields) != 3 || size != len(fields[0]) || !unicode.Is(unicode.Han, r) {
+			return nil, fmt.Errorf("kana: builtin kanji readings line %d: %w: %q", n+1, ErrInvalidEntry, line)
+		}
+		var reading kanjiReading
+		for i, field := range fields[1:] {
+			if field == "-" {
+				continue
+			}
+			kana, ok := normalizeReading(field)
+			if !ok {
+				return nil, fmt.Errorf("kana: builtin kanji readings line %d: %w: %q", n+1, ErrInvalidEntry, line)
+			}
+			if i == 0 {
+				reading.on = kana
+			} else {
+				reading.kun = kana
+			}
+		}
+		kanji[r] = reading
+	}
+	return &dictionary{words: words, maxLen: maxLength(words), kanji: kanji}, nil
+}
+
+// LoadDictionary は、"表記<TAB>読み" を1行ずつ書いた辞書を読み込みます。空行と "#" で
+// 始まる行は読み飛ばします。読みはひらがなに揃えて返すため、そのまま
+// WithUserDictionary に渡せます。表記が空か読みが仮名でない行があると、行番号を添えた
+// ErrInvalidEntry を返します。
+func LoadDictionary(r io.Reader) (map[string]string, error) {
+	entries := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		surface, reading, ok := strings.Cut(line, "\t")
+		if !ok {
+			return nil, fmt.Errorf("line %d: %w: %q has no tab-separated reading", n, ErrInvalidEntry, line)
+		}
+		surface, reading = strings.TrimSpace(surface), strings.TrimSpace(reading)
+		kana, ok := normalizeReading(reading)
+		if surface == "" || !ok {
+			return nil, fmt.Errorf("line %d: %w: %q", n, ErrInvalidEntry, line)
+		}
+		entries[surface] = kana
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("kana: failed to read dictionary: %w", err)
+	}
+	return entries, nil
+}
+
+// Converter は漢字かな交じりの文を読みに書き換えます。lyria.ReadingConverter を満たし、
+// 複数のゴルーチンから同時に使えます。
+type Converter struct {
+	script      Script
+	userEntries map[string]string // Option で受け取った利用者辞書
+	user        map[string]string // 読みをひらがなに揃えた利用者辞書
+	userMaxLen  int
+	dict        *dictionary
+}
+
+// New は同梱の辞書を使う Converter を初期化します。
+//
+//	conv, err := kana.New(kana.WithUserDictionary(map[string]string{"星野": "ほしの"}))
+//	workflow, err := lyria.New(gc, nil, nil, lyria.WithReadingConverter(conv))
+func New(opts ...Option) (*Converter, error) {
+	c := &Converter{script: Hiragana, dict: builtin()}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.user = make(map[string]string, len(c.userEntries))
+	for surface, reading := range c.userEntries {
+		kana, ok := normalizeReading(reading)
+		if strings.TrimSpace(surface) == "" || !ok {
+			return nil, fmt.Errorf("%w: %q → %q", ErrInvalidEntry, surface, reading)
+		}
+		c.user[surface] = kana
+	}
+	c.userMaxLen = maxLength(c.user)
+	return c, nil
+}
+
+// ConvertToReading は input の漢字を読みに書き換えた文字列を返します。
+func (c *Converter) ConvertToReading(input string) string {
+	var b strings.Builder
+	for _, token := range c.Tokenize(input) {
+		b.WriteString(token.Reading)
+	}
+	return b.String()
+}
+
+// Tokenize は input を、タグ・辞書の語・1字ずつ読んだ漢字・そのまま残す文字列に
+// 分けます。各 Token の Surface をつなぐと input に、Reading をつなぐと
+// ConvertToReading の結果になります。
+func (c *Converter) Tokenize(input string) []Token {
+	runes := []rune(input)
+	var tokens []Token
+	var text []rune // TokenText としてまとめている途中の文字
+	flush := func() {
+		if len(text) > 0 {
+			tokens = append(tokens, Token{Surface: string(text), Reading: c.write(string(text)), Kind: TokenText})
+			text = nil
+		}
+	}
+
+	for i := 0; i < len(runes); {
+		if end := tagEnd(runes, i); end > 0 {
+			flush()
+			tag := string(runes[i:end])
+			tokens = append(tokens, Token{Surface: tag, Reading: tag, Kind: TokenTag})
+			i = end
+			continue
+		}
+		if surface, reading, ok := c.lookup(runes, i); ok {
+			flush()
+			tokens = append(tokens, Token{Surface: surface, Reading: c.write(reading), Kind: TokenWord})
+			i += utf8.RuneCountInString(surface)
+			continue
+		}
+		if !isKanji(runes[i]) {
+			text = append(text, runes[i])
+			i++
+			continue
+		}
+		flush()
+		tokens = append(tokens, c.readKanji(runes, i, tokens))
+		i++
+	}
+	flush()
+	return tokens
+}
+
+// lookup は runes[i:] の先頭に一致する最も長い語を、利用者辞書・同梱の辞書の順に
+// 探します。同梱の辞書は漢字で始まる位置でだけ引き、利用者辞書は仮名や英字で始まる
+// 固有名詞（"ミセス" など）のためにどの位置でも引きます。
+func (c *Converter) lookup(runes []rune, i int) (string, string, bool) {
+	kanji := isKanji(runes[i])
+	longest := c.userMaxLen
+	if kanji {
+		longest = max(longest, c.dict.maxLen)
+	}
+	for n := min(longest, len(runes)-i); n > 0; n-- {
+		surface := string(runes[i : i+n])
+		if reading, ok := c.user[surface]; ok {
+			return surface, reading, true
+		}
+		if reading, ok := c.dict.words[surface]; ok && kanji {
+			return surface, reading, true
+		}
+	}
+	return "", "", false
+}
+
+// counters は、数字に続くときの助数詞の読みです（"3時" の "時" は "じ"）。
+var counters = map[rune]string{
+	'時': "じ", '分': "ふん", '秒': "びょう", '人': "にん", '日': "にち", '年': "ねん",
+	'月': "がつ", '回': "かい", '度': "ど", '歳': "さい", '階': "かい",
+}
+
+// readKanji は辞書に無い漢字 runes[i] を1字で読みます。数字に続く助数詞は counters の
+// 読み、送り仮名が続けば訓読み、漢字が隣り合えば音読み、それ以外は訓読みを優先します。
+// "々" は直前の漢字の読みを繰り返します。
+func (c *Converter) readKanji(runes []rune, i int, previous []Token) Token {
+	surface := string(runes[i])
+	if runes[i] == '々' {
+		if n := len(previous); n > 0 && previous[n-1].Kind == TokenKanji {
+			return Token{Surface: surface, Reading: previous[n-1].Reading, Kind: TokenKanji}
+		}
+		return Token{Surface: surface, Reading: surface, Kind: TokenUnknown}
+	}
+	reading, ok := c.dict.kanji[runes[i]]
+	if !ok || (reading.on == "" && reading.kun == "") {
+		return Token{Surface: surface, Reading: surface, Kind: TokenUnknown}
+	}
+	if i > 0 && unicode.IsDigit(runes[i-1]) {
+		if counter, ok := counters[runes[i]]; ok {
+			return Token{Surface: surface, Reading: c.write(counter), Kind: TokenKanji}
+		}
+	}
+	prefer, fallback := reading.kun, reading.on
+	okurigana := i+1 < len(runes) && isHiragana(runes[i+1])
+	compound := (i > 0 && isKanji(runes[i-1])) || (i+1 < len(runes) && isKanji(runes[i+1]))
+	if compound && !okurigana {
+		prefer, fallback = reading.on, reading.kun
+	}
+	if prefer == "" {
+		prefer = fallback
+	}
+	return Token{Surface: surface, Reading: c.write(prefer), Kind: TokenKanji}
+}
+
+// write は、ひらがなの読み（または残す文字列）を Script に合わせて書きます。
+func (c *Converter) write(s string) string {
+	if c.script != Katakana {
+		return s
+	}
+	return strings.Map(func(r rune) rune {
+		if r >= 'ぁ' && r <= 'ゖ' {
+			return r + 0x60
+		}
+		return r
+	}, s)
+}
+
+// tagEnd は、runes[i] から始まる "[...]" か "【...】" のタグの直後の位置を返します。
+// 同じ行で閉じていなければ 0 です。
+func tagEnd(runes []rune, i int) int {
+	var closing rune
+	switch runes[i] {
+	case '[':
+		closing = ']'
+	case '【':
+		closing = '】'
+	default:
+		return 0
+	}
+	for j := i + 1; j < len(runes) && runes[j] != '\n'; j++ {
+		if runes[j] == closing {
+			return j + 1
+		}
+	}
+	return 0
+}
+
+// normalizeReading は読みをひらがなに揃えます。仮名と長音符以外を含む場合は false です。
+func normalizeReading(reading string) (string, bool) {
+	if reading == "" {
+		return "", false
+	}
+	var b strings.Builder
+	for _, r := range reading {
+		switch {
+		case isHiragana(r), r == 'ー':
+			b.WriteRune(r)
+		case r >= 'ァ' && r <= 'ヶ':
+			b.WriteRune(r - 0x60)
+		default:
+			return "", false
+		}
+	}
+	return b.String(), true
+}
+
+func isKanji(r rune) bool {
+	return r == '々' || unicode.Is(unicode.Han, r)
+}
+
+func isHiragana(r rune) bool {
+	return r >= 'ぁ' && r <= 'ゖ'
+}
+
+// maxLength は words の表記の最大の文字数です。
+func maxLength(words map[string]string) int {
+	n := 0
+	for surface := range words {
+		n = max(n, utf8.RuneCountInString(surface))
+	}
+	return n
+}
@@ -0,0 +1,131 @@
+package kana
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// 読みの変換器が lyria.ReadingConverter を満たすことを確認します（lyria を import しない形）。
+var _ interface{ ConvertToReading(string) string } = (*Converter)(nil)
+
+func TestConvertToReadingCorpus(t *testing.T) {
+	data, err := os.ReadFile("testdata/corpus.tsv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	converter, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	cases := 0
+	for line := range strings.SplitSeq(string(data), "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		input, want, ok := strings.Cut(line, "\t")
+		if !ok {
+			t.Fatalf("corpus の行にタブがありません: %q", line)
+		}
+		cases++
+		if got := converter.ConvertToReading(input); got != want {
+			t.Errorf("ConvertToReading(%q) = %q, want %q", input, got, want)
+		}
+	}
+	if cases == 0 {
+		t.Fatal("corpus が空です")
+	}
+}
+
+func TestConvertToReadingKeepsTagsAndLayout(t *testing.T) {
+	converter, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	input := "[Verse 1]\n夜空に星\n\n【サビ】\n走り出せ！\n[Chorus: 二人で]\n"
+	want := "[Verse 1]\nよぞらにほし\n\n【サビ】\nはしりだせ！\n[Chorus: 二人で]\n"
+	if got := converter.ConvertToReading(input); got != want {
+		t.Errorf("ConvertToReading() = %q, want %q", got, want)
+	}
+	// 閉じていない括弧はタグとして扱わない。
+	if got := converter.ConvertToReading("[夢"); got != "[ゆめ" {
+		t.Errorf("ConvertToReading(%q) = %q", "[夢", got)
+	}
+}
+
+func TestUserDictionaryTakesPrecedence(t *testing.T) {
+	dictionary, err := LoadDictionary(strings.NewReader("# 固有名詞\n星野\tホシノ\n明日香\tあすか\nミセス\tみせす\n"))
+	if err != nil {
+		t.Fatalf("LoadDictionary() error = %v", err)
+	}
+	converter, err := New(WithUserDictionary(dictionary), WithUserDictionary(map[string]string{"今日": "こんにち"}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if got, want := converter.ConvertToReading("星野明日香と今日のミセス"), "ほしのあすかとこんにちのみせす"; got != want {
+		t.Errorf("ConvertToReading() = %q, want %q", got, want)
+	}
+}
+
+func TestKatakanaScript(t *testing.T) {
+	converter, err := New(WithScript(Katakana))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if got, want := converter.ConvertToReading("[Intro]\n君のメロディーが響く"), "[Intro]\nキミノメロディーガヒビク"; got != want {
+		t.Errorf("ConvertToReading() = %q, want %q", got, want)
+	}
+}
+
+func TestTokenizeReportsGuessesAndUnknownKanji(t *testing.T) {
+	converter, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	tokens := converter.Tokenize("【間奏】今日、鬱の雲")
+	var kinds []TokenKind
+	var surfaces strings.Builder
+	for _, token := range tokens {
+		kinds = append(kinds, token.Kind)
+		surfaces.WriteString(token.Surface)
+	}
+	want := []TokenKind{TokenTag, TokenWord, TokenText, TokenUnknown, TokenText, TokenKanji}
+	if len(kinds) != len(want) {
+		t.Fatalf("Tokenize() = %+v", tokens)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Errorf("tokens[%d].Kind = %v, want %v (%+v)", i, kinds[i], want[i], tokens[i])
+		}
+	}
+	if surfaces.String() != "【間奏】今日、鬱の雲" {
+		t.Errorf("Surface をつないだ文字列が入力と違います: %q", surfaces.String())
+	}
+}
+
+func TestInvalidDictionaryEntries(t *testing.T) {
+	for name, entries := range map[string]map[string]string{
+		"漢字の読み": {"星野": "星の"},
+		"英字の読み": {"星野": "hoshino"},
+		"空の表記":  {" ": "ほし"},
+		"空の読み":  {"星野": ""},
+	} {
+		if _, err := New(WithUserDictionary(entries)); !errors.Is(err, ErrInvalidEntry) {
+			t.Errorf("%s: New() error = %v, want ErrInvalidEntry", name, err)
+		}
+	}
+	if _, err := LoadDictionary(strings.NewReader("星野 ほしの\n")); !errors.Is(err, ErrInvalidEntry) {
+		t.Errorf("タブの無い行の LoadDictionary() error = %v, want ErrInvalidEntry", err)
+	}
+}
+
+func TestBuiltinDictionaryLoads(t *testing.T) {
+	d, err := loadBuiltin()
+	if err != nil {
+		t.Fatalf("loadBuiltin() error = %v", err)
+	}
+	if len(d.words) == 0 || len(d.kanji) == 0 || d.maxLen == 0 {
+		t.Errorf("同梱の辞書が空です: %d 語, %d 字", len(d.words), len(d.kanji))
+	}
+}
@@ -0,0 +1,30 @@
+package kana
+
+import "maps"
+
+// Option は Converter の設定を適用する関数型です。
+type Option func(*Converter)
+
+// WithScript は読みを書く文字を設定します。既定は Hiragana です。
+func WithScript(script Script) Option {
+	return func(c *Converter) {
+		if script == Hiragana || script == Katakana {
+			c.script = script
+		}
+	}
+}
+
+// WithUserDictionary は、同梱の辞書より優先して引く語（表記 → 読み）を追加します。
+// 人名・地名・バンド名などの固有名詞や、同梱の辞書と違う読みをさせたい語に使います。
+//
+// 読みはひらがなかカタカナで書きます（長音符 "ー" も使えます）。複数回指定した場合は
+// まとめて使い、同じ表記は後に指定したものを優先します。読みが仮名でない項目があると
+// New が ErrInvalidEntry を返します。
+func WithUserDictionary(entries map[string]string) Option {
+	return func(c *Converter) {
+		if c.userEntries == nil {
+			c.userEntries = make(map[string]string, len(entries))
+		}
+		maps.Copy(c.userEntries, entries)
+	}
+}
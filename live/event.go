@@ -0,0 +1,110 @@
+package live
+
+import (
+	"time"
+
+	"github.com/shouni/go-gemini-client/gemini"
+)
+
+// EventType はイベントの種類です。
+type EventType string
+
+// イベントの種類です。
+const (
+	// EventText はモデルの応答テキストの断片です（Event.Text）。
+	EventText EventType = "text"
+	// EventAudio はモデルの応答音声の断片です（Event.Audio / Event.MIMEType）。
+	EventAudio EventType = "audio"
+	// EventInputTranscript は、送った音声の文字起こしの断片です（Event.Text）。
+	EventInputTranscript EventType = "input_transcript"
+	// EventOutputTranscript は、応答音声の文字起こしの断片です（Event.Text）。
+	EventOutputTranscript EventType = "output_transcript"
+	// EventToolCall はモデルからの関数呼び出しの要求です（Event.FunctionCalls）。
+	// 結果は Session.SendToolResponses で返します。
+	EventToolCall EventType = "tool_call"
+	// EventToolCallCancelled は、関数呼び出しが取り消されたことを示します（Event.CancelledCallIDs）。
+	EventToolCallCancelled EventType = "tool_call_cancelled"
+	// EventInterrupted は、ユーザーの発話などで応答が中断されたことを示します。
+	// まだ受け取っていなかった応答の断片は、このイベントより前に捨てられています。
+	// 再生中の音声があれば止めてください。
+	EventInterrupted EventType = "interrupted"
+	// EventTurnComplete は、モデルの1ターンの応答が終わったことを示します。
+	EventTurnComplete EventType = "turn_complete"
+	// EventUsage はトークン使用量の通知です（Event.Usage）。
+	EventUsage EventType = "usage"
+	// EventGoAway は、サーバーが間もなく接続を切ることを示します（Event.TimeLeft）。
+	// 再開できる設定であれば、切断後に自動で再接続します。
+	EventGoAway EventType = "go_away"
+	// EventResumed は、切断されたセッションを再開したことを示します。
+	// 切断の間に届くはずだった応答の断片は失われている可能性があります。
+	EventResumed EventType = "resumed"
+)
+
+// Event はセッションで起きた出来事1つです。
+//
+// gemini.LiveMessage は1件に複数の出来事を載せ得るため、種類ごとに分けて
+// 届いた順に並べ直したものです。Type に関係しないフィールドはゼロ値です。
+type Event struct {
+	Type EventType
+	// Text は EventText・EventInputTranscript・EventOutputTranscript の本文です。
+	Text string
+	// Audio と MIMEType は EventAudio の音声断片とその形式です（"audio/pcm;rate=24000" など）。
+	Audio    []byte
+	MIMEType string
+	// FunctionCalls は EventToolCall の呼び出し要求です。
+	FunctionCalls []gemini.FunctionCall
+	// CancelledCallIDs は EventToolCallCancelled で取り消された呼び出しの ID です。
+	CancelledCallIDs []string
+	// TimeLeft は EventGoAway で、接続が切られるまでの残り時間です。
+	TimeLeft time.Duration
+	// Usage は EventUsage のトークン使用量です。
+	Usage *gemini.TokenUsage
+}
+
+// isResponseOutput は、中断された応答の一部として捨てる対象のイベントかを返します。
+func (e Event) isResponseOutput() bool {
+	switch e.Type {
+	case EventText, EventAudio, EventOutputTranscript:
+		return true
+	}
+	return false
+}
+
+// eventsFrom はメッセージ1件を、届いた順のイベントの列に分けます。
+//
+// 並びは「入力の文字起こし → 応答 → 関数呼び出し → 割り込み → ターン終了」で、
+// 利用側が上から順に処理すれば自然な流れになるようにしています。
+func eventsFrom(msg *gemini.LiveMessage) []Event {
+	var events []Event
+	if msg.InputTranscript != "" {
+		events = append(events, Event{Type: EventInputTranscript, Text: msg.InputTranscript})
+	}
+	if msg.Text != "" {
+		events = append(events, Event{Type: EventText, Text: msg.Text})
+	}
+	for _, audio := range msg.Audio {
+		events = append(events, Event{Type: EventAudio, Audio: audio.Data, MIMEType: audio.MIMEType})
+	}
+	if msg.OutputTranscript != "" {
+		events = append(events, Event{Type: EventOutputTranscript, Text: msg.OutputTranscript})
+	}
+	if len(msg.FunctionCalls) > 0 {
+		events = append(events, Event{Type: EventToolCall, FunctionCalls: msg.FunctionCalls})
+	}
+	if len(msg.CancelledCallIDs) > 0 {
+		events = append(events, Event{Type: EventToolCallCancelled, CancelledCallIDs: msg.CancelledCallIDs})
+	}
+	if msg.Interrupted {
+		events = append(events, Event{Type: EventInterrupted})
+	}
+	if msg.TurnComplete {
+		events = append(events, Event{Type: EventTurnComplete})
+	}
+	if msg.Usage != nil {
+		events = append(events, Event{Type: EventUsage, Usage: msg.Usage})
+	}
+	if msg.GoAway {
+		events = append(events, Event{Type: EventGoAway, TimeLeft: msg.GoAwayTimeLeft})
+	}
+	return events
+}
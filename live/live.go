@@ -0,0 +1,359 @@
+// Package live は、Gemini Live API による双方向のリアルタイム対話（音声・テキストの
+// 入力を流しながら、応答の断片を逐次受け取る）を扱うクライアントを提供します。
+//
+// gemini パッケージが持つのは接続1本分の送受信（gemini.LiveConn）で、セッションとしての
+// 扱い——応答を種類ごとのイベントに分けること、割り込まれた応答の断片を捨てること、
+// サーバーの切断をまたいでセッションを再開すること——をこのパッケージが受け持ちます。
+//
+// 依存は gemini.LiveConnector の注入だけで、genai SDK には触れません。
+// テストでは gemini.LiveConnector をローカルの代役に差し替えられます。
+package live
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/shouni/go-gemini-client/gemini"
+)
+
+const (
+	// DefaultMaxReconnects は、切断されたセッションの再開を試みる既定の回数です。
+	DefaultMaxReconnects = 3
+	// DefaultReconnectDelay は、再開に失敗したときに次を試みるまでの既定の待ち時間です。
+	DefaultReconnectDelay = time.Second
+)
+
+// セッションに関するセンチネルエラーです。
+var (
+	// ErrConnectorRequired は、New に nil の接続クライアントが渡された場合に返されます。
+	ErrConnectorRequired = errors.New("live: connector is required")
+	// ErrSessionClosed は、Close 済みのセッションを使おうとした場合に返されます。
+	ErrSessionClosed = errors.New("live: session is closed")
+	// ErrConnectionLost は、接続が切れてセッションを再開できなかった場合に返されます。
+	// 再開の設定（gemini.LiveConfig.Resumption）が無い場合、サーバーがまだ再開用の
+	// ハンドルを送っていない場合、再開を試みる回数を使い切った場合が該当します。
+	ErrConnectionLost = errors.New("live: connection lost")
+)
+
+// Client はライブセッションを開くクライアントです。
+type Client struct {
+	connector      gemini.LiveConnector
+	maxReconnects  int
+	reconnectDelay time.Duration
+	logger         *slog.Logger
+}
+
+// New は、接続クライアントを注入して Client を初期化します。
+//
+// connector には *gemini.Client をそのまま渡せます。
+//
+//	gc, err := gemini.NewClient(ctx, cfg)
+//	lc, err := live.New(gc, live.WithMaxReconnects(5))
+func New(connector gemini.LiveConnector, opts ...Option) (*Client, error) {
+	if connector == nil {
+		return nil, ErrConnectorRequired
+	}
+	c := &Client{
+		connector:      connector,
+		maxReconnects:  DefaultMaxReconnects,
+		reconnectDelay: DefaultReconnectDelay,
+		logger:         slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Connect はセッションを開き、受信を始めます。
+//
+// セッションは ctx がキャンセルされるか Close を呼ぶまで続きます。切断をまたいで
+// 再開したい場合は cfg.Resumption を true にしてください。以前のセッションを
+// 引き継ぐ場合は、そのセッションの ResumptionHandle を cfg.ResumptionHandle に設定します。
+func (c *Client) Connect(ctx context.Context, modelName string, cfg gemini.LiveConfig) (*Session, error) {
+	conn, err := c.connector.ConnectLive(ctx, modelName, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionCtx, cancel := context.WithCancel(ctx)
+	s := &Session{
+		client:  c,
+		model:   modelName,
+		cfg:     cfg,
+		ctx:     sessionCtx,
+		cancel:  cancel,
+		conn:    conn,
+		handle:  cfg.ResumptionHandle,
+		changed: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	stop := context.AfterFunc(sessionCtx, func() { _ = s.Close() })
+	go func() {
+		defer stop()
+		s.readLoop()
+	}()
+	return s, nil
+}
+
+// Session は開いているライブセッション1つです。
+//
+// 送信系のメソッドは並行して呼び出せます。再開のための再接続中に呼び出した送信は、
+// 再接続が終わるまで待ってから新しい接続へ送られます。
+// 受信（Receive / Events）は1つのゴルーチンから行ってください。
+type Session struct {
+	client *Client
+	model  string
+	cfg    gemini.LiveConfig
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// connMu は接続の差し替えを守ります。再接続の間は書き込みロックを持ち続け、
+	// その間の送信を待たせます。送信は接続を取り出すときだけ読み込みロックを取り、
+	// 書き込みの間は持ちません（止まった書き込みを Close で打ち切れるようにするためです）。
+	connMu sync.RWMutex
+	conn   gemini.LiveConn
+
+	// mu は以下の受信側の状態を守ります。
+	mu      sync.Mutex
+	queue   []Event
+	err     error
+	handle  string
+	changed chan struct{} // queue か err が変わるたびに close して作り直す
+	closing bool
+
+	closeOnce sync.Once
+	closeErr  error
+	done      chan struct{}
+}
+
+// SendText はテキストを1ターン分送り、モデルに応答させます。
+func (s *Session) SendText(text string) error {
+	return s.send(func(conn gemini.LiveConn) error { return conn.SendText(text, true) })
+}
+
+// SendAudio は音声の断片をリアルタイム入力として送ります。
+// mimeType は "audio/pcm;rate=16000" のように PCM の形式を示します。
+func (s *Session) SendAudio(chunk []byte, mimeType string) error {
+	return s.send(func(conn gemini.LiveConn) error { return conn.SendAudio(chunk, mimeType) })
+}
+
+// EndAudio は、音声入力が（一時的に）途切れたことを伝えます。
+// マイクを止めたときに呼ぶと、モデルは残りの音声を待たずに応答します。
+func (s *Session) EndAudio() error {
+	return s.send(func(conn gemini.LiveConn) error { return conn.SendAudioStreamEnd() })
+}
+
+// SendToolResponses は、EventToolCall で受け取った関数呼び出しの結果を返します。
+func (s *Session) SendToolResponses(responses ...gemini.FunctionResponse) error {
+	return s.send(func(conn gemini.LiveConn) error { return conn.SendToolResponses(responses) })
+}
+
+func (s *Session) send(fn func(gemini.LiveConn) error) error {
+	s.connMu.RLock()
+	conn := s.conn
+	s.connMu.RUnlock()
+	if s.isClosing() {
+		return ErrSessionClosed
+	}
+	if err := fn(conn); err != nil {
+		return fmt.Errorf("live: 送信に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// Receive は次のイベントを待って返します。
+//
+// セッションが終わった後は、残っているイベントを返し切ってから終了の理由を返します
+// （Close した場合は ErrSessionClosed、再開できなかった場合は ErrConnectionLost）。
+func (s *Session) Receive(ctx context.Context) (Event, error) {
+	for {
+		s.mu.Lock()
+		if len(s.queue) > 0 {
+			event := s.queue[0]
+			s.queue = s.queue[1:]
+			s.mu.Unlock()
+			return event, nil
+		}
+		if s.err != nil {
+			err := s.err
+			s.mu.Unlock()
+			return Event{}, err
+		}
+		changed := s.changed
+		s.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return Event{}, ctx.Err()
+		}
+	}
+}
+
+// Events は、セッションが終わるまでイベントを順に返すイテレータです。
+// セッションが終わると、終了の理由をエラーとして1度だけ返して止まります。
+//
+//	for event, err := range session.Events(ctx) {
+//	    if err != nil {
+//	        break
+//	    }
+//	    // event.Type で分岐
+//	}
+func (s *Session) Events(ctx context.Context) iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		for {
+			event, err := s.Receive(ctx)
+			if !yield(event, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// ResumptionHandle は、このセッションを後から再開するためのハンドルを返します。
+// サーバーがまだ送っていない場合は空文字列です。
+func (s *Session) ResumptionHandle() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.handle
+}
+
+// Close はセッションを閉じ、受信を止めます。複数回呼んでも安全です。
+// 接続が切れて終わったセッションでも、後始末のために呼んでください。
+func (s *Session) Close() error {
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		s.closing = true
+		s.mu.Unlock()
+		// 再接続の待機中であれば、先に打ち切ってから接続を取りに行く。
+		s.cancel()
+
+		s.connMu.Lock()
+		s.closeErr = s.conn.Close()
+		s.connMu.Unlock()
+	})
+	<-s.done
+	return s.closeErr
+}
+
+func (s *Session) isClosing() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closing
+}
+
+// readLoop は接続からメッセージを読み続け、イベントとして積みます。
+// 接続が切れた場合は、再開できる限り張り直して読み続けます。
+func (s *Session) readLoop() {
+	defer close(s.done)
+	for {
+		s.connMu.RLock()
+		conn := s.conn
+		s.connMu.RUnlock()
+
+		msg, err := conn.Receive()
+		if err != nil {
+			if !s.isClosing() && s.reconnect(err) {
+				continue
+			}
+			// 再接続の途中で Close された場合も、利用側から見れば閉じたことが理由になる。
+			if s.isClosing() {
+				s.finish(ErrSessionClosed)
+			} else {
+				s.finish(fmt.Errorf("%w: %w", ErrConnectionLost, err))
+			}
+			return
+		}
+		s.dispatch(msg)
+	}
+}
+
+// dispatch はメッセージ1件分のイベントを積みます。
+//
+// 割り込みが届いた場合は、まだ受け取られていない応答の断片を先に捨てます。
+// 割り込まれた応答を後から再生すると、ユーザーの発話に古い応答が被るためです。
+func (s *Session) dispatch(msg *gemini.LiveMessage) {
+	events := eventsFrom(msg)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if msg.ResumptionHandle != "" {
+		s.handle = msg.ResumptionHandle
+	}
+	if len(events) == 0 {
+		return
+	}
+	if msg.Interrupted {
+		kept := s.queue[:0]
+		for _, event := range s.queue {
+			if !event.isResponseOutput() {
+				kept = append(kept, event)
+			}
+		}
+		s.queue = kept
+	}
+	s.queue = append(s.queue, events...)
+	s.notifyLocked()
+}
+
+// reconnect は、前回受け取ったハンドルでセッションを再開します。
+// 再開できた場合は true を返します。再接続の間は送信を待たせます。
+func (s *Session) reconnect(cause error) bool {
+	handle := s.ResumptionHandle()
+	if handle == "" || s.client.maxReconnects == 0 {
+		return false
+	}
+	cfg := s.cfg
+	cfg.Resumption, cfg.ResumptionHandle = true, handle
+
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+	for attempt := 1; attempt <= s.client.maxReconnects; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-time.After(s.client.reconnectDelay):
+			case <-s.ctx.Done():
+				return false
+			}
+		}
+		if s.ctx.Err() != nil {
+			return false
+		}
+		s.client.logger.WarnContext(s.ctx, "ライブセッションが切断されました。再開します",
+			"model", s.model, "attempt", attempt, "error", cause)
+		conn, err := s.client.connector.ConnectLive(s.ctx, s.model, cfg)
+		if err != nil {
+			cause = err
+			continue
+		}
+		_ = s.conn.Close()
+		s.conn = conn
+
+		s.mu.Lock()
+		s.queue = append(s.queue, Event{Type: EventResumed})
+		s.notifyLocked()
+		s.mu.Unlock()
+		return true
+	}
+	return false
+}
+
+// finish はセッションの終了の理由を記録し、待機中の Receive を起こします。
+func (s *Session) finish(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+	s.notifyLocked()
+}
+
+// notifyLocked は待機中の Receive を起こします。s.mu を持った状態で呼びます。
+func (s *Session) notifyLocked() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
@@ -0,0 +1,403 @@
+package live
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shouni/go-gemini-client/gemini"
+)
+
+// fakeConn は gemini.LiveConn のテストダブルです。push したメッセージを順に
+// Receive で返し、drop するとその位置で接続が切れたように振る舞います。
+type fakeConn struct {
+	incoming chan received
+	closed   chan struct{}
+
+	mu        sync.Mutex
+	texts     []string
+	audio     [][]byte
+	responses []gemini.FunctionResponse
+	closeOnce sync.Once
+}
+
+type received struct {
+	msg *gemini.LiveMessage
+	err error
+}
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{
+		incoming: make(chan received, 16),
+		closed:   make(chan struct{}),
+	}
+}
+
+func (c *fakeConn) push(msg *gemini.LiveMessage) { c.incoming <- received{msg: msg} }
+
+func (c *fakeConn) drop(err error) { c.incoming <- received{err: err} }
+
+func (c *fakeConn) SendText(text string, _ bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.texts = append(c.texts, text)
+	return nil
+}
+
+func (c *fakeConn) SendAudio(chunk []byte, _ string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.audio = append(c.audio, chunk)
+	return nil
+}
+
+func (c *fakeConn) SendAudioStreamEnd() error { return nil }
+
+func (c *fakeConn) SendToolResponses(responses []gemini.FunctionResponse) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.responses = append(c.responses, responses...)
+	return nil
+}
+
+func (c *fakeConn) Receive() (*gemini.LiveMessage, error) {
+	select {
+	case r := <-c.incoming:
+		return r.msg, r.err
+	case <-c.closed:
+		return nil, errors.New("use of closed network connection")
+	}
+}
+
+func (c *fakeConn) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	return nil
+}
+
+func (c *fakeConn) sentTexts() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.texts...)
+}
+
+// fakeConnector は接続のたびに conns から順に接続を払い出します。
+type fakeConnector struct {
+	mu      sync.Mutex
+	conns   []*fakeConn
+	configs []gemini.LiveConfig
+	err     error
+}
+
+func (f *fakeConnector) ConnectLive(_ context.Context, _ string, cfg gemini.LiveConfig) (gemini.LiveConn, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.configs = append(f.configs, cfg)
+	if f.err != nil || len(f.conns) == 0 {
+		return nil, errors.Join(f.err, errors.New("no more connections"))
+	}
+	conn := f.conns[0]
+	f.conns = f.conns[1:]
+	return conn, nil
+}
+
+func (f *fakeConnector) connectConfigs() []gemini.LiveConfig {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]gemini.LiveConfig(nil), f.configs...)
+}
+
+func connect(t *testing.T, connector *fakeConnector, cfg gemini.LiveConfig, opts ...Option) *Session {
+	t.Helper()
+	client, err := New(connector, append([]Option{WithReconnectDelay(time.Millisecond)}, opts...)...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	session, err := client.Connect(context.Background(), "gemini-live", cfg)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+	return session
+}
+
+func receive(t *testing.T, session *Session) Event {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	event, err := session.Receive(ctx)
+	if err != nil {
+		t.Fatalf("Receive() error = %v", err)
+	}
+	return event
+}
+
+// waitQueued は、積まれたイベントの末尾が want になるまで待ちます。割り込みで
+// 捨てられる様子を確かめるため、受信側が読み出す前の状態を作るのに使います。
+func waitQueued(t *testing.T, session *Session, want EventType) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		session.mu.Lock()
+		queued := len(session.queue) > 0 && session.queue[len(session.queue)-1].Type == want
+		session.mu.Unlock()
+		if queued {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for a queued %s event", want)
+}
+
+func TestNewRequiresConnector(t *testing.T) {
+	if _, err := New(nil); !errors.Is(err, ErrConnectorRequired) {
+		t.Fatalf("New(nil) error = %v, want ErrConnectorRequired", err)
+	}
+}
+
+func TestSession_SplitsMessagesIntoEvents(t *testing.T) {
+	conn := newFakeConn()
+	session := connect(t, &fakeConnector{conns: []*fakeConn{conn}}, gemini.LiveConfig{})
+
+	conn.push(&gemini.LiveMessage{
+		InputTranscript:  "天気は？",
+		Text:             "晴れです",
+		Audio:            []gemini.Attachment{{MIMEType: "audio/pcm;rate=24000", Data: []byte{1, 2}}},
+		OutputTranscript: "晴れです",
+	})
+	conn.push(&gemini.LiveMessage{FunctionCalls: []gemini.FunctionCall{{ID: "c1", Name: "weather"}}})
+	conn.push(&gemini.LiveMessage{CancelledCallIDs: []string{"c1"}})
+	conn.push(&gemini.LiveMessage{TurnComplete: true, Usage: &gemini.TokenUsage{TotalTokenCount: 9}})
+	conn.push(&gemini.LiveMessage{GoAway: true, GoAwayTimeLeft: 10 * time.Second})
+
+	want := []EventType{
+		EventInputTranscript, EventText, EventAudio, EventOutputTranscript,
+		EventToolCall, EventToolCallCancelled, EventTurnComplete, EventUsage, EventGoAway,
+	}
+	for i, wantType := range want {
+		event := receive(t, session)
+		if event.Type != wantType {
+			t.Fatalf("event[%d].Type = %s, want %s", i, event.Type, wantType)
+		}
+		switch event.Type {
+		case EventAudio:
+			if event.MIMEType != "audio/pcm;rate=24000" || len(event.Audio) != 2 {
+				t.Errorf("audio event = %+v", event)
+			}
+		case EventToolCall:
+			if event.FunctionCalls[0].ID != "c1" {
+				t.Errorf("tool call event = %+v", event)
+			}
+		case EventUsage:
+			if event.Usage.TotalTokenCount != 9 {
+				t.Errorf("usage event = %+v", event)
+			}
+		case EventGoAway:
+			if event.TimeLeft != 10*time.Second {
+				t.Errorf("go away event = %+v", event)
+			}
+		}
+	}
+}
+
+// TestSession_InterruptionDropsPendingOutput verifies that response fragments the caller has not
+// read yet are discarded when the model is interrupted, while other events are kept.
+func TestSession_InterruptionDropsPendingOutput(t *testing.T) {
+	conn := newFakeConn()
+	session := connect(t, &fakeConnector{conns: []*fakeConn{conn}}, gemini.LiveConfig{})
+
+	conn.push(&gemini.LiveMessage{Text: "長い説明の", Audio: []gemini.Attachment{{Data: []byte{1}}}})
+	conn.push(&gemini.LiveMessage{InputTranscript: "ちょっと待って"})
+	conn.push(&gemini.LiveMessage{OutputTranscript: "長い説明の続き"})
+	waitQueued(t, session, EventOutputTranscript)
+
+	conn.push(&gemini.LiveMessage{Interrupted: true})
+	waitQueued(t, session, EventInterrupted)
+	conn.push(&gemini.LiveMessage{Text: "はい、どうぞ"})
+
+	if event := receive(t, session); event.Type != EventInputTranscript {
+		t.Fatalf("first event = %s, want the kept input transcript", event.Type)
+	}
+	if event := receive(t, session); event.Type != EventInterrupted {
+		t.Fatalf("second event = %s, want interrupted", event.Type)
+	}
+	if event := receive(t, session); event.Type != EventText || event.Text != "はい、どうぞ" {
+		t.Fatalf("third event = %+v, want the response after the interruption", event)
+	}
+}
+
+func TestSession_ResumesAfterDisconnect(t *testing.T) {
+	first, second := newFakeConn(), newFakeConn()
+	connector := &fakeConnector{conns: []*fakeConn{first, second}}
+	session := connect(t, connector, gemini.LiveConfig{SystemPrompt: "sys", Resumption: true})
+
+	first.push(&gemini.LiveMessage{ResumptionHandle: "handle-1"})
+	first.push(&gemini.LiveMessage{Text: "前半"})
+	if event := receive(t, session); event.Text != "前半" {
+		t.Fatalf("event = %+v", event)
+	}
+	if session.ResumptionHandle() != "handle-1" {
+		t.Errorf("ResumptionHandle() = %q, want handle-1", session.ResumptionHandle())
+	}
+
+	first.drop(errors.New("websocket: close 1011"))
+	if event := receive(t, session); event.Type != EventResumed {
+		t.Fatalf("event = %s, want resumed", event.Type)
+	}
+	configs := connector.connectConfigs()
+	if len(configs) != 2 || configs[1].ResumptionHandle != "handle-1" || configs[1].SystemPrompt != "sys" {
+		t.Fatalf("reconnect config = %+v, want original config with the handle", configs)
+	}
+	select {
+	case <-first.closed:
+	default:
+		t.Error("the dropped connection should be closed")
+	}
+
+	if err := session.SendText("続けて"); err != nil {
+		t.Fatalf("SendText() error = %v", err)
+	}
+	if got := second.sentTexts(); len(got) != 1 || got[0] != "続けて" {
+		t.Errorf("sent on new connection = %v", got)
+	}
+	second.push(&gemini.LiveMessage{Text: "後半"})
+	if event := receive(t, session); event.Text != "後半" {
+		t.Fatalf("event = %+v, want output from the new connection", event)
+	}
+}
+
+func TestSession_ConnectionLostWithoutHandle(t *testing.T) {
+	conn := newFakeConn()
+	connector := &fakeConnector{conns: []*fakeConn{conn, newFakeConn()}}
+	session := connect(t, connector, gemini.LiveConfig{})
+
+	conn.push(&gemini.LiveMessage{Text: "最後の断片"})
+	conn.drop(errors.New("websocket: close 1006"))
+
+	if event := receive(t, session); event.Text != "最後の断片" {
+		t.Fatalf("queued events should be delivered before the error, got %+v", event)
+	}
+	_, err := session.Receive(context.Background())
+	if !errors.Is(err, ErrConnectionLost) {
+		t.Fatalf("Receive() error = %v, want ErrConnectionLost", err)
+	}
+	if n := len(connector.connectConfigs()); n != 1 {
+		t.Errorf("connect calls = %d, want no reconnect without a handle", n)
+	}
+}
+
+func TestSession_GivesUpAfterMaxReconnects(t *testing.T) {
+	conn := newFakeConn()
+	connector := &fakeConnector{conns: []*fakeConn{conn}}
+	session := connect(t, connector, gemini.LiveConfig{ResumptionHandle: "handle-0"}, WithMaxReconnects(2))
+
+	conn.drop(errors.New("websocket: close 1011"))
+	_, err := session.Receive(context.Background())
+	if !errors.Is(err, ErrConnectionLost) {
+		t.Fatalf("Receive() error = %v, want ErrConnectionLost", err)
+	}
+	if n := len(connector.connectConfigs()); n != 3 {
+		t.Errorf("connect calls = %d, want 1 initial + 2 reconnects", n)
+	}
+}
+
+func TestSession_CloseEndsSession(t *testing.T) {
+	conn := newFakeConn()
+	session := connect(t, &fakeConnector{conns: []*fakeConn{conn}}, gemini.LiveConfig{Resumption: true})
+
+	if err := session.SendToolResponses(gemini.FunctionResponse{ID: "c1", Name: "f"}); err != nil {
+		t.Fatalf("SendToolResponses() error = %v", err)
+	}
+	if err := session.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := session.Close(); err != nil {
+		t.Fatalf("second Close() error = %v", err)
+	}
+	if _, err := session.Receive(context.Background()); !errors.Is(err, ErrSessionClosed) {
+		t.Errorf("Receive() error = %v, want ErrSessionClosed", err)
+	}
+	if err := session.SendText("x"); !errors.Is(err, ErrSessionClosed) {
+		t.Errorf("SendText() error = %v, want ErrSessionClosed", err)
+	}
+	if len(conn.responses) != 1 || conn.responses[0].ID != "c1" {
+		t.Errorf("tool responses = %+v", conn.responses)
+	}
+}
+
+// stalledConn は、EndAudio の書き込みが Close されるまで返らない接続です。
+// 応答の無い相手への書き込みが止まった状態を表します。自分自身を返す接続元も兼ねます。
+type stalledConn struct {
+	*fakeConn
+	writing chan struct{}
+}
+
+func (c *stalledConn) ConnectLive(context.Context, string, gemini.LiveConfig) (gemini.LiveConn, error) {
+	return c, nil
+}
+
+func (c *stalledConn) SendAudioStreamEnd() error {
+	close(c.writing)
+	<-c.closed
+	return errors.New("use of closed network connection")
+}
+
+func TestSession_CloseInterruptsStalledSend(t *testing.T) {
+	conn := newFakeConn()
+	stalled := &stalledConn{fakeConn: conn, writing: make(chan struct{})}
+	client, err := New(stalled)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	session, err := client.Connect(context.Background(), "gemini-live", gemini.LiveConfig{})
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+
+	sent := make(chan error, 1)
+	go func() { sent <- session.EndAudio() }()
+	<-stalled.writing
+
+	closed := make(chan error, 1)
+	go func() { closed <- session.Close() }()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close() waited for the stalled write")
+	}
+	if err := <-sent; err == nil {
+		t.Error("EndAudio() error = nil, want the write interrupted by Close")
+	}
+}
+
+func TestSession_ContextCancelClosesSession(t *testing.T) {
+	conn := newFakeConn()
+	client, err := New(&fakeConnector{conns: []*fakeConn{conn}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	session, err := client.Connect(ctx, "gemini-live", gemini.LiveConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+	var got error
+	for _, err := range session.Events(context.Background()) {
+		got = err
+	}
+	if !errors.Is(got, ErrSessionClosed) {
+		t.Errorf("Events() ended with %v, want ErrSessionClosed", got)
+	}
+}
+
+func TestSession_ReceiveHonoursContext(t *testing.T) {
+	session := connect(t, &fakeConnector{conns: []*fakeConn{newFakeConn()}}, gemini.LiveConfig{})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := session.Receive(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Receive() error = %v, want DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,40 @@
+package live
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Option は Client の設定を適用する関数型です。
+// 不正な値（負数・ゼロ以下・nil）は「指定なし」として無視し、既定値のままにします。
+type Option func(*Client)
+
+// WithMaxReconnects は、切断されたセッションの再開を何回まで試みるかを設定します。
+// 0 を指定すると再開しません。再開に成功すれば、回数はゼロに戻ります。
+func WithMaxReconnects(n int) Option {
+	return func(c *Client) {
+		if n >= 0 {
+			c.maxReconnects = n
+		}
+	}
+}
+
+// WithReconnectDelay は、再開に失敗したときに次を試みるまでの待ち時間を設定します。
+// 最初の1回は待たずに試みます。
+func WithReconnectDelay(d time.Duration) Option {
+	return func(c *Client) {
+		if d > 0 {
+			c.reconnectDelay = d
+		}
+	}
+}
+
+// WithLogger は、このクライアントが出すログの出力先を設定します。
+// 未指定（または nil）の場合は slog.Default() を使います。
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		if logger != nil {
+			c.logger = logger
+		}
+	}
+}
@@ -5,6 +5,8 @@ package lyria
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/shouni/go-gemini-client/gemini"
@@ -17,6 +19,7 @@ type lyriaAudioGenerator struct {
 	aiClient          gemini.Generator
 	promptBuilder     AudioPromptBuilder
 	converter         ReadingConverter
+	languages         map[string]LanguageSupport // WithLanguage で登録した言語ごとの部品
 	defaultLyriaModel string
 	limiter           *rate.Limiter // nil はレート制限なし（テストが構造体リテラルで直接構築するため）
 	execTimeout       time.Duration
@@ -25,25 +28,96 @@ type lyriaAudioGenerator struct {
 
 // GenerateAudio は MusicRecipe 全体を 1 回の Lyria 呼び出しで音声化します。
 func (g *lyriaAudioGenerator) GenerateAudio(ctx context.Context, recipe *MusicRecipe, images []ImagePayload) ([]byte, error) {
+	audio, _, err := g.generateAudioMetered(ctx, recipe, images)
+	return audio, err
+}
+
+// generateAudioMetered は GenerateAudio と同じ処理で、使ったモデルとトークン使用量も返します。
+func (g *lyriaAudioGenerator) generateAudioMetered(ctx context.Context, recipe *MusicRecipe, images []ImagePayload) ([]byte, stageMeter, error) {
+	if recipe == nil {
+		return nil, stageMeter{model: g.defaultLyriaModel}, fmt.Errorf("%w: music recipe", ErrNilInput)
+	}
+	audio, meter, err := g.renderFullSong(ctx, recipe, images)
+	if err != nil {
+		return nil, meter, err
+	}
+	return audio.Data, meter, nil
+}
+
+// renderFullSong は曲全体を 1 回の Lyria 呼び出しで音声化し、MIME type 付きで返します。
+//
+// プロンプトはレシピの言語の AudioPromptBuilder で組み立て、その言語の ReadingConverter で
+// 変換します。セクションごとに言語が違うレシピでは、プロンプト全体ではなく歌詞の
+// まとまりごとに、歌うセクションの言語で変換してから組み立てます。
+func (g *lyriaAudioGenerator) renderFullSong(ctx context.Context, recipe *MusicRecipe, images []ImagePayload) (gemini.Attachment, stageMeter, error) {
+	builder := g.audioPrompts(recipe.Language())
+	var promptText string
+	if recipe.IsMultilingual() {
+		promptText = builder.BuildFullSong(g.convertLyricsBySection(recipe))
+	} else {
+		promptText = g.convertReading(recipe.Language(), builder.BuildFullSong(recipe))
+	}
+	return g.render(ctx, "audio-full", recipe, promptText, images)
+}
+
+// GenerateClip は GenerateAudio と同じく曲全体を音声化し、形式を解析した AudioClip で
+// 返します。
+func (g *lyriaAudioGenerator) GenerateClip(ctx context.Context, recipe *MusicRecipe, images []ImagePayload) (*AudioClip, error) {
 	if recipe == nil {
 		return nil, fmt.Errorf("%w: music recipe", ErrNilInput)
 	}
 
+	audio, _, err := g.renderFullSong(ctx, recipe, images)
+	if err != nil {
+		return nil, err
+	}
+	return NewAudioClip(audio.Data, audio.MIMEType)
+}
+
+// GenerateSection は recipe.Sections[index] だけを 1 回の Lyria 呼び出しで音声化します。
+// プロンプトはセクションの言語（Recipe.SectionLang）の AudioPromptBuilder が
+// SectionPromptBuilder も満たしていればそれで、満たしていなければ defaultSectionPrompt で
+// 組み立て、セクションの言語の ReadingConverter で変換します。
+// 音声は NewAudioClip で解析するので、生の PCM は WAV に包んで返します。
+func (g *lyriaAudioGenerator) GenerateSection(ctx context.Context, recipe *MusicRecipe, index int, images []ImagePayload) (*AudioClip, error) {
+	if recipe == nil {
+		return nil, fmt.Errorf("%w: music recipe", ErrNilInput)
+	}
+	if index < 0 || index >= len(recipe.Sections) {
+		return nil, fmt.Errorf("%w: section index %d (recipe has %d sections)", ErrNoSections, index, len(recipe.Sections))
+	}
+
+	lang := recipe.SectionLang(index)
+	var promptText string
+	if builder, ok := g.audioPrompts(lang).(SectionPromptBuilder); ok {
+		promptText = builder.BuildSection(recipe, index)
+	} else {
+		promptText = defaultSectionPrompt(recipe, recipe.Sections[index])
+	}
+	audio, _, err := g.render(ctx, "audio-section", recipe, g.convertReading(lang, promptText), images)
+	if err != nil {
+		return nil, err
+	}
+	return NewAudioClip(audio.Data, audio.MIMEType)
+}
+
+// render は promptText を Lyria に渡し、最初の音声を MIME type 付きで返します。
+// promptText は読みの変換を済ませたものを渡します。同じ内容の同時呼び出しは
+// namespace ごとに singleflight でまとめます。
+func (g *lyriaAudioGenerator) render(ctx context.Context, namespace string, recipe *MusicRecipe, promptText string, images []ImagePayload) (gemini.Attachment, stageMeter, error) {
 	targetModel := g.defaultLyriaModel
 	if recipe.AudioModel != "" {
 		targetModel = recipe.AudioModel
 	}
+	meter := stageMeter{model: targetModel}
 
-	promptText := g.promptBuilder.BuildFullSong(recipe)
-	if recipe.IsJapanese() {
-		promptText = g.converter.ConvertToReading(promptText)
-	}
 	imageHash := calculateImagesHash(images)
-	key := singleflightKey("audio-full", targetModel, promptText, singleflightSeedKey(recipe.Seed), imageHash)
-	audio, err := doSingleflight(ctx, &g.group, key, g.execTimeout, func(execCtx context.Context) ([]byte, error) {
+	key := singleflightKey(namespace, targetModel, promptText, singleflightSeedKey(recipe.Seed), imageHash)
+	rendered, err := doSingleflight(ctx, &g.group, key, g.execTimeout, func(execCtx context.Context) (generated[gemini.Attachment], error) {
+		var zero generated[gemini.Attachment]
 		if g.limiter != nil {
 			if err := g.limiter.Wait(execCtx); err != nil {
-				return nil, err
+				return zero, err
 			}
 		}
 
@@ -55,17 +129,59 @@ func (g *lyriaAudioGenerator) GenerateAudio(ctx context.Context, recipe *MusicRe
 			buildAudioGenerateOptions(recipe.Seed),
 		)
 		if err != nil {
-			return nil, fmt.Errorf("lyria generation failed (model: %s): %w", targetModel, err)
+			return zero, fmt.Errorf("lyria generation failed (model: %s): %w", targetModel, err)
 		}
 		if resp == nil || len(resp.Audios) == 0 {
-			return nil, fmt.Errorf("%w (model: %s)", ErrNoAudio, targetModel)
+			return zero, fmt.Errorf("%w (model: %s)", ErrNoAudio, targetModel)
 		}
 
-		return resp.Audios[0], nil
+		audio := firstAudio(resp)
+		return generated[gemini.Attachment]{value: &audio, usage: resp.Usage}, nil
 	})
 	if err != nil {
-		return nil, err
+		return gemini.Attachment{}, meter, err
 	}
 
-	return cloneBytes(audio), nil
+	meter.usage = cloneUsage(rendered.usage)
+	audio := rendered.value
+	return gemini.Attachment{Data: cloneBytes(audio.Data), MIMEType: audio.MIMEType}, meter, nil
+}
+
+// firstAudio は、レスポンスの最初の音声を MIME type 付きで返します。
+// Attachments を組み立てない Generator の実装（テストの偽物など）もあるため、
+// 見つからなければ Audios[0] を MIME type 無しで返します。
+func firstAudio(resp *gemini.Response) gemini.Attachment {
+	for _, attachment := range resp.Attachments {
+		if len(attachment.Data) > 0 && strings.HasPrefix(strings.ToLower(attachment.MIMEType), "audio/") {
+			return attachment
+		}
+	}
+	return gemini.Attachment{Data: resp.Audios[0]}
+}
+
+// defaultSectionPrompt は、SectionPromptBuilder が無い場合のセクション用プロンプトです。
+// 曲全体で揃えるべき要素（ムード・テンポ・キー・楽器・ボーカル）を毎回添えるのは、
+// セクションを別々に生成すると、指示の無い要素がセクションごとにばらつくためです。
+func defaultSectionPrompt(recipe *MusicRecipe, section MusicSection) string {
+	var lines []string
+	add := func(label, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			lines = append(lines, label+": "+value)
+		}
+	}
+	add("Song", recipe.Title)
+	add("Mood", recipe.Mood)
+	if recipe.Tempo > 0 {
+		add("Tempo", strconv.Itoa(recipe.Tempo)+" BPM")
+	}
+	add("Key", recipe.Key)
+	add("Instruments", strings.Join(recipe.Instruments, ", "))
+	add("Vocals", recipe.VocalProfile)
+	name := section.Name
+	if seconds := sectionSeconds(section); seconds > 0 {
+		name += " (" + strconv.Itoa(seconds) + " seconds)"
+	}
+	add("Section", name)
+	add("Direction", section.Prompt)
+	return strings.Join(lines, "\n")
 }
@@ -0,0 +1,161 @@
+package lyria
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrCheckpointNotFound は、CheckpointStore に指定した実行の記録が無い場合に返されます。
+var ErrCheckpointNotFound = errors.New("lyria: checkpoint not found")
+
+// Checkpoint は Workflow.Run 1回分の途中経過です。段が完了するたびに保存されます。
+type Checkpoint struct {
+	RunID string `json:"run_id"`
+	// Lyrics は歌詞の段の成果物です。未完了なら nil です。
+	Lyrics *LyricsDraft `json:"lyrics,omitempty"`
+	// Recipe は作曲の段の成果物です。未完了なら nil です。
+	Recipe *MusicRecipe `json:"recipe,omitempty"`
+	// Audio は音声の段の成果物です。未完了なら nil です。
+	Audio []byte `json:"audio,omitempty"`
+	// Stages は完了した段の記録です。
+	Stages []StageStats `json:"stages,omitempty"`
+	// Completed は、すべての段が完了したかです。完了済みの記録からは再開しません。
+	Completed bool      `json:"completed"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// clone は Checkpoint を呼び出し元が安全に変更できるように複製します。
+func (c *Checkpoint) clone() *Checkpoint {
+	if c == nil {
+		return nil
+	}
+	dst := *c
+	dst.Lyrics = c.Lyrics.Clone()
+	dst.Recipe = c.Recipe.Clone()
+	dst.Audio = cloneBytes(c.Audio)
+	dst.Stages = make([]StageStats, len(c.Stages))
+	for i, stage := range c.Stages {
+		stage.Usage = cloneUsage(stage.Usage)
+		dst.Stages[i] = stage
+	}
+	return &dst
+}
+
+// CheckpointStore は Workflow.Run の途中経過の保存先です。
+//
+// 同じ RunID の Save は上書きです。実装は並行に呼び出されても安全である必要があります。
+type CheckpointStore interface {
+	// Load は記録を返します。無い場合は ErrCheckpointNotFound を返します。
+	Load(ctx context.Context, runID string) (*Checkpoint, error)
+	// Save は記録を保存します。
+	Save(ctx context.Context, checkpoint *Checkpoint) error
+}
+
+// MemoryCheckpointStore はプロセス内だけに記録を持つ CheckpointStore です。
+// 再起動をまたげないため、テストや、同じプロセス内での再試行向けです。
+type MemoryCheckpointStore struct {
+	mu          sync.Mutex
+	checkpoints map[string]*Checkpoint
+}
+
+// NewMemoryCheckpointStore は空の MemoryCheckpointStore を返します。
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{checkpoints: make(map[string]*Checkpoint)}
+}
+
+// Load は記録を返します。
+func (s *MemoryCheckpointStore) Load(_ context.Context, runID string) (*Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	checkpoint, ok := s.checkpoints[runID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrCheckpointNotFound, runID)
+	}
+	return checkpoint.clone(), nil
+}
+
+// Save は記録を保存します。
+func (s *MemoryCheckpointStore) Save(_ context.Context, checkpoint *Checkpoint) error {
+	if checkpoint == nil {
+		return fmt.Errorf("%w: checkpoint", ErrNilInput)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoints[checkpoint.RunID] = checkpoint.clone()
+	return nil
+}
+
+// FileCheckpointStore は、実行ごとに1つの JSON ファイル（<dir>/<RunID>.json）へ記録を
+// 書く CheckpointStore です。
+//
+// 一時ファイルへ書いてから rename するため、途中で落ちても壊れたファイルは残りません。
+// 音声も JSON（base64）に含めるので、ファイルは音声の 4/3 倍ほどの大きさになります。
+type FileCheckpointStore struct {
+	dir string
+}
+
+// NewFileCheckpointStore は dir を保存先とする FileCheckpointStore を返します。
+// ディレクトリが無ければ最初の Save で作成します。
+func NewFileCheckpointStore(dir string) *FileCheckpointStore {
+	return &FileCheckpointStore{dir: dir}
+}
+
+// Load は記録を返します。
+func (s *FileCheckpointStore) Load(_ context.Context, runID string) (*Checkpoint, error) {
+	path := s.path(runID)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %q", ErrCheckpointNotFound, runID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lyria: failed to read checkpoint %s: %w", path, err)
+	}
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("lyria: failed to decode checkpoint %s: %w", path, err)
+	}
+	return &checkpoint, nil
+}
+
+// Save は記録を保存します。
+func (s *FileCheckpointStore) Save(_ context.Context, checkpoint *Checkpoint) error {
+	if checkpoint == nil {
+		return fmt.Errorf("%w: checkpoint", ErrNilInput)
+	}
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("lyria: failed to encode checkpoint: %w", err)
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("lyria: failed to create checkpoint directory %s: %w", s.dir, err)
+	}
+
+	path := s.path(checkpoint.RunID)
+	tmp, err := os.CreateTemp(s.dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("lyria: failed to write checkpoint %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name()) // rename 後は存在しないので失敗しても構わない。
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("lyria: failed to write checkpoint %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("lyria: failed to write checkpoint %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("lyria: failed to write checkpoint %s: %w", path, err)
+	}
+	return nil
+}
+
+// path は runID の記録のファイルパスです。
+func (s *FileCheckpointStore) path(runID string) string {
+	return filepath.Join(s.dir, runID+".json")
+}
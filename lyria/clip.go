@@ -0,0 +1,240 @@
+package lyria
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"mime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shouni/go-gemini-client/internal/wav"
+)
+
+// DefaultPCMSampleRate は、生の PCM（audio/l16・audio/pcm）の MIME type に
+// サンプリング周波数が無い場合に仮定する値（Hz）です。
+const DefaultPCMSampleRate = 48000
+
+// AudioClip は、形式を解析した生成音声です。
+type AudioClip struct {
+	// MIMEType は Data の形式です（"audio/wav" か "audio/mpeg"）。生の PCM で返った
+	// 場合は WAV に包むので "audio/wav" になります。
+	MIMEType string
+	// Data は音声のバイト列です。そのままファイルへ書き出して再生できます。
+	Data []byte
+	// SampleRate はサンプリング周波数（Hz）です。
+	SampleRate int
+	// Channels はチャンネル数です。
+	Channels int
+	// BitsPerSample は1サンプルあたりのビット数です。MP3 では 0 です。
+	BitsPerSample int
+	// Duration は再生時間です。
+	Duration time.Duration
+}
+
+// IsWAV は、Data が WAV かを返します。
+func (c *AudioClip) IsWAV() bool {
+	return c != nil && c.MIMEType == "audio/wav"
+}
+
+// NewAudioClip は、音声のバイト列を解析して AudioClip を作ります。
+//
+// WAV はヘッダから判定します。mimeType が生の PCM（audio/l16・audio/pcm）を示す場合は、
+// rate / channels パラメータの形式で WAV に包みます（PCM のサンプルは偶然 MP3 の
+// フレーム同期に見えることがあるため、MP3 の判定より先に mimeType に従います）。
+// それ以外は先頭のバイト列から MP3 を判定し、判定できない場合は ErrUnsupportedAudio を
+// 返します（Lyria が返す MIME type は省かれたり汎用的だったりするため、WAV と MP3 は
+// mimeType より中身を信じます）。
+func NewAudioClip(data []byte, mimeType string) (*AudioClip, error) {
+	if len(data) == 0 {
+		return nil, ErrNoAudio
+	}
+
+	if isWAV(data) {
+		format, pcm, err := wav.Decode(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrUnsupportedAudio, err)
+		}
+		return newWAVClip(data, format, len(pcm)), nil
+	}
+
+	mediaType, params, err := mime.ParseMediaType(mimeType)
+	if err == nil {
+		switch strings.ToLower(mediaType) {
+		case "audio/l16", "audio/pcm":
+			format := wav.Format{SampleRate: DefaultPCMSampleRate, Channels: 1, BitsPerSample: 16}
+			if rate, err := strconv.Atoi(params["rate"]); err == nil && rate > 0 {
+				format.SampleRate = rate
+			}
+			if channels, err := strconv.Atoi(params["channels"]); err == nil && channels > 0 {
+				format.Channels = channels
+			}
+			wrapped, err := wav.Encode(data, format)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrUnsupportedAudio, err)
+			}
+			return NewAudioClip(wrapped, "audio/wav")
+		}
+	}
+
+	if isMP3(data) {
+		return parseMP3(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: 形式を判定できず、MIME type %q も解釈できません", ErrUnsupportedAudio, mimeType)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedAudio, mimeType)
+}
+
+// newWAVClip は、WAV の AudioClip を作ります。
+func newWAVClip(data []byte, format wav.Format, pcmBytes int) *AudioClip {
+	return &AudioClip{
+		MIMEType:      "audio/wav",
+		Data:          data,
+		SampleRate:    format.SampleRate,
+		Channels:      format.Channels,
+		BitsPerSample: format.BitsPerSample,
+		Duration:      format.Duration(pcmBytes),
+	}
+}
+
+// isWAV は、data が RIFF/WAVE で始まるかを返します。
+func isWAV(data []byte) bool {
+	return len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WAVE"
+}
+
+// isMP3 は、data が ID3v2 タグか MPEG オーディオのフレーム同期で始まるかを返します。
+func isMP3(data []byte) bool {
+	if bytes.HasPrefix(data, []byte("ID3")) {
+		return true
+	}
+	_, ok := parseMP3Header(data)
+	return ok
+}
+
+// mp3Header は MPEG オーディオ（Layer III）のフレームヘッダ1つ分です。
+type mp3Header struct {
+	mpeg1      bool
+	sampleRate int
+	channels   int
+	frameSize  int
+	samples    int // 1フレームあたりのサンプル数
+}
+
+var (
+	// mp3BitratesV1 / V2 は MPEG-1 / MPEG-2 以降の Layer III のビットレート（kbps）です。
+	// 添字はヘッダのビットレート番号で、0（free format）と 15（不正）は扱いません。
+	mp3BitratesV1 = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}
+	mp3BitratesV2 = [16]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0}
+	// mp3SampleRates は MPEG-1 のサンプリング周波数です。MPEG-2 は 1/2、MPEG-2.5 は 1/4 です。
+	mp3SampleRates = [3]int{44100, 48000, 32000}
+)
+
+// parseMP3Header は、data の先頭4バイトを Layer III のフレームヘッダとして読みます。
+func parseMP3Header(data []byte) (mp3Header, bool) {
+	if len(data) < 4 || data[0] != 0xFF || data[1]&0xE0 != 0xE0 {
+		return mp3Header{}, false
+	}
+	version := (data[1] >> 3) & 0x03 // 0: MPEG-2.5, 2: MPEG-2, 3: MPEG-1
+	layer := (data[1] >> 1) & 0x03   // 1: Layer III
+	bitrateIndex := data[2] >> 4
+	rateIndex := (data[2] >> 2) & 0x03
+	if version == 1 || layer != 1 || bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+		return mp3Header{}, false
+	}
+
+	h := mp3Header{mpeg1: version == 3, channels: 2, samples: 576}
+	bitrate := mp3BitratesV2[bitrateIndex]
+	h.sampleRate = mp3SampleRates[rateIndex]
+	switch version {
+	case 3:
+		bitrate = mp3BitratesV1[bitrateIndex]
+		h.samples = 1152
+	case 2:
+		h.sampleRate /= 2
+	case 0:
+		h.sampleRate /= 4
+	}
+	if data[3]>>6 == 3 {
+		h.channels = 1
+	}
+	padding := int(data[2]>>1) & 0x01
+	h.frameSize = h.samples/8*bitrate*1000/h.sampleRate + padding
+	return h, true
+}
+
+// parseMP3 は MP3 の形式と再生時間を読みます。
+//
+// 先頭フレームに Xing / Info ヘッダ（VBR のエンコーダが書くフレーム数）があれば
+// それを使い、無ければフレームを末尾まで辿って数えます。デコードはしません。
+func parseMP3(data []byte) (*AudioClip, error) {
+	offset := 0
+	if len(data) >= 10 && bytes.HasPrefix(data, []byte("ID3")) {
+		// ID3v2 のサイズは各バイト 7 ビットの syncsafe 整数で、ヘッダ 10 バイトを含まない。
+		size := int(data[6]&0x7F)<<21 | int(data[7]&0x7F)<<14 | int(data[8]&0x7F)<<7 | int(data[9]&0x7F)
+		offset = 10 + size
+		if data[5]&0x10 != 0 {
+			offset += 10 // フッタ
+		}
+	}
+	// タグの後ろに詰め物が入ることがあるため、最初のフレーム同期まで読み飛ばす。
+	for offset < len(data) {
+		if _, ok := parseMP3Header(data[offset:]); ok {
+			break
+		}
+		offset++
+	}
+
+	first, ok := parseMP3Header(data[offset:])
+	if !ok {
+		return nil, fmt.Errorf("%w: MP3 のフレームが見つかりません", ErrUnsupportedAudio)
+	}
+	clip := &AudioClip{MIMEType: "audio/mpeg", Data: data, SampleRate: first.sampleRate, Channels: first.channels}
+
+	if frames, ok := xingFrameCount(data[offset:], first); ok {
+		clip.Duration = time.Duration(frames) * time.Duration(first.samples) * time.Second / time.Duration(first.sampleRate)
+		return clip, nil
+	}
+
+	samples := 0
+	for offset < len(data) {
+		h, ok := parseMP3Header(data[offset:])
+		if !ok || h.frameSize <= 4 {
+			break // ID3v1 タグや途中で切れたフレーム
+		}
+		samples += h.samples
+		offset += h.frameSize
+	}
+	clip.Duration = time.Duration(samples) * time.Second / time.Duration(first.sampleRate)
+	return clip, nil
+}
+
+// xingFrameCount は、先頭フレームの Xing / Info ヘッダからフレーム数を読みます。
+// このフレーム自体は音声を含まないため、数には入りません。
+func xingFrameCount(frame []byte, h mp3Header) (int, bool) {
+	// Xing ヘッダはサイド情報の直後に置かれる。サイド情報の長さは版とチャンネル数で決まる。
+	var sideInfo int
+	switch {
+	case h.mpeg1 && h.channels == 1:
+		sideInfo = 17
+	case h.mpeg1:
+		sideInfo = 32
+	case h.channels == 1:
+		sideInfo = 9
+	default:
+		sideInfo = 17
+	}
+	at := 4 + sideInfo
+	if len(frame) < at+12 {
+		return 0, false
+	}
+	if tag := string(frame[at : at+4]); tag != "Xing" && tag != "Info" {
+		return 0, false
+	}
+	flags := binary.BigEndian.Uint32(frame[at+4 : at+8])
+	if flags&0x01 == 0 {
+		return 0, false
+	}
+	return int(binary.BigEndian.Uint32(frame[at+8 : at+12])), true
+}
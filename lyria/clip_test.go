@@ -0,0 +1,196 @@
+package lyria
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/shouni/go-gemini-client/gemini"
+	"github.com/shouni/go-gemini-client/internal/wav"
+	"github.com/stretchr/testify/mock"
+)
+
+// sineWAV は、前後に silence の無音を挟んだ周波数 freq・振幅 amplitude の正弦波の WAV を作ります。
+func sineWAV(t *testing.T, format wav.Format, freq, amplitude float64, length, silence time.Duration) []byte {
+	t.Helper()
+	lead := format.Offset(silence) / (format.Channels * 2)
+	frames := format.Offset(length) / (format.Channels * 2)
+	pcm := make([]byte, (lead*2+frames)*format.Channels*2)
+	for f := range frames {
+		value := int16(amplitude * 32767 * math.Sin(2*math.Pi*freq*float64(f)/float64(format.SampleRate)))
+		for ch := range format.Channels {
+			binary.LittleEndian.PutUint16(pcm[((lead+f)*format.Channels+ch)*2:], uint16(value))
+		}
+	}
+	data, err := wav.Encode(pcm, format)
+	if err != nil {
+		t.Fatalf("wav.Encode() error = %v", err)
+	}
+	return data
+}
+
+// cbrMP3 は、ID3v2 タグの後に MPEG-1 Layer III・128kbps・44.1kHz・ステレオの
+// フレームを frames 個並べた MP3 を作ります（中身は無音の扱いで、デコードはしません）。
+func cbrMP3(frames int) []byte {
+	var buf bytes.Buffer
+	buf.Write([]byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0, 20})
+	buf.Write(make([]byte, 20))
+	for range frames {
+		frame := make([]byte, 417) // 144 * 128000 / 44100
+		copy(frame, []byte{0xFF, 0xFB, 0x90, 0x00})
+		buf.Write(frame)
+	}
+	return buf.Bytes()
+}
+
+func TestNewAudioClip(t *testing.T) {
+	t.Run("WAV", func(t *testing.T) {
+		format := wav.Format{SampleRate: 48000, Channels: 2, BitsPerSample: 16}
+		clip, err := NewAudioClip(sineWAV(t, format, 440, 0.5, 2*time.Second, 0), "")
+		if err != nil {
+			t.Fatalf("NewAudioClip() error = %v", err)
+		}
+		if clip.MIMEType != "audio/wav" || clip.SampleRate != 48000 || clip.Channels != 2 || clip.BitsPerSample != 16 || clip.Duration != 2*time.Second {
+			t.Errorf("clip = %+v", *clip)
+		}
+	})
+
+	t.Run("生の PCM は WAV に包む", func(t *testing.T) {
+		clip, err := NewAudioClip(make([]byte, 24000*2*2), "audio/L16; rate=24000; channels=2")
+		if err != nil {
+			t.Fatalf("NewAudioClip() error = %v", err)
+		}
+		if !clip.IsWAV() || !isWAV(clip.Data) || clip.SampleRate != 24000 || clip.Channels != 2 || clip.Duration != time.Second {
+			t.Errorf("clip = {MIMEType: %s, SampleRate: %d, Channels: %d, Duration: %v}", clip.MIMEType, clip.SampleRate, clip.Channels, clip.Duration)
+		}
+	})
+
+	t.Run("PCM の MIME type は MP3 に見えるサンプルより優先する", func(t *testing.T) {
+		pcm := make([]byte, 48000*2)
+		copy(pcm, []byte{0xFF, 0xFB, 0x90, 0x00}) // MPEG-1 Layer III のフレーム同期に見える
+		if !isMP3(pcm) {
+			t.Fatal("テストの PCM が MP3 に見えません")
+		}
+		clip, err := NewAudioClip(pcm, "audio/pcm;rate=48000")
+		if err != nil {
+			t.Fatalf("NewAudioClip() error = %v", err)
+		}
+		if !clip.IsWAV() || clip.Duration != time.Second {
+			t.Errorf("clip = {MIMEType: %s, Duration: %v}, want 1s の WAV", clip.MIMEType, clip.Duration)
+		}
+	})
+
+	t.Run("MP3 はフレームを数える", func(t *testing.T) {
+		clip, err := NewAudioClip(cbrMP3(100), "application/octet-stream")
+		if err != nil {
+			t.Fatalf("NewAudioClip() error = %v", err)
+		}
+		want := 100 * 1152 * time.Second / 44100
+		if clip.MIMEType != "audio/mpeg" || clip.SampleRate != 44100 || clip.Channels != 2 || clip.Duration != want {
+			t.Errorf("clip = {MIMEType: %s, SampleRate: %d, Channels: %d, Duration: %v}, want Duration %v", clip.MIMEType, clip.SampleRate, clip.Channels, clip.Duration, want)
+		}
+	})
+
+	t.Run("MP3 は Xing ヘッダのフレーム数を使う", func(t *testing.T) {
+		data := cbrMP3(3)
+		first := data[30:] // ID3v2 タグの後ろ
+		copy(first[36:], "Xing")
+		binary.BigEndian.PutUint32(first[40:], 0x01)
+		binary.BigEndian.PutUint32(first[44:], 1000)
+		clip, err := NewAudioClip(data, "audio/mpeg")
+		if err != nil {
+			t.Fatalf("NewAudioClip() error = %v", err)
+		}
+		if want := 1000 * 1152 * time.Second / 44100; clip.Duration != want {
+			t.Errorf("Duration = %v, want %v", clip.Duration, want)
+		}
+	})
+
+	t.Run("解釈できない形式", func(t *testing.T) {
+		if _, err := NewAudioClip([]byte("OggS...."), "audio/ogg"); !errors.Is(err, ErrUnsupportedAudio) {
+			t.Errorf("err = %v, want ErrUnsupportedAudio", err)
+		}
+	})
+}
+
+func TestAudioClipLoudness(t *testing.T) {
+	// BS.1770 では、1 チャンネルの 997Hz・0 dBFS の正弦波は -3.01 LUFS になる。
+	format := wav.Format{SampleRate: 48000, Channels: 1, BitsPerSample: 16}
+	clip, err := NewAudioClip(sineWAV(t, format, 997, 0.5, 3*time.Second, 0), "audio/wav")
+	if err != nil {
+		t.Fatalf("NewAudioClip() error = %v", err)
+	}
+	loudness, err := clip.Loudness()
+	if err != nil {
+		t.Fatalf("Loudness() error = %v", err)
+	}
+	if want := -3.01 - 6.02; math.Abs(loudness-want) > 0.1 {
+		t.Errorf("Loudness() = %.2f, want %.2f", loudness, want)
+	}
+}
+
+func TestAudioClipNormalize(t *testing.T) {
+	format := wav.Format{SampleRate: 48000, Channels: 2, BitsPerSample: 16}
+	clip, err := NewAudioClip(sineWAV(t, format, 997, 0.1, 3*time.Second, 500*time.Millisecond), "audio/wav")
+	if err != nil {
+		t.Fatalf("NewAudioClip() error = %v", err)
+	}
+
+	normalized, err := clip.Normalize(Normalization{SampleRate: 24000, TargetLUFS: -14, TrimSilence: true})
+	if err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+	if normalized.SampleRate != 24000 || normalized.Channels != 2 {
+		t.Errorf("形式 = %d Hz / %d ch, want 24000 Hz / 2 ch", normalized.SampleRate, normalized.Channels)
+	}
+	if d := normalized.Duration - 3*time.Second; d < -5*time.Millisecond || d > 5*time.Millisecond {
+		t.Errorf("Duration = %v, want 約 3s（前後の無音を除く）", normalized.Duration)
+	}
+	loudness, err := normalized.Loudness()
+	if err != nil {
+		t.Fatalf("Loudness() error = %v", err)
+	}
+	if math.Abs(loudness-(-14)) > 0.2 {
+		t.Errorf("Loudness() = %.2f, want -14", loudness)
+	}
+	if clip.Duration != 4*time.Second {
+		t.Errorf("元の clip が変更されています: Duration = %v", clip.Duration)
+	}
+
+	if _, err := (&AudioClip{MIMEType: "audio/mpeg", Data: cbrMP3(1)}).Normalize(Normalization{TargetLUFS: -14}); !errors.Is(err, ErrUnsupportedAudio) {
+		t.Errorf("MP3 の Normalize() err = %v, want ErrUnsupportedAudio", err)
+	}
+}
+
+func TestGenerateClipKeepsMIMETypeAndNormalizes(t *testing.T) {
+	ctx := context.Background()
+	mAI := new(MockGeminiClient)
+	workflow := newTestWorkflow(t, mAI, WithNormalization(Normalization{SampleRate: 16000}))
+
+	pcm := make([]byte, 48000*2)
+	mAI.On("GenerateWithAttachments", mock.Anything, "lyria-3", "full", mock.Anything, mock.Anything).Return(&gemini.Response{
+		Audios:      [][]byte{pcm},
+		Attachments: []gemini.Attachment{{MIMEType: "audio/pcm;rate=48000", Data: pcm}},
+	}, nil)
+
+	clip, err := workflow.GenerateClip(ctx, &MusicRecipe{Title: "Song"}, nil)
+	if err != nil {
+		t.Fatalf("GenerateClip() error = %v", err)
+	}
+	if !clip.IsWAV() || clip.SampleRate != 16000 || clip.Channels != 1 || clip.Duration != time.Second {
+		t.Errorf("clip = {MIMEType: %s, SampleRate: %d, Channels: %d, Duration: %v}", clip.MIMEType, clip.SampleRate, clip.Channels, clip.Duration)
+	}
+
+	// GenerateAudio はこれまでどおり Lyria が返したバイト列をそのまま返す。
+	audio, err := workflow.GenerateAudio(ctx, &MusicRecipe{Title: "Song"}, nil)
+	if err != nil {
+		t.Fatalf("GenerateAudio() error = %v", err)
+	}
+	if !bytes.Equal(audio, pcm) {
+		t.Errorf("GenerateAudio() が加工されたバイト列を返しました")
+	}
+}
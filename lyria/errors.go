@@ -17,6 +17,15 @@ var (
 	ErrEmptyLyrics = errors.New("lyria: lyrics draft is empty")
 	// ErrNoAudio は、Lyria の呼び出しは成功したのに音声データが返らなかった場合に返されます。
 	ErrNoAudio = errors.New("lyria: no audio data received")
+	// ErrNoSections は、セクション単位の生成でレシピにセクションが無い場合や、
+	// 範囲外のセクションを指定した場合に返されます。
+	ErrNoSections = errors.New("lyria: recipe has no such section")
+	// ErrUnsupportedAudio は、音声の形式を解釈できない場合や、形式のせいで処理できない
+	// 場合（結合・正規化は 16 bit PCM の WAV だけが対象です）に返されます。
+	ErrUnsupportedAudio = errors.New("lyria: unsupported audio format")
+	// ErrRejected は、Run のフックが段の成果物を受け入れなかったことを表します。
+	// フックが拒否の理由をこれでラップして返すことを想定しています。
+	ErrRejected = errors.New("lyria: stage output rejected")
 	// ErrInvalidResponse は、モデル出力が期待する JSON として解釈できなかった場合に
 	// 返されます。再生成（リトライ）で解決することがあります。
 	ErrInvalidResponse = errors.New("lyria: model response is not valid")
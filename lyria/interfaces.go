@@ -23,19 +23,39 @@ type TextPromptGenerator interface {
 	GenerateRecipe(mode string, lyrics *LyricsDraft) (string, error)
 }
 
+// LocalizedPromptGenerator は、AIModels.Lang に合わせた歌詞およびレシピのプロンプトを
+// 構築します。TextPromptGenerator が併せて満たしていれば GenerateLyrics / Compose で
+// 自動的に使われ、満たしていなければ言語を渡さない TextPromptGenerator のメソッドを使います。
+// lang は BCP 47 の言語タグで、AIModels.Lang が無ければ素材の文から推した言語（推せなければ
+// 空）が渡ります。
+type LocalizedPromptGenerator interface {
+	GenerateLyricsIn(lang, mode, input string) (string, error)
+	GenerateRecipeIn(lang, mode string, lyrics *LyricsDraft) (string, error)
+}
+
 // AudioPromptBuilder は Lyria の音声生成用プロンプトを構築するインターフェースです。
 type AudioPromptBuilder interface {
 	BuildFullSong(recipe *MusicRecipe) string
 }
 
+// SectionPromptBuilder は、セクション単位の生成（Workflow.GenerateSections）で使う
+// プロンプトを構築します。AudioPromptBuilder が併せて満たしていれば自動で使われ、
+// 満たしていなければレシピのムード・テンポ・楽器とセクションの Prompt から組み立てます。
+type SectionPromptBuilder interface {
+	BuildSection(recipe *MusicRecipe, index int) string
+}
+
 // ReadingConverter は Lyria に渡すプロンプトを読み上げ向けの表記に変換します。
+// WithReadingConverter のものは日本語で歌う歌詞に、WithLanguage で登録したものは
+// その言語で歌う歌詞に使われます。
 type ReadingConverter interface {
 	ConvertToReading(input string) string
 }
 
 // noopReadingConverter は、WithReadingConverter が指定されなかった場合に使われる
 // 何もしないデフォルト実装です。入力をそのまま返します。
-// 読み仮名変換が必要な場合は、呼び出し側で ReadingConverter の実装を注入してください。
+// 読み仮名変換が必要な場合は、呼び出し側で ReadingConverter の実装（漢字の読みなら
+// kana.Converter）を注入してください。
 type noopReadingConverter struct{}
 
 // ConvertToReading は入力をそのまま返します。
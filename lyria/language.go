@@ -0,0 +1,97 @@
+package lyria
+
+import (
+	"cmp"
+	"strings"
+
+	"github.com/shouni/go-gemini-client/music"
+)
+
+// LanguageSupport は、1つの言語の歌詞を扱う部品の組です。WithLanguage で言語タグごとに
+// 登録します。nil の項目は New に渡したもの（読みの変換は、日本語なら
+// WithReadingConverter のもの、それ以外は変換しない）を使います。
+type LanguageSupport struct {
+	// Converter は、この言語で歌う歌詞を Lyria に渡す前に読み上げ向けの表記へ変換します。
+	Converter ReadingConverter
+	// TextPrompts は、この言語の歌詞とレシピのプロンプトを組み立てます。
+	// LocalizedPromptGenerator も満たしていれば、言語タグを渡してそちらを使います。
+	TextPrompts TextPromptGenerator
+	// AudioPrompts は、この言語の音声のプロンプトを組み立てます。SectionPromptBuilder も
+	// 満たしていれば、この言語で歌うセクションのプロンプトにも使います。
+	AudioPrompts AudioPromptBuilder
+}
+
+// lookupLanguage は、言語タグに登録された LanguageSupport を、タグそのもの → 主言語
+// （"pt-BR" → "pt"）の順に探します。空のタグは日本語の扱いです。
+func lookupLanguage(languages map[string]LanguageSupport, tag string) (LanguageSupport, bool) {
+	tag = cmp.Or(tag, LangJapanese)
+	if canonical, ok := music.CanonicalLang(tag); ok {
+		tag = canonical
+	}
+	if support, ok := languages[tag]; ok {
+		return support, true
+	}
+	support, ok := languages[music.BaseLang(tag)]
+	return support, ok
+}
+
+// resolveLyricsLang は、歌詞を書く言語を決めます。AIModels.Lang が無ければ素材の文から
+// 推し、それも決まらなければ空（日本語の扱い）です。
+func resolveLyricsLang(lang, input string) string {
+	if lang != "" {
+		return lang
+	}
+	return music.DetectLang(input)
+}
+
+// textPrompts は lang の歌詞・レシピのプロンプトの組み立てを返します。
+func (g *lyriaTextGenerator) textPrompts(lang string) TextPromptGenerator {
+	if support, ok := lookupLanguage(g.languages, lang); ok && support.TextPrompts != nil {
+		return support.TextPrompts
+	}
+	return g.promptGen
+}
+
+// audioPrompts は lang の音声のプロンプトの組み立てを返します。
+func (g *lyriaAudioGenerator) audioPrompts(lang string) AudioPromptBuilder {
+	if support, ok := lookupLanguage(g.languages, lang); ok && support.AudioPrompts != nil {
+		return support.AudioPrompts
+	}
+	return g.promptBuilder
+}
+
+// convertReading は、lang で歌う text を、その言語の ReadingConverter で変換します。
+// 登録が無い場合、日本語は WithReadingConverter の変換器を使い、それ以外は変換しません。
+func (g *lyriaAudioGenerator) convertReading(lang, text string) string {
+	converter := g.converter
+	if support, ok := lookupLanguage(g.languages, lang); ok && support.Converter != nil {
+		converter = support.Converter
+	} else if !music.SameLang(cmp.Or(lang, LangJapanese), LangJapanese) {
+		return text
+	}
+	if converter == nil {
+		return text
+	}
+	return converter.ConvertToReading(text)
+}
+
+// convertLyricsBySection は、セクションごとに言語の違うレシピの歌詞を、まとまりごとに
+// 割り当てたセクション（music.LyricSheet.Align）の言語で変換した複製を返します。
+// 割り当ての無いまとまりはレシピの言語で変換します。
+func (g *lyriaAudioGenerator) convertLyricsBySection(recipe *MusicRecipe) *MusicRecipe {
+	converted := recipe.Clone()
+	if converted.Lyrics == nil {
+		return converted
+	}
+	sheet := converted.Lyrics.Sheet()
+	for i, index := range sheet.Align(converted.Sections) {
+		lang := converted.Language()
+		if index >= 0 {
+			lang = converted.SectionLang(index)
+		}
+		block := &sheet.Blocks[i]
+		block.Lines = strings.Split(g.convertReading(lang, strings.Join(block.Lines, "\n")), "\n")
+	}
+	converted.Lyrics.Lyrics = sheet.String()
+	return converted
+}
@@ -0,0 +1,227 @@
+package lyria
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// replaceConverter は old を new に書き換える ReadingConverter です。
+type replaceConverter struct {
+	old, new string
+}
+
+func (c replaceConverter) ConvertToReading(input string) string {
+	return strings.ReplaceAll(input, c.old, c.new)
+}
+
+func bilingualRecipe() *MusicRecipe {
+	return &MusicRecipe{
+		Title: "Night Drive",
+		Tempo: 96,
+		Sections: []MusicSection{
+			{Name: "Verse", Duration: 20, EndSeconds: 20, Prompt: "arpeggio"},
+			{Name: "Chorus", Duration: 16, StartSeconds: 20, EndSeconds: 36, Prompt: "full band", Lang: LangEnglish},
+		},
+		Lyrics: &LyricsDraft{Title: "Night Drive", Lyrics: "[Verse]\n夜空を走る\n\n[Chorus]\nunder the 夜空 tonight"},
+	}
+}
+
+func TestGenerateLyricsDetectsLanguageWhenUnset(t *testing.T) {
+	log := &callLog{}
+	ai := new(MockGeminiClient)
+	onJSON(t, ai, log,
+		`{"title": "Song", "theme": "t", "hook": "h", "lyrics": "[Verse]\nla la"}`,
+		`{"title": "Song", "theme": "t", "mood": "m", "tempo": 100, "instruments": [], "sections": [{"name": "Verse", "duration_seconds": 30, "start_seconds": 0, "end_seconds": 30, "prompt": "p"}]}`,
+		`{"title": "Song", "theme": "t", "hook": "h", "lyrics": "[Verse]\nla la"}`,
+	)
+	workflow, err := New(ai, nil, nil, WithGeminiModel("gemini-flash"), WithLyriaModel("lyria-3"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	draft, err := workflow.GenerateLyrics(context.Background(), AIModels{}, &CollectedContent{Prompt: "A rainy night at the station, and the last train is gone"})
+	if err != nil {
+		t.Fatalf("GenerateLyrics() error = %v", err)
+	}
+	if draft.Lang != LangEnglish {
+		t.Errorf("draft.Lang = %q, want %q", draft.Lang, LangEnglish)
+	}
+	if prompt := log.prompts()[0]; !strings.Contains(prompt, "in English") {
+		t.Errorf("素材の言語のプロンプトになっていません: %q", prompt)
+	}
+
+	// 推した言語は Compose でレシピの言語になる。
+	recipe, err := workflow.Compose(context.Background(), AIModels{}, draft)
+	if err != nil {
+		t.Fatalf("Compose() error = %v", err)
+	}
+	if prompt := log.prompts()[1]; recipe.Lang != LangEnglish || !strings.Contains(prompt, "singing in English") {
+		t.Errorf("recipe.Lang = %q, prompt = %q", recipe.Lang, prompt)
+	}
+
+	// 指定した Lang は推さずにそのまま使う。
+	draft, err = workflow.GenerateLyrics(context.Background(), AIModels{Lang: "es-MX"}, &CollectedContent{Prompt: "rainy station"})
+	if err != nil {
+		t.Fatalf("GenerateLyrics() error = %v", err)
+	}
+	if prompt := log.prompts()[2]; draft.Lang != "es-MX" || !strings.Contains(prompt, "in Spanish") {
+		t.Errorf("draft.Lang = %q, prompt = %q", draft.Lang, prompt)
+	}
+}
+
+func TestWithLanguageRegistersPerLanguageParts(t *testing.T) {
+	log := &callLog{}
+	ai := new(MockGeminiClient)
+	ai.onGenerate("lyria-3", mock.Anything).Return(audioResponse([]byte{1}, ""), nil).Run(log.record)
+	workflow, err := New(ai, nil, fixedAudioPromptBuilder{fullSong: "기본 밤"},
+		WithGeminiModel("gemini-flash"),
+		WithLyriaModel("lyria-3"),
+		WithReadingConverter(replaceConverter{old: "夜空", new: "よぞら"}),
+		WithLanguage("ko", LanguageSupport{Converter: replaceConverter{old: "밤", new: "bam"}}),
+		WithLanguage("pt-BR", LanguageSupport{AudioPrompts: fixedAudioPromptBuilder{fullSong: "noite 夜空"}}),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for _, tt := range []struct {
+		lang string
+		want string
+	}{
+		{"ko-KR", "기본 bam"},   // 主言語の登録に当たる
+		{"pt-BR", "noite 夜空"}, // 日本語以外は WithReadingConverter で変換しない
+		{"pt-PT", "기본 밤"},     // 地域付きの登録は他の地域に当たらない
+		{"", "기본 밤"},          // 日本語は WithReadingConverter を使う（"夜空" が無いのでそのまま）
+		{LangJapanese, "기본 밤"},
+	} {
+		log.reset()
+		if _, err := workflow.GenerateAudio(context.Background(), &MusicRecipe{Title: "Song", AIModels: AIModels{Lang: tt.lang}}, nil); err != nil {
+			t.Fatalf("GenerateAudio(%q) error = %v", tt.lang, err)
+		}
+		if prompt := log.prompts()[0]; prompt != tt.want {
+			t.Errorf("Lang %q のプロンプト = %q, want %q", tt.lang, prompt, tt.want)
+		}
+	}
+
+	if _, err := New(ai, nil, nil, WithGeminiModel("g"), WithLyriaModel("l"), WithLanguage("not a tag", LanguageSupport{})); !errors.Is(err, ErrWorkflowConfig) {
+		t.Errorf("不正な言語タグの New() error = %v, want ErrWorkflowConfig", err)
+	}
+}
+
+func TestBilingualRecipeConvertsEachSectionInItsLanguage(t *testing.T) {
+	log := &callLog{}
+	ai := sectionAudioMock(t, log, map[string]int16{"Verse": 1000, "Chorus": 2000}, 100*time.Millisecond)
+	workflow, err := New(ai, nil, nil,
+		WithGeminiModel("gemini-flash"),
+		WithLyriaModel("lyria-3"),
+		WithReadingConverter(replaceConverter{old: "夜空", new: "よぞら"}),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	recipe := bilingualRecipe()
+
+	if _, err := workflow.GenerateAudio(context.Background(), recipe, nil); err != nil {
+		t.Fatalf("GenerateAudio() error = %v", err)
+	}
+	full := log.prompts()[0]
+	for _, want := range []string{"よぞらを走る", "under the 夜空 tonight", "Chorus（16 秒）［English で歌う］"} {
+		if !strings.Contains(full, want) {
+			t.Errorf("曲全体のプロンプトに %q がありません:\n%s", want, full)
+		}
+	}
+	if recipe.Lyrics.Lyrics != bilingualRecipe().Lyrics.Lyrics {
+		t.Errorf("呼び出し元のレシピの歌詞が書き換えられました: %q", recipe.Lyrics.Lyrics)
+	}
+
+	log.reset()
+	if _, err := workflow.GenerateSections(context.Background(), recipe, nil); err != nil {
+		t.Fatalf("GenerateSections() error = %v", err)
+	}
+	var verse, chorus string
+	for _, prompt := range log.prompts() {
+		if strings.Contains(prompt, "Verse") {
+			verse = prompt
+		} else {
+			chorus = prompt
+		}
+	}
+	if !strings.Contains(verse, "このセクションの歌詞（日本語で歌う）:\nよぞらを走る") {
+		t.Errorf("日本語のセクションのプロンプト = %q", verse)
+	}
+	if !strings.Contains(chorus, "Lyrics for this section (sung in English):\nunder the 夜空 tonight") {
+		t.Errorf("英語のセクションのプロンプト = %q", chorus)
+	}
+}
+
+func TestComposeInfersSectionLanguages(t *testing.T) {
+	ai := new(MockGeminiClient)
+	onJSON(t, ai, &callLog{}, `{"title": "Song", "theme": "t", "mood": "m", "tempo": 100, "instruments": [],
+		"sections": [
+			{"name": "Verse", "duration_seconds": 20, "start_seconds": 0, "end_seconds": 20, "prompt": "p"},
+			{"name": "Chorus", "duration_seconds": 20, "start_seconds": 20, "end_seconds": 40, "prompt": "p"}
+		]}`)
+	workflow, err := New(ai, nil, nil, WithGeminiModel("gemini-flash"), WithLyriaModel("lyria-3"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	lyrics := &LyricsDraft{Title: "Song", Lang: LangJapanese, Lyrics: "[Verse]\n夜空を走る君の声\n\n[Chorus]\nI will hold you in the night"}
+
+	recipe, err := workflow.Compose(context.Background(), AIModels{}, lyrics)
+	if err != nil {
+		t.Fatalf("Compose() error = %v", err)
+	}
+	if recipe.Lang != LangJapanese || recipe.Sections[0].Lang != "" || recipe.Sections[1].Lang != LangEnglish {
+		t.Errorf("Lang = %q, Sections = %+v", recipe.Lang, recipe.Sections)
+	}
+	if !recipe.IsMultilingual() {
+		t.Error("IsMultilingual() = false")
+	}
+}
+
+func TestRevisionKeepsLanguages(t *testing.T) {
+	t.Run("ReviseLyrics は歌詞の言語を引き継ぐ", func(t *testing.T) {
+		ai := new(MockGeminiClient)
+		onJSON(t, ai, &callLog{}, `{"title": "Song", "theme": "t", "hook": "h", "lyrics": "[Verse]\nbrighter words"}`)
+		workflow := newTestWorkflow(t, ai)
+
+		revised, err := workflow.ReviseLyrics(context.Background(), AIModels{}, &LyricsDraft{Title: "Song", Lang: "en-US", Lyrics: "[Verse]\ngloomy words"}, "make it brighter")
+		if err != nil {
+			t.Fatalf("ReviseLyrics() error = %v", err)
+		}
+		if revised.Lang != "en-US" {
+			t.Errorf("revised.Lang = %q, want en-US", revised.Lang)
+		}
+	})
+
+	t.Run("修正したレシピにもセクションの言語を推す", func(t *testing.T) {
+		// 1回目は名前の無いセクションで Validate を通らず、直した応答には lang が無い。
+		section := `{"name": %q, "duration_seconds": 20, "start_seconds": %d, "end_seconds": %d, "prompt": "p"}`
+		recipe := func(chorus string) string {
+			return `{"title": "Song", "tempo": 100, "mood": "m", "instruments": [], "sections": [` +
+				fmt.Sprintf(section, "Verse", 0, 20) + ", " + fmt.Sprintf(section, chorus, 20, 40) + `]}`
+		}
+		log := &callLog{}
+		ai := new(MockGeminiClient)
+		onJSON(t, ai, log, recipe(""), recipe("Chorus"))
+		workflow := newTestWorkflow(t, ai, WithRecipeRepair(1))
+		lyrics := &LyricsDraft{Title: "Song", Lang: LangJapanese, Lyrics: "[Verse]\n夜空を走る君の声\n\n[Chorus]\nI will hold you in the night"}
+
+		composed, err := workflow.Compose(context.Background(), AIModels{}, lyrics)
+		if err != nil {
+			t.Fatalf("Compose() error = %v", err)
+		}
+		if prompts := log.prompts(); len(prompts) != 2 {
+			t.Fatalf("prompts = %d, want a repair round", len(prompts))
+		}
+		if composed.Sections[0].Lang != "" || composed.Sections[1].Lang != LangEnglish {
+			t.Errorf("Sections = %+v, want the chorus inferred as English after the repair", composed.Sections)
+		}
+	})
+}
@@ -3,8 +3,10 @@ package lyria
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/shouni/go-gemini-client/gemini"
+	"github.com/shouni/go-gemini-client/music"
 	"golang.org/x/time/rate"
 )
 
@@ -22,19 +24,42 @@ type Workflow struct {
 	lyricist Lyricist
 	composer Composer
 	audio    AudioGenerator
+
+	// renderer はセクション単位の生成と AudioClip の生成を担います。構造体リテラルで
+	// 組んだ Workflow では nil のことがあり、その場合それらは ErrWorkflowConfig を返します。
+	renderer           audioRenderer
+	normalization      *Normalization
+	sectionConcurrency int
+	crossfade          time.Duration
+
+	// checkpoints は Run の途中経過の保存先です。nil は保存しません。
+	checkpoints  CheckpointStore
+	afterLyrics  LyricsHook
+	afterCompose RecipeHook
+
+	// reviser は ReviseLyrics / ReviseRecipe を担います。構造体リテラルで組んだ
+	// Workflow では nil のことがあり、その場合それらは ErrWorkflowConfig を返します。
+	reviser reviser
+
+	// ranker は Variations のテイクの順位付けを担います。rankingModel が空なら順位付けしません。
+	ranker       takeRanker
+	rankingModel string
 }
 
 // New は、指定された構成を使用して新しい Workflow を初期化して返します。
+//
+// promptGen と audioPromptBuilder は nil にでき、その場合は同梱のテンプレートだけを使う
+// PromptTemplates が使われます。
 func New(aiClient gemini.Generator, promptGen TextPromptGenerator, audioPromptBuilder AudioPromptBuilder, overrides ...Option) (*Workflow, error) {
 	opts := applyOptions(overrides...)
 	if aiClient == nil {
 		return nil, fmt.Errorf("%w: aiClient is required", ErrWorkflowConfig)
 	}
 	if promptGen == nil {
-		return nil, fmt.Errorf("%w: promptGen is required", ErrWorkflowConfig)
+		promptGen = defaultPromptTemplates()
 	}
 	if audioPromptBuilder == nil {
-		return nil, fmt.Errorf("%w: audioPromptBuilder is required", ErrWorkflowConfig)
+		audioPromptBuilder = defaultPromptTemplates()
 	}
 	if opts.geminiModel == "" {
 		return nil, fmt.Errorf("%w: GeminiModel is required but not set", ErrWorkflowConfig)
@@ -50,35 +75,79 @@ func New(aiClient gemini.Generator, promptGen TextPromptGenerator, audioPromptBu
 	if converter == nil {
 		converter = noopReadingConverter{}
 	}
+	languages := make(map[string]LanguageSupport, len(opts.languages))
+	for _, language := range opts.languages {
+		tag, ok := music.CanonicalLang(language.tag)
+		if !ok {
+			return nil, fmt.Errorf("%w: language tag %q is not a BCP 47 language tag", ErrWorkflowConfig, language.tag)
+		}
+		languages[tag] = language.support
+	}
 
 	textGenerator := &lyriaTextGenerator{
-		aiClient:     aiClient,
-		promptGen:    promptGen,
-		defaultModel: opts.geminiModel,
-		limiter:      textLimiter,
-		execTimeout:  opts.execTimeout,
+		aiClient:      aiClient,
+		promptGen:     promptGen,
+		languages:     languages,
+		defaultModel:  opts.geminiModel,
+		limiter:       textLimiter,
+		execTimeout:   opts.execTimeout,
+		recipeRepairs: max(opts.recipeRepairs, 0),
+	}
+
+	audioGenerator := &lyriaAudioGenerator{
+		aiClient:          aiClient,
+		promptBuilder:     audioPromptBuilder,
+		converter:         converter,
+		languages:         languages,
+		limiter:           limiter,
+		execTimeout:       opts.execTimeout,
+		defaultLyriaModel: opts.lyriaModel,
+	}
+
+	sectionConcurrency := opts.sectionConcurrency
+	if sectionConcurrency <= 0 {
+		sectionConcurrency = DefaultSectionConcurrency
+	}
+	crossfade := opts.crossfade
+	switch {
+	case crossfade == 0:
+		crossfade = DefaultCrossfade
+	case crossfade < 0:
+		crossfade = 0
 	}
 
 	return &Workflow{
-		lyricist: textGenerator,
-		composer: textGenerator,
-		audio: &lyriaAudioGenerator{
-			aiClient:          aiClient,
-			promptBuilder:     audioPromptBuilder,
-			converter:         converter,
-			limiter:           limiter,
-			execTimeout:       opts.execTimeout,
-			defaultLyriaModel: opts.lyriaModel,
-		},
+		lyricist:           textGenerator,
+		composer:           textGenerator,
+		audio:              audioGenerator,
+		renderer:           audioGenerator,
+		normalization:      opts.normalization,
+		checkpoints:        opts.checkpoints,
+		afterLyrics:        opts.afterLyrics,
+		afterCompose:       opts.afterCompose,
+		reviser:            textGenerator,
+		ranker:             textGenerator,
+		rankingModel:       opts.rankingModel,
+		sectionConcurrency: sectionConcurrency,
+		crossfade:          crossfade,
 	}, nil
 }
 
+// audioRenderer は、AudioGenerator の音声生成に加えて、セクション単位の生成と
+// MIME type 付きの生成を行います。lyriaAudioGenerator が満たします。
+type audioRenderer interface {
+	GenerateSection(ctx context.Context, recipe *MusicRecipe, index int, images []ImagePayload) (*AudioClip, error)
+	GenerateClip(ctx context.Context, recipe *MusicRecipe, images []ImagePayload) (*AudioClip, error)
+	renderFullSong(ctx context.Context, recipe *MusicRecipe, images []ImagePayload) (gemini.Attachment, stageMeter, error)
+}
+
 // GenerateLyrics builds a lyric draft from collected content.
 func (w *Workflow) GenerateLyrics(ctx context.Context, ai AIModels, input *CollectedContent) (*LyricsDraft, error) {
 	return w.lyricist.GenerateLyrics(ctx, ai, input)
 }
 
-// Compose builds a music recipe from a lyric draft.
+// Compose builds a music recipe from a lyric draft. The recipe is repaired with
+// music.Recipe.Normalize, and re-asked for when WithRecipeRepair is set.
 func (w *Workflow) Compose(ctx context.Context, ai AIModels, lyrics *LyricsDraft) (*MusicRecipe, error) {
 	return w.composer.Compose(ctx, ai, lyrics)
 }
@@ -87,3 +156,20 @@ func (w *Workflow) Compose(ctx context.Context, ai AIModels, lyrics *LyricsDraft
 func (w *Workflow) GenerateAudio(ctx context.Context, recipe *MusicRecipe, images []ImagePayload) ([]byte, error) {
 	return w.audio.GenerateAudio(ctx, recipe, images)
 }
+
+// GenerateClip generates full-song audio like GenerateAudio, but returns it as an
+// AudioClip with its MIME type, duration, sample rate and channels. If WithNormalization
+// is set, the clip is normalized before it is returned.
+func (w *Workflow) GenerateClip(ctx context.Context, recipe *MusicRecipe, images []ImagePayload) (*AudioClip, error) {
+	if w.renderer == nil {
+		return nil, fmt.Errorf("%w: GenerateClip requires a Workflow built by New", ErrWorkflowConfig)
+	}
+	clip, err := w.renderer.GenerateClip(ctx, recipe, images)
+	if err != nil {
+		return nil, err
+	}
+	if w.normalization != nil {
+		return clip.Normalize(*w.normalization)
+	}
+	return clip, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -66,6 +67,117 @@ func (b fixedAudioPromptBuilder) BuildFullSong(*MusicRecipe) string {
 	return b.fullSong
 }
 
+// stubPromptGen は、入力をそのまま埋め込んだプロンプトを返す TextPromptGenerator です。
+type stubPromptGen struct{}
+
+func (stubPromptGen) GenerateLyrics(mode string, input string) (string, error) {
+	return "lyrics:" + mode + ":" + input, nil
+}
+
+func (stubPromptGen) GenerateRecipe(mode string, lyrics *LyricsDraft) (string, error) {
+	return "recipe:" + mode + ":" + lyrics.Title, nil
+}
+
+// newTestWorkflow は、ai を生成クライアントにした Workflow を New で組みます。歌詞・レシピの
+// プロンプトは stubPromptGen、曲全体の音声のプロンプトは "full" で、テキストのモデルは
+// "gemini-flash"、Lyria のモデルは "lyria-3" です。opts はその後に適用します。
+func newTestWorkflow(t *testing.T, ai gemini.Generator, opts ...Option) *Workflow {
+	t.Helper()
+	workflow, err := New(ai, stubPromptGen{}, fixedAudioPromptBuilder{fullSong: "full"},
+		append([]Option{WithGeminiModel("gemini-flash"), WithLyriaModel("lyria-3")}, opts...)...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return workflow
+}
+
+// onGenerate は、model への GenerateWithAttachments のうち prompt に合う呼び出しの期待を
+// 登録します。prompt には文字列か引数の条件（promptContaining、mock.Anything など）を渡します。
+func (m *MockGeminiClient) onGenerate(model string, prompt interface{}) *mock.Call {
+	return m.On("GenerateWithAttachments", mock.Anything, model, prompt, mock.Anything, mock.Anything)
+}
+
+// promptContaining は、プロンプトに s を含む呼び出しに合う引数の条件です。
+func promptContaining(s string) interface{} {
+	return mock.MatchedBy(func(prompt string) bool { return strings.Contains(prompt, s) })
+}
+
+// textResponse は、テキストのモデルが text を返した応答です。
+func textResponse(text string) *gemini.Response {
+	return &gemini.Response{Text: text, Usage: &gemini.TokenUsage{PromptTokenCount: 10, CandidatesTokenCount: 5, TotalTokenCount: 15}}
+}
+
+// audioResponse は、Lyria が data を mimeType の音声として返した応答です。mimeType が空なら
+// Attachments を組み立てず、Audios だけを返します。
+func audioResponse(data []byte, mimeType string) *gemini.Response {
+	resp := &gemini.Response{Audios: [][]byte{data}}
+	if mimeType != "" {
+		resp.Attachments = []gemini.Attachment{{MIMEType: mimeType, Data: data}}
+	}
+	return resp
+}
+
+// onJSON は、"gemini-flash" への構造化出力の呼び出しに texts を1つずつ順に返す期待を登録し、
+// 呼び出しを log に記録します。
+func onJSON(t *testing.T, ai *MockGeminiClient, log *callLog, texts ...string) {
+	t.Helper()
+	for _, text := range texts {
+		ai.On("GenerateWithAttachments", mock.Anything, "gemini-flash", mock.Anything, mock.Anything, jsonGenerateOptionsWithSeed(t, nil)).
+			Return(textResponse(text), nil).Once().Run(log.record)
+	}
+}
+
+// recordedCall は、callLog が記録した GenerateWithAttachments の呼び出し1回分です。
+type recordedCall struct {
+	model       string
+	prompt      string
+	attachments []gemini.Attachment
+	opts        gemini.GenerateOptions
+}
+
+// callLog は、mock.Call.Run に record を渡して、MockGeminiClient が受けた呼び出しを記録します。
+// 同時に処理している呼び出し数の最大も数えます。
+type callLog struct {
+	mu     sync.Mutex
+	calls  []recordedCall
+	active int
+	peak   int
+}
+
+// record は呼び出しを記録します。重なりを数えられるよう、呼び出しを少しだけ長引かせます。
+func (l *callLog) record(args mock.Arguments) {
+	attachments, _ := args.Get(3).([]gemini.Attachment)
+	l.mu.Lock()
+	l.calls = append(l.calls, recordedCall{model: args.String(1), prompt: args.String(2), attachments: attachments, opts: args.Get(4).(gemini.GenerateOptions)})
+	l.active++
+	l.peak = max(l.peak, l.active)
+	l.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	l.mu.Lock()
+	l.active--
+	l.mu.Unlock()
+}
+
+// prompts は記録したプロンプトを呼び出し順に返します。
+func (l *callLog) prompts() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	prompts := make([]string, len(l.calls))
+	for i, call := range l.calls {
+		prompts[i] = call.prompt
+	}
+	return prompts
+}
+
+// reset は記録を消します。
+func (l *callLog) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.calls = nil
+}
+
 func jsonGenerateOptionsWithSeed(t *testing.T, wantSeed *int64) interface{} {
 	t.Helper()
 	return mock.MatchedBy(func(opts gemini.GenerateOptions) bool {
@@ -0,0 +1,295 @@
+package lyria
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/shouni/go-gemini-client/internal/wav"
+)
+
+// DefaultSilenceThresholdDB は、Normalization.TrimSilence で無音とみなす振幅の既定値（dBFS）です。
+const DefaultSilenceThresholdDB = -50.0
+
+// Normalization は、AudioClip.Normalize で揃える出力の形式です。
+// ゼロ値のフィールドは「揃えない」を表します。
+type Normalization struct {
+	// SampleRate は出力のサンプリング周波数（Hz）です。0 は元のままです。
+	SampleRate int
+	// TargetLUFS は、ITU-R BS.1770 の統合ラウドネスをこの値（LUFS、-14 や -23 など）へ
+	// 揃えます。0 はラウドネスを変えません。ピークが 16 bit の範囲を超える分は丸めるだけで、
+	// リミッタは掛けません。
+	TargetLUFS float64
+	// TrimSilence は、先頭と末尾の無音を取り除きます。
+	TrimSilence bool
+	// SilenceThresholdDB は TrimSilence で無音とみなす振幅（dBFS）です。
+	// 0 は DefaultSilenceThresholdDB です。
+	SilenceThresholdDB float64
+}
+
+// Normalize は、n に従って音声を揃えた新しい AudioClip を返します。c は変更しません。
+//
+// 処理は無音の除去 → サンプリング周波数の変換（線形補間）→ ラウドネスの調整の順です。
+// 対象は 16 bit PCM の WAV だけで、MP3 などは ErrUnsupportedAudio を返します
+// （このパッケージは MP3 をデコードしません）。
+func (c *AudioClip) Normalize(n Normalization) (*AudioClip, error) {
+	format, channels, err := c.decodeSamples()
+	if err != nil {
+		return nil, err
+	}
+
+	if n.TrimSilence {
+		threshold := n.SilenceThresholdDB
+		if threshold == 0 {
+			threshold = DefaultSilenceThresholdDB
+		}
+		channels = trimSilence(channels, math.Pow(10, threshold/20))
+	}
+	if n.SampleRate > 0 && n.SampleRate != format.SampleRate {
+		channels = resample(channels, format.SampleRate, n.SampleRate)
+		format.SampleRate = n.SampleRate
+	}
+	if n.TargetLUFS != 0 {
+		if loudness := integratedLoudness(channels, format.SampleRate); !math.IsInf(loudness, -1) {
+			gain := math.Pow(10, (n.TargetLUFS-loudness)/20)
+			for _, samples := range channels {
+				for i := range samples {
+					samples[i] *= gain
+				}
+			}
+		}
+	}
+
+	data, err := encodeSamples(channels, format)
+	if err != nil {
+		return nil, err
+	}
+	return NewAudioClip(data, "audio/wav")
+}
+
+// Loudness は、ITU-R BS.1770（EBU R 128）の統合ラウドネスを LUFS で返します。
+// 無音の場合は -Inf です。対象は 16 bit PCM の WAV だけです。
+func (c *AudioClip) Loudness() (float64, error) {
+	format, channels, err := c.decodeSamples()
+	if err != nil {
+		return 0, err
+	}
+	return integratedLoudness(channels, format.SampleRate), nil
+}
+
+// decodeSamples は、16 bit PCM の WAV をチャンネルごとの [-1, 1) の値へ展開します。
+func (c *AudioClip) decodeSamples() (wav.Format, [][]float64, error) {
+	if c == nil {
+		return wav.Format{}, nil, fmt.Errorf("%w: audio clip", ErrNilInput)
+	}
+	if !c.IsWAV() {
+		return wav.Format{}, nil, fmt.Errorf("%w: %s は処理できません（16 bit PCM の WAV のみ）", ErrUnsupportedAudio, c.MIMEType)
+	}
+	format, pcm, err := wav.Decode(c.Data)
+	if err != nil {
+		return wav.Format{}, nil, fmt.Errorf("%w: %w", ErrUnsupportedAudio, err)
+	}
+	if !format.Valid() || format.BitsPerSample != 16 {
+		return wav.Format{}, nil, fmt.Errorf("%w: 形式 %+v は処理できません（16 bit PCM のみ）", ErrUnsupportedAudio, format)
+	}
+
+	frames := len(pcm) / (format.Channels * 2)
+	channels := make([][]float64, format.Channels)
+	for ch := range channels {
+		channels[ch] = make([]float64, frames)
+	}
+	for f := range frames {
+		for ch := range format.Channels {
+			sample := int16(binary.LittleEndian.Uint16(pcm[(f*format.Channels+ch)*2:]))
+			channels[ch][f] = float64(sample) / 32768
+		}
+	}
+	return format, channels, nil
+}
+
+// encodeSamples は、チャンネルごとの値を 16 bit PCM の WAV へ戻します。範囲外は丸めます。
+func encodeSamples(channels [][]float64, format wav.Format) ([]byte, error) {
+	frames := 0
+	if len(channels) > 0 {
+		frames = len(channels[0])
+	}
+	pcm := make([]byte, frames*len(channels)*2)
+	for f := range frames {
+		for ch, samples := range channels {
+			value := math.Round(samples[f] * 32768)
+			binary.LittleEndian.PutUint16(pcm[(f*len(channels)+ch)*2:], uint16(int16(min(max(value, -32768), 32767))))
+		}
+	}
+	data, err := wav.Encode(pcm, format)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnsupportedAudio, err)
+	}
+	return data, nil
+}
+
+// trimSilence は、全チャンネルの振幅が threshold 未満のフレームを先頭と末尾から取り除きます。
+func trimSilence(channels [][]float64, threshold float64) [][]float64 {
+	if len(channels) == 0 {
+		return channels
+	}
+	loud := func(f int) bool {
+		for _, samples := range channels {
+			if math.Abs(samples[f]) >= threshold {
+				return true
+			}
+		}
+		return false
+	}
+	start, end := 0, len(channels[0])
+	for start < end && !loud(start) {
+		start++
+	}
+	for end > start && !loud(end-1) {
+		end--
+	}
+	for ch := range channels {
+		channels[ch] = channels[ch][start:end]
+	}
+	return channels
+}
+
+// resample は、各チャンネルを線形補間で from Hz から to Hz へ変換します。
+// 音楽の最終的な書き出しには帯域制限付きの変換が望ましいですが、ここでは
+// 依存を持たずに配信向けの形式へ揃えることを優先しています。
+func resample(channels [][]float64, from, to int) [][]float64 {
+	out := make([][]float64, len(channels))
+	for ch, samples := range channels {
+		n := int(int64(len(samples)) * int64(to) / int64(from))
+		converted := make([]float64, n)
+		for i := range n {
+			pos := float64(i) * float64(from) / float64(to)
+			j := int(pos)
+			if j+1 >= len(samples) {
+				converted[i] = samples[len(samples)-1]
+				continue
+			}
+			frac := pos - float64(j)
+			converted[i] = samples[j]*(1-frac) + samples[j+1]*frac
+		}
+		out[ch] = converted
+	}
+	return out
+}
+
+// biquad は2次の IIR フィルタです（a0 で正規化済み）。
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+}
+
+// apply は samples にフィルタを掛けた新しいスライスを返します。
+func (q biquad) apply(samples []float64) []float64 {
+	out := make([]float64, len(samples))
+	var x1, x2, y1, y2 float64
+	for i, x := range samples {
+		y := q.b0*x + q.b1*x1 + q.b2*x2 - q.a1*y1 - q.a2*y2
+		x2, x1 = x1, x
+		y2, y1 = y1, y
+		out[i] = y
+	}
+	return out
+}
+
+// kWeighting は、BS.1770 の K 特性（高域シェルフ + 高域通過）の係数を sampleRate 向けに
+// 求めます。規格は 48kHz の係数だけを示すため、アナログ原型から双一次変換で導きます
+// （libebur128 と同じ式で、48kHz では規格の値に一致します）。
+func kWeighting(sampleRate int) (shelf, highPass biquad) {
+	fs := float64(sampleRate)
+
+	const (
+		shelfFreq = 1681.974450955533
+		shelfGain = 3.999843853973347
+		shelfQ    = 0.7071752369554196
+	)
+	k := math.Tan(math.Pi * shelfFreq / fs)
+	vh := math.Pow(10, shelfGain/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/shelfQ + k*k
+	shelf = biquad{
+		b0: (vh + vb*k/shelfQ + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/shelfQ + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/shelfQ + k*k) / a0,
+	}
+
+	const (
+		highPassFreq = 38.13547087602444
+		highPassQ    = 0.5003270373238773
+	)
+	k = math.Tan(math.Pi * highPassFreq / fs)
+	a0 = 1 + k/highPassQ + k*k
+	highPass = biquad{
+		b0: 1,
+		b1: -2,
+		b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/highPassQ + k*k) / a0,
+	}
+	return shelf, highPass
+}
+
+// integratedLoudness は BS.1770 の統合ラウドネス（LUFS）を求めます。
+//
+// K 特性を掛けた信号を 400ms・75% 重なりのブロックに分け、-70 LUFS の絶対ゲートと、
+// それを通ったブロックの平均から -10 LU の相対ゲートで静かな部分を除いて平均します。
+// チャンネルの重みはすべて 1 です（5.1ch のサラウンドの重み付けは扱いません）。
+func integratedLoudness(channels [][]float64, sampleRate int) float64 {
+	if len(channels) == 0 || len(channels[0]) == 0 || sampleRate <= 0 {
+		return math.Inf(-1)
+	}
+	shelf, highPass := kWeighting(sampleRate)
+	weighted := make([][]float64, len(channels))
+	for ch, samples := range channels {
+		weighted[ch] = highPass.apply(shelf.apply(samples))
+	}
+
+	frames := len(weighted[0])
+	block := sampleRate * 400 / 1000
+	step := block / 4
+	if frames < block {
+		// 400ms に満たない音声は全体を1ブロックとして測る。
+		block, step = frames, frames
+	}
+	var powers []float64
+	for start := 0; start+block <= frames; start += step {
+		power := 0.0
+		for _, samples := range weighted {
+			sum := 0.0
+			for _, x := range samples[start : start+block] {
+				sum += x * x
+			}
+			power += sum / float64(block)
+		}
+		powers = append(powers, power)
+	}
+
+	loudness := func(power float64) float64 { return -0.691 + 10*math.Log10(power) }
+	gatedMean := func(threshold float64) (float64, bool) {
+		sum, n := 0.0, 0
+		for _, power := range powers {
+			if loudness(power) > threshold {
+				sum += power
+				n++
+			}
+		}
+		if n == 0 {
+			return 0, false
+		}
+		return sum / float64(n), true
+	}
+
+	absolute, ok := gatedMean(-70)
+	if !ok {
+		return math.Inf(-1)
+	}
+	relative, ok := gatedMean(loudness(absolute) - 10)
+	if !ok {
+		return math.Inf(-1)
+	}
+	return loudness(relative)
+}
@@ -9,6 +9,22 @@ type options struct {
 	textRateInterval time.Duration
 	readingConverter ReadingConverter
 	execTimeout      time.Duration
+
+	sectionConcurrency int
+	crossfade          time.Duration
+	normalization      *Normalization
+	checkpoints        CheckpointStore
+	afterLyrics        LyricsHook
+	afterCompose       RecipeHook
+	rankingModel       string
+	recipeRepairs      int
+	languages          []languageOption
+}
+
+// languageOption は WithLanguage で受け取った登録です。New が言語タグを検証します。
+type languageOption struct {
+	tag     string
+	support LanguageSupport
 }
 
 // Option configures Adapter.
@@ -29,6 +45,7 @@ func WithLyriaModel(value string) Option {
 }
 
 // WithReadingConverter injects a custom converter to format text into reader-friendly phonetics.
+// It applies to prompts sung in Japanese; use WithLanguage for other languages.
 func WithReadingConverter(converter ReadingConverter) Option {
 	return func(opts *options) {
 		opts.readingConverter = converter
@@ -59,6 +76,88 @@ func WithExecTimeout(value time.Duration) Option {
 	}
 }
 
+// WithSectionConcurrency sets how many sections GenerateSections (and how many takes
+// Variations) renders at once.
+// Every call still waits on the audio rate limiter. Zero or less (unset) means
+// DefaultSectionConcurrency.
+func WithSectionConcurrency(n int) Option {
+	return func(opts *options) {
+		opts.sectionConcurrency = n
+	}
+}
+
+// WithCrossfade sets the length of the crossfade applied at section boundaries when
+// GenerateSections stitches the track. Zero (unset) means DefaultCrossfade; a negative
+// value disables crossfading and butts sections together.
+func WithCrossfade(value time.Duration) Option {
+	return func(opts *options) {
+		opts.crossfade = value
+	}
+}
+
+// WithNormalization makes GenerateClip normalize every clip with n (resampling,
+// loudness normalization and silence trimming). Unset means clips are returned as
+// Lyria produced them.
+func WithNormalization(n Normalization) Option {
+	return func(opts *options) {
+		opts.normalization = &n
+	}
+}
+
+// WithCheckpointStore makes Run persist each stage's artifact to store and resume an
+// unfinished run from the last completed stage. Unset means Run keeps nothing between calls.
+func WithCheckpointStore(store CheckpointStore) Option {
+	return func(opts *options) {
+		opts.checkpoints = store
+	}
+}
+
+// WithAfterLyrics sets a hook that Run calls right after generating lyrics. The hook
+// may edit the draft in place or return an error (wrapping ErrRejected) to stop the run.
+func WithAfterLyrics(hook LyricsHook) Option {
+	return func(opts *options) {
+		opts.afterLyrics = hook
+	}
+}
+
+// WithAfterCompose sets a hook that Run calls right after composing the recipe. The hook
+// may edit the recipe in place or return an error (wrapping ErrRejected) to stop the run.
+func WithAfterCompose(hook RecipeHook) Option {
+	return func(opts *options) {
+		opts.afterCompose = hook
+	}
+}
+
+// WithTakeRanking makes Variations ask model (a Gemini model that accepts audio input)
+// to score the takes against the recipe and return them best first. Unset means
+// Variations returns the takes unranked in generation order.
+func WithTakeRanking(model string) Option {
+	return func(opts *options) {
+		opts.rankingModel = model
+	}
+}
+
+// WithRecipeRepair makes Compose re-ask the text model, up to attempts times, when the
+// composed recipe still fails music.Recipe.Validate after Normalize, and return the
+// validation error if it never passes. Zero (unset) means Compose only normalizes the
+// recipe and never rejects it.
+func WithRecipeRepair(attempts int) Option {
+	return func(opts *options) {
+		opts.recipeRepairs = attempts
+	}
+}
+
+// WithLanguage registers the reading converter and prompt builders used for lyrics
+// sung in tag, a BCP 47 language tag such as "ko" or "pt-BR". Lookups try the exact tag
+// first and then its base language, so registering "pt" also covers "pt-BR". Nil fields
+// fall back to what New was given; a "ja" Converter overrides WithReadingConverter.
+// New returns ErrWorkflowConfig for a tag that is not a BCP 47 language tag.
+func WithLanguage(tag string, support LanguageSupport) Option {
+	return func(opts *options) {
+		opts.languages = append(opts.languages, languageOption{tag: tag, support: support})
+	}
+}
+
 func applyOptions(overrides ...Option) options {
 	var opts options
 	for _, override := range overrides {
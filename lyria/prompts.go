@@ -0,0 +1,301 @@
+package lyria
+
+import (
+	"bytes"
+	"cmp"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/shouni/go-gemini-client/music"
+)
+
+// builtinPrompts は標準のプロンプトのテンプレートです（prompts/<lang>/<kind>/<mode>.tmpl）。
+//
+//go:embed prompts
+var builtinPrompts embed.FS
+
+// プロンプトのテンプレートの種類です。
+const (
+	promptKindLyrics   = "lyrics"
+	promptKindRecipe   = "recipe"
+	promptKindFullSong = "full_song"
+	promptKindSection  = "section"
+	defaultPromptMode  = "default"
+)
+
+// PromptData は、プロンプトのテンプレートに渡す値です。入る項目はテンプレートの種類
+// （lyrics / recipe / full_song / section）によって異なります。
+type PromptData struct {
+	// Lang はプロンプトを選んだ言語タグです（"ja"、"en-US" など）。section ではセクションの
+	// 言語（Recipe.SectionLang）です。テンプレートが別の言語のものでも、この言語で
+	// 書かせる・歌わせるよう {{language .Lang}} で言語名を書けます。
+	Lang string
+	// Mode は LyricsMode（lyrics）か ComposeMode（それ以外）です。空の場合は "default" です。
+	Mode string
+	// Input は歌詞の素材（CollectedContent.Prompt）です。lyrics でだけ入ります。
+	Input string
+	// Lyrics は作曲する歌詞です。recipe でだけ入ります。
+	Lyrics *LyricsDraft
+	// Recipe は音声化するレシピです。full_song と section で入ります。
+	Recipe *MusicRecipe
+	// Section と Index は生成するセクションとその添字です。section でだけ入ります。
+	Section *MusicSection
+	Index   int
+	// SectionLines は、このセクションで歌う歌詞の行です（music.LyricSheet.Align で割り当てた
+	// まとまりの行）。section でだけ入り、割り当てが無ければ空です。
+	SectionLines []string
+}
+
+// PromptTemplates は、text/template で書いたプロンプトから TextPromptGenerator・
+// AudioPromptBuilder・SectionPromptBuilder・LocalizedPromptGenerator を満たす標準の実装です。
+// New に nil を渡すと、同梱のテンプレートだけを使うものが使われます。
+//
+// テンプレートは <lang>/<kind>/<mode>.tmpl に置きます。kind は lyrics / recipe /
+// full_song / section、mode は LyricsMode / ComposeMode の値（未指定は default）です。
+// lang は BCP 47 の言語タグ（"pt-BR" など）で、使うテンプレートは <lang>/<kind>/<mode> →
+// <主言語>/<kind>/<mode> → <lang>/<kind>/default → <主言語>/<kind>/default →
+// en/<kind>/<mode> → en/<kind>/default の順に探します（lang が空なら ja）。同梱しているのは
+// ja と en の default だけで、それ以外のモードでは default のテンプレートにモード名を作風と
+// して渡します。英語のテンプレートは言語名を PromptData.Lang から書くため、テンプレートの
+// 無い言語でもその言語で書かせる・歌わせるプロンプトになります。
+//
+// テンプレートでは PromptData の項目と、join（strings.Join）・seconds（セクションの秒数）・
+// language（言語タグの英語名、music.LangName）が使えます。
+type PromptTemplates struct {
+	templates map[string]*template.Template // "ja/recipe/default" → テンプレート
+}
+
+// PromptTemplates が各プロンプトの役割を満たすことをコンパイル時に保証します。
+var (
+	_ TextPromptGenerator      = (*PromptTemplates)(nil)
+	_ LocalizedPromptGenerator = (*PromptTemplates)(nil)
+	_ AudioPromptBuilder       = (*PromptTemplates)(nil)
+	_ SectionPromptBuilder     = (*PromptTemplates)(nil)
+)
+
+// defaultPromptTemplates は同梱のテンプレートだけを使う PromptTemplates です。
+// 同梱のテンプレートはテストで検証しているため、解析に失敗した場合は panic します。
+var defaultPromptTemplates = sync.OnceValue(func() *PromptTemplates {
+	templates, err := NewPromptTemplates()
+	if err != nil {
+		panic(err)
+	}
+	return templates
+})
+
+// NewPromptTemplates は、同梱のテンプレートに overrides のテンプレートを重ねた
+// PromptTemplates を返します。
+//
+// overrides の各 fs.FS は同梱のものと同じ <lang>/<kind>/<mode>.tmpl の配置で、後に
+// 渡したものほど優先します（os.DirFS や embed.FS を fs.Sub したものを渡せます）。
+// 配置に合わない .tmpl ファイルや、解析・試しの実行に失敗したテンプレートがあると
+// エラーを返します。
+func NewPromptTemplates(overrides ...fs.FS) (*PromptTemplates, error) {
+	builtin, err := fs.Sub(builtinPrompts, "prompts")
+	if err != nil {
+		return nil, fmt.Errorf("lyria: failed to open builtin prompt templates: %w", err)
+	}
+	p := &PromptTemplates{templates: make(map[string]*template.Template)}
+	for _, fsys := range append([]fs.FS{builtin}, overrides...) {
+		if fsys == nil {
+			continue
+		}
+		if err := p.load(fsys); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// load は fsys のテンプレートを読み込みます。
+func (p *PromptTemplates) load(fsys fs.FS) error {
+	return fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || path.Ext(name) != ".tmpl" {
+			return nil
+		}
+		parts := strings.Split(strings.TrimSuffix(name, ".tmpl"), "/")
+		if len(parts) != 3 || !isPromptKind(parts[1]) {
+			return fmt.Errorf("lyria: prompt template %s must be placed at <lang>/<kind>/<mode>.tmpl (kind: lyrics, recipe, full_song or section)", name)
+		}
+		lang, ok := music.CanonicalLang(parts[0])
+		if !ok {
+			return fmt.Errorf("lyria: prompt template %s must be placed under a BCP 47 language tag directory", name)
+		}
+		parts[0] = lang
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return fmt.Errorf("lyria: failed to read prompt template %s: %w", name, err)
+		}
+		tmpl, err := template.New(name).Funcs(promptFuncs).Parse(string(data))
+		if err != nil {
+			return fmt.Errorf("lyria: failed to parse prompt template %s: %w", name, err)
+		}
+		// フィールド名の誤りなどは実行するまで分からないため、見本の値で一度実行しておく。
+		if err := tmpl.Execute(&bytes.Buffer{}, samplePromptData(parts[0], parts[1], parts[2])); err != nil {
+			return fmt.Errorf("lyria: prompt template %s failed on sample data: %w", name, err)
+		}
+		p.templates[strings.Join(parts, "/")] = tmpl
+		return nil
+	})
+}
+
+// promptFuncs はテンプレートで使える関数です。
+var promptFuncs = template.FuncMap{
+	"join":     strings.Join,
+	"seconds":  sectionSeconds,
+	"language": music.LangName,
+}
+
+func isPromptKind(kind string) bool {
+	switch kind {
+	case promptKindLyrics, promptKindRecipe, promptKindFullSong, promptKindSection:
+		return true
+	}
+	return false
+}
+
+// samplePromptData は、テンプレートの試しの実行に使う値です。
+func samplePromptData(lang, kind, mode string) PromptData {
+	lyrics := &LyricsDraft{Title: "Title", Theme: "Theme", Hook: "Hook", Lyrics: "[Verse]\nline", Keywords: []string{"keyword"}, Mood: "Mood", Narrative: "Narrative"}
+	recipe := &MusicRecipe{
+		Title: "Title", Theme: "Theme", Mood: "Mood", Tempo: 120, Key: "C major", VocalProfile: "Vocals",
+		Instruments: []string{"piano"},
+		Sections:    []MusicSection{{Name: "Verse", Duration: 30, EndSeconds: 30, Prompt: "Prompt"}},
+		Lyrics:      lyrics,
+		AIModels:    AIModels{Lang: lang, ComposeMode: mode},
+	}
+	data := PromptData{Lang: lang, Mode: mode}
+	switch kind {
+	case promptKindLyrics:
+		data.Input = "Input"
+	case promptKindRecipe:
+		data.Lyrics = lyrics
+	case promptKindFullSong:
+		data.Recipe = recipe
+	case promptKindSection:
+		data.Recipe, data.Section, data.SectionLines = recipe, &recipe.Sections[0], []string{"line"}
+	}
+	return data
+}
+
+// execute は lang・kind・mode に合うテンプレートを探して実行します。
+func (p *PromptTemplates) execute(lang, kind, mode string, data PromptData) (string, error) {
+	lang = cmp.Or(lang, LangJapanese)
+	if canonical, ok := music.CanonicalLang(lang); ok {
+		lang = canonical
+	}
+	base := music.BaseLang(lang)
+	mode = cmp.Or(mode, defaultPromptMode)
+	data.Lang, data.Mode = lang, mode
+
+	for _, candidate := range []string{
+		lang + "/" + kind + "/" + mode,
+		base + "/" + kind + "/" + mode,
+		lang + "/" + kind + "/" + defaultPromptMode,
+		base + "/" + kind + "/" + defaultPromptMode,
+		LangEnglish + "/" + kind + "/" + mode,
+		LangEnglish + "/" + kind + "/" + defaultPromptMode,
+	} {
+		tmpl, ok := p.templates[candidate]
+		if !ok {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return "", fmt.Errorf("lyria: failed to execute prompt template %s: %w", candidate, err)
+		}
+		return strings.TrimSpace(buf.String()), nil
+	}
+	return "", fmt.Errorf("lyria: no prompt template for %s/%s/%s", lang, kind, mode)
+}
+
+// GenerateLyrics は日本語（Lang 未指定の扱い）の歌詞のプロンプトを返します。
+func (p *PromptTemplates) GenerateLyrics(mode string, input string) (string, error) {
+	return p.GenerateLyricsIn("", mode, input)
+}
+
+// GenerateRecipe は日本語（Lang 未指定の扱い）のレシピのプロンプトを返します。
+func (p *PromptTemplates) GenerateRecipe(mode string, lyrics *LyricsDraft) (string, error) {
+	return p.GenerateRecipeIn("", mode, lyrics)
+}
+
+// GenerateLyricsIn は lang の歌詞のプロンプトを返します。
+func (p *PromptTemplates) GenerateLyricsIn(lang, mode, input string) (string, error) {
+	return p.execute(lang, promptKindLyrics, mode, PromptData{Input: input})
+}
+
+// GenerateRecipeIn は lang のレシピのプロンプトを返します。
+func (p *PromptTemplates) GenerateRecipeIn(lang, mode string, lyrics *LyricsDraft) (string, error) {
+	if lyrics == nil {
+		return "", fmt.Errorf("%w: lyrics draft", ErrNilInput)
+	}
+	return p.execute(lang, promptKindRecipe, mode, PromptData{Lyrics: lyrics})
+}
+
+// BuildFullSong は、レシピの Lang と ComposeMode に合うテンプレートで曲全体のプロンプトを
+// 返します。重ねたテンプレートの実行に失敗した場合は、同梱のテンプレートで組み立てます。
+func (p *PromptTemplates) BuildFullSong(recipe *MusicRecipe) string {
+	if recipe == nil {
+		return ""
+	}
+	return p.buildAudio(promptKindFullSong, recipe.Language(), recipe, PromptData{Recipe: recipe})
+}
+
+// BuildSection は、セクションの言語（Recipe.SectionLang）とレシピの ComposeMode に合う
+// テンプレートで index 番目のセクションのプロンプトを返します。
+func (p *PromptTemplates) BuildSection(recipe *MusicRecipe, index int) string {
+	if recipe == nil {
+		return ""
+	}
+	data := PromptData{Recipe: recipe, Index: index}
+	if index >= 0 && index < len(recipe.Sections) {
+		data.Section = &recipe.Sections[index]
+		data.SectionLines = sectionLines(recipe, index)
+	}
+	return p.buildAudio(promptKindSection, recipe.SectionLang(index), recipe, data)
+}
+
+// buildAudio は音声用のプロンプトを組み立てます。AudioPromptBuilder はエラーを返せない
+// ため、失敗した場合は同梱のテンプレートに切り替えます（それも失敗すれば空文字列です）。
+func (p *PromptTemplates) buildAudio(kind, lang string, recipe *MusicRecipe, data PromptData) string {
+	mode := recipe.ComposeMode
+	promptText, err := p.execute(lang, kind, mode, data)
+	if err == nil {
+		return promptText
+	}
+	if builtin := defaultPromptTemplates(); builtin != p {
+		if promptText, err := builtin.execute(lang, kind, defaultPromptMode, data); err == nil {
+			return promptText
+		}
+	}
+	return ""
+}
+
+// sectionLines は、recipe の歌詞のうち index 番目のセクションで歌う行を返します。
+func sectionLines(recipe *MusicRecipe, index int) []string {
+	if recipe.Lyrics == nil {
+		return nil
+	}
+	sheet := recipe.Lyrics.Sheet()
+	for i, assigned := range sheet.Align(recipe.Sections) {
+		if assigned != index {
+			continue
+		}
+		var lines []string
+		for _, line := range sheet.Blocks[i].Lines {
+			if line != "" {
+				lines = append(lines, line)
+			}
+		}
+		return lines
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package lyria
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func promptRecipe(lang string) *MusicRecipe {
+	return &MusicRecipe{
+		Title:        "Night Drive",
+		Mood:         "nostalgic",
+		Tempo:        96,
+		Key:          "F# minor",
+		VocalProfile: "soft female vocal",
+		Instruments:  []string{"synth", "drum machine"},
+		Sections: []MusicSection{
+			{Name: "Intro", Duration: 8, EndSeconds: 8, Prompt: "pads"},
+			{Name: "Verse", Duration: 20, StartSeconds: 8, EndSeconds: 28, Prompt: "arpeggio"},
+			{Name: "Chorus", Duration: 16, StartSeconds: 28, EndSeconds: 44, Prompt: "full band"},
+		},
+		Lyrics:   &LyricsDraft{Title: "Night Drive", Lyrics: "[Verse]\n街の灯り\n\n[Chorus]\n走り出せ\n今すぐ"},
+		AIModels: AIModels{Lang: lang, ComposeMode: "synthwave"},
+	}
+}
+
+func TestPromptTemplatesDefaults(t *testing.T) {
+	templates, err := NewPromptTemplates()
+	if err != nil {
+		t.Fatalf("NewPromptTemplates() error = %v", err)
+	}
+
+	ja, err := templates.GenerateLyrics("", "雨の日の駅")
+	if err != nil {
+		t.Fatalf("GenerateLyrics() error = %v", err)
+	}
+	if !strings.Contains(ja, "日本語で歌う歌詞") || !strings.Contains(ja, "雨の日の駅") || strings.Contains(ja, "作風") {
+		t.Errorf("日本語の歌詞のプロンプト = %q", ja)
+	}
+	en, err := templates.GenerateLyricsIn(LangEnglish, "ballad", "rainy station")
+	if err != nil {
+		t.Fatalf("GenerateLyricsIn() error = %v", err)
+	}
+	if !strings.Contains(en, "in English") || !strings.Contains(en, "Style: ballad") {
+		t.Errorf("英語の歌詞のプロンプト = %q", en)
+	}
+
+	recipe, err := templates.GenerateRecipeIn(LangJapanese, "jazz", &LyricsDraft{Title: "駅", Lyrics: "[Verse]\nla"})
+	if err != nil {
+		t.Fatalf("GenerateRecipeIn() error = %v", err)
+	}
+	if !strings.Contains(recipe, "ジャンル・作風: jazz") || !strings.Contains(recipe, "曲名: 駅") || !strings.Contains(recipe, "[Verse]\nla") {
+		t.Errorf("レシピのプロンプト = %q", recipe)
+	}
+
+	full := templates.BuildFullSong(promptRecipe(LangEnglish))
+	for _, want := range []string{"Song: Night Drive", "Tempo: 96 BPM", "Key: F# minor", "Instruments: synth, drum machine", "Vocals: soft female vocal", "- Verse (20 seconds): arpeggio", "Lyrics (sung in English):\n[Verse]"} {
+		if !strings.Contains(full, want) {
+			t.Errorf("BuildFullSong() に %q がありません:\n%s", want, full)
+		}
+	}
+
+	section := templates.BuildSection(promptRecipe(""), 2)
+	for _, want := range []string{"テンポ: 96 BPM", "セクション: Chorus（16 秒）", "展開: full band", "このセクションの歌詞（日本語で歌う）:\n走り出せ\n今すぐ"} {
+		if !strings.Contains(section, want) {
+			t.Errorf("BuildSection() に %q がありません:\n%s", want, section)
+		}
+	}
+	if intro := templates.BuildSection(promptRecipe(""), 0); strings.Contains(intro, "歌詞") {
+		t.Errorf("歌詞の無いイントロのプロンプトに歌詞があります:\n%s", intro)
+	}
+}
+
+func TestPromptTemplatesOverridePerMode(t *testing.T) {
+	overrides := fstest.MapFS{
+		"ja/recipe/jazz.tmpl":         {Data: []byte("jazz recipe for {{.Lyrics.Title}} ({{.Lang}})")},
+		"en/full_song/synthwave.tmpl": {Data: []byte("synthwave {{.Recipe.Tempo}} BPM")},
+		"README.md":                   {Data: []byte("not a template")},
+	}
+	templates, err := NewPromptTemplates(overrides)
+	if err != nil {
+		t.Fatalf("NewPromptTemplates() error = %v", err)
+	}
+
+	if got, _ := templates.GenerateRecipe("jazz", &LyricsDraft{Title: "駅"}); got != "jazz recipe for 駅 (ja)" {
+		t.Errorf("GenerateRecipe(jazz) = %q", got)
+	}
+	if got, _ := templates.GenerateRecipe("pop", &LyricsDraft{Title: "駅"}); !strings.Contains(got, "作曲家") {
+		t.Errorf("上書きの無いモードは default を使うはずです: %q", got)
+	}
+	if got := templates.BuildFullSong(promptRecipe(LangEnglish)); got != "synthwave 96 BPM" {
+		t.Errorf("BuildFullSong() = %q", got)
+	}
+	// 英語以外の言語で上書きが無ければ、その言語の default を使う。
+	if got := templates.BuildFullSong(promptRecipe(LangJapanese)); !strings.Contains(got, "曲名: Night Drive") {
+		t.Errorf("BuildFullSong(ja) = %q", got)
+	}
+}
+
+func TestNewPromptTemplatesRejectsBrokenTemplates(t *testing.T) {
+	for name, fsys := range map[string]fstest.MapFS{
+		"配置が違う":      {"recipe/jazz.tmpl": {Data: []byte("x")}},
+		"種類が無い":      {"ja/cover/default.tmpl": {Data: []byte("x")}},
+		"構文エラー":      {"ja/lyrics/default.tmpl": {Data: []byte("{{.Input")}},
+		"存在しないフィールド": {"ja/lyrics/default.tmpl": {Data: []byte("{{.Prompt}}")}},
+	} {
+		if _, err := NewPromptTemplates(fsys); err == nil {
+			t.Errorf("%s: NewPromptTemplates() error = nil", name)
+		}
+	}
+}
+
+func TestNewUsesDefaultPromptsWhenNil(t *testing.T) {
+	log := &callLog{}
+	ai := new(MockGeminiClient)
+	onJSON(t, ai, log, `{"title": "Song", "theme": "t", "hook": "h", "lyrics": "la"}`)
+	workflow, err := New(ai, nil, nil, WithGeminiModel("gemini-flash"), WithLyriaModel("lyria-3"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := workflow.GenerateLyrics(context.Background(), AIModels{Lang: LangEnglish, LyricsMode: "ballad"}, &CollectedContent{Prompt: "rain"}); err != nil {
+		t.Fatalf("GenerateLyrics() error = %v", err)
+	}
+	if prompt := log.prompts()[0]; !strings.Contains(prompt, "in English") || !strings.Contains(prompt, "Style: ballad") {
+		t.Errorf("AIModels.Lang に合ったプロンプトになっていません: %q", prompt)
+	}
+}
+
+func TestPromptTemplatesFallBackByLanguageTag(t *testing.T) {
+	templates, err := NewPromptTemplates(fstest.MapFS{
+		"PT/lyrics/default.tmpl":    {Data: []byte("letra ({{.Lang}}): {{.Input}}")},
+		"pt-br/lyrics/samba.tmpl":   {Data: []byte("samba ({{.Lang}})")},
+		"EN_us/full_song/rock.tmpl": {Data: []byte("rock {{.Recipe.Title}}")},
+	})
+	if err != nil {
+		t.Fatalf("NewPromptTemplates() error = %v", err)
+	}
+
+	for _, tt := range []struct {
+		lang, mode, want string
+	}{
+		{"pt-BR", "samba", "samba (pt-BR)"},                // タグそのもの
+		{"pt_br", "pop", "letra (pt-BR): chuva"},           // 主言語の default
+		{"pt-PT", "samba", "letra (pt-PT): chuva"},         // 他の地域のモードは使わない
+		{"ko", "", "Write song lyrics in Korean based on"}, // テンプレートの無い言語は英語のもので言語名を書く
+	} {
+		got, err := templates.GenerateLyricsIn(tt.lang, tt.mode, "chuva")
+		if err != nil || !strings.Contains(got, tt.want) {
+			t.Errorf("GenerateLyricsIn(%q, %q) = %q, %v, want %q", tt.lang, tt.mode, got, err, tt.want)
+		}
+	}
+
+	recipe := promptRecipe("en-US")
+	recipe.ComposeMode = "rock"
+	if got := templates.BuildFullSong(recipe); got != "rock Night Drive" {
+		t.Errorf("BuildFullSong(en-US) = %q", got)
+	}
+	recipe.Lang = "pt-BR"
+	if got := templates.BuildFullSong(recipe); !strings.Contains(got, "Lyrics (sung in Portuguese):") {
+		t.Errorf("BuildFullSong(pt-BR) = %q", got)
+	}
+
+	if _, err := NewPromptTemplates(fstest.MapFS{"日本語/lyrics/default.tmpl": {Data: []byte("x")}}); err == nil {
+		t.Error("言語タグでないディレクトリの NewPromptTemplates() error = nil")
+	}
+}
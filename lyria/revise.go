@@ -0,0 +1,135 @@
+package lyria
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/shouni/go-gemini-client/gemini"
+)
+
+// RevisionPromptBuilder は、既存の歌詞やレシピを指示に沿って直させるプロンプトを構築します。
+// TextPromptGenerator が併せて満たしていれば ReviseLyrics / ReviseRecipe で自動的に使われ、
+// 満たしていなければ defaultRevisionPrompt を使います。
+//
+// kind は "lyrics" か "recipe"、current は直す対象の JSON です。
+type RevisionPromptBuilder interface {
+	BuildRevision(kind string, current string, instruction string) (string, error)
+}
+
+// reviser は既存の歌詞・レシピをテキストモデルに直させます。lyriaTextGenerator が満たします。
+type reviser interface {
+	reviseLyrics(ctx context.Context, ai AIModels, lyrics *LyricsDraft, instruction string) (*LyricsDraft, error)
+	reviseRecipe(ctx context.Context, ai AIModels, recipe *MusicRecipe, instruction string) (*MusicRecipe, error)
+}
+
+// ReviseLyrics asks the text model to edit an existing lyric draft according to a
+// natural-language instruction, and returns the revised draft. The language (Lang) of
+// lyrics is carried over unchanged. lyrics is not modified.
+func (w *Workflow) ReviseLyrics(ctx context.Context, ai AIModels, lyrics *LyricsDraft, instruction string) (*LyricsDraft, error) {
+	if w.reviser == nil {
+		return nil, fmt.Errorf("%w: ReviseLyrics requires a Workflow built by New", ErrWorkflowConfig)
+	}
+	return w.reviser.reviseLyrics(ctx, ai, lyrics, instruction)
+}
+
+// ReviseRecipe asks the text model to edit an existing music recipe according to a
+// natural-language instruction, and returns the revised recipe. The lyrics and AIModels
+// of recipe are carried over unchanged, and the revised recipe is normalized like
+// Compose output (see music.Recipe.Normalize and music.Recipe.InferSectionLangs).
+// recipe is not modified.
+func (w *Workflow) ReviseRecipe(ctx context.Context, ai AIModels, recipe *MusicRecipe, instruction string) (*MusicRecipe, error) {
+	if w.reviser == nil {
+		return nil, fmt.Errorf("%w: ReviseRecipe requires a Workflow built by New", ErrWorkflowConfig)
+	}
+	return w.reviser.reviseRecipe(ctx, ai, recipe, instruction)
+}
+
+// reviseLyrics は歌詞ドラフトを instruction に沿って直させます。
+func (g *lyriaTextGenerator) reviseLyrics(ctx context.Context, ai AIModels, lyrics *LyricsDraft, instruction string) (*LyricsDraft, error) {
+	if lyrics == nil {
+		return nil, fmt.Errorf("%w: lyrics draft", ErrNilInput)
+	}
+	promptText, err := g.revisionPrompt("lyrics", lyrics, instruction)
+	if err != nil {
+		return nil, err
+	}
+
+	revised, _, err := generateJSON[LyricsDraft](ctx, g, "revise-lyrics", g.resolveModel(ai.TextModel), promptText, ai.Seed, lyricsDraftSchema())
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(revised.Lyrics) == "" {
+		return nil, ErrEmptyLyrics
+	}
+	// 言語はスキーマに含めておらずモデルに書き換えさせないため、元の歌詞から付け直す。
+	draft := revised.Clone()
+	draft.Lang = lyrics.Lang
+	return draft, nil
+}
+
+// reviseRecipe はレシピを instruction に沿って直させます。
+func (g *lyriaTextGenerator) reviseRecipe(ctx context.Context, ai AIModels, recipe *MusicRecipe, instruction string) (*MusicRecipe, error) {
+	if recipe == nil {
+		return nil, fmt.Errorf("%w: music recipe", ErrNilInput)
+	}
+	revised, _, err := g.reviseRecipeMetered(ctx, ai, recipe, instruction)
+	return revised, err
+}
+
+// reviseRecipeMetered は reviseRecipe と同じ処理で、トークン使用量も返します。
+// 直したレシピには Compose と同じく Normalize と InferSectionLangs を掛けます。
+func (g *lyriaTextGenerator) reviseRecipeMetered(ctx context.Context, ai AIModels, recipe *MusicRecipe, instruction string) (*MusicRecipe, *gemini.TokenUsage, error) {
+	// 歌詞と AIModels はスキーマに含めておらずモデルに書き換えさせないため、
+	// 直させる JSON からも外す（Compose と同じく、生成後にコードが付け直す）。
+	current := recipe.Clone()
+	current.Lyrics = nil
+	current.AIModels = AIModels{}
+	promptText, err := g.revisionPrompt("recipe", current, instruction)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	shared, usage, err := generateJSON[MusicRecipe](ctx, g, "revise-compose", g.resolveModel(ai.TextModel), promptText, ai.Seed, musicRecipeSchema())
+	if err != nil {
+		return nil, usage, err
+	}
+	revised := shared.Clone()
+	revised.Lyrics = recipe.Lyrics.Clone()
+	revised.AIModels = recipe.Clone().AIModels
+	revised.Normalize()
+	revised.InferSectionLangs()
+	return revised, usage, nil
+}
+
+// revisionPrompt は、current を JSON にして直させるプロンプトを組み立てます。
+func (g *lyriaTextGenerator) revisionPrompt(kind string, current any, instruction string) (string, error) {
+	instruction = strings.TrimSpace(instruction)
+	if instruction == "" {
+		return "", fmt.Errorf("%w: revision instruction", ErrNilInput)
+	}
+	data, err := json.MarshalIndent(current, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %s for revision: %w", kind, err)
+	}
+	if builder, ok := g.promptGen.(RevisionPromptBuilder); ok {
+		promptText, err := builder.BuildRevision(kind, string(data), instruction)
+		if err != nil {
+			return "", fmt.Errorf("failed to build revision prompt (kind: %s): %w", kind, err)
+		}
+		return promptText, nil
+	}
+	return defaultRevisionPrompt(kind, string(data), instruction), nil
+}
+
+// defaultRevisionPrompt は、RevisionPromptBuilder が無い場合の修正用プロンプトです。
+// 指示されていない部分まで書き換えられると人が手を入れた意味が無くなるため、
+// 変更を指示の範囲にとどめるよう明示しています。
+func defaultRevisionPrompt(kind, current, instruction string) string {
+	return "You are revising an existing song " + kind + ".\n" +
+		"Apply the instruction below and return the complete revised " + kind + " as JSON with the same fields.\n" +
+		"Change only what the instruction asks for and keep every other field exactly as it is.\n\n" +
+		"Instruction:\n" + instruction + "\n\n" +
+		"Current " + kind + " (JSON):\n" + current + "\n"
+}
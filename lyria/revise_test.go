@@ -0,0 +1,124 @@
+package lyria
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/shouni/go-gemini-client/music"
+)
+
+type revisionPromptGen struct {
+	stubPromptGen
+}
+
+func (revisionPromptGen) BuildRevision(kind string, current string, instruction string) (string, error) {
+	return "custom " + kind + " / " + instruction, nil
+}
+
+func TestReviseLyrics(t *testing.T) {
+	log := &callLog{}
+	ai := new(MockGeminiClient)
+	onJSON(t, ai, log, `{"title": "Song", "theme": "t", "hook": "h", "lyrics": "brighter words"}`)
+	workflow := newTestWorkflow(t, ai)
+	original := &LyricsDraft{Title: "Song", Theme: "t", Hook: "h", Lyrics: "gloomy words"}
+
+	revised, err := workflow.ReviseLyrics(context.Background(), AIModels{}, original, "make it brighter")
+	if err != nil {
+		t.Fatalf("ReviseLyrics() error = %v", err)
+	}
+	if revised.Lyrics != "brighter words" || original.Lyrics != "gloomy words" {
+		t.Errorf("revised = %q, original = %q", revised.Lyrics, original.Lyrics)
+	}
+	prompt := log.prompts()[0]
+	if !strings.Contains(prompt, "make it brighter") || !strings.Contains(prompt, `"lyrics": "gloomy words"`) {
+		t.Errorf("プロンプトに指示と現在の歌詞がありません: %q", prompt)
+	}
+
+	if _, err := workflow.ReviseLyrics(context.Background(), AIModels{}, original, "  "); !errors.Is(err, ErrNilInput) {
+		t.Errorf("空の指示の err = %v, want ErrNilInput", err)
+	}
+}
+
+func TestReviseRecipeKeepsLyricsAndModels(t *testing.T) {
+	log := &callLog{}
+	ai := new(MockGeminiClient)
+	onJSON(t, ai, log, `{"title": "Song", "tempo": 140, "mood": "energetic", "instruments": ["drums"], "sections": []}`)
+	workflow, err := New(ai, revisionPromptGen{}, fixedAudioPromptBuilder{},
+		WithGeminiModel("gemini-flash"), WithLyriaModel("lyria-3"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	original := &MusicRecipe{
+		Title:    "Song",
+		Tempo:    90,
+		Lyrics:   &LyricsDraft{Title: "Song", Lyrics: "words"},
+		AIModels: AIModels{AudioModel: "lyria-custom"},
+	}
+
+	revised, err := workflow.ReviseRecipe(context.Background(), AIModels{}, original, "faster")
+	if err != nil {
+		t.Fatalf("ReviseRecipe() error = %v", err)
+	}
+	if revised.Tempo != 140 || original.Tempo != 90 {
+		t.Errorf("revised.Tempo = %d, original.Tempo = %d", revised.Tempo, original.Tempo)
+	}
+	if revised.Lyrics == nil || revised.Lyrics.Lyrics != "words" || revised.AudioModel != "lyria-custom" {
+		t.Errorf("歌詞と AIModels が引き継がれていません: %+v", revised)
+	}
+	if prompt := log.prompts()[0]; prompt != "custom recipe / faster" {
+		t.Errorf("RevisionPromptBuilder が使われていません: %q", prompt)
+	}
+}
+
+func TestComposeNormalizesAndRepairsRecipe(t *testing.T) {
+	lyrics := &LyricsDraft{Title: "Song", Lyrics: "la la"}
+	// 重なったタイミングと表記揺れのキーは Normalize で直るが、名前の無いセクションは直らない。
+	invalid := `{"title": "Song", "tempo": 0, "key": "ebm", "mood": "calm", "instruments": [], "sections": [
+		{"name": "Verse", "duration_seconds": 20, "start_seconds": 0, "end_seconds": 20, "prompt": "p"},
+		{"name": "", "duration_seconds": 10, "start_seconds": 5, "end_seconds": 15, "prompt": "p"}]}`
+	fixed := `{"title": "Song", "tempo": 0, "key": "ebm", "mood": "calm", "instruments": [], "sections": [
+		{"name": "Verse", "duration_seconds": 20, "start_seconds": 0, "end_seconds": 20, "prompt": "p"},
+		{"name": "Chorus", "duration_seconds": 10, "start_seconds": 5, "end_seconds": 15, "prompt": "p"}]}`
+
+	t.Run("修正の指定が無ければ Normalize だけ", func(t *testing.T) {
+		ai := new(MockGeminiClient)
+		onJSON(t, ai, &callLog{}, invalid)
+		workflow := newTestWorkflow(t, ai)
+		recipe, err := workflow.Compose(context.Background(), AIModels{}, lyrics)
+		if err != nil {
+			t.Fatalf("Compose() error = %v", err)
+		}
+		if recipe.Tempo != music.DefaultTempo || recipe.Key != "Eb minor" || recipe.Sections[1].StartSeconds != 20 || recipe.Sections[1].EndSeconds != 30 {
+			t.Errorf("recipe = {Tempo: %d, Key: %q, Sections: %+v}", recipe.Tempo, recipe.Key, recipe.Sections)
+		}
+	})
+
+	t.Run("Validate を通らなければ問題を伝えて直させる", func(t *testing.T) {
+		log := &callLog{}
+		ai := new(MockGeminiClient)
+		onJSON(t, ai, log, invalid, fixed)
+		workflow := newTestWorkflow(t, ai, WithRecipeRepair(2))
+		recipe, err := workflow.Compose(context.Background(), AIModels{}, lyrics)
+		if err != nil {
+			t.Fatalf("Compose() error = %v", err)
+		}
+		if prompts := log.prompts(); len(prompts) != 2 || !strings.Contains(prompts[1], "Sections[1].Name") {
+			t.Errorf("prompts = %q, want 2 回目に問題のフィールドを伝える", prompts)
+		}
+		if recipe.Sections[1].Name != "Chorus" || recipe.Sections[1].StartSeconds != 20 || recipe.Lyrics.Title != "Song" {
+			t.Errorf("recipe = %+v", recipe)
+		}
+	})
+
+	t.Run("直らなければ検証エラーを返す", func(t *testing.T) {
+		ai := new(MockGeminiClient)
+		onJSON(t, ai, &callLog{}, invalid, invalid)
+		workflow := newTestWorkflow(t, ai, WithRecipeRepair(1))
+		_, err := workflow.Compose(context.Background(), AIModels{}, lyrics)
+		if fieldErr, ok := errors.AsType[*music.FieldError](err); !ok || fieldErr.Field != "Sections[1].Name" || !errors.Is(err, music.ErrInvalidRecipe) {
+			t.Errorf("Compose() error = %v, want Sections[1].Name の *music.FieldError", err)
+		}
+	})
+}
@@ -0,0 +1,269 @@
+package lyria
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shouni/go-gemini-client/gemini"
+)
+
+// LyricsHook は、Run が歌詞を生成した直後に呼ばれるフックです。
+//
+// lyrics を確認し、必要ならその場で書き換えてください。エラーを返すと Run はそこで
+// 止まり、その段の成果物は保存されません（呼び直すと歌詞から生成し直します）。
+// 拒否の理由には ErrRejected をラップすると、呼び出し側が errors.Is で判別できます。
+type LyricsHook func(ctx context.Context, lyrics *LyricsDraft) error
+
+// RecipeHook は、Run がレシピを生成した直後に呼ばれるフックです。扱いは LyricsHook と同じです。
+type RecipeHook func(ctx context.Context, recipe *MusicRecipe) error
+
+// Stage は Workflow.Run の段です。
+type Stage string
+
+const (
+	// StageLyrics は歌詞生成（GenerateLyrics）の段です。
+	StageLyrics Stage = "lyrics"
+	// StageCompose は作曲（Compose）の段です。
+	StageCompose Stage = "compose"
+	// StageAudio は音声生成（GenerateAudio）の段です。
+	StageAudio Stage = "audio"
+)
+
+// StageStats は1つの段の記録です。
+type StageStats struct {
+	Stage Stage `json:"stage"`
+	// Model は段で使ったモデルです。Workflow が差し替えられた役割を使っていて
+	// 分からない場合は、AIModels の指定（空のこともあります）です。
+	Model string `json:"model,omitempty"`
+	// Duration は段の所要時間です。
+	Duration time.Duration `json:"duration"`
+	// Usage はトークン使用量です。モデルが返さなかった場合は nil です。
+	Usage *gemini.TokenUsage `json:"usage,omitempty"`
+	// Resumed は、この実行では生成せずチェックポイントから復元したことを表します。
+	Resumed bool `json:"resumed,omitempty"`
+}
+
+// RunResult は Workflow.Run の結果です。途中で失敗した場合も、それまでの成果物が入ります。
+type RunResult struct {
+	// RunID は実行の識別子で、チェックポイントのキーです（RunKey を参照）。
+	RunID  string
+	Lyrics *LyricsDraft
+	Recipe *MusicRecipe
+	Audio  []byte
+	// Stages は段の記録を実行順に並べたものです。
+	Stages []StageStats
+}
+
+// Usage は全段のトークン使用量の合計です。どの段も返さなかった場合は nil です。
+func (r *RunResult) Usage() *gemini.TokenUsage {
+	if r == nil {
+		return nil
+	}
+	var total *gemini.TokenUsage
+	for _, stage := range r.Stages {
+		total = addUsage(total, stage.Usage)
+	}
+	return total
+}
+
+// StageError は、Workflow.Run のある段が失敗したことを表します。
+// errors.Is で原因（ErrEmptyLyrics や ErrNoAudio など）を判定できます。
+type StageError struct {
+	Stage Stage
+	RunID string
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("lyria: %s stage failed (run %s): %v", e.Stage, e.RunID, e.Err)
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// stageMeter は、段で使ったモデルとトークン使用量です。
+type stageMeter struct {
+	model string
+	usage *gemini.TokenUsage
+}
+
+// Workflow の標準の役割（lyriaTextGenerator / lyriaAudioGenerator）が満たす、計測付きの
+// 呼び出しです。テストなどで役割が差し替えられている場合は、通常のメソッドで代用します。
+type (
+	meteredLyricist interface {
+		generateLyricsMetered(ctx context.Context, ai AIModels, input *CollectedContent) (*LyricsDraft, stageMeter, error)
+	}
+	meteredComposer interface {
+		composeMetered(ctx context.Context, ai AIModels, lyrics *LyricsDraft) (*MusicRecipe, stageMeter, error)
+	}
+	meteredAudioGenerator interface {
+		generateAudioMetered(ctx context.Context, recipe *MusicRecipe, images []ImagePayload) ([]byte, stageMeter, error)
+	}
+)
+
+// RunKey は、ai と input から Workflow.Run の RunID を求めます。
+// 同じ指定・同じ入力（プロンプトと画像）なら同じ値になります。
+func RunKey(ai AIModels, input *CollectedContent) string {
+	hasher := sha256.New()
+	for _, part := range []string{ai.TextModel, ai.AudioModel, ai.LyricsMode, ai.ComposeMode, ai.Lang, singleflightSeedKey(ai.Seed)} {
+		writeHashPart(hasher, []byte(part))
+	}
+	if input != nil {
+		writeHashPart(hasher, []byte(input.Prompt))
+		writeHashPart(hasher, []byte(calculateImagesHash(input.Images)))
+	}
+	return hex.EncodeToString(hasher.Sum(nil))[:32]
+}
+
+// Run は、歌詞生成 → 作曲 → 音声生成の 3 段を続けて実行します。
+//
+// WithCheckpointStore を設定すると、段が完了するたびに成果物を保存します。同じ ai と
+// input で Run を呼び直すと（RunID は RunKey で決まります）、未完了の記録があれば
+// 完了済みの段を飛ばして続きから実行します。すべての段が完了した記録からは再開せず、
+// 最初から実行し直します。
+//
+// WithAfterLyrics / WithAfterCompose のフックは、その段を生成した直後（保存の前）に
+// 呼ばれます。フックが書き換えた内容が保存され、次の段に渡ります。チェックポイントから
+// 復元した段ではフックを呼び直しません。
+//
+// 段が失敗すると（フックが拒否した場合を含む） *StageError を返します。RunResult にはそれまでの成果物と段の記録が
+// 入ります。段の間に品質ゲートを挟みたい場合は、これまでどおり 3 つのメソッドを
+// 個別に呼んでください。
+func (w *Workflow) Run(ctx context.Context, ai AIModels, input *CollectedContent) (*RunResult, error) {
+	if input == nil {
+		return nil, fmt.Errorf("%w: collected content", ErrNilInput)
+	}
+
+	result := &RunResult{RunID: RunKey(ai, input)}
+	checkpoint, err := w.resumeCheckpoint(ctx, result.RunID)
+	if err != nil {
+		return nil, err
+	}
+	result.Lyrics, result.Recipe = checkpoint.Lyrics.Clone(), checkpoint.Recipe.Clone()
+	for _, stage := range checkpoint.Stages {
+		stage.Resumed = true
+		result.Stages = append(result.Stages, stage)
+	}
+
+	if result.Lyrics == nil {
+		lyrics, err := runStage(result, StageLyrics, ai.TextModel, func() (*LyricsDraft, stageMeter, error) {
+			if metered, ok := w.lyricist.(meteredLyricist); ok {
+				return metered.generateLyricsMetered(ctx, ai, input)
+			}
+			lyrics, err := w.lyricist.GenerateLyrics(ctx, ai, input)
+			return lyrics, stageMeter{model: ai.TextModel}, err
+		})
+		if err != nil {
+			return result, err
+		}
+		if w.afterLyrics != nil {
+			if err := w.afterLyrics(ctx, lyrics); err != nil {
+				return result, &StageError{Stage: StageLyrics, RunID: result.RunID, Err: err}
+			}
+		}
+		result.Lyrics, checkpoint.Lyrics = lyrics, lyrics.Clone()
+		if err := w.saveCheckpoint(ctx, checkpoint, result); err != nil {
+			return result, err
+		}
+	}
+
+	if result.Recipe == nil {
+		recipe, err := runStage(result, StageCompose, ai.TextModel, func() (*MusicRecipe, stageMeter, error) {
+			if metered, ok := w.composer.(meteredComposer); ok {
+				return metered.composeMetered(ctx, ai, result.Lyrics)
+			}
+			recipe, err := w.composer.Compose(ctx, ai, result.Lyrics)
+			return recipe, stageMeter{model: ai.TextModel}, err
+		})
+		if err != nil {
+			return result, err
+		}
+		if w.afterCompose != nil {
+			if err := w.afterCompose(ctx, recipe); err != nil {
+				return result, &StageError{Stage: StageCompose, RunID: result.RunID, Err: err}
+			}
+		}
+		result.Recipe, checkpoint.Recipe = recipe, recipe.Clone()
+		if err := w.saveCheckpoint(ctx, checkpoint, result); err != nil {
+			return result, err
+		}
+	}
+
+	audio, err := runStage(result, StageAudio, ai.AudioModel, func() ([]byte, stageMeter, error) {
+		if metered, ok := w.audio.(meteredAudioGenerator); ok {
+			return metered.generateAudioMetered(ctx, result.Recipe, input.Images)
+		}
+		audio, err := w.audio.GenerateAudio(ctx, result.Recipe, input.Images)
+		return audio, stageMeter{model: ai.AudioModel}, err
+	})
+	if err != nil {
+		return result, err
+	}
+	result.Audio, checkpoint.Audio = audio, cloneBytes(audio)
+	checkpoint.Completed = true
+	if err := w.saveCheckpoint(ctx, checkpoint, result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// runStage は段を1つ実行し、記録を result に加えます。
+func runStage[T any](result *RunResult, stage Stage, model string, fn func() (T, stageMeter, error)) (T, error) {
+	start := time.Now()
+	value, meter, err := fn()
+	result.Stages = append(result.Stages, StageStats{
+		Stage:    stage,
+		Model:    cmp.Or(meter.model, model),
+		Duration: time.Since(start),
+		Usage:    meter.usage,
+	})
+	if err != nil {
+		var zero T
+		return zero, &StageError{Stage: stage, RunID: result.RunID, Err: err}
+	}
+	return value, nil
+}
+
+// resumeCheckpoint は、再開できる記録を返します。記録が無い・完了済み・ストア未設定の
+// 場合は空の記録を返します。
+func (w *Workflow) resumeCheckpoint(ctx context.Context, runID string) (*Checkpoint, error) {
+	fresh := &Checkpoint{RunID: runID}
+	if w.checkpoints == nil {
+		return fresh, nil
+	}
+	checkpoint, err := w.checkpoints.Load(ctx, runID)
+	if errors.Is(err, ErrCheckpointNotFound) {
+		return fresh, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lyria: failed to load checkpoint (run %s): %w", runID, err)
+	}
+	if checkpoint == nil || checkpoint.Completed {
+		return fresh, nil
+	}
+	return checkpoint, nil
+}
+
+// saveCheckpoint は、完了した段の記録を checkpoint に写して保存します。
+func (w *Workflow) saveCheckpoint(ctx context.Context, checkpoint *Checkpoint, result *RunResult) error {
+	if w.checkpoints == nil {
+		return nil
+	}
+	checkpoint.Stages = checkpoint.Stages[:0]
+	for _, stage := range result.Stages {
+		stage.Resumed = false
+		stage.Usage = cloneUsage(stage.Usage)
+		checkpoint.Stages = append(checkpoint.Stages, stage)
+	}
+	checkpoint.UpdatedAt = time.Now()
+	if err := w.checkpoints.Save(ctx, checkpoint); err != nil {
+		return fmt.Errorf("lyria: failed to save checkpoint (run %s): %w", checkpoint.RunID, err)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package lyria
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/shouni/go-gemini-client/gemini"
+)
+
+const (
+	runLyricsJSON = `{"title": "Song", "theme": "t", "hook": "h", "lyrics": "la la"}`
+	runRecipeJSON = `{"title": "Song", "tempo": 100, "mood": "calm", "instruments": [], "sections": []}`
+)
+
+// onPipeline は、歌詞・作曲・音声の各段の呼び出しに応答する期待を、それぞれ lyrics・
+// compose・audio 回ずつ登録します。
+func onPipeline(ai *MockGeminiClient, lyrics, compose, audio int) {
+	ai.onGenerate("gemini-flash", promptContaining("lyrics:")).Return(textResponse(runLyricsJSON), nil).Times(lyrics)
+	ai.onGenerate("gemini-flash", promptContaining("recipe:")).Return(textResponse(runRecipeJSON), nil).Times(compose)
+	ai.onGenerate("lyria-3", "full").Return(audioResponse([]byte{1, 2, 3}, ""), nil).Times(audio)
+}
+
+func TestRunExecutesAllStages(t *testing.T) {
+	ai := new(MockGeminiClient)
+	onPipeline(ai, 1, 1, 1)
+	store := NewMemoryCheckpointStore()
+	workflow := newTestWorkflow(t, ai, WithCheckpointStore(store))
+	input := &CollectedContent{Prompt: "rainy day"}
+
+	result, err := workflow.Run(context.Background(), AIModels{}, input)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if result.Lyrics.Title != "Song" || result.Recipe.Tempo != 100 || string(result.Audio) != "\x01\x02\x03" {
+		t.Errorf("成果物 = %+v / %+v / %v", result.Lyrics, result.Recipe, result.Audio)
+	}
+	wantStages := []struct {
+		stage Stage
+		model string
+	}{{StageLyrics, "gemini-flash"}, {StageCompose, "gemini-flash"}, {StageAudio, "lyria-3"}}
+	if len(result.Stages) != len(wantStages) {
+		t.Fatalf("Stages = %+v", result.Stages)
+	}
+	for i, want := range wantStages {
+		got := result.Stages[i]
+		if got.Stage != want.stage || got.Model != want.model || got.Resumed {
+			t.Errorf("Stages[%d] = %+v, want stage %s / model %s", i, got, want.stage, want.model)
+		}
+	}
+	if usage := result.Usage(); usage == nil || usage.TotalTokenCount != 30 {
+		t.Errorf("Usage() = %+v, want TotalTokenCount 30（音声の段は使用量を返さない）", usage)
+	}
+
+	checkpoint, err := store.Load(context.Background(), result.RunID)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !checkpoint.Completed || len(checkpoint.Stages) != 3 || string(checkpoint.Audio) != "\x01\x02\x03" {
+		t.Errorf("checkpoint = %+v", checkpoint)
+	}
+	ai.AssertExpectations(t)
+}
+
+func TestRunResumesFromLastCompletedStage(t *testing.T) {
+	ai := new(MockGeminiClient)
+	ai.onGenerate("lyria-3", "full").Return(nil, errors.New("lyria unavailable")).Once()
+	// 歌詞と作曲は1回目と3回目の Run で、音声は3回とも生成する。
+	onPipeline(ai, 2, 2, 2)
+	store := NewMemoryCheckpointStore()
+	workflow := newTestWorkflow(t, ai, WithCheckpointStore(store))
+	input := &CollectedContent{Prompt: "rainy day"}
+
+	result, err := workflow.Run(context.Background(), AIModels{}, input)
+	stageErr, ok := errors.AsType[*StageError](err)
+	if !ok || stageErr.Stage != StageAudio {
+		t.Fatalf("Run() error = %v, want *StageError（audio）", err)
+	}
+	if result.Recipe == nil || result.Audio != nil {
+		t.Errorf("失敗時の RunResult = %+v, want レシピまでの成果物", result)
+	}
+
+	result, err = workflow.Run(context.Background(), AIModels{}, input)
+	if err != nil {
+		t.Fatalf("再開した Run() error = %v", err)
+	}
+	// 歌詞と作曲は1回ずつ、音声は2回。
+	ai.AssertNumberOfCalls(t, "GenerateWithAttachments", 4)
+	if len(result.Stages) != 3 || !result.Stages[0].Resumed || !result.Stages[1].Resumed || result.Stages[2].Resumed {
+		t.Errorf("Stages = %+v, want 歌詞と作曲だけが Resumed", result.Stages)
+	}
+	if result.Stages[0].Usage == nil {
+		t.Errorf("復元した段の Usage が失われています")
+	}
+
+	// 完了済みの記録からは再開せず、最初から実行し直す。
+	if _, err := workflow.Run(context.Background(), AIModels{}, input); err != nil {
+		t.Fatalf("3回目の Run() error = %v", err)
+	}
+	ai.AssertExpectations(t)
+}
+
+func TestRunKeyDependsOnInput(t *testing.T) {
+	a := RunKey(AIModels{}, &CollectedContent{Prompt: "a"})
+	if a != RunKey(AIModels{}, &CollectedContent{Prompt: "a"}) {
+		t.Error("同じ入力で RunKey が変わりました")
+	}
+	if a == RunKey(AIModels{}, &CollectedContent{Prompt: "b"}) || a == RunKey(AIModels{TextModel: "x"}, &CollectedContent{Prompt: "a"}) {
+		t.Error("異なる入力で RunKey が一致しました")
+	}
+}
+
+func TestFileCheckpointStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewFileCheckpointStore(t.TempDir() + "/checkpoints")
+
+	if _, err := store.Load(ctx, "missing"); !errors.Is(err, ErrCheckpointNotFound) {
+		t.Errorf("Load() err = %v, want ErrCheckpointNotFound", err)
+	}
+
+	want := &Checkpoint{
+		RunID:  "run1",
+		Lyrics: &LyricsDraft{Title: "Song", Lyrics: "la"},
+		Audio:  []byte{1, 2, 3},
+		Stages: []StageStats{{Stage: StageLyrics, Model: "gemini-flash", Usage: &gemini.TokenUsage{TotalTokenCount: 7}}},
+	}
+	if err := store.Save(ctx, want); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, err := store.Load(ctx, "run1")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got.Lyrics.Title != "Song" || string(got.Audio) != "\x01\x02\x03" || got.Stages[0].Usage.TotalTokenCount != 7 {
+		t.Errorf("Load() = %+v", got)
+	}
+}
+
+func TestRunAppliesStageHooks(t *testing.T) {
+	ai := new(MockGeminiClient)
+	// 拒否された作曲の段だけやり直す。
+	onPipeline(ai, 1, 2, 1)
+	store := NewMemoryCheckpointStore()
+	rejectOnce := true
+	workflow := newTestWorkflow(t, ai,
+		WithCheckpointStore(store),
+		WithAfterLyrics(func(_ context.Context, lyrics *LyricsDraft) error {
+			lyrics.Title = "Edited"
+			return nil
+		}),
+		WithAfterCompose(func(_ context.Context, recipe *MusicRecipe) error {
+			if rejectOnce {
+				rejectOnce = false
+				return fmt.Errorf("%w: tempo %d is too slow", ErrRejected, recipe.Tempo)
+			}
+			recipe.Tempo = 128
+			return nil
+		}),
+	)
+	input := &CollectedContent{Prompt: "rainy day"}
+
+	result, err := workflow.Run(context.Background(), AIModels{}, input)
+	stageErr, ok := errors.AsType[*StageError](err)
+	if !ok || stageErr.Stage != StageCompose || !errors.Is(err, ErrRejected) {
+		t.Fatalf("Run() error = %v, want 作曲の段の ErrRejected", err)
+	}
+	if result.Recipe != nil {
+		t.Errorf("拒否したレシピで先へ進みました: recipe = %+v", result.Recipe)
+	}
+	ai.AssertNumberOfCalls(t, "GenerateWithAttachments", 2)
+
+	result, err = workflow.Run(context.Background(), AIModels{}, input)
+	if err != nil {
+		t.Fatalf("再開した Run() error = %v", err)
+	}
+	if result.Lyrics.Title != "Edited" || result.Recipe.Tempo != 128 {
+		t.Errorf("フックの編集が反映されていません: title = %q, tempo = %d", result.Lyrics.Title, result.Recipe.Tempo)
+	}
+	checkpoint, err := store.Load(context.Background(), result.RunID)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if checkpoint.Lyrics.Title != "Edited" || checkpoint.Recipe.Tempo != 128 {
+		t.Errorf("チェックポイントに編集後の成果物が保存されていません: %+v", checkpoint)
+	}
+	ai.AssertExpectations(t)
+}
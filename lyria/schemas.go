@@ -45,6 +45,7 @@ func musicRecipeSchema() *gemini.Schema {
 						"start_seconds":    {Type: gemini.TypeInteger},
 						"end_seconds":      {Type: gemini.TypeInteger},
 						"prompt":           {Type: gemini.TypeString},
+						"lang":             {Type: gemini.TypeString},
 					},
 					Required: []string{"name", "duration_seconds", "start_seconds", "end_seconds", "prompt"},
 				},
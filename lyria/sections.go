@@ -0,0 +1,247 @@
+package lyria
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/shouni/go-gemini-client/internal/wav"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// DefaultSectionConcurrency は、GenerateSections が同時に生成するセクション数の既定値です。
+	DefaultSectionConcurrency = 2
+	// DefaultCrossfade は、セクションの境目に掛けるクロスフェードの長さの既定値です。
+	DefaultCrossfade = 500 * time.Millisecond
+)
+
+// SectionAudio は、セクション1つ分の生成結果です。
+type SectionAudio struct {
+	// Index は MusicRecipe.Sections の添字です。
+	Index int
+	// Section は生成に使ったセクションの指定です。
+	Section MusicSection
+	// Audio は Lyria が返したこのセクションの音声（WAV）です。結合前のものなので、
+	// トラックに収めた長さより長いことがあります。
+	Audio []byte
+	// Start は結合したトラックでこのセクションが始まる位置です。
+	Start time.Duration
+	// Duration は結合したトラックでこのセクションに割り当てた長さです
+	// （クロスフェードで次のセクションに重なる分は含みません）。
+	Duration time.Duration
+}
+
+// SectionedSong は、GenerateSections / RegenerateSection の結果です。
+type SectionedSong struct {
+	// Sections はセクションごとの結果を MusicRecipe.Sections と同じ順に並べたものです。
+	Sections []SectionAudio
+	// Audio はセクションを結合した1本のトラック（WAV）です。
+	Audio []byte
+	// Duration はトラックの長さです。
+	Duration time.Duration
+}
+
+// GenerateSections は、レシピのセクションを1つずつ Lyria で生成し、1本のトラックに
+// 結合します。
+//
+// GenerateAudio は曲全体を1回で生成するため、セクションごとの Prompt や秒数は
+// 曲全体のプロンプトに埋もれます。こちらはセクションごとに生成するので、それぞれの
+// 指示が効き、気に入らないセクションだけを RegenerateSection で作り直せます。
+//
+// 生成は WithSectionConcurrency の数まで並行し、各呼び出しは GenerateAudio と同じ
+// レート制限を待ちます。結合では各セクションを StartSeconds の位置に置いて EndSeconds で
+// 切り（未指定なら直前のセクションの続きに Duration 秒、それも無ければ生成された長さの
+// まま置きます）、境目には WithCrossfade の長さのクロスフェードを掛けます。
+// 結合できるのは 16 bit PCM の WAV だけで、それ以外は ErrUnsupportedAudio を返します。
+func (w *Workflow) GenerateSections(ctx context.Context, recipe *MusicRecipe, images []ImagePayload) (*SectionedSong, error) {
+	if err := w.checkSections(recipe); err != nil {
+		return nil, err
+	}
+
+	clips := make([][]byte, len(recipe.Sections))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(w.sectionConcurrency)
+	for i := range recipe.Sections {
+		g.Go(func() error {
+			clip, err := w.renderer.GenerateSection(gctx, recipe, i, images)
+			if err != nil {
+				return fmt.Errorf("section %d (%s): %w", i, recipe.Sections[i].Name, err)
+			}
+			clips[i] = clip.Data
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return stitchSections(recipe.Sections, clips, w.crossfade)
+}
+
+// RegenerateSection は、song の index 番目のセクションだけを生成し直して結合し直します。
+//
+// 他のセクションは song の音声をそのまま使うので、Lyria を呼ぶのは1回だけです。
+// recipe には song を作ったときと同じセクション数のレシピを渡してください。
+// index 番目の Prompt や秒数を書き換えて渡せば、その指定で作り直せます
+// （Seed を固定している場合、同じ指定のままでは同じ音声が返ることがあります）。
+// song は変更せず、新しい SectionedSong を返します。
+func (w *Workflow) RegenerateSection(ctx context.Context, recipe *MusicRecipe, song *SectionedSong, index int, images []ImagePayload) (*SectionedSong, error) {
+	if err := w.checkSections(recipe); err != nil {
+		return nil, err
+	}
+	if song == nil {
+		return nil, fmt.Errorf("%w: sectioned song", ErrNilInput)
+	}
+	if len(song.Sections) != len(recipe.Sections) {
+		return nil, fmt.Errorf("%w: song has %d sections but recipe has %d", ErrNoSections, len(song.Sections), len(recipe.Sections))
+	}
+	if index < 0 || index >= len(recipe.Sections) {
+		return nil, fmt.Errorf("%w: section index %d (recipe has %d sections)", ErrNoSections, index, len(recipe.Sections))
+	}
+
+	clip, err := w.renderer.GenerateSection(ctx, recipe, index, images)
+	if err != nil {
+		return nil, fmt.Errorf("section %d (%s): %w", index, recipe.Sections[index].Name, err)
+	}
+	clips := make([][]byte, len(song.Sections))
+	for i, section := range song.Sections {
+		clips[i] = section.Audio
+	}
+	clips[index] = clip.Data
+	return stitchSections(recipe.Sections, clips, w.crossfade)
+}
+
+// checkSections は、セクション単位の生成を始められるかを確認します。
+func (w *Workflow) checkSections(recipe *MusicRecipe) error {
+	if w.renderer == nil {
+		return fmt.Errorf("%w: section generation requires a Workflow built by New", ErrWorkflowConfig)
+	}
+	if recipe == nil {
+		return fmt.Errorf("%w: music recipe", ErrNilInput)
+	}
+	if len(recipe.Sections) == 0 {
+		return ErrNoSections
+	}
+	return nil
+}
+
+// sectionSeconds は、セクションに指定された秒数を返します。指定が無ければ 0 です。
+func sectionSeconds(section MusicSection) int {
+	if section.EndSeconds > section.StartSeconds {
+		return section.EndSeconds - section.StartSeconds
+	}
+	return max(section.Duration, 0)
+}
+
+// placedClip は、トラック上に置く1セクション分の PCM です。
+type placedClip struct {
+	pcm        []byte
+	startFrame int
+	spanFrames int
+}
+
+// stitchSections は、セクションごとの WAV をトラック上の位置に置いて1本に結合します。
+// 各クリップは NewAudioClip で形式を確かめ、WAV 以外は ErrUnsupportedAudio です。
+//
+// 各セクションはトラック上の区間 [start, start+span) を受け持ちます。次のセクションが
+// あり、生成された音声が区間より長ければ、区間の終わりから crossfade の分だけ鳴らし続けて
+// フェードアウトさせ、次のセクションは頭の crossfade の分でフェードインさせます。
+// 音声が区間より短ければ、残りは無音になります。
+func stitchSections(sections []MusicSection, clips [][]byte, crossfade time.Duration) (*SectionedSong, error) {
+	var format wav.Format
+	decoded := make([][]byte, len(clips))
+	for i, data := range clips {
+		clip, err := NewAudioClip(data, "")
+		if err != nil {
+			return nil, fmt.Errorf("section %d: %w", i, err)
+		}
+		if !clip.IsWAV() {
+			return nil, fmt.Errorf("%w: section %d is %s (only WAV can be stitched)", ErrUnsupportedAudio, i, clip.MIMEType)
+		}
+		f, pcm, err := wav.Decode(clip.Data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: section %d: %w", ErrUnsupportedAudio, i, err)
+		}
+		if !f.Valid() || f.BitsPerSample != 16 {
+			return nil, fmt.Errorf("%w: section %d has format %+v (only 16-bit PCM is supported)", ErrUnsupportedAudio, i, f)
+		}
+		if i == 0 {
+			format = f
+		} else if f != format {
+			return nil, fmt.Errorf("%w: section %d format %+v differs from section 0 format %+v", ErrUnsupportedAudio, i, f, format)
+		}
+		decoded[i] = pcm
+	}
+
+	frameSize := format.Channels * 2
+	framesOf := func(d time.Duration) int { return format.Offset(d) / frameSize }
+	fadeFrames := framesOf(crossfade)
+
+	placed := make([]placedClip, len(decoded))
+	song := &SectionedSong{Sections: make([]SectionAudio, len(decoded))}
+	cursor, totalFrames := 0, 0
+	for i, pcm := range decoded {
+		section := sections[i]
+		clipFrames := len(pcm) / frameSize
+
+		start := cursor
+		if section.StartSeconds > 0 || section.EndSeconds > 0 {
+			start = framesOf(time.Duration(section.StartSeconds) * time.Second)
+		}
+		span := clipFrames
+		if seconds := sectionSeconds(section); seconds > 0 {
+			span = framesOf(time.Duration(seconds) * time.Second)
+		}
+		placed[i] = placedClip{pcm: pcm, startFrame: start, spanFrames: span}
+		cursor = start + span
+		totalFrames = max(totalFrames, cursor)
+
+		song.Sections[i] = SectionAudio{
+			Index:    i,
+			Section:  section,
+			Audio:    clips[i],
+			Start:    format.Duration(start * frameSize),
+			Duration: format.Duration(span * frameSize),
+		}
+	}
+
+	mix := make([]int32, totalFrames*format.Channels)
+	for i, clip := range placed {
+		last := i == len(placed)-1
+		clipFrames := len(clip.pcm) / frameSize
+		keep := min(clipFrames, clip.spanFrames)
+		if !last {
+			keep = min(clipFrames, clip.spanFrames+fadeFrames)
+		}
+		keep = min(keep, totalFrames-clip.startFrame)
+		tail := keep - clip.spanFrames // 次のセクションへ重ねる分
+
+		for f := range keep {
+			gain := 1.0
+			if i > 0 && f < fadeFrames {
+				gain = float64(f) / float64(fadeFrames)
+			}
+			if tail > 0 && f >= clip.spanFrames {
+				gain *= float64(keep-f) / float64(tail+1)
+			}
+			for ch := range format.Channels {
+				offset := (f*format.Channels + ch) * 2
+				sample := int16(binary.LittleEndian.Uint16(clip.pcm[offset:]))
+				mix[(clip.startFrame+f)*format.Channels+ch] += int32(float64(sample) * gain)
+			}
+		}
+	}
+
+	out := make([]byte, len(mix)*2)
+	for i, sample := range mix {
+		binary.LittleEndian.PutUint16(out[i*2:], uint16(int16(min(max(sample, -32768), 32767))))
+	}
+	audio, err := wav.Encode(out, format)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnsupportedAudio, err)
+	}
+	song.Audio = audio
+	song.Duration = format.Duration(len(out))
+	return song, nil
+}
@@ -0,0 +1,273 @@
+package lyria
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shouni/go-gemini-client/internal/wav"
+	"github.com/stretchr/testify/mock"
+)
+
+// testSectionFormat は、テストで扱う音声の形式です。1 秒 = 1000 フレームで位置を数えやすくしています。
+var testSectionFormat = wav.Format{SampleRate: 1000, Channels: 1, BitsPerSample: 16}
+
+// constantWAV は、全サンプルが level の length 分の WAV を作ります。
+func constantWAV(t *testing.T, level int16, length time.Duration) []byte {
+	t.Helper()
+	pcm := make([]byte, testSectionFormat.Offset(length))
+	for i := 0; i < len(pcm); i += 2 {
+		binary.LittleEndian.PutUint16(pcm[i:], uint16(level))
+	}
+	data, err := wav.Encode(pcm, testSectionFormat)
+	if err != nil {
+		t.Fatalf("wav.Encode() error = %v", err)
+	}
+	return data
+}
+
+// sampleAt は、WAV の位置 at のサンプルを返します。
+func sampleAt(t *testing.T, data []byte, at time.Duration) int16 {
+	t.Helper()
+	format, pcm, err := wav.Decode(data)
+	if err != nil {
+		t.Fatalf("wav.Decode() error = %v", err)
+	}
+	offset := format.Offset(at)
+	return int16(binary.LittleEndian.Uint16(pcm[offset:]))
+}
+
+// sectionAudioMock は、プロンプトに levels の語を含む Lyria の呼び出しに、その音量の
+// length 分の WAV を返す MockGeminiClient です。呼び出しは log に記録します。
+func sectionAudioMock(t *testing.T, log *callLog, levels map[string]int16, length time.Duration) *MockGeminiClient {
+	t.Helper()
+	ai := new(MockGeminiClient)
+	for word, level := range levels {
+		ai.onGenerate("lyria-3", promptContaining(word)).Return(audioResponse(constantWAV(t, level, length), ""), nil).Run(log.record)
+	}
+	return ai
+}
+
+func threeSectionRecipe() *MusicRecipe {
+	return &MusicRecipe{
+		Title: "Song",
+		Mood:  "bright",
+		Tempo: 120,
+		Sections: []MusicSection{
+			{Name: "Intro", StartSeconds: 0, EndSeconds: 1, Prompt: "soft piano"},
+			{Name: "Verse", StartSeconds: 1, EndSeconds: 2, Prompt: "driving drums"},
+			{Name: "Outro", StartSeconds: 2, EndSeconds: 3, Prompt: "fading strings"},
+		},
+	}
+}
+
+func TestGenerateSectionsStitchesWithCrossfade(t *testing.T) {
+	log := &callLog{}
+	ai := sectionAudioMock(t, log, map[string]int16{"soft piano": 1000, "driving drums": 2000, "fading strings": 3000}, 1500*time.Millisecond)
+	workflow := newTestWorkflow(t, ai, WithCrossfade(100*time.Millisecond), WithSectionConcurrency(3))
+
+	song, err := workflow.GenerateSections(context.Background(), threeSectionRecipe(), nil)
+	if err != nil {
+		t.Fatalf("GenerateSections() error = %v", err)
+	}
+
+	if song.Duration != 3*time.Second {
+		t.Errorf("Duration = %v, want 3s（EndSeconds で切り、余りは捨てる）", song.Duration)
+	}
+	prompts := log.prompts()
+	if len(prompts) != 3 {
+		t.Fatalf("Lyria の呼び出し = %d, want 3", len(prompts))
+	}
+	for _, prompt := range prompts {
+		if !strings.Contains(prompt, "Mood: bright") || !strings.Contains(prompt, "Tempo: 120 BPM") {
+			t.Errorf("セクションのプロンプトに曲全体の指定がありません: %q", prompt)
+		}
+	}
+
+	for _, tc := range []struct {
+		at   time.Duration
+		want int16
+	}{
+		{500 * time.Millisecond, 1000},
+		{1500 * time.Millisecond, 2000},
+		{2500 * time.Millisecond, 3000},
+	} {
+		if got := sampleAt(t, song.Audio, tc.at); got != tc.want {
+			t.Errorf("%v のサンプル = %d, want %d", tc.at, got, tc.want)
+		}
+	}
+	// 境目の直後は前のセクションのフェードアウトと次のセクションのフェードインが重なる。
+	if got := sampleAt(t, song.Audio, 1050*time.Millisecond); got <= 1000 || got >= 2000 {
+		t.Errorf("クロスフェード中のサンプル = %d, want 1000 と 2000 の間", got)
+	}
+
+	for i, section := range song.Sections {
+		if section.Index != i || section.Start != time.Duration(i)*time.Second || section.Duration != time.Second {
+			t.Errorf("Sections[%d] = {Index: %d, Start: %v, Duration: %v}", i, section.Index, section.Start, section.Duration)
+		}
+	}
+}
+
+func TestGenerateSectionsPlacesSectionsWithoutTimings(t *testing.T) {
+	ai := sectionAudioMock(t, &callLog{}, map[string]int16{"one": 1000, "two": 2000}, 800*time.Millisecond)
+	workflow := newTestWorkflow(t, ai, WithCrossfade(-1))
+
+	recipe := &MusicRecipe{Sections: []MusicSection{
+		{Name: "A", Duration: 1, Prompt: "one"},
+		{Name: "B", Prompt: "two"},
+	}}
+	song, err := workflow.GenerateSections(context.Background(), recipe, nil)
+	if err != nil {
+		t.Fatalf("GenerateSections() error = %v", err)
+	}
+
+	// A は 1 秒の区間に 0.8 秒の音声なので残りは無音、B は続きに生成された長さのまま置く。
+	if song.Duration != 1800*time.Millisecond {
+		t.Errorf("Duration = %v, want 1.8s", song.Duration)
+	}
+	if got := sampleAt(t, song.Audio, 900*time.Millisecond); got != 0 {
+		t.Errorf("区間の余りのサンプル = %d, want 0", got)
+	}
+	if got := sampleAt(t, song.Audio, time.Second); got != 2000 {
+		t.Errorf("クロスフェード無効時の次のセクションの頭 = %d, want 2000", got)
+	}
+}
+
+func TestGenerateSectionsBoundsConcurrency(t *testing.T) {
+	log := &callLog{}
+	ai := new(MockGeminiClient)
+	ai.onGenerate("lyria-3", mock.Anything).Return(audioResponse(constantWAV(t, 100, time.Second), ""), nil).Run(log.record)
+	workflow := newTestWorkflow(t, ai, WithSectionConcurrency(2))
+
+	recipe := &MusicRecipe{}
+	for i := range 6 {
+		// 名前を変えてプロンプトを別にし、singleflight でまとめられないようにする。
+		recipe.Sections = append(recipe.Sections, MusicSection{Name: string(rune('A' + i)), Duration: 1})
+	}
+	if _, err := workflow.GenerateSections(context.Background(), recipe, nil); err != nil {
+		t.Fatalf("GenerateSections() error = %v", err)
+	}
+	if log.peak > 2 {
+		t.Errorf("同時に生成したセクション数 = %d, want <= 2", log.peak)
+	}
+}
+
+type sectionPromptBuilder struct{}
+
+func (sectionPromptBuilder) BuildFullSong(*MusicRecipe) string { return "full" }
+
+func (sectionPromptBuilder) BuildSection(recipe *MusicRecipe, index int) string {
+	return "custom " + recipe.Sections[index].Name
+}
+
+func TestGenerateSectionsUsesSectionPromptBuilder(t *testing.T) {
+	log := &callLog{}
+	ai := sectionAudioMock(t, log, map[string]int16{"custom Intro": 1, "custom Verse": 2, "custom Outro": 3}, time.Second)
+	workflow, err := New(ai, stubPromptGen{}, sectionPromptBuilder{},
+		WithGeminiModel("gemini-flash"), WithLyriaModel("lyria-3"), WithSectionConcurrency(1))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if _, err := workflow.GenerateSections(context.Background(), threeSectionRecipe(), nil); err != nil {
+		t.Fatalf("GenerateSections() error = %v", err)
+	}
+	if want := []string{"custom Intro", "custom Verse", "custom Outro"}; strings.Join(log.prompts(), ",") != strings.Join(want, ",") {
+		t.Errorf("prompts = %q, want %q", log.prompts(), want)
+	}
+}
+
+func TestRegenerateSectionRendersOnlyThatSection(t *testing.T) {
+	log := &callLog{}
+	ai := sectionAudioMock(t, log, map[string]int16{"soft piano": 1000, "driving drums": 2000, "fading strings": 3000, "quiet bass": 500}, time.Second)
+	workflow := newTestWorkflow(t, ai, WithCrossfade(-1))
+
+	recipe := threeSectionRecipe()
+	song, err := workflow.GenerateSections(context.Background(), recipe, nil)
+	if err != nil {
+		t.Fatalf("GenerateSections() error = %v", err)
+	}
+	log.reset()
+
+	revised := recipe.Clone()
+	revised.Sections[1].Prompt = "quiet bass"
+	regenerated, err := workflow.RegenerateSection(context.Background(), revised, song, 1, nil)
+	if err != nil {
+		t.Fatalf("RegenerateSection() error = %v", err)
+	}
+
+	if prompts := log.prompts(); len(prompts) != 1 {
+		t.Errorf("Lyria の呼び出し = %d, want 1", len(prompts))
+	}
+	if got := sampleAt(t, regenerated.Audio, 1500*time.Millisecond); got != 500 {
+		t.Errorf("作り直したセクションのサンプル = %d, want 500", got)
+	}
+	if got := sampleAt(t, regenerated.Audio, 2500*time.Millisecond); got != 3000 {
+		t.Errorf("他のセクションのサンプル = %d, want 3000", got)
+	}
+	if got := sampleAt(t, song.Audio, 1500*time.Millisecond); got != 2000 {
+		t.Errorf("元の song が変更されています: サンプル = %d, want 2000", got)
+	}
+}
+
+func TestGenerateSectionsErrors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("セクションが無い", func(t *testing.T) {
+		workflow := newTestWorkflow(t, new(MockGeminiClient))
+		if _, err := workflow.GenerateSections(ctx, &MusicRecipe{}, nil); !errors.Is(err, ErrNoSections) {
+			t.Errorf("err = %v, want ErrNoSections", err)
+		}
+	})
+
+	t.Run("WAV ではない音声", func(t *testing.T) {
+		ai := new(MockGeminiClient)
+		ai.onGenerate("lyria-3", mock.Anything).Return(audioResponse([]byte("ID3 mp3"), ""), nil)
+		workflow := newTestWorkflow(t, ai)
+		_, err := workflow.GenerateSections(ctx, threeSectionRecipe(), nil)
+		if !errors.Is(err, ErrUnsupportedAudio) {
+			t.Errorf("err = %v, want ErrUnsupportedAudio", err)
+		}
+	})
+
+	t.Run("範囲外のセクションを作り直す", func(t *testing.T) {
+		workflow := newTestWorkflow(t, new(MockGeminiClient))
+		song := &SectionedSong{Sections: make([]SectionAudio, 3)}
+		if _, err := workflow.RegenerateSection(ctx, threeSectionRecipe(), song, 3, nil); !errors.Is(err, ErrNoSections) {
+			t.Errorf("err = %v, want ErrNoSections", err)
+		}
+	})
+
+	t.Run("構造体リテラルの Workflow", func(t *testing.T) {
+		workflow := &Workflow{}
+		if _, err := workflow.GenerateSections(ctx, threeSectionRecipe(), nil); !errors.Is(err, ErrWorkflowConfig) {
+			t.Errorf("err = %v, want ErrWorkflowConfig", err)
+		}
+	})
+}
+
+func TestGenerateSectionsWrapsRawPCM(t *testing.T) {
+	ai := new(MockGeminiClient)
+	for word, level := range map[string]int16{"soft piano": 1000, "driving drums": 2000, "fading strings": 3000} {
+		_, pcm, err := wav.Decode(constantWAV(t, level, time.Second))
+		if err != nil {
+			t.Fatalf("wav.Decode() error = %v", err)
+		}
+		ai.onGenerate("lyria-3", promptContaining(word)).Return(audioResponse(pcm, "audio/L16;rate=1000"), nil)
+	}
+	workflow := newTestWorkflow(t, ai, WithCrossfade(-1))
+
+	song, err := workflow.GenerateSections(context.Background(), threeSectionRecipe(), nil)
+	if err != nil {
+		t.Fatalf("GenerateSections() error = %v", err)
+	}
+	if song.Duration != 3*time.Second || !isWAV(song.Sections[1].Audio) {
+		t.Errorf("Duration = %v, want 3s and WAV sections", song.Duration)
+	}
+	if got := sampleAt(t, song.Audio, 1500*time.Millisecond); got != 2000 {
+		t.Errorf("1.5s のサンプル = %d, want 2000", got)
+	}
+}
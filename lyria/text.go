@@ -1,6 +1,7 @@
 package lyria
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -18,10 +19,19 @@ const defaultComposeMode = "default"
 type lyriaTextGenerator struct {
 	aiClient     gemini.Generator
 	promptGen    TextPromptGenerator
+	languages    map[string]LanguageSupport // WithLanguage で登録した言語ごとの部品
 	defaultModel string
 	limiter      *rate.Limiter // nil はレート制限なし（テストが構造体リテラルで直接構築するため）
 	execTimeout  time.Duration
 	group        singleflight.Group
+
+	// recipeRepairs は、Compose のレシピが Validate を通らない場合に直させる回数の上限です。
+	// 0 は直させず、Normalize だけを掛けて返します。
+	recipeRepairs int
+
+	// maxInlineBytes は、順位付けでテイクをインラインで送る合計の上限です。
+	// 0 は maxRankingInlineBytes です。
+	maxInlineBytes int
 }
 
 // resolveModel は呼び出しごとのモデル指定があればそれを、なければデフォルトモデルを返します。
@@ -32,85 +42,145 @@ func (g *lyriaTextGenerator) resolveModel(override string) string {
 	return g.defaultModel
 }
 
+// generated は、singleflight で共有する生成結果と、その呼び出しのトークン使用量です。
+type generated[T any] struct {
+	value *T
+	usage *gemini.TokenUsage
+}
+
 // generateJSON は歌詞・レシピ生成で共通の「singleflight → Gemini 呼び出し → JSON デコード」
 // フローを実行します。kind はエラーメッセージと singleflight キーの識別子です。
 // 戻り値は singleflight で共有されるため、呼び出し側で複製してから返してください。
-func generateJSON[T any](ctx context.Context, g *lyriaTextGenerator, kind, model, prompt string, seed *int64, schema *gemini.Schema) (*T, error) {
+func generateJSON[T any](ctx context.Context, g *lyriaTextGenerator, kind, model, prompt string, seed *int64, schema *gemini.Schema) (*T, *gemini.TokenUsage, error) {
 	// seed は生成結果を変えるため、必ずキーに含める。含め忘れると同一プロンプトで
 	// seed 違いの同時呼び出しが 1 回の生成結果を共有してしまう。
 	key := singleflightKey(kind, model, prompt, singleflightSeedKey(seed))
-	return doSingleflight(ctx, &g.group, key, g.execTimeout, func(execCtx context.Context) (*T, error) {
+	result, err := doSingleflight(ctx, &g.group, key, g.execTimeout, func(execCtx context.Context) (generated[T], error) {
+		var zero generated[T]
 		if g.limiter != nil {
 			if err := g.limiter.Wait(execCtx); err != nil {
-				return nil, err
+				return zero, err
 			}
 		}
 
 		resp, err := g.aiClient.GenerateWithAttachments(execCtx, model, prompt, nil, buildJSONGenerateOptions(seed, schema))
 		if err != nil {
-			return nil, fmt.Errorf("%s generation failed (model: %s): %w", kind, model, err)
+			return zero, fmt.Errorf("%s generation failed (model: %s): %w", kind, model, err)
 		}
 		if resp == nil {
-			return nil, fmt.Errorf("%w: %s response is nil", ErrInvalidResponse, kind)
+			return zero, fmt.Errorf("%w: %s response is nil", ErrInvalidResponse, kind)
 		}
 
 		raw := strings.TrimSpace(resp.Text)
 		if raw == "" {
-			return nil, fmt.Errorf("%w: AI returned an empty string for the %s", ErrInvalidResponse, kind)
+			return zero, fmt.Errorf("%w: AI returned an empty string for the %s", ErrInvalidResponse, kind)
 		}
 
 		jsonStr := gemini.CleanJSONResponse(raw)
 		var out T
 		if err := json.Unmarshal([]byte(jsonStr), &out); err != nil {
 			// 生出力の全文はログを肥大化させるため、診断に足りる先頭だけを残す。
-			return nil, fmt.Errorf("%w: failed to unmarshal %s json: %w (raw: %s)",
+			return zero, fmt.Errorf("%w: failed to unmarshal %s json: %w (raw: %s)",
 				ErrInvalidResponse, kind, err, truncateForError(jsonStr))
 		}
-		return &out, nil
+		return generated[T]{value: &out, usage: resp.Usage}, nil
 	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return result.value, cloneUsage(result.usage), nil
+}
+
+// cloneUsage は共有されたトークン使用量を複製します。
+func cloneUsage(usage *gemini.TokenUsage) *gemini.TokenUsage {
+	if usage == nil {
+		return nil
+	}
+	dst := *usage
+	return &dst
 }
 
-// GenerateLyrics は収集済みコンテンツから歌詞ドラフトを生成します。
+// GenerateLyrics は収集済みコンテンツから歌詞ドラフトを生成します。AIModels.Lang が無ければ
+// 素材の文（CollectedContent.Prompt）から言語を推し、生成した歌詞の Lang に残します。
 func (g *lyriaTextGenerator) GenerateLyrics(ctx context.Context, ai AIModels, input *CollectedContent) (*LyricsDraft, error) {
+	lyrics, _, err := g.generateLyricsMetered(ctx, ai, input)
+	return lyrics, err
+}
+
+// generateLyricsMetered は GenerateLyrics と同じ処理で、使ったモデルとトークン使用量も返します。
+func (g *lyriaTextGenerator) generateLyricsMetered(ctx context.Context, ai AIModels, input *CollectedContent) (*LyricsDraft, stageMeter, error) {
+	model := g.resolveModel(ai.TextModel)
+	meter := stageMeter{model: model}
 	if input == nil {
-		return nil, fmt.Errorf("%w: collected content", ErrNilInput)
+		return nil, meter, fmt.Errorf("%w: collected content", ErrNilInput)
 	}
 
-	promptText, err := g.promptGen.GenerateLyrics(ai.LyricsMode, input.Prompt)
+	lang := resolveLyricsLang(ai.Lang, input.Prompt)
+	promptGen := g.textPrompts(lang)
+	var promptText string
+	var err error
+	if localized, ok := promptGen.(LocalizedPromptGenerator); ok {
+		promptText, err = localized.GenerateLyricsIn(lang, ai.LyricsMode, input.Prompt)
+	} else {
+		promptText, err = promptGen.GenerateLyrics(ai.LyricsMode, input.Prompt)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to build lyrics prompt: %w", err)
+		return nil, meter, fmt.Errorf("failed to build lyrics prompt: %w", err)
 	}
 
-	lyrics, err := generateJSON[LyricsDraft](ctx, g, "lyrics", g.resolveModel(ai.TextModel), promptText, ai.Seed, lyricsDraftSchema())
+	lyrics, usage, err := generateJSON[LyricsDraft](ctx, g, "lyrics", model, promptText, ai.Seed, lyricsDraftSchema())
+	meter.usage = usage
 	if err != nil {
-		return nil, err
+		return nil, meter, err
 	}
 	if strings.TrimSpace(lyrics.Lyrics) == "" {
-		return nil, ErrEmptyLyrics
+		return nil, meter, ErrEmptyLyrics
 	}
 
-	return lyrics.Clone(), nil
+	draft := lyrics.Clone()
+	draft.Lang = lang
+	return draft, meter, nil
 }
 
-// Compose は歌詞ドラフトから楽曲レシピを生成します。
+// Compose は歌詞ドラフトから楽曲レシピを生成します。AIModels.Lang が無ければ歌詞の Lang を
+// レシピの言語にします。生成したレシピには music.Recipe.Normalize を掛け、
+// レシピと違う言語で書かれたまとまりを歌うセクションに Lang を入れます
+// （music.Recipe.InferSectionLangs）。
 func (g *lyriaTextGenerator) Compose(ctx context.Context, ai AIModels, lyrics *LyricsDraft) (*MusicRecipe, error) {
+	recipe, _, err := g.composeMetered(ctx, ai, lyrics)
+	return recipe, err
+}
+
+// composeMetered は Compose と同じ処理で、使ったモデルとトークン使用量も返します。
+func (g *lyriaTextGenerator) composeMetered(ctx context.Context, ai AIModels, lyrics *LyricsDraft) (*MusicRecipe, stageMeter, error) {
+	model := g.resolveModel(ai.TextModel)
+	meter := stageMeter{model: model}
 	if lyrics == nil {
-		return nil, fmt.Errorf("%w: lyrics draft", ErrNilInput)
+		return nil, meter, fmt.Errorf("%w: lyrics draft", ErrNilInput)
 	}
 
 	targetMode := ai.ComposeMode
 	if targetMode == "" {
 		targetMode = defaultComposeMode
 	}
-
-	promptText, err := g.promptGen.GenerateRecipe(targetMode, lyrics)
+	ai.Lang = cmp.Or(ai.Lang, lyrics.Lang)
+
+	promptGen := g.textPrompts(ai.Lang)
+	var promptText string
+	var err error
+	if localized, ok := promptGen.(LocalizedPromptGenerator); ok {
+		promptText, err = localized.GenerateRecipeIn(ai.Lang, targetMode, lyrics)
+	} else {
+		promptText, err = promptGen.GenerateRecipe(targetMode, lyrics)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to build prompt (mode: %s): %w", targetMode, err)
+		return nil, meter, fmt.Errorf("failed to build prompt (mode: %s): %w", targetMode, err)
 	}
 
-	shared, err := generateJSON[MusicRecipe](ctx, g, "compose", g.resolveModel(ai.TextModel), promptText, ai.Seed, musicRecipeSchema())
+	shared, usage, err := generateJSON[MusicRecipe](ctx, g, "compose", model, promptText, ai.Seed, musicRecipeSchema())
+	meter.usage = usage
 	if err != nil {
-		return nil, err
+		return nil, meter, err
 	}
 
 	// 呼び出し元固有の情報は共有結果を複製してから付与する。
@@ -121,5 +191,46 @@ func (g *lyriaTextGenerator) Compose(ctx context.Context, ai AIModels, lyrics *L
 		seed := *ai.Seed
 		recipe.Seed = &seed
 	}
-	return recipe, nil
+	recipe.Normalize()
+	recipe.InferSectionLangs()
+
+	// Normalize で直せない問題が残っていれば、問題を伝えて直させる。
+	for attempt := 0; g.recipeRepairs > 0; attempt++ {
+		invalid := recipe.Validate()
+		if invalid == nil {
+			break
+		}
+		if attempt == g.recipeRepairs {
+			return nil, meter, fmt.Errorf("composed recipe is still invalid after %d repair attempts: %w", attempt, invalid)
+		}
+		repaired, usage, err := g.reviseRecipeMetered(ctx, ai, recipe, recipeRepairInstruction(invalid))
+		meter.usage = addUsage(meter.usage, usage)
+		if err != nil {
+			return nil, meter, fmt.Errorf("failed to repair composed recipe: %w", err)
+		}
+		recipe = repaired
+	}
+	return recipe, meter, nil
+}
+
+// recipeRepairInstruction は、Validate の問題を直させる指示を組み立てます。
+func recipeRepairInstruction(invalid error) string {
+	return "The recipe fails validation. Fix every problem listed below " +
+		"(sections must run back to back from 0 seconds, with end_seconds = start_seconds + duration_seconds):\n" +
+		invalid.Error()
+}
+
+// addUsage は usage を total に足し込んだ結果を返します。どちらも nil なら nil です。
+func addUsage(total, usage *gemini.TokenUsage) *gemini.TokenUsage {
+	if usage == nil {
+		return total
+	}
+	if total == nil {
+		total = &gemini.TokenUsage{}
+	}
+	total.PromptTokenCount += usage.PromptTokenCount
+	total.CandidatesTokenCount += usage.CandidatesTokenCount
+	total.TotalTokenCount += usage.TotalTokenCount
+	total.ThoughtsTokenCount += usage.ThoughtsTokenCount
+	return total
 }
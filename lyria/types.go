@@ -22,7 +22,8 @@ type (
 	MusicSection = music.Section
 )
 
-// LangJapanese と LangEnglish は MusicRecipe.Lang に指定できる言語コードです。
+// LangJapanese と LangEnglish は、既定のプロンプトを同梱している言語のタグです。
+// MusicRecipe.Lang にはこれ以外の BCP 47 の言語タグも指定できます。
 const (
 	LangJapanese = music.LangJapanese
 	LangEnglish  = music.LangEnglish
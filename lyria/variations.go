@@ -0,0 +1,287 @@
+package lyria
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shouni/go-gemini-client/gemini"
+	"golang.org/x/sync/errgroup"
+)
+
+// Take は Variations が生成した1本の音声です。
+type Take struct {
+	// Index は生成順の番号（0 始まり）です。順位付けで並べ替えても変わりません。
+	Index int
+	// Seed はこのテイクの生成に使ったシードです。MusicRecipe.Seed にこの値を入れて
+	// GenerateAudio を呼べば、同じテイクを作り直せます。
+	Seed int64
+	// Audio は Lyria が返した音声のバイト列です。
+	Audio []byte
+	// MIMEType は Audio の MIME type です。Lyria が返さなかった場合は空です。
+	MIMEType string
+	// Rank は順位（1 が最良）です。順位付けしていない場合は 0 です。
+	Rank int
+	// Score は順位付けのモデルが付けた点数（0〜10）です。
+	Score float64
+	// Reason は点数の理由です。
+	Reason string
+}
+
+// maxRankingInlineBytes は、順位付けでテイクをインラインで送る合計の上限です。
+// Gemini の1回のリクエストは 20MB までのため、プロンプトの分を残しておきます。
+const maxRankingInlineBytes = 14 << 20
+
+// stagedCleanupTimeout は、順位付けのためにアップロードしたテイクを削除する処理の上限時間です。
+// 呼び出し側の context が既にキャンセルされていても削除は試みるため、独立した期限を持たせます。
+const stagedCleanupTimeout = 15 * time.Second
+
+// takeRanker はテイクをレシピに照らして採点します。lyriaTextGenerator が満たします。
+type takeRanker interface {
+	rankTakes(ctx context.Context, model string, recipe *MusicRecipe, takes []Take) ([]takeScore, error)
+}
+
+// takeScore は、順位付けのモデルが返す1テイク分の採点です。
+type takeScore struct {
+	Take   int     `json:"take"`
+	Score  float64 `json:"score"`
+	Reason string  `json:"reason"`
+}
+
+// takeRankingSchema は順位付けの構造化出力スキーマです。
+func takeRankingSchema() *gemini.Schema {
+	return &gemini.Schema{
+		Type: gemini.TypeObject,
+		Properties: map[string]*gemini.Schema{
+			"takes": {
+				Type: gemini.TypeArray,
+				Items: &gemini.Schema{
+					Type: gemini.TypeObject,
+					Properties: map[string]*gemini.Schema{
+						"take":   {Type: gemini.TypeInteger},
+						"score":  {Type: gemini.TypeNumber},
+						"reason": {Type: gemini.TypeString},
+					},
+					Required: []string{"take", "score", "reason"},
+				},
+			},
+		},
+		Required: []string{"takes"},
+	}
+}
+
+// Variations renders n audio takes of recipe, each with a distinct seed derived from
+// recipe.Seed (or from a random base when it is nil), and returns them with their seeds.
+// images are passed to every take, as in GenerateAudio. Takes are rendered concurrently
+// up to WithSectionConcurrency and every call waits on the audio rate limiter. If
+// WithTakeRanking is set, the takes are then scored against the recipe and returned
+// best first.
+//
+// Take i uses the base seed plus i, wrapped into the int32 range. Take 0 therefore uses
+// recipe.Seed itself, so a recipe with a fixed seed gets the same audio as GenerateAudio.
+// Because the seeds differ, takes are never merged by singleflight. n must be at least 1;
+// otherwise Variations returns ErrNilInput without rendering anything.
+//
+// If ranking fails, Variations returns the takes in generation order (Rank 0) together
+// with the error, so the rendered audio is not lost.
+func (w *Workflow) Variations(ctx context.Context, recipe *MusicRecipe, n int, images []ImagePayload) ([]Take, error) {
+	if w.renderer == nil {
+		return nil, fmt.Errorf("%w: Variations requires a Workflow built by New", ErrWorkflowConfig)
+	}
+	if recipe == nil {
+		return nil, fmt.Errorf("%w: music recipe", ErrNilInput)
+	}
+	if n < 1 {
+		return nil, fmt.Errorf("%w: at least one take is required (n: %d)", ErrNilInput, n)
+	}
+
+	var base int64
+	if recipe.Seed != nil {
+		base = *recipe.Seed
+		if base > math.MaxInt32 || base < math.MinInt32 {
+			return nil, fmt.Errorf("%w (seed: %d)", gemini.ErrInvalidSeed, base)
+		}
+	} else {
+		// 基準値を乱数で決めても、テイクごとのシードは返すので再現できる。
+		base = int64(rand.Int32())
+	}
+
+	takes := make([]Take, n)
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(w.sectionConcurrency)
+	for i := range takes {
+		seed := takeSeed(base, i)
+		g.Go(func() error {
+			variant := recipe.Clone()
+			variant.Seed = &seed
+			audio, _, err := w.renderer.renderFullSong(gctx, variant, images)
+			if err != nil {
+				return fmt.Errorf("take %d (seed %d): %w", i, seed, err)
+			}
+			takes[i] = Take{Index: i, Seed: seed, Audio: audio.Data, MIMEType: audio.MIMEType}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	if w.rankingModel == "" || w.ranker == nil {
+		return takes, nil
+	}
+	scores, err := w.ranker.rankTakes(ctx, w.rankingModel, recipe, takes)
+	if err != nil {
+		return takes, fmt.Errorf("lyria: failed to rank takes (model: %s): %w", w.rankingModel, err)
+	}
+	return applyTakeScores(takes, scores), nil
+}
+
+// takeSeed は i 本目のテイクに使うシードを返します。int32 の上限を超える分は
+// 下限側へ折り返します（API のシードは int32 のため）。
+func takeSeed(base int64, i int) int64 {
+	seed := base + int64(i)
+	if seed > math.MaxInt32 {
+		seed -= 1 << 32
+	}
+	return seed
+}
+
+// applyTakeScores は採点をテイクに写し、点数の高い順（同点なら生成順）に並べ替えます。
+// 採点の無いテイクは 0 点として最後に回します。同じテイクが複数回採点された場合は
+// 最初のものを使います。
+func applyTakeScores(takes []Take, scores []takeScore) []Take {
+	ranked := slices.Clone(takes)
+	scored := make([]bool, len(ranked))
+	for _, score := range scores {
+		if score.Take < 0 || score.Take >= len(ranked) || scored[score.Take] {
+			continue
+		}
+		scored[score.Take] = true
+		ranked[score.Take].Score = score.Score
+		ranked[score.Take].Reason = strings.TrimSpace(score.Reason)
+	}
+	slices.SortStableFunc(ranked, func(a, b Take) int {
+		if scored[a.Index] != scored[b.Index] {
+			if scored[a.Index] {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(b.Score, a.Score)
+	})
+	for i := range ranked {
+		ranked[i].Rank = i + 1
+	}
+	return ranked
+}
+
+// rankTakes は、テイクの音声を添付してモデルに採点させます。
+//
+// 添付の内容ごとに結果が変わるため、プロンプトだけをキーにする singleflight は使いません。
+func (g *lyriaTextGenerator) rankTakes(ctx context.Context, model string, recipe *MusicRecipe, takes []Take) ([]takeScore, error) {
+	attachments, cleanup, err := g.takeAttachments(ctx, takes)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	// AIModels は楽曲の中身ではないため、採点の材料から外す。
+	brief := recipe.Clone()
+	brief.AIModels = AIModels{}
+	data, err := json.MarshalIndent(brief, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode recipe for ranking: %w", err)
+	}
+
+	if g.limiter != nil {
+		if err := g.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := g.aiClient.GenerateWithAttachments(ctx, model, defaultRankingPrompt(len(takes), string(data)), attachments, buildJSONGenerateOptions(nil, takeRankingSchema()))
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil || strings.TrimSpace(resp.Text) == "" {
+		return nil, fmt.Errorf("%w: AI returned an empty ranking", ErrInvalidResponse)
+	}
+
+	jsonStr := gemini.CleanJSONResponse(strings.TrimSpace(resp.Text))
+	var out struct {
+		Takes []takeScore `json:"takes"`
+	}
+	if err := json.Unmarshal([]byte(jsonStr), &out); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal ranking json: %w (raw: %s)",
+			ErrInvalidResponse, err, truncateForError(jsonStr))
+	}
+	if len(out.Takes) == 0 {
+		return nil, fmt.Errorf("%w: ranking has no takes (raw: %s)", ErrInvalidResponse, truncateForError(jsonStr))
+	}
+	return out.Takes, nil
+}
+
+// takeAttachments は、テイクの音声を採点のリクエストに添付する形にします。
+//
+// 合計が上限（maxInlineBytes）に収まればインラインで送ります。超える場合は、1回のリクエストの
+// 上限を超えないよう、すべてのテイクを File API へアップロードして URI で参照します
+// （aiClient が gemini.FileManager を満たさなければ ErrWorkflowConfig です）。
+// 返す関数はアップロードしたファイルを削除します（何もしていない場合は何もしません）。
+func (g *lyriaTextGenerator) takeAttachments(ctx context.Context, takes []Take) ([]gemini.Attachment, func(), error) {
+	attachments := make([]gemini.Attachment, len(takes))
+	total := 0
+	for i, take := range takes {
+		mimeType := take.MIMEType
+		if mimeType == "" {
+			mimeType = gemini.DetectMIMEType(take.Audio)
+		}
+		attachments[i] = gemini.Attachment{Data: take.Audio, MIMEType: mimeType}
+		total += len(take.Audio)
+	}
+	limit := cmp.Or(g.maxInlineBytes, maxRankingInlineBytes)
+	if total <= limit {
+		return attachments, func() {}, nil
+	}
+
+	files, ok := g.aiClient.(gemini.FileManager)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: ranking %d takes (%d bytes) exceeds the inline limit of %d bytes and the client does not implement gemini.FileManager",
+			ErrWorkflowConfig, len(takes), total, limit)
+	}
+	var uploaded []string
+	// 削除の失敗は採点に影響せず、File API のファイルは期限が来れば消えるため無視する。
+	cleanup := func() {
+		cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), stagedCleanupTimeout)
+		defer cancel()
+		for _, name := range uploaded {
+			_ = files.DeleteFile(cleanupCtx, name)
+		}
+	}
+	for i := range attachments {
+		file, err := files.UploadFile(ctx, bytes.NewReader(attachments[i].Data), attachments[i].MIMEType, "take-"+strconv.Itoa(i))
+		if err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("failed to upload take %d for ranking: %w", i, err)
+		}
+		uploaded = append(uploaded, file.Name)
+		attachments[i] = gemini.Attachment{MIMEType: attachments[i].MIMEType, URI: file.URI}
+	}
+	return attachments, cleanup, nil
+}
+
+// defaultRankingPrompt は、テイクを採点させるプロンプトです。添付の順番とテイク番号の
+// 対応を明示しておかないと、モデルが 1 始まりで数えることがあります。
+func defaultRankingPrompt(count int, recipe string) string {
+	return "You are judging " + strconv.Itoa(count) + " audio takes rendered from the same music recipe.\n" +
+		"The attached audio files are take 0 to take " + strconv.Itoa(count-1) + ", in that order.\n" +
+		"Score every take from 0 to 10 for how well it matches the recipe (mood, tempo, key, instruments, vocals, structure and lyrics) " +
+		"and for its overall audio quality, and give a one-sentence reason.\n\n" +
+		"Recipe (JSON):\n" + recipe + "\n"
+}
@@ -0,0 +1,219 @@
+package lyria
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/shouni/go-gemini-client/gemini"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockFileGeminiClient は、gemini.FileManager も満たす MockGeminiClient です。
+type MockFileGeminiClient struct {
+	MockGeminiClient
+}
+
+func (m *MockFileGeminiClient) UploadFile(ctx context.Context, r io.Reader, mimeType, displayName string) (gemini.UploadedFile, error) {
+	args := m.Called(ctx, r, mimeType, displayName)
+	return args.Get(0).(gemini.UploadedFile), args.Error(1)
+}
+
+func (m *MockFileGeminiClient) DeleteFile(ctx context.Context, name string) error {
+	args := m.Called(ctx, name)
+	return args.Error(0)
+}
+
+// seedIs は、音声の生成オプションのシードが seed の呼び出しに合う引数の条件です。
+func seedIs(seed int64) interface{} {
+	return mock.MatchedBy(func(opts gemini.GenerateOptions) bool {
+		return opts.Seed != nil && *opts.Seed == seed
+	})
+}
+
+// onTakes は、シード seeds の Lyria の呼び出しに、シードを書き込んだ MP3 の音声を
+// 1回ずつ返す期待を登録します。
+func onTakes(ai *MockGeminiClient, seeds ...int64) {
+	for _, seed := range seeds {
+		audio := []byte("take:" + strconv.FormatInt(seed, 10))
+		ai.On("GenerateWithAttachments", mock.Anything, "lyria-3", "full", mock.Anything, seedIs(seed)).
+			Return(audioResponse(audio, "audio/mpeg"), nil).Once()
+	}
+}
+
+func TestVariationsUseDerivedSeeds(t *testing.T) {
+	ai := new(MockGeminiClient)
+	onTakes(ai, 100, 101, 102)
+	workflow := newTestWorkflow(t, ai)
+
+	takes, err := workflow.Variations(context.Background(), &MusicRecipe{Title: "Song", AIModels: AIModels{Seed: gemini.Ptr[int64](100)}}, 3, nil)
+	if err != nil {
+		t.Fatalf("Variations() error = %v", err)
+	}
+	if len(takes) != 3 {
+		t.Fatalf("takes = %d, want 3", len(takes))
+	}
+	for i, take := range takes {
+		want := int64(100 + i)
+		if take.Index != i || take.Seed != want || string(take.Audio) != "take:"+strconv.FormatInt(want, 10) || take.MIMEType != "audio/mpeg" || take.Rank != 0 {
+			t.Errorf("takes[%d] = %+v, want seed %d", i, take, want)
+		}
+	}
+	// シードが違うので singleflight でまとめず、3 回とも呼ぶ。
+	ai.AssertExpectations(t)
+}
+
+func TestVariationsWithoutSeedAreReproducible(t *testing.T) {
+	log := &callLog{}
+	ai := new(MockGeminiClient)
+	ai.onGenerate("lyria-3", "full").Return(audioResponse([]byte("take"), "audio/mpeg"), nil).Run(log.record)
+	workflow := newTestWorkflow(t, ai)
+	recipe := &MusicRecipe{Title: "Song"}
+
+	takes, err := workflow.Variations(context.Background(), recipe, 2, nil)
+	if err != nil {
+		t.Fatalf("Variations() error = %v", err)
+	}
+	if takes[0].Seed == takes[1].Seed {
+		t.Errorf("テイクのシードが同じです: %d", takes[0].Seed)
+	}
+	if recipe.Seed != nil {
+		t.Errorf("recipe.Seed が書き換えられました: %d", *recipe.Seed)
+	}
+
+	// 返されたシードをレシピに入れれば、同じシードで作り直せる。
+	if _, err := workflow.GenerateAudio(context.Background(), &MusicRecipe{Title: "Song", AIModels: AIModels{Seed: &takes[1].Seed}}, nil); err != nil {
+		t.Fatalf("GenerateAudio() error = %v", err)
+	}
+	if again := log.calls[len(log.calls)-1].opts.Seed; again == nil || *again != takes[1].Seed {
+		t.Errorf("GenerateAudio() のシード = %v, want %d", again, takes[1].Seed)
+	}
+}
+
+func TestVariationsRankTakes(t *testing.T) {
+	log := &callLog{}
+	ai := new(MockGeminiClient)
+	onTakes(ai, 1, 2, 3)
+	ai.onGenerate("gemini-judge", mock.Anything).
+		Return(textResponse(`{"takes": [{"take": 0, "score": 4, "reason": "too slow"}, {"take": 2, "score": 9, "reason": " fits the mood "}]}`), nil).
+		Run(log.record)
+	workflow := newTestWorkflow(t, ai, WithTakeRanking("gemini-judge"))
+
+	takes, err := workflow.Variations(context.Background(), &MusicRecipe{Title: "Song", AIModels: AIModels{Seed: gemini.Ptr[int64](1)}}, 3, nil)
+	if err != nil {
+		t.Fatalf("Variations() error = %v", err)
+	}
+	if ranked := log.calls[0].attachments; len(ranked) != 3 || ranked[1].MIMEType != "audio/mpeg" || string(ranked[1].Data) != "take:2" {
+		t.Errorf("順位付けの添付 = %+v, want 3 本の音声を生成順に", ranked)
+	}
+	wantOrder := []int{2, 0, 1} // 採点の無いテイクは最後
+	for i, take := range takes {
+		if take.Index != wantOrder[i] || take.Rank != i+1 {
+			t.Errorf("takes[%d] = {Index: %d, Rank: %d}, want Index %d", i, take.Index, take.Rank, wantOrder[i])
+		}
+	}
+	if takes[0].Score != 9 || takes[0].Reason != "fits the mood" {
+		t.Errorf("takes[0] = {Score: %v, Reason: %q}", takes[0].Score, takes[0].Reason)
+	}
+}
+
+func TestVariationsKeepTakesWhenRankingFails(t *testing.T) {
+	ai := new(MockGeminiClient)
+	onTakes(ai, 7, 8)
+	ai.onGenerate("gemini-judge", mock.Anything).Return(textResponse("not json"), nil)
+	workflow := newTestWorkflow(t, ai, WithTakeRanking("gemini-judge"))
+
+	takes, err := workflow.Variations(context.Background(), &MusicRecipe{Title: "Song", AIModels: AIModels{Seed: gemini.Ptr[int64](7)}}, 2, nil)
+	if !errors.Is(err, ErrInvalidResponse) {
+		t.Fatalf("Variations() error = %v, want ErrInvalidResponse", err)
+	}
+	if len(takes) != 2 || takes[0].Index != 0 || takes[0].Rank != 0 || len(takes[1].Audio) == 0 {
+		t.Errorf("takes = %+v, want 生成順・順位なしのテイク", takes)
+	}
+}
+
+func TestTakeSeedWrapsWithinInt32(t *testing.T) {
+	if got := takeSeed(math.MaxInt32, 1); got != math.MinInt32 {
+		t.Errorf("takeSeed(MaxInt32, 1) = %d, want MinInt32", got)
+	}
+	if _, err := newTestWorkflow(t, new(MockGeminiClient)).Variations(context.Background(), &MusicRecipe{AIModels: AIModels{Seed: gemini.Ptr[int64](math.MaxInt64)}}, 2, nil); !errors.Is(err, gemini.ErrInvalidSeed) {
+		t.Errorf("範囲外のシードの err = %v, want gemini.ErrInvalidSeed", err)
+	}
+}
+
+func TestVariationsPassImagesToEveryTake(t *testing.T) {
+	log := &callLog{}
+	ai := new(MockGeminiClient)
+	ai.onGenerate("lyria-3", "full").Return(audioResponse([]byte("take"), "audio/mpeg"), nil).Run(log.record)
+	workflow := newTestWorkflow(t, ai)
+	images := []ImagePayload{{MIMEType: "image/png", Data: []byte("cover")}}
+
+	if _, err := workflow.Variations(context.Background(), &MusicRecipe{Title: "Song"}, 2, images); err != nil {
+		t.Fatalf("Variations() error = %v", err)
+	}
+	if len(log.calls) != 2 {
+		t.Fatalf("Lyria の呼び出し = %d, want 2", len(log.calls))
+	}
+	for i, call := range log.calls {
+		if len(call.attachments) != 1 || string(call.attachments[0].Data) != "cover" {
+			t.Errorf("calls[%d].attachments = %+v, want the cover image", i, call.attachments)
+		}
+	}
+}
+
+func TestVariationsRejectNonPositiveCount(t *testing.T) {
+	workflow := newTestWorkflow(t, new(MockGeminiClient))
+	for _, n := range []int{0, -1} {
+		if _, err := workflow.Variations(context.Background(), &MusicRecipe{Title: "Song"}, n, nil); !errors.Is(err, ErrNilInput) {
+			t.Errorf("Variations(n = %d) error = %v, want ErrNilInput", n, err)
+		}
+	}
+}
+
+func TestVariationsStageLargeTakesForRanking(t *testing.T) {
+	log := &callLog{}
+	ai := new(MockFileGeminiClient)
+	onTakes(&ai.MockGeminiClient, 1, 2)
+	ai.onGenerate("gemini-judge", mock.Anything).Return(textResponse(`{"takes": [{"take": 1, "score": 8, "reason": "good"}]}`), nil).Run(log.record)
+	for i := range 2 {
+		ai.On("UploadFile", mock.Anything, mock.Anything, "audio/mpeg", "take-"+strconv.Itoa(i)).
+			Return(gemini.UploadedFile{Name: "files/take-" + strconv.Itoa(i), URI: "https://files/take-" + strconv.Itoa(i)}, nil).Once()
+		ai.On("DeleteFile", mock.Anything, "files/take-"+strconv.Itoa(i)).Return(nil).Once()
+	}
+	workflow := newTestWorkflow(t, ai, WithTakeRanking("gemini-judge"))
+	// テイク2本の合計（"take:1" と "take:2" で12バイト）が上限を超えるようにする。
+	workflow.ranker.(*lyriaTextGenerator).maxInlineBytes = 10
+
+	takes, err := workflow.Variations(context.Background(), &MusicRecipe{Title: "Song", AIModels: AIModels{Seed: gemini.Ptr[int64](1)}}, 2, nil)
+	if err != nil {
+		t.Fatalf("Variations() error = %v", err)
+	}
+	if takes[0].Index != 1 {
+		t.Errorf("takes[0].Index = %d, want 1", takes[0].Index)
+	}
+	ranked := log.calls[0].attachments
+	if len(ranked) != 2 || ranked[1].URI != "https://files/take-1" || len(ranked[1].Data) != 0 || ranked[1].MIMEType != "audio/mpeg" {
+		t.Errorf("順位付けの添付 = %+v, want アップロードしたファイルの URI", ranked)
+	}
+	// アップロードしたファイルは採点の後で消す。
+	ai.AssertExpectations(t)
+}
+
+func TestVariationsRankingTooLargeWithoutFileManager(t *testing.T) {
+	ai := new(MockGeminiClient)
+	onTakes(ai, 1, 2)
+	workflow := newTestWorkflow(t, ai, WithTakeRanking("gemini-judge"))
+	workflow.ranker.(*lyriaTextGenerator).maxInlineBytes = 10
+
+	takes, err := workflow.Variations(context.Background(), &MusicRecipe{Title: "Song", AIModels: AIModels{Seed: gemini.Ptr[int64](1)}}, 2, nil)
+	if !errors.Is(err, ErrWorkflowConfig) || !strings.Contains(err.Error(), "gemini.FileManager") {
+		t.Errorf("Variations() error = %v, want ErrWorkflowConfig", err)
+	}
+	if len(takes) != 2 {
+		t.Errorf("takes = %d, want 順位付けに失敗しても生成したテイクを返す", len(takes))
+	}
+}
@@ -0,0 +1,93 @@
+package music
+
+import "strings"
+
+// japaneseTonics は日本音名（ハニホヘトイロ）と英音名の対応です。
+var japaneseTonics = map[string]string{
+	"ハ": "C", "ニ": "D", "ホ": "E", "ヘ": "F", "ト": "G", "イ": "A", "ロ": "B",
+}
+
+// CanonicalKey は、自由な表記のキーを "C# minor" / "Bb major" の形に揃えます。
+//
+// 主音（A〜G）・変化記号（"#" / "♯" / "sharp" と "b" / "♭" / "flat"）・旋法
+// （"major" / "maj" / "M" と "minor" / "min" / "m"、省略時は長調）の組み合わせと、
+// "嬰ヘ短調" のような日本語の調名を解釈します。異名同音は書き換えません
+// （"Db major" は "Db major" のままです）。解釈できない場合は "", false を返します。
+func CanonicalKey(key string) (string, bool) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", false
+	}
+	if canonical, ok := canonicalJapaneseKey(key); ok {
+		return canonical, true
+	}
+
+	s := strings.NewReplacer("♯", "#", "♭", "b", "-", " ", "_", " ").Replace(key)
+	if lower := strings.ToLower(s); strings.HasPrefix(lower, "key of ") {
+		s = strings.TrimSpace(s[len("key of "):])
+	}
+	if s == "" {
+		return "", false
+	}
+
+	tonic := strings.ToUpper(s[:1])
+	if tonic < "A" || tonic > "G" {
+		return "", false
+	}
+	rest := strings.TrimLeft(s[1:], " ")
+
+	accidental := ""
+	lowerRest := strings.ToLower(rest)
+	for _, candidate := range []struct{ prefix, symbol string }{
+		{"sharp", "#"}, {"flat", "b"}, {"#", "#"}, {"b", "b"},
+	} {
+		if strings.HasPrefix(lowerRest, candidate.prefix) {
+			accidental = candidate.symbol
+			rest = strings.TrimLeft(rest[len(candidate.prefix):], " ")
+			break
+		}
+	}
+
+	var mode string
+	switch {
+	case rest == "M":
+		// 大文字の M だけは長調（"CM" = C major）の略記として扱う。
+		mode = "major"
+	default:
+		switch strings.ToLower(rest) {
+		case "", "major", "maj":
+			mode = "major"
+		case "minor", "min", "m":
+			mode = "minor"
+		default:
+			return "", false
+		}
+	}
+	return tonic + accidental + " " + mode, true
+}
+
+// canonicalJapaneseKey は "ハ長調" / "嬰ヘ短調" / "変ロ長調" の形の調名を解釈します。
+func canonicalJapaneseKey(key string) (string, bool) {
+	var mode string
+	switch {
+	case strings.HasSuffix(key, "長調"):
+		mode, key = "major", strings.TrimSuffix(key, "長調")
+	case strings.HasSuffix(key, "短調"):
+		mode, key = "minor", strings.TrimSuffix(key, "短調")
+	default:
+		return "", false
+	}
+
+	accidental := ""
+	switch {
+	case strings.HasPrefix(key, "嬰"):
+		accidental, key = "#", strings.TrimPrefix(key, "嬰")
+	case strings.HasPrefix(key, "変"):
+		accidental, key = "b", strings.TrimPrefix(key, "変")
+	}
+	tonic, ok := japaneseTonics[strings.TrimSpace(key)]
+	if !ok {
+		return "", false
+	}
+	return tonic + accidental + " " + mode, true
+}
@@ -0,0 +1,260 @@
+package music
+
+import (
+	"cmp"
+	"strings"
+	"unicode"
+)
+
+// CanonicalLang は BCP 47 の言語タグを、慣習どおりの大文字・小文字と "-" 区切りに揃えます
+// （"JA_jp" → "ja-JP"、"zh-hant-tw" → "zh-Hant-TW"）。
+//
+// 主言語は2〜3文字の英字で、続く部分タグは1〜8文字の英数字です。
+// 4文字の英字は文字体系、2文字の英字と3桁の数字は地域として書き方を揃え、それ以外の
+// 部分タグ（変種・拡張）は小文字にします。タグの形になっていない場合は "", false を返します。
+// 言語や地域が実在するかまでは確かめません。
+func CanonicalLang(tag string) (string, bool) {
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+	if tag == "" {
+		return "", false
+	}
+	subtags := strings.Split(tag, "-")
+	for i, subtag := range subtags {
+		if len(subtag) == 0 || len(subtag) > 8 || strings.IndexFunc(subtag, func(r rune) bool {
+			return r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r))
+		}) >= 0 {
+			return "", false
+		}
+		lower := strings.ToLower(subtag)
+		switch {
+		case i == 0:
+			if len(lower) < 2 || len(lower) > 3 || !isAlpha(lower) {
+				return "", false
+			}
+			subtags[i] = lower
+		case len(lower) == 4 && isAlpha(lower) && i == 1:
+			subtags[i] = strings.ToUpper(lower[:1]) + lower[1:]
+		case len(lower) == 2 && isAlpha(lower), len(lower) == 3 && strings.IndexFunc(lower, unicode.IsLetter) < 0:
+			subtags[i] = strings.ToUpper(lower)
+		default:
+			subtags[i] = lower
+		}
+	}
+	return strings.Join(subtags, "-"), true
+}
+
+func isAlpha(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool { return !unicode.IsLetter(r) }) < 0
+}
+
+// BaseLang は言語タグの主言語の部分を小文字で返します（"ja-JP" → "ja"）。
+func BaseLang(tag string) string {
+	base, _, _ := strings.Cut(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"), "-")
+	return strings.ToLower(base)
+}
+
+// SameLang は、2つの言語タグの主言語が同じかどうかを返します（"ja" と "ja-JP" は同じです）。
+func SameLang(a, b string) bool {
+	return BaseLang(a) == BaseLang(b)
+}
+
+// langNames は、プロンプトに書く言語の英語名です。
+var langNames = map[string]string{
+	"ja": "Japanese", "en": "English", "ko": "Korean", "zh": "Chinese",
+	"es": "Spanish", "fr": "French", "de": "German", "it": "Italian", "pt": "Portuguese",
+	"ru": "Russian", "ar": "Arabic", "hi": "Hindi", "th": "Thai", "vi": "Vietnamese",
+	"id": "Indonesian", "tr": "Turkish", "nl": "Dutch", "pl": "Polish", "sv": "Swedish",
+	"el": "Greek", "he": "Hebrew", "uk": "Ukrainian",
+}
+
+// LangName は言語タグの主言語の英語名を返します（"pt-BR" → "Portuguese"）。表に無い言語は
+// タグをそのまま返し、空のタグは日本語（Lang 未指定の扱い）の名前を返します。
+func LangName(tag string) string {
+	if name, ok := langNames[BaseLang(cmp.Or(tag, LangJapanese))]; ok {
+		return name
+	}
+	return tag
+}
+
+// cjkWeight は、DetectLang が漢字・仮名・ハングル1字を何字分に数えるかです。
+const cjkWeight = 3
+
+// scriptLangs は、その文字体系の文字が多い文を書く言語です。漢字と仮名・ハングルは
+// DetectLang が別に扱います。
+var scriptLangs = []struct {
+	table *unicode.RangeTable
+	lang  string
+}{
+	{unicode.Cyrillic, "ru"},
+	{unicode.Arabic, "ar"},
+	{unicode.Devanagari, "hi"},
+	{unicode.Thai, "th"},
+	{unicode.Greek, "el"},
+	{unicode.Hebrew, "he"},
+}
+
+// latinStopwords は、ラテン文字の言語を見分けるための、よく現れる短い語です。
+var latinStopwords = map[string][]string{
+	"en": {"the", "and", "you", "is", "of", "my", "in", "to", "it", "me", "we", "love"},
+	"es": {"el", "la", "de", "que", "y", "en", "los", "mi", "tu", "amor", "es", "por"},
+	"fr": {"le", "la", "les", "de", "et", "je", "tu", "est", "un", "une", "mon", "amour"},
+	"de": {"der", "die", "das", "und", "ich", "du", "ist", "nicht", "mein", "ein", "liebe"},
+	"pt": {"o", "a", "de", "que", "e", "do", "da", "meu", "você", "não", "amor", "em"},
+	"it": {"il", "la", "di", "che", "e", "io", "tu", "non", "mio", "amore", "un", "sono"},
+}
+
+// DetectLang は text の文字から言語を推し、主言語のタグ（"ja" / "en" / "ko" など）を返します。
+// 文字が無い場合は "" です。
+//
+// 文字体系ごとに文字を数え、最も多いもので決めます。漢字・仮名・ハングルは1字で1音節ほどに
+// なるため3字分に数えます。漢字は仮名があれば日本語、ハングルがあれば韓国語、どちらも
+// 無ければ中国語に数え、キリル文字・アラビア文字などは文字体系の言語とします。ラテン文字の
+// 文は、英語・スペイン語・フランス語・ドイツ語・ポルトガル語・イタリア語のよく現れる語の
+// 数で選び、決め手が無ければ英語とします。短い文では誤ることがあるため、確かな場合は AIModels.Lang を
+// 指定してください。
+func DetectLang(text string) string {
+	var kana, hangul, han, latin, letters int
+	scripts := make(map[string]int)
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		default:
+			for _, script := range scriptLangs {
+				if unicode.Is(script.table, r) {
+					scripts[script.lang]++
+					break
+				}
+			}
+		}
+	}
+	if letters == 0 {
+		return ""
+	}
+
+	best, count := "", 0
+	candidates := []struct {
+		lang  string
+		count int
+	}{{LangJapanese, cjkWeight * (kana + han)}, {"ko", cjkWeight * (hangul + han)}, {"zh", cjkWeight * han}, {LangEnglish, latin}}
+	if kana == 0 {
+		candidates[0].count = 0
+	}
+	if hangul == 0 {
+		candidates[1].count = 0
+	}
+	for _, candidate := range candidates {
+		if candidate.count > count {
+			best, count = candidate.lang, candidate.count
+		}
+	}
+	for _, script := range scriptLangs {
+		if scripts[script.lang] > count {
+			best, count = script.lang, scripts[script.lang]
+		}
+	}
+	if best == LangEnglish {
+		return detectLatinLang(text)
+	}
+	return best
+}
+
+// detectLatinLang は、ラテン文字の文の言語をよく現れる語の数で選びます。
+func detectLatinLang(text string) string {
+	counts := make(map[string]int)
+	for word := range strings.FieldsFuncSeq(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	}) {
+		for lang, stopwords := range latinStopwords {
+			for _, stopword := range stopwords {
+				if word == stopword {
+					counts[lang]++
+				}
+			}
+		}
+	}
+	best := LangEnglish
+	for _, lang := range []string{"es", "fr", "de", "pt", "it"} {
+		if counts[lang] > counts[best] {
+			best = lang
+		}
+	}
+	return best
+}
+
+// Language は、このレシピの歌詞・ボーカルの言語タグです。Lang 未指定は日本語扱いです。
+func (r *Recipe) Language() string {
+	return cmp.Or(r.Lang, LangJapanese)
+}
+
+// SectionLang は index 番目のセクションの言語タグです。セクションの Lang が無ければ
+// レシピの Language を返します。
+func (r *Recipe) SectionLang(index int) string {
+	if index >= 0 && index < len(r.Sections) && r.Sections[index].Lang != "" {
+		return r.Sections[index].Lang
+	}
+	return r.Language()
+}
+
+// IsMultilingual は、レシピと主言語の違う言語で歌うセクションがあるかどうかを返します。
+func (r *Recipe) IsMultilingual() bool {
+	for i := range r.Sections {
+		if !SameLang(r.SectionLang(i), r.Language()) {
+			return true
+		}
+	}
+	return false
+}
+
+// InferSectionLangs は、Lang の無いセクションのうち、割り当てた歌詞（LyricSheet.Align）を
+// DetectLang で調べてレシピと主言語の違う言語だったものに Lang を入れます。
+// 日本語の歌に英語のサビがあるような2言語の歌を、セクションごとの言語に分けるのに使います。
+// 歌詞の無いセクションや、レシピと同じ言語のセクションはそのままです。"la la la" のような
+// 短い（文字が minInferLetters 字に満たない）まとまりは、言語を決めずにそのままにします。
+func (r *Recipe) InferSectionLangs() {
+	if r == nil || r.Lyrics == nil {
+		return
+	}
+	sheet := r.Lyrics.Sheet()
+	for i, index := range sheet.Align(r.Sections) {
+		if index < 0 || r.Sections[index].Lang != "" {
+			continue
+		}
+		text := strings.Join(sheet.Blocks[i].Lines, "\n")
+		if countLetters(text) < minInferLetters {
+			continue
+		}
+		detected := DetectLang(text)
+		if detected != "" && !SameLang(detected, r.Language()) {
+			r.Sections[index].Lang = detected
+		}
+	}
+}
+
+// minInferLetters は、InferSectionLangs が言語を決めるのに要る文字の数です。
+const minInferLetters = 10
+
+// countLetters は text の文字（unicode.IsLetter）の数です。漢字・仮名・ハングルは
+// DetectLang と同じく cjkWeight 字分に数えます。
+func countLetters(text string) int {
+	n := 0
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			n += cjkWeight
+		case unicode.IsLetter(r):
+			n++
+		}
+	}
+	return n
+}
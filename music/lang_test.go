@@ -0,0 +1,114 @@
+package music
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCanonicalLang(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"ja", "ja", true},
+		{"JA_jp", "ja-JP", true},
+		{"en-us", "en-US", true},
+		{"zh-hant-tw", "zh-Hant-TW", true},
+		{"es-419", "es-419", true},
+		{"sr-Latn", "sr-Latn", true},
+		{"", "", false},
+		{"j", "", false},
+		{"Latn", "", false},
+		{"en--US", "", false},
+		{"日本語", "", false},
+		{"not a tag", "", false},
+	} {
+		got, ok := CanonicalLang(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("CanonicalLang(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestDetectLang(t *testing.T) {
+	for _, tt := range []struct {
+		text string
+		want string
+	}{
+		{"雨の日の駅で君を待つ", "ja"},
+		{"東京タワー", "ja"},
+		{"비 오는 날의 역", "ko"},
+		{"雨天的车站", "zh"},
+		{"A rainy night at the station", "en"},
+		{"La noche de lluvia en la estación, mi amor", "es"},
+		{"Je t'attends à la gare, mon amour", "fr"},
+		{"Ich warte auf dich und die Nacht ist lang", "de"},
+		{"Дождливая ночь", "ru"},
+		{"夜空を走る baby, tonight", "ja"},
+		{"12345 !?", ""},
+	} {
+		if got := DetectLang(tt.text); got != tt.want {
+			t.Errorf("DetectLang(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestRecipeLanguages(t *testing.T) {
+	r := &Recipe{
+		Sections: []Section{{Name: "Verse"}, {Name: "Chorus", Lang: "en-US"}},
+		Lyrics:   &LyricsDraft{Lyrics: "[Verse]\n夜空を走る\n[Chorus]\nhold me"},
+	}
+	if r.Language() != LangJapanese || !r.IsJapanese() {
+		t.Errorf("Lang 未指定の Language() = %q, IsJapanese() = %v", r.Language(), r.IsJapanese())
+	}
+	if r.SectionLang(0) != LangJapanese || r.SectionLang(1) != "en-US" || !r.IsMultilingual() {
+		t.Errorf("SectionLang = %q, %q, IsMultilingual() = %v", r.SectionLang(0), r.SectionLang(1), r.IsMultilingual())
+	}
+	if !(&Recipe{AIModels: AIModels{Lang: "ja-JP"}}).IsJapanese() {
+		t.Error(`"ja-JP" の IsJapanese() = false`)
+	}
+	if LangName("pt-BR") != "Portuguese" || LangName("") != "Japanese" || LangName("tlh") != "tlh" {
+		t.Errorf("LangName = %q, %q, %q", LangName("pt-BR"), LangName(""), LangName("tlh"))
+	}
+
+	inferred := &Recipe{
+		AIModels: AIModels{Lang: LangEnglish},
+		Sections: []Section{{Name: "Verse"}, {Name: "Chorus"}, {Name: "Bridge", Lang: "fr"}, {Name: "Outro"}},
+		Lyrics:   &LyricsDraft{Lyrics: "[Verse]\nwaiting for the night\n[Chorus]\n夜空を走る\n[Bridge]\n夜空\n[Outro]\nla la la"},
+	}
+	inferred.InferSectionLangs()
+	if got := []string{inferred.Sections[0].Lang, inferred.Sections[1].Lang, inferred.Sections[2].Lang, inferred.Sections[3].Lang}; got[0] != "" || got[1] != "ja" || got[2] != "fr" || got[3] != "" {
+		t.Errorf("InferSectionLangs 後の Lang = %q", got)
+	}
+}
+
+func TestRecipeValidatesAndNormalizesLang(t *testing.T) {
+	r := &Recipe{
+		Tempo:    120,
+		AIModels: AIModels{Lang: "en_us"},
+		Sections: []Section{
+			{Name: "Verse", Duration: 30, EndSeconds: 30, Lang: "EN-US"},
+			{Name: "Chorus", Duration: 30, StartSeconds: 30, EndSeconds: 60, Lang: "ja_JP"},
+		},
+	}
+	r.Normalize()
+	if r.Lang != "en-US" || r.Sections[0].Lang != "" || r.Sections[1].Lang != "ja-JP" {
+		t.Errorf("Normalize 後の Lang = %q, Sections = %+v", r.Lang, r.Sections)
+	}
+	if err := r.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+
+	r.Lang, r.Sections[1].Lang = "English", "日本語"
+	err := r.Validate()
+	var fields []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		if fieldErr, ok := errors.AsType[*FieldError](e); ok {
+			fields = append(fields, fieldErr.Field)
+		}
+	}
+	if len(fields) != 2 || fields[0] != "Lang" || fields[1] != "Sections[1].Lang" {
+		t.Errorf("Validate() の問題のあったフィールド = %v", fields)
+	}
+}
@@ -0,0 +1,286 @@
+package music
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// LyricBlock は、歌詞の "[Verse]" のようなタグ1つ分のまとまりです。
+type LyricBlock struct {
+	// Tag は角括弧の中身です（"Verse 1"、"サビ" など）。タグの無いまとまりでは空です。
+	Tag string
+	// Lines は歌詞の行です。タグ付きのまとまりでは、途中の空行も "" として残ります。
+	Lines []string
+}
+
+// Kind は Tag をセクションの種類（"verse" / "chorus" / "bridge" など）に揃えたものです。
+// 番号や補足（"Verse 2"、"Chorus x2"、"Verse: male"）を除き、日本語の呼び方（"Aメロ"、
+// "サビ"）も同じ種類に揃えます。タグが無ければ空です。
+func (b LyricBlock) Kind() string {
+	return sectionKind(b.Tag)
+}
+
+// LyricSheet は、LyricsDraft.Lyrics の自由な文字列をタグごとのまとまりに分けたものです。
+type LyricSheet struct {
+	Blocks []LyricBlock
+}
+
+// ParseLyricSheet は歌詞の文字列をまとまりに分けます。
+//
+// "[...]"（または "【...】"）だけの行をタグとして、次のタグまでを1つのまとまりにします。最初のタグより前の
+// 行や、タグの無い歌詞は、空行で区切った段落をそれぞれタグの無いまとまりにします。
+// 各まとまりの前後の空行は取り除きます。String で文字列に戻せます。
+func ParseLyricSheet(text string) LyricSheet {
+	var sheet LyricSheet
+	var current *LyricBlock
+	flush := func() {
+		if current == nil {
+			return
+		}
+		for len(current.Lines) > 0 && current.Lines[len(current.Lines)-1] == "" {
+			current.Lines = current.Lines[:len(current.Lines)-1]
+		}
+		if current.Tag != "" || len(current.Lines) > 0 {
+			sheet.Blocks = append(sheet.Blocks, *current)
+		}
+		current = nil
+	}
+
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	for line := range strings.SplitSeq(text, "\n") {
+		line = strings.TrimSpace(line)
+		if tag, ok := parseLyricTag(line); ok {
+			flush()
+			current = &LyricBlock{Tag: tag}
+			continue
+		}
+		if line == "" {
+			// タグの無いまとまりは空行で終わる。タグ付きのまとまりでは段落の区切りとして残す。
+			if current != nil && current.Tag == "" {
+				flush()
+			} else if current != nil && len(current.Lines) > 0 {
+				current.Lines = append(current.Lines, "")
+			}
+			continue
+		}
+		if current == nil {
+			current = &LyricBlock{}
+		}
+		current.Lines = append(current.Lines, line)
+	}
+	flush()
+	return sheet
+}
+
+// parseLyricTag は "[Verse 1]" や "【サビ】" のような行からタグを取り出します。
+func parseLyricTag(line string) (string, bool) {
+	for _, brackets := range [][2]string{{"[", "]"}, {"【", "】"}} {
+		inner, ok := strings.CutPrefix(line, brackets[0])
+		if !ok {
+			continue
+		}
+		inner, ok = strings.CutSuffix(inner, brackets[1])
+		if !ok {
+			return "", false
+		}
+		tag := strings.TrimSpace(inner)
+		if tag == "" || strings.ContainsAny(tag, "[]【】") {
+			return "", false
+		}
+		return tag, true
+	}
+	return "", false
+}
+
+// String は、プロンプトビルダーが受け取る形の歌詞の文字列に戻します。タグ付きの
+// まとまりは "[Tag]" の行から始め（"【...】" のタグも "[...]" で書きます）、まとまりの間は空行1つで区切ります。
+// ParseLyricSheet(s.String()) は s と同じまとまりになります（タグの無いまとまりに
+// 空行を含めた場合を除きます）。
+func (s LyricSheet) String() string {
+	parts := make([]string, 0, len(s.Blocks))
+	for _, block := range s.Blocks {
+		lines := block.Lines
+		if block.Tag != "" {
+			lines = append([]string{"[" + block.Tag + "]"}, lines...)
+		}
+		parts = append(parts, strings.Join(lines, "\n"))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// Sheet は Lyrics をまとまりに分けた LyricSheet を返します。
+func (d *LyricsDraft) Sheet() LyricSheet {
+	if d == nil {
+		return LyricSheet{}
+	}
+	return ParseLyricSheet(d.Lyrics)
+}
+
+// Align は、各まとまりをどのセクションで歌うかを求め、Blocks と同じ順にセクションの
+// 添字（対応するセクションが無ければ -1）を返します。
+//
+// 前から順に、種類（Kind）が同じ最初のセクションを割り当てます。種類の合うセクションが
+// 無いまとまり（タグの無いものを含む）は、まだ割り当てていない次のセクションのうち、
+// 後ろのまとまりが種類で取る予定の無いもの、タグが無い場合はさらにイントロ・間奏・
+// アウトロ以外のものに割り当てます。セクションの順番は入れ替えません。
+func (s LyricSheet) Align(sections []Section) []int {
+	assigned := make([]int, len(s.Blocks))
+	cursor := 0
+	for i, block := range s.Blocks {
+		assigned[i] = -1
+		kind := block.Kind()
+		match := -1
+		if kind != "" {
+			for j := cursor; j < len(sections); j++ {
+				if sectionKind(sections[j].Name) == kind {
+					match = j
+					break
+				}
+			}
+		}
+		if match < 0 {
+			reserved := make(map[string]bool)
+			for _, later := range s.Blocks[i+1:] {
+				if k := later.Kind(); k != "" {
+					reserved[k] = true
+				}
+			}
+			for j := cursor; j < len(sections); j++ {
+				k := sectionKind(sections[j].Name)
+				if reserved[k] || (kind == "" && instrumentalKinds[k]) {
+					continue
+				}
+				match = j
+				break
+			}
+		}
+		if match >= 0 {
+			assigned[i] = match
+			cursor = match + 1
+		}
+	}
+	return assigned
+}
+
+// TimedLine は、タイミングを付けた歌詞の1行です。
+type TimedLine struct {
+	Text  string
+	Start time.Duration
+	End   time.Duration
+	// Block は LyricSheet.Blocks の添字、Section は歌うセクションの添字です。
+	Block   int
+	Section int
+}
+
+// Timeline は、Align で割り当てたセクションの区間に各まとまりの行を均等に並べます。
+//
+// セクションの区間は StartSeconds から EndSeconds まで（EndSeconds が無ければ Duration 秒）
+// です。先に Recipe.Normalize で連続した並びにしておくことを想定しています。空行と、
+// セクションに割り当てられなかった・長さの無いセクションのまとまりの行は含みません。
+func (s LyricSheet) Timeline(sections []Section) []TimedLine {
+	var timeline []TimedLine
+	for i, index := range s.Align(sections) {
+		if index < 0 {
+			continue
+		}
+		section := sections[index]
+		start := time.Duration(section.StartSeconds) * time.Second
+		end := time.Duration(section.EndSeconds) * time.Second
+		if section.EndSeconds <= section.StartSeconds {
+			end = start + time.Duration(section.Duration)*time.Second
+		}
+		var lines []string
+		for _, line := range s.Blocks[i].Lines {
+			if line != "" {
+				lines = append(lines, line)
+			}
+		}
+		if end <= start || len(lines) == 0 {
+			continue
+		}
+		span := end - start
+		for j, line := range lines {
+			timeline = append(timeline, TimedLine{
+				Text:    line,
+				Start:   start + span*time.Duration(j)/time.Duration(len(lines)),
+				End:     start + span*time.Duration(j+1)/time.Duration(len(lines)),
+				Block:   i,
+				Section: index,
+			})
+		}
+	}
+	return timeline
+}
+
+// LRC は、Timeline の行を LRC 形式（"[mm:ss.xx]歌詞"）で書き出します。
+func (s LyricSheet) LRC(sections []Section) string {
+	var b strings.Builder
+	for _, line := range s.Timeline(sections) {
+		centis := line.Start.Milliseconds() / 10
+		fmt.Fprintf(&b, "[%02d:%02d.%02d]%s\n", centis/6000, centis/100%60, centis%100, line.Text)
+	}
+	return b.String()
+}
+
+// WebVTT は、Timeline の行を WebVTT 形式の字幕として書き出します。
+func (s LyricSheet) WebVTT(sections []Section) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n")
+	escaper := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	for i, line := range s.Timeline(sections) {
+		fmt.Fprintf(&b, "\n%d\n%s --> %s\n%s\n", i+1, vttTimestamp(line.Start), vttTimestamp(line.End), escaper.Replace(line.Text))
+	}
+	return b.String()
+}
+
+// vttTimestamp は WebVTT のタイムスタンプ（"hh:mm:ss.ttt"）です。
+func vttTimestamp(d time.Duration) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3_600_000, ms/60_000%60, ms/1000%60, ms%1000)
+}
+
+// sectionAliases は、セクション名の呼び方を種類に揃える表です。
+var sectionAliases = map[string]string{
+	"verse": "verse", "aメロ": "verse",
+	"pre chorus": "pre-chorus", "prechorus": "pre-chorus", "build": "pre-chorus", "bメロ": "pre-chorus",
+	"chorus": "chorus", "hook": "chorus", "refrain": "chorus", "サビ": "chorus",
+	"bridge": "bridge", "cメロ": "bridge", "ブリッジ": "bridge",
+	"intro": "intro", "イントロ": "intro",
+	"outro": "outro", "ending": "outro", "アウトロ": "outro", "エンディング": "outro",
+	"interlude": "interlude", "instrumental": "interlude", "solo": "interlude", "break": "interlude", "間奏": "interlude",
+}
+
+// instrumentalKinds は、ふつう歌詞を持たないセクションの種類です。
+var instrumentalKinds = map[string]bool{"intro": true, "outro": true, "interlude": true}
+
+// sectionKind は、セクション名やタグを種類に揃えます。表に無い名前は、番号や補足を
+// 除いて小文字にしたものを返します。
+func sectionKind(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if before, _, ok := strings.Cut(name, ":"); ok {
+		name = before
+	}
+	if before, _, ok := strings.Cut(name, "("); ok {
+		name = before
+	}
+	var words []string
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '#'
+	}) {
+		if word != "x" { // "Chorus x2" の "x"
+			words = append(words, word)
+		}
+	}
+	joined := strings.Join(words, " ")
+	if kind, ok := sectionAliases[joined]; ok {
+		return kind
+	}
+	for _, word := range words { // "Final Chorus" など
+		if kind, ok := sectionAliases[word]; ok {
+			return kind
+		}
+	}
+	return joined
+}
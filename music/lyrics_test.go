@@ -0,0 +1,124 @@
+package music
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+const testLyrics = `夜明け前の街で
+
+[Verse 1]
+窓を開けて
+風を待つ
+
+まだ眠い空
+【サビ】
+走り出せ
+今すぐ
+[Chorus x2]
+走り出せ & 叫べ <今>
+`
+
+func TestParseLyricSheet(t *testing.T) {
+	sheet := ParseLyricSheet(testLyrics)
+
+	want := []LyricBlock{
+		{Tag: "", Lines: []string{"夜明け前の街で"}},
+		{Tag: "Verse 1", Lines: []string{"窓を開けて", "風を待つ", "", "まだ眠い空"}},
+		{Tag: "サビ", Lines: []string{"走り出せ", "今すぐ"}},
+		{Tag: "Chorus x2", Lines: []string{"走り出せ & 叫べ <今>"}},
+	}
+	if len(sheet.Blocks) != len(want) {
+		t.Fatalf("Blocks = %+v", sheet.Blocks)
+	}
+	for i, block := range sheet.Blocks {
+		if block.Tag != want[i].Tag || !slices.Equal(block.Lines, want[i].Lines) {
+			t.Errorf("Blocks[%d] = %+v, want %+v", i, block, want[i])
+		}
+	}
+
+	// 文字列に戻して読み直しても同じまとまりになる。
+	again := ParseLyricSheet(sheet.String())
+	if again.String() != sheet.String() || len(again.Blocks) != len(sheet.Blocks) {
+		t.Errorf("往復で変わりました:\n%s\n---\n%s", sheet.String(), again.String())
+	}
+	if got := (&LyricsDraft{Lyrics: "[Verse]\nla"}).Sheet().String(); got != "[Verse]\nla" {
+		t.Errorf("Sheet().String() = %q", got)
+	}
+}
+
+func TestLyricBlockKind(t *testing.T) {
+	for tag, want := range map[string]string{
+		"Verse 2":      "verse",
+		"Chorus x2":    "chorus",
+		"Pre-Chorus":   "pre-chorus",
+		"Final Chorus": "chorus",
+		"Verse: male":  "verse",
+		"サビ":           "chorus",
+		"Aメロ":          "verse",
+		"間奏":           "interlude",
+		"Rap":          "rap",
+		"":             "",
+	} {
+		if got := (LyricBlock{Tag: tag}).Kind(); got != want {
+			t.Errorf("Kind(%q) = %q, want %q", tag, got, want)
+		}
+	}
+}
+
+func testSections() []Section {
+	return []Section{
+		{Name: "Intro", Duration: 8, StartSeconds: 0, EndSeconds: 8},
+		{Name: "Verse", Duration: 20, StartSeconds: 8, EndSeconds: 28},
+		{Name: "Chorus", Duration: 10, StartSeconds: 28, EndSeconds: 38},
+		{Name: "Interlude", Duration: 4, StartSeconds: 38, EndSeconds: 42},
+		{Name: "Chorus", Duration: 10, StartSeconds: 42, EndSeconds: 52},
+	}
+}
+
+func TestLyricSheetAlign(t *testing.T) {
+	sheet := ParseLyricSheet(testLyrics)
+	// 冒頭のタグ無しのまとまりは、イントロ・間奏を避け、後ろのまとまりが取る Verse / Chorus も
+	// 取らないため、割り当てられない。
+	got := sheet.Align(testSections())
+	want := []int{-1, 1, 2, 4}
+	if !slices.Equal(got, want) {
+		t.Errorf("Align() = %v, want %v", got, want)
+	}
+
+	// タグの無い歌詞は、歌のあるセクションへ順に割り当てる。
+	plain := ParseLyricSheet("one\n\ntwo\n\nthree")
+	if got := plain.Align(testSections()); !slices.Equal(got, []int{1, 2, 4}) {
+		t.Errorf("タグ無しの Align() = %v, want [1 2 4]", got)
+	}
+}
+
+func TestLyricSheetExports(t *testing.T) {
+	sheet := ParseLyricSheet(testLyrics)
+	sections := testSections()
+
+	timeline := sheet.Timeline(sections)
+	if len(timeline) != 6 {
+		t.Fatalf("Timeline() = %+v, want 6 行（空行と割り当ての無いまとまりを除く）", timeline)
+	}
+	// Verse（8〜28 秒）の 3 行は均等に並ぶ。
+	if first := timeline[0]; first.Text != "窓を開けて" || first.Start != 8*time.Second || first.End != 8*time.Second+20*time.Second/3 {
+		t.Errorf("timeline[0] = %+v", first)
+	}
+	if last := timeline[5]; last.Start != 42*time.Second || last.End != 52*time.Second || last.Section != 4 {
+		t.Errorf("timeline[5] = %+v", last)
+	}
+
+	lrc := sheet.LRC(sections)
+	wantLRC := "[00:08.00]窓を開けて\n[00:14.66]風を待つ\n[00:21.33]まだ眠い空\n[00:28.00]走り出せ\n[00:33.00]今すぐ\n[00:42.00]走り出せ & 叫べ <今>\n"
+	if lrc != wantLRC {
+		t.Errorf("LRC() =\n%s\nwant\n%s", lrc, wantLRC)
+	}
+
+	vtt := sheet.WebVTT(sections)
+	wantTail := "\n6\n00:00:42.000 --> 00:00:52.000\n走り出せ &amp; 叫べ &lt;今&gt;\n"
+	if len(vtt) < len(wantTail) || vtt[:7] != "WEBVTT\n" || vtt[len(vtt)-len(wantTail):] != wantTail {
+		t.Errorf("WebVTT() =\n%s", vtt)
+	}
+}
@@ -18,7 +18,8 @@ type AIModels struct {
 	LyricsMode  string `json:"lyrics_mode,omitempty"`
 	ComposeMode string `json:"compose_mode,omitempty"`
 	Seed        *int64 `json:"seed,omitempty"`
-	// Lang は歌詞・ボーカルの言語コードです（"ja" / "en"）。空は "ja" 扱いです。
+	// Lang は歌詞・ボーカルの言語を表す BCP 47 の言語タグです（"ja"、"en-US"、"pt-BR" など）。
+	// 空は、歌詞の生成では素材の文から推した言語、音声の生成では "ja" の扱いです。
 	Lang string `json:"lang,omitempty"`
 }
 
@@ -31,6 +32,9 @@ type LyricsDraft struct {
 	Keywords  []string `json:"keywords,omitempty"`
 	Mood      string   `json:"mood,omitempty"`
 	Narrative string   `json:"narrative,omitempty"`
+	// Lang は歌詞を書いた言語の BCP 47 の言語タグです。lyria の GenerateLyrics が、
+	// AIModels.Lang か素材の文から推した言語を入れます。空は不明（日本語の扱い）です。
+	Lang string `json:"lang,omitempty"`
 }
 
 // Clone は LyricsDraft を呼び出し元が安全に変更できるように複製します。
@@ -44,7 +48,8 @@ func (d *LyricsDraft) Clone() *LyricsDraft {
 	return &dst
 }
 
-// LangJapanese と LangEnglish は Recipe.Lang に指定できる言語コードです。
+// LangJapanese と LangEnglish は、既定のプロンプトを同梱している言語のタグです。
+// Recipe.Lang にはこれ以外の BCP 47 の言語タグも指定できます。
 const (
 	LangJapanese = "ja"
 	LangEnglish  = "en"
@@ -64,9 +69,10 @@ type Recipe struct {
 	AIModels
 }
 
-// IsJapanese は、このレシピが日本語楽曲かどうかを返します。Lang 未指定は日本語扱いです。
+// IsJapanese は、このレシピが日本語楽曲かどうかを返します。Lang 未指定は日本語扱いで、
+// "ja-JP" のような地域付きのタグも日本語です。
 func (r *Recipe) IsJapanese() bool {
-	return r.Lang == "" || r.Lang == LangJapanese
+	return SameLang(r.Language(), LangJapanese)
 }
 
 // Clone は Recipe と内部のスライスやポインタを複製します。
@@ -96,4 +102,7 @@ type Section struct {
 	StartSeconds int    `json:"start_seconds"`
 	EndSeconds   int    `json:"end_seconds"`
 	Prompt       string `json:"prompt"`
+	// Lang は、このセクションをレシピの Lang と違う言語で歌う場合の言語タグです。
+	// 空ならレシピの Lang で歌います（Recipe.SectionLang）。
+	Lang string `json:"lang,omitempty"`
 }
@@ -0,0 +1,152 @@
+package music
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// テンポとして妥当とみなす範囲（BPM）です。Normalize はこの範囲へ丸めます。
+const (
+	MinTempo = 40
+	MaxTempo = 240
+	// DefaultTempo は、テンポが指定されていない（0 以下の）場合に Normalize が使う値です。
+	DefaultTempo = 120
+)
+
+// ErrInvalidRecipe は Recipe.Validate が見つけた問題の共通の原因です。
+// 個々の問題は *FieldError として返り、いずれも errors.Is でこれと比較できます。
+var ErrInvalidRecipe = errors.New("music: recipe is not valid")
+
+// FieldError は、Recipe のフィールド1つの問題を表します。
+type FieldError struct {
+	// Field は問題のあったフィールドです（"Tempo"、"Sections[1].EndSeconds" など）。
+	Field string
+	// Value は指定されていた値です。
+	Value any
+	// Reason は問題の内容です。
+	Reason string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("music: %s=%v: %s", e.Field, e.Value, e.Reason)
+}
+
+func (e *FieldError) Unwrap() error {
+	return ErrInvalidRecipe
+}
+
+// Validate は、レシピが生成にそのまま使える形かを確かめます。
+//
+// テンポが MinTempo〜MaxTempo にあること、キーが CanonicalKey で、レシピとセクションの
+// Lang が CanonicalLang で解釈できること、
+// セクションが1つ以上あり、それぞれ名前と長さを持ち、0 秒から隙間も重なりも無く
+// 並んでいること（EndSeconds = StartSeconds + Duration で、次のセクションの
+// StartSeconds が前の EndSeconds と一致すること）を検査します。
+//
+// 問題はすべて *FieldError として errors.Join でまとめて返します。問題が無ければ nil です。
+// タイミングやテンポ・キーの表記の多くは Normalize で直せます。
+func (r *Recipe) Validate() error {
+	if r == nil {
+		return &FieldError{Field: "Recipe", Value: nil, Reason: "recipe is nil"}
+	}
+	var errs []error
+	reject := func(field string, value any, reason string) {
+		errs = append(errs, &FieldError{Field: field, Value: value, Reason: reason})
+	}
+
+	if r.Tempo < MinTempo || r.Tempo > MaxTempo {
+		reject("Tempo", r.Tempo, fmt.Sprintf("must be between %d and %d BPM", MinTempo, MaxTempo))
+	}
+	if r.Key != "" {
+		if _, ok := CanonicalKey(r.Key); !ok {
+			reject("Key", r.Key, `must name a tonic and a mode, such as "C# minor"`)
+		}
+	}
+	if r.Lang != "" {
+		if _, ok := CanonicalLang(r.Lang); !ok {
+			reject("Lang", r.Lang, `must be a BCP 47 language tag, such as "ja" or "en-US"`)
+		}
+	}
+	if len(r.Sections) == 0 {
+		reject("Sections", "[]", "must have at least one section")
+	}
+
+	cursor := 0
+	for i, section := range r.Sections {
+		field := fmt.Sprintf("Sections[%d]", i)
+		if strings.TrimSpace(section.Name) == "" {
+			reject(field+".Name", section.Name, "must not be empty")
+		}
+		if section.Duration <= 0 {
+			reject(field+".Duration", section.Duration, "must be positive")
+		}
+		if section.Lang != "" {
+			if _, ok := CanonicalLang(section.Lang); !ok {
+				reject(field+".Lang", section.Lang, `must be a BCP 47 language tag, such as "ja" or "en-US"`)
+			}
+		}
+		switch {
+		case section.StartSeconds < cursor:
+			reject(field+".StartSeconds", section.StartSeconds, fmt.Sprintf("overlaps the previous section, which ends at %d", cursor))
+		case section.StartSeconds > cursor:
+			reject(field+".StartSeconds", section.StartSeconds, fmt.Sprintf("leaves a gap after %d", cursor))
+		}
+		if section.EndSeconds != section.StartSeconds+section.Duration {
+			reject(field+".EndSeconds", section.EndSeconds, fmt.Sprintf("must equal StartSeconds + Duration (%d)", section.StartSeconds+section.Duration))
+		}
+		cursor = max(section.EndSeconds, section.StartSeconds)
+	}
+	return errors.Join(errs...)
+}
+
+// Normalize は、Validate が問題にする点のうち機械的に直せるものをその場で直します。
+//
+//   - セクションを 0 秒から隙間なく並べ直します。各セクションの長さは Duration を優先し、
+//     Duration が無ければ EndSeconds - StartSeconds を使います。どちらも無いセクションは
+//     長さ 0 のまま残ります（Validate が問題にします）。
+//   - テンポが 0 以下なら DefaultTempo に、範囲外なら MinTempo〜MaxTempo に丸めます。
+//   - キーを CanonicalKey の表記（"C# minor" など）に揃えます。解釈できないキーはそのままです。
+//   - セクション名の前後の空白を取り除きます。
+//   - レシピとセクションの Lang を CanonicalLang の表記（"ja-JP" など）に揃えます。
+//     レシピと同じ言語タグのセクションの Lang は空にします。
+//
+// 名前の無いセクションなど、内容を決めないと直せない問題は残ります。
+func (r *Recipe) Normalize() {
+	if r == nil {
+		return
+	}
+
+	switch {
+	case r.Tempo <= 0:
+		r.Tempo = DefaultTempo
+	default:
+		r.Tempo = min(max(r.Tempo, MinTempo), MaxTempo)
+	}
+	if key, ok := CanonicalKey(r.Key); ok {
+		r.Key = key
+	}
+	if lang, ok := CanonicalLang(r.Lang); ok {
+		r.Lang = lang
+	}
+
+	cursor := 0
+	for i := range r.Sections {
+		section := &r.Sections[i]
+		section.Name = strings.TrimSpace(section.Name)
+		if lang, ok := CanonicalLang(section.Lang); ok {
+			section.Lang = lang
+		}
+		if section.Lang == r.Language() {
+			section.Lang = ""
+		}
+		length := section.Duration
+		if length <= 0 {
+			length = max(section.EndSeconds-section.StartSeconds, 0)
+		}
+		section.StartSeconds = cursor
+		section.Duration = length
+		section.EndSeconds = cursor + length
+		cursor = section.EndSeconds
+	}
+}
@@ -0,0 +1,116 @@
+package music
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCanonicalKey(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"C# minor", "C# minor", true},
+		{"c#m", "C# minor", true},
+		{"C sharp minor", "C# minor", true},
+		{"E♭ major", "Eb major", true},
+		{"Bbm", "Bb minor", true},
+		{"A-flat major", "Ab major", true},
+		{"key of D", "D major", true},
+		{"FM", "F major", true},
+		{"Db maj", "Db major", true},
+		{"嬰ヘ短調", "F# minor", true},
+		{"変ロ長調", "Bb major", true},
+		{"ハ長調", "C major", true},
+		{"", "", false},
+		{"H minor", "", false},
+		{"C dorian", "", false},
+		{"melancholic", "", false},
+	} {
+		got, ok := CanonicalKey(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("CanonicalKey(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRecipeValidateReportsEveryField(t *testing.T) {
+	r := &Recipe{
+		Tempo: 0,
+		Key:   "somewhere",
+		Sections: []Section{
+			{Name: "Intro", Duration: 10, StartSeconds: 0, EndSeconds: 10},
+			{Name: "Verse", Duration: 30, StartSeconds: 5, EndSeconds: 40},
+			{Name: " ", Duration: 20, StartSeconds: 40, EndSeconds: 50},
+		},
+	}
+
+	err := r.Validate()
+	if !errors.Is(err, ErrInvalidRecipe) {
+		t.Fatalf("Validate() error = %v, want ErrInvalidRecipe", err)
+	}
+	want := map[string]bool{
+		"Tempo": true, "Key": true,
+		"Sections[1].StartSeconds": true, "Sections[1].EndSeconds": true,
+		"Sections[2].Name": true, "Sections[2].EndSeconds": true,
+	}
+	var got []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		fieldErr, ok := errors.AsType[*FieldError](e)
+		if !ok {
+			t.Fatalf("%v は *FieldError ではありません", e)
+		}
+		got = append(got, fieldErr.Field)
+		if !want[fieldErr.Field] {
+			t.Errorf("想定外の問題: %v", fieldErr)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("問題のあったフィールド = %v, want %d 件", got, len(want))
+	}
+
+	if err := (&Recipe{Tempo: 120, Key: "A minor", Sections: []Section{{Name: "Verse", Duration: 30, EndSeconds: 30}}}).Validate(); err != nil {
+		t.Errorf("正しいレシピの Validate() = %v", err)
+	}
+	if err := (&Recipe{Tempo: 120}).Validate(); !errors.Is(err, ErrInvalidRecipe) {
+		t.Errorf("セクションの無いレシピの Validate() = %v, want ErrInvalidRecipe", err)
+	}
+}
+
+func TestRecipeNormalizeRepairsTimeline(t *testing.T) {
+	r := &Recipe{
+		Tempo: 999,
+		Key:   "f#m",
+		Sections: []Section{
+			{Name: " Intro ", Duration: 10, StartSeconds: 3, EndSeconds: 8},
+			{Name: "Verse", StartSeconds: 5, EndSeconds: 35}, // Duration 無し → End - Start
+			{Name: "Chorus", Duration: 20},
+		},
+	}
+
+	r.Normalize()
+
+	if r.Tempo != MaxTempo || r.Key != "F# minor" {
+		t.Errorf("Tempo = %d, Key = %q, want %d, \"F# minor\"", r.Tempo, r.Key, MaxTempo)
+	}
+	want := []Section{
+		{Name: "Intro", Duration: 10, StartSeconds: 0, EndSeconds: 10},
+		{Name: "Verse", Duration: 30, StartSeconds: 10, EndSeconds: 40},
+		{Name: "Chorus", Duration: 20, StartSeconds: 40, EndSeconds: 60},
+	}
+	for i, section := range r.Sections {
+		if section != want[i] {
+			t.Errorf("Sections[%d] = %+v, want %+v", i, section, want[i])
+		}
+	}
+	if err := r.Validate(); err != nil {
+		t.Errorf("Normalize 後の Validate() = %v", err)
+	}
+
+	zero := &Recipe{}
+	zero.Normalize()
+	if zero.Tempo != DefaultTempo {
+		t.Errorf("テンポ 0 の Normalize 後 = %d, want %d", zero.Tempo, DefaultTempo)
+	}
+}
@@ -0,0 +1,56 @@
+package transcribe
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/shouni/go-gemini-client/gemini"
+)
+
+// Option は Client の設定を適用する関数型です。
+// 不正な値（ゼロ以下・nil）は「指定なし」として無視し、既定値のままにします。
+type Option func(*Client)
+
+// WithWindow は、長い録音を分割する窓の長さと、隣り合う窓の重なりを設定します。
+//
+// 重なりは、窓の境界で切れた発話を両側のどちらかで完全に拾うためのものです。
+// 重なりが窓の長さの半分以上になる指定は無視します（窓が進まなくなるため）。
+func WithWindow(size, overlap time.Duration) Option {
+	return func(c *Client) {
+		if size <= 0 || overlap < 0 || overlap*2 >= size {
+			return
+		}
+		c.windowSize = size
+		c.windowOverlap = overlap
+	}
+}
+
+// WithMaxInlineBytes は、インラインで送る音声の上限バイト数を設定します。
+// これを超える Data は File API へアップロードしてから参照します。
+func WithMaxInlineBytes(n int) Option {
+	return func(c *Client) {
+		if n > 0 {
+			c.maxInlineBytes = n
+		}
+	}
+}
+
+// WithFileManager は、大きな音声のアップロードに使う File API クライアントを設定します。
+// 未指定の場合、New に渡した generator が gemini.FileManager を満たしていればそれを使います。
+func WithFileManager(files gemini.FileManager) Option {
+	return func(c *Client) {
+		if files != nil {
+			c.files = files
+		}
+	}
+}
+
+// WithLogger は、このクライアントが出すログの出力先を設定します。
+// 未指定（または nil）の場合は slog.Default() を使います。
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		if logger != nil {
+			c.logger = logger
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package transcribe
+
+import "github.com/shouni/go-gemini-client/gemini"
+
+// transcriptSchema は文字起こし結果の構造化出力スキーマです。
+// ResponseMIMEType "application/json" と併用することで、モデル出力が
+// このスキーマに文法レベルで制約されます。
+//
+// 時刻を数値ではなく "MM:SS.mmm" の文字列で受けるのは、モデルが秒数の計算より
+// 時計の表記のほうを安定して出力するためです。
+func transcriptSchema() *gemini.Schema {
+	return &gemini.Schema{
+		Type: gemini.TypeObject,
+		Properties: map[string]*gemini.Schema{
+			"language": {Type: gemini.TypeString},
+			"segments": {
+				Type: gemini.TypeArray,
+				Items: &gemini.Schema{
+					Type: gemini.TypeObject,
+					Properties: map[string]*gemini.Schema{
+						"start":    {Type: gemini.TypeString},
+						"end":      {Type: gemini.TypeString},
+						"speaker":  {Type: gemini.TypeString},
+						"text":     {Type: gemini.TypeString},
+						"language": {Type: gemini.TypeString},
+					},
+					Required:         []string{"start", "end", "speaker", "text"},
+					PropertyOrdering: []string{"start", "end", "speaker", "text", "language"},
+				},
+			},
+		},
+		Required: []string{"segments"},
+	}
+}
+
+// transcriptResponse は transcriptSchema に対応するモデル出力です。
+type transcriptResponse struct {
+	Language string            `json:"language"`
+	Segments []segmentResponse `json:"segments"`
+}
+
+type segmentResponse struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Speaker  string `json:"speaker"`
+	Text     string `json:"text"`
+	Language string `json:"language"`
+}
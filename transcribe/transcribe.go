@@ -0,0 +1,374 @@
+// Package transcribe は、音声の文字起こしと話者分離（誰がいつ話したか）を扱う
+// クライアントを提供します。
+//
+// gemini パッケージが持つのは「音声を添付して生成する」1往復で、文字起こしとしての
+// 扱い——大きな音声の File API への退避、構造化出力のスキーマと時刻の解釈、長い録音の
+// 重なりを持つ窓への分割と、その結果のつなぎ合わせ——をこのパッケージが受け持ちます。
+//
+// 依存は gemini.Generator の注入だけで、genai SDK には触れません。
+package transcribe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/shouni/go-gemini-client/gemini"
+	"github.com/shouni/go-gemini-client/internal/wav"
+)
+
+const (
+	// DefaultWindowSize は、長い録音を分割する窓の既定の長さです。
+	// 長い音声を1回で送ると後半ほど時刻がずれ、発話の取りこぼしも増えるため、
+	// 精度が安定する程度の長さに区切ります。
+	DefaultWindowSize = 10 * time.Minute
+	// DefaultWindowOverlap は、隣り合う窓の既定の重なりです。
+	DefaultWindowOverlap = 30 * time.Second
+	// DefaultMaxInlineBytes は、インラインで送る音声の既定の上限です。
+	// リクエスト全体の上限（20MB）に対し、base64 で約 4/3 倍に膨らむ分を見込んでいます。
+	DefaultMaxInlineBytes = 14 << 20
+)
+
+// cleanupTimeout は、アップロードした音声を削除する処理の上限時間です。
+// 呼び出し側の context が既にキャンセルされていても削除は試みるため、独立した期限を持たせます。
+const cleanupTimeout = 15 * time.Second
+
+// basePrompt は文字起こしの指示です。モデルへの指示なので英語で書いています。
+const basePrompt = "Transcribe the attached audio verbatim. Split it into segments, one per continuous utterance by a single speaker. " +
+	"For each segment give the start and end time as MM:SS.mmm measured from the start of the audio, " +
+	`a speaker label ("Speaker 1", "Speaker 2", ... when the name is unknown), the spoken text, and its BCP 47 language code. ` +
+	"Also give the main language of the audio."
+
+// 入力・結果に関するセンチネルエラーです。
+var (
+	// ErrGeneratorRequired は、New に nil の生成クライアントが渡された場合に返されます。
+	ErrGeneratorRequired = errors.New("transcribe: generator is required")
+	// ErrEmptyAudio は、文字起こしする音声が無い場合に返されます。
+	ErrEmptyAudio = errors.New("transcribe: audio is required")
+	// ErrAudioTooLarge は、音声がインラインの上限を超えているのに、退避先の
+	// File API クライアントが無い場合に返されます。
+	ErrAudioTooLarge = errors.New("transcribe: audio is too large to send inline")
+	// ErrInvalidResponse は、モデル出力が期待する JSON として解釈できなかった場合に返されます。
+	// 再生成で解決することがあります。
+	ErrInvalidResponse = errors.New("transcribe: invalid model response")
+)
+
+// maxErrorPayload は、エラーメッセージに含めるモデル出力の最大バイト数です。
+const maxErrorPayload = 200
+
+// Client は文字起こしを扱うクライアントです。
+type Client struct {
+	generator      gemini.Generator
+	files          gemini.FileManager
+	windowSize     time.Duration
+	windowOverlap  time.Duration
+	maxInlineBytes int
+	logger         *slog.Logger
+}
+
+// New は、生成クライアントを注入して Client を初期化します。
+//
+// generator が gemini.FileManager も満たす場合（*gemini.Client がそうです）は、
+// 大きな音声の退避にもそれを使います。
+//
+//	gc, err := gemini.NewClient(ctx, cfg)
+//	tc, err := transcribe.New(gc)
+func New(generator gemini.Generator, opts ...Option) (*Client, error) {
+	if generator == nil {
+		return nil, ErrGeneratorRequired
+	}
+	c := &Client{
+		generator:      generator,
+		windowSize:     DefaultWindowSize,
+		windowOverlap:  DefaultWindowOverlap,
+		maxInlineBytes: DefaultMaxInlineBytes,
+		logger:         slog.Default(),
+	}
+	if files, ok := generator.(gemini.FileManager); ok {
+		c.files = files
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Transcribe は音声を文字起こしし、話者と時刻の付いた発話の列を返します。
+//
+// 音声が窓の長さより長い場合は、重なりを持つ窓に分けて順に送り、結果をつなぎ合わせます。
+// WAV は PCM を窓ごとに切り出して送ります。それ以外の形式は切り出せないため、
+// 全体を1度だけ送り先に置き（必要なら File API へアップロードし）、窓ごとに範囲を
+// 指示します。この場合、窓ごとに全体分の入力トークンがかかる点に注意してください。
+//
+// 窓は前から順に処理し、それまでに付いた話者ラベルを次の窓のプロンプトで伝えます。
+// 窓ごとに独立してラベルを付けると、同じ人が窓ごとに別の "Speaker 1" になるためです。
+// 1つの窓でも失敗した場合は、それまでの結果を捨ててエラーを返します。
+func (c *Client) Transcribe(ctx context.Context, modelName string, req Request) (*Transcript, error) {
+	if req.Audio.IsEmpty() {
+		return nil, ErrEmptyAudio
+	}
+	if err := req.Audio.ValidateFor(gemini.ModalityAudio); err != nil {
+		return nil, err
+	}
+
+	duration := req.Duration
+	format, pcm, isWAV := decodeWAV(req.Audio)
+	if isWAV {
+		duration = format.Duration(len(pcm))
+	}
+	windows := planWindows(duration, c.windowSize, c.windowOverlap)
+	sliced := isWAV && len(windows) > 1
+	ranged := !isWAV && len(windows) > 1
+
+	shared := req.Audio
+	if ranged {
+		staged, cleanup, err := c.stage(ctx, req.Audio)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		shared = staged
+	}
+
+	transcript := &Transcript{Duration: duration}
+	languages := newLanguageTally()
+	for i, w := range windows {
+		audio, offset := shared, time.Duration(0)
+		if sliced {
+			data, err := wav.Encode(pcm[format.Offset(w.start):format.Offset(w.end)], format)
+			if err != nil {
+				return nil, fmt.Errorf("音声の切り出しに失敗しました（窓 %d）: %w", i, err)
+			}
+			audio, offset = gemini.Attachment{MIMEType: "audio/wav", Data: data}, w.start
+		}
+
+		prompt := buildPrompt(req, w, ranged, transcript.Speakers())
+		result, usage, err := c.transcribeWindow(ctx, modelName, prompt, audio, !ranged)
+		if err != nil {
+			if len(windows) > 1 {
+				return nil, fmt.Errorf("文字起こしに失敗しました（窓 %d: %s〜%s）: %w", i, formatTimestamp(w.start), formatTimestamp(w.end), err)
+			}
+			return nil, err
+		}
+		segments, err := segmentsFrom(result, w, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, segment := range segments {
+			languages.add(segment.Language, segment.End-segment.Start)
+		}
+		languages.fallback(result.Language)
+		transcript.Segments = append(transcript.Segments, segments...)
+		transcript.Usage = addUsage(transcript.Usage, usage)
+	}
+	transcript.Segments = stitch(transcript.Segments)
+	transcript.Language = languages.main()
+	return transcript, nil
+}
+
+// decodeWAV は、添付がインラインの WAV であれば形式と PCM を返します。
+func decodeWAV(audio gemini.Attachment) (wav.Format, []byte, bool) {
+	if len(audio.Data) == 0 || gemini.DetectMIMEType(audio.Data) != "audio/wav" {
+		return wav.Format{}, nil, false
+	}
+	format, pcm, err := wav.Decode(audio.Data)
+	if err != nil || !format.Valid() {
+		return wav.Format{}, nil, false
+	}
+	return format, pcm, true
+}
+
+// transcribeWindow は1つの窓を送り、モデル出力を解釈して返します。
+// stage が true の場合は、送る前に必要なら File API へ退避し、送った後で削除します。
+func (c *Client) transcribeWindow(ctx context.Context, modelName, prompt string, audio gemini.Attachment, stage bool) (*transcriptResponse, *gemini.TokenUsage, error) {
+	if stage {
+		staged, cleanup, err := c.stage(ctx, audio)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer cleanup()
+		audio = staged
+	}
+
+	opts := gemini.GenerateOptions{
+		ResponseMIMEType: "application/json",
+		ResponseSchema:   transcriptSchema(),
+	}
+	resp, err := c.generator.GenerateWithAttachments(ctx, modelName, prompt, []gemini.Attachment{audio}, opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("文字起こしに失敗しました（モデル: %s）: %w", modelName, err)
+	}
+	if resp == nil {
+		return nil, nil, fmt.Errorf("%w: response is nil", ErrInvalidResponse)
+	}
+
+	raw := strings.TrimSpace(resp.Text)
+	if raw == "" {
+		return nil, nil, fmt.Errorf("%w: model returned an empty string", ErrInvalidResponse)
+	}
+	jsonStr := gemini.CleanJSONResponse(raw)
+	var out transcriptResponse
+	if err := json.Unmarshal([]byte(jsonStr), &out); err != nil {
+		return nil, nil, fmt.Errorf("%w: failed to unmarshal transcript json: %w (raw: %s)",
+			ErrInvalidResponse, err, truncateForError(jsonStr))
+	}
+	return &out, resp.Usage, nil
+}
+
+// stage は、インラインの上限を超える音声を File API へアップロードし、URI で参照する
+// 添付に置き換えます。返す関数はアップロードしたファイルを削除します（何もしていない
+// 場合は何もしません）。削除の失敗は結果に影響しないため、ログに残すだけにします。
+func (c *Client) stage(ctx context.Context, audio gemini.Attachment) (gemini.Attachment, func(), error) {
+	if len(audio.Data) <= c.maxInlineBytes {
+		return audio, func() {}, nil
+	}
+	if c.files == nil {
+		return gemini.Attachment{}, nil, fmt.Errorf("%w: %d バイト（上限 %d バイト）。WithFileManager を設定してください",
+			ErrAudioTooLarge, len(audio.Data), c.maxInlineBytes)
+	}
+	uploaded, err := c.files.UploadFile(ctx, bytes.NewReader(audio.Data), audio.MIMEType, "transcribe")
+	if err != nil {
+		return gemini.Attachment{}, nil, fmt.Errorf("音声のアップロードに失敗しました: %w", err)
+	}
+	cleanup := func() {
+		cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cleanupTimeout)
+		defer cancel()
+		if err := c.files.DeleteFile(cleanupCtx, uploaded.Name); err != nil {
+			c.logger.WarnContext(ctx, "アップロードした音声の削除に失敗しました", "file", uploaded.Name, "error", err)
+		}
+	}
+	return gemini.Attachment{MIMEType: audio.MIMEType, URI: uploaded.URI}, cleanup, nil
+}
+
+// buildPrompt は窓1つ分の指示を組み立てます。
+func buildPrompt(req Request, w window, ranged bool, labelled []string) string {
+	var b strings.Builder
+	b.WriteString(basePrompt)
+	if ranged {
+		fmt.Fprintf(&b, "\nOnly transcribe the audio between %s and %s. Measure timestamps from the start of the whole recording.",
+			formatTimestamp(w.start), formatTimestamp(w.end))
+	}
+	if len(req.Speakers) > 0 {
+		fmt.Fprintf(&b, "\nKnown speakers: %s. Use these names for the speakers you can identify.", strings.Join(req.Speakers, ", "))
+	}
+	if len(labelled) > 0 {
+		fmt.Fprintf(&b, "\nSpeakers already labelled earlier in this recording: %s. Reuse the same label when the same voice speaks again.", strings.Join(labelled, ", "))
+	}
+	if language := strings.TrimSpace(req.Language); language != "" {
+		fmt.Fprintf(&b, "\nThe main language is probably %s.", language)
+	}
+	if instructions := strings.TrimSpace(req.Instructions); instructions != "" {
+		b.WriteString("\n\n")
+		b.WriteString(instructions)
+	}
+	return b.String()
+}
+
+// segmentsFrom は、窓1つ分のモデル出力を録音全体の時刻の発話へ変換し、
+// この窓から採用するものだけを返します。offset は、送った音声の先頭が録音全体の
+// どの時刻に当たるかです。
+func segmentsFrom(result *transcriptResponse, w window, offset time.Duration) ([]Segment, error) {
+	var segments []Segment
+	for i, s := range result.Segments {
+		text := strings.TrimSpace(s.Text)
+		if text == "" {
+			continue
+		}
+		start, err := parseTimestamp(s.Start)
+		if err != nil {
+			return nil, fmt.Errorf("%w: segments[%d].start: %w", ErrInvalidResponse, i, err)
+		}
+		end, err := parseTimestamp(s.End)
+		if err != nil {
+			return nil, fmt.Errorf("%w: segments[%d].end: %w", ErrInvalidResponse, i, err)
+		}
+		start, end = start+offset, max(end, start)+offset
+		if !w.keeps(start) {
+			continue
+		}
+		language := strings.TrimSpace(s.Language)
+		if language == "" {
+			language = strings.TrimSpace(result.Language)
+		}
+		segments = append(segments, Segment{
+			Start:    start,
+			End:      end,
+			Speaker:  strings.TrimSpace(s.Speaker),
+			Text:     text,
+			Language: language,
+		})
+	}
+	return segments, nil
+}
+
+// languageTally は、言語ごとの発話時間を数えて主な言語を決めます。
+type languageTally struct {
+	order    []string
+	duration map[string]time.Duration
+	// declared は、発話ごとの言語が無い場合に使う、モデルが申告した全体の言語です。
+	declared string
+}
+
+func newLanguageTally() *languageTally {
+	return &languageTally{duration: make(map[string]time.Duration)}
+}
+
+func (t *languageTally) add(language string, d time.Duration) {
+	if language == "" {
+		return
+	}
+	if _, ok := t.duration[language]; !ok {
+		t.order = append(t.order, language)
+	}
+	// 長さ 0 の発話も「話された」ことには変わりないので、最小単位を数える。
+	t.duration[language] += max(d, time.Millisecond)
+}
+
+// fallback は、最初に申告された全体の言語を控えておきます。
+func (t *languageTally) fallback(language string) {
+	if t.declared == "" {
+		t.declared = strings.TrimSpace(language)
+	}
+}
+
+// main は、発話時間が最も長い言語を返します。同じ長さの場合は先に現れた方です。
+func (t *languageTally) main() string {
+	best := t.declared
+	var longest time.Duration
+	for _, language := range t.order {
+		if t.duration[language] > longest {
+			best, longest = language, t.duration[language]
+		}
+	}
+	return best
+}
+
+// addUsage はトークン使用量を合算します。どちらかが nil の場合はもう一方を返します。
+func addUsage(total, usage *gemini.TokenUsage) *gemini.TokenUsage {
+	if usage == nil {
+		return total
+	}
+	if total == nil {
+		sum := *usage
+		return &sum
+	}
+	total.PromptTokenCount += usage.PromptTokenCount
+	total.CandidatesTokenCount += usage.CandidatesTokenCount
+	total.TotalTokenCount += usage.TotalTokenCount
+	total.ThoughtsTokenCount += usage.ThoughtsTokenCount
+	return total
+}
+
+// truncateForError は、エラーメッセージに含めるモデル出力を先頭 maxErrorPayload バイトに
+// 切り詰めます。
+func truncateForError(s string) string {
+	if len(s) <= maxErrorPayload {
+		return s
+	}
+	return strings.ToValidUTF8(s[:maxErrorPayload], "") + "…(truncated)"
+}
@@ -0,0 +1,355 @@
+package transcribe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shouni/go-gemini-client/gemini"
+	"github.com/shouni/go-gemini-client/internal/wav"
+)
+
+// fakeGenerator は gemini.Generator のテストダブルです。
+type fakeGenerator struct {
+	calls []fakeCall
+	// respond は呼び出しごとの応答を決めます。index は 0 始まりの呼び出し番号です。
+	respond func(index int, call fakeCall) (*gemini.Response, error)
+}
+
+type fakeCall struct {
+	prompt      string
+	attachments []gemini.Attachment
+	opts        gemini.GenerateOptions
+}
+
+func (f *fakeGenerator) GenerateWithAttachments(_ context.Context, _ string, prompt string, attachments []gemini.Attachment, opts gemini.GenerateOptions) (*gemini.Response, error) {
+	call := fakeCall{prompt: prompt, attachments: attachments, opts: opts}
+	f.calls = append(f.calls, call)
+	return f.respond(len(f.calls)-1, call)
+}
+
+// fakeModel は File API も持つ生成クライアント（*gemini.Client 相当）です。
+type fakeModel struct {
+	fakeGenerator
+	uploaded [][]byte
+	deleted  []string
+}
+
+func (f *fakeModel) UploadFile(_ context.Context, r io.Reader, _, _ string) (gemini.UploadedFile, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return gemini.UploadedFile{}, err
+	}
+	f.uploaded = append(f.uploaded, data)
+	name := fmt.Sprintf("files/%d", len(f.uploaded))
+	return gemini.UploadedFile{URI: "https://example.test/" + name, Name: name}, nil
+}
+
+func (f *fakeModel) DeleteFile(_ context.Context, name string) error {
+	f.deleted = append(f.deleted, name)
+	return nil
+}
+
+// jsonResponse はモデル出力として transcriptResponse を JSON で返します。
+func jsonResponse(t *testing.T, resp transcriptResponse) *gemini.Response {
+	t.Helper()
+	data, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &gemini.Response{Text: string(data), Usage: &gemini.TokenUsage{TotalTokenCount: 10}}
+}
+
+// mp3 は MIME type の検証を通る最小の MP3 です。
+func mp3(size int) gemini.Attachment {
+	data := make([]byte, size)
+	copy(data, "ID3")
+	return gemini.Attachment{MIMEType: "audio/mpeg", Data: data}
+}
+
+// wavOf は長さ d の無音の WAV（8kHz・16bit・モノラル）を作ります。
+func wavOf(t *testing.T, d time.Duration) gemini.Attachment {
+	t.Helper()
+	format := wav.Format{SampleRate: 8000, Channels: 1, BitsPerSample: 16}
+	data, err := wav.Encode(make([]byte, format.Offset(d)), format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gemini.Attachment{MIMEType: "audio/wav", Data: data}
+}
+
+func newTestClient(t *testing.T, generator gemini.Generator, opts ...Option) *Client {
+	t.Helper()
+	c, err := New(generator, opts...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return c
+}
+
+func TestNewRequiresGenerator(t *testing.T) {
+	if _, err := New(nil); !errors.Is(err, ErrGeneratorRequired) {
+		t.Fatalf("New(nil) error = %v, want ErrGeneratorRequired", err)
+	}
+}
+
+func TestTranscribe_SingleRequest(t *testing.T) {
+	gen := &fakeGenerator{respond: func(int, fakeCall) (*gemini.Response, error) {
+		return jsonResponse(t, transcriptResponse{
+			Language: "ja",
+			Segments: []segmentResponse{
+				{Start: "00:00.000", End: "00:03.500", Speaker: "田中", Text: "おはようございます。"},
+				{Start: "00:04", End: "00:06", Speaker: "Speaker 2", Text: "Good morning.", Language: "en"},
+				{Start: "00:07", End: "00:07", Speaker: "田中", Text: "  "},
+			},
+		}), nil
+	}}
+	c := newTestClient(t, gen)
+
+	got, err := c.Transcribe(context.Background(), "m", Request{
+		Audio:        mp3(64),
+		Speakers:     []string{"田中"},
+		Language:     "ja",
+		Instructions: "社名は「シャウニ」と表記してください。",
+	})
+	if err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+
+	call := gen.calls[0]
+	if call.opts.ResponseMIMEType != "application/json" || call.opts.ResponseSchema == nil {
+		t.Errorf("opts = %+v, want structured output", call.opts)
+	}
+	for _, want := range []string{"Known speakers: 田中.", "probably ja", "シャウニ"} {
+		if !strings.Contains(call.prompt, want) {
+			t.Errorf("prompt does not contain %q:\n%s", want, call.prompt)
+		}
+	}
+	if strings.Contains(call.prompt, "Only transcribe") {
+		t.Error("single request should not restrict the range")
+	}
+	if len(call.attachments) != 1 || len(call.attachments[0].Data) != 64 {
+		t.Errorf("attachments = %+v, want the audio inline", call.attachments)
+	}
+
+	want := []Segment{
+		{Start: 0, End: 3500 * time.Millisecond, Speaker: "田中", Text: "おはようございます。", Language: "ja"},
+		{Start: 4 * time.Second, End: 6 * time.Second, Speaker: "Speaker 2", Text: "Good morning.", Language: "en"},
+	}
+	if len(got.Segments) != len(want) {
+		t.Fatalf("Segments = %+v, want %+v", got.Segments, want)
+	}
+	for i := range want {
+		if got.Segments[i] != want[i] {
+			t.Errorf("Segments[%d] = %+v, want %+v", i, got.Segments[i], want[i])
+		}
+	}
+	if got.Language != "ja" {
+		t.Errorf("Language = %q, want ja", got.Language)
+	}
+	if got.Text() != "田中: おはようございます。\nSpeaker 2: Good morning." {
+		t.Errorf("Text() = %q", got.Text())
+	}
+	if got.Usage == nil || got.Usage.TotalTokenCount != 10 {
+		t.Errorf("Usage = %+v", got.Usage)
+	}
+}
+
+func TestTranscribe_MainLanguageByDuration(t *testing.T) {
+	gen := &fakeGenerator{respond: func(int, fakeCall) (*gemini.Response, error) {
+		return jsonResponse(t, transcriptResponse{
+			Language: "en",
+			Segments: []segmentResponse{
+				{Start: "0", End: "2", Speaker: "A", Text: "hi", Language: "en"},
+				{Start: "2", End: "10", Speaker: "B", Text: "こんにちは", Language: "ja"},
+			},
+		}), nil
+	}}
+	got, err := newTestClient(t, gen).Transcribe(context.Background(), "m", Request{Audio: mp3(8)})
+	if err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+	if got.Language != "ja" {
+		t.Errorf("Language = %q, want ja (longest speech)", got.Language)
+	}
+}
+
+func TestTranscribe_OffloadsLargeAudio(t *testing.T) {
+	model := &fakeModel{}
+	model.respond = func(int, fakeCall) (*gemini.Response, error) {
+		return jsonResponse(t, transcriptResponse{Segments: []segmentResponse{{Start: "0", End: "1", Speaker: "A", Text: "x"}}}), nil
+	}
+	c := newTestClient(t, model, WithMaxInlineBytes(32))
+
+	if _, err := c.Transcribe(context.Background(), "m", Request{Audio: mp3(64)}); err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+	if len(model.uploaded) != 1 || len(model.uploaded[0]) != 64 {
+		t.Fatalf("uploaded = %d files, want the audio once", len(model.uploaded))
+	}
+	sent := model.calls[0].attachments[0]
+	if sent.URI != "https://example.test/files/1" || len(sent.Data) != 0 || sent.MIMEType != "audio/mpeg" {
+		t.Errorf("sent attachment = %+v, want URI reference", sent)
+	}
+	if len(model.deleted) != 1 || model.deleted[0] != "files/1" {
+		t.Errorf("deleted = %v, want uploaded file removed", model.deleted)
+	}
+}
+
+func TestTranscribe_TooLargeWithoutFileManager(t *testing.T) {
+	gen := &fakeGenerator{}
+	c := newTestClient(t, gen, WithMaxInlineBytes(32))
+	if _, err := c.Transcribe(context.Background(), "m", Request{Audio: mp3(64)}); !errors.Is(err, ErrAudioTooLarge) {
+		t.Fatalf("error = %v, want ErrAudioTooLarge", err)
+	}
+	if len(gen.calls) != 0 {
+		t.Error("generator should not be called")
+	}
+}
+
+// TestTranscribe_SlicesLongWAV verifies that a long WAV is cut into overlapping windows, that
+// window-relative timestamps are shifted back onto the recording, and that utterances in the
+// overlaps are taken from exactly one window.
+func TestTranscribe_SlicesLongWAV(t *testing.T) {
+	offsets := []time.Duration{0, 8 * time.Second, 16 * time.Second}
+	gen := &fakeGenerator{}
+	gen.respond = func(index int, call fakeCall) (*gemini.Response, error) {
+		format, pcm, err := wav.Decode(call.attachments[0].Data)
+		if err != nil {
+			t.Fatalf("window %d is not WAV: %v", index, err)
+		}
+		// 窓の先頭から2秒ごとに、録音全体での秒数を本文にした発話を返す。
+		var segments []segmentResponse
+		for rel := time.Duration(0); rel < format.Duration(len(pcm)); rel += 2 * time.Second {
+			abs := offsets[index] + rel
+			segments = append(segments, segmentResponse{
+				Start:   fmt.Sprintf("00:%02d", int(rel.Seconds())),
+				End:     fmt.Sprintf("00:%02d", int(rel.Seconds())+1),
+				Speaker: "Speaker 1",
+				Text:    fmt.Sprint(int(abs.Seconds())),
+			})
+		}
+		return jsonResponse(t, transcriptResponse{Language: "ja", Segments: segments}), nil
+	}
+	c := newTestClient(t, gen, WithWindow(10*time.Second, 2*time.Second))
+
+	got, err := c.Transcribe(context.Background(), "m", Request{Audio: wavOf(t, 25*time.Second)})
+	if err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+
+	if len(gen.calls) != 3 {
+		t.Fatalf("calls = %d, want 3 windows", len(gen.calls))
+	}
+	wantLengths := []time.Duration{10 * time.Second, 10 * time.Second, 9 * time.Second}
+	for i, call := range gen.calls {
+		format, pcm, _ := wav.Decode(call.attachments[0].Data)
+		if d := format.Duration(len(pcm)); d != wantLengths[i] {
+			t.Errorf("window %d length = %v, want %v", i, d, wantLengths[i])
+		}
+	}
+	if !strings.Contains(gen.calls[1].prompt, "already labelled earlier in this recording: Speaker 1.") {
+		t.Errorf("second window should carry the speaker labels:\n%s", gen.calls[1].prompt)
+	}
+
+	var texts []string
+	for i, segment := range got.Segments {
+		texts = append(texts, segment.Text)
+		if want := time.Duration(i*2) * time.Second; segment.Start != want {
+			t.Errorf("Segments[%d].Start = %v, want %v", i, segment.Start, want)
+		}
+	}
+	if want := "0 2 4 6 8 10 12 14 16 18 20 22 24"; strings.Join(texts, " ") != want {
+		t.Errorf("stitched = %q, want %q", strings.Join(texts, " "), want)
+	}
+	if got.Duration != 25*time.Second {
+		t.Errorf("Duration = %v, want 25s", got.Duration)
+	}
+	if got.Usage.TotalTokenCount != 30 {
+		t.Errorf("Usage.TotalTokenCount = %d, want 30 (summed)", got.Usage.TotalTokenCount)
+	}
+}
+
+// TestTranscribe_RangesNonWAV verifies that formats that cannot be sliced are uploaded once and
+// each window is selected through the prompt with absolute timestamps.
+func TestTranscribe_RangesNonWAV(t *testing.T) {
+	model := &fakeModel{}
+	model.respond = func(index int, _ fakeCall) (*gemini.Response, error) {
+		start := []string{"00:01", "00:09.5", "00:20"}[index]
+		return jsonResponse(t, transcriptResponse{Segments: []segmentResponse{
+			{Start: start, End: start, Speaker: "A", Text: start},
+			// 重なりの前半に当たる発話は前の窓が採るので捨てられる。
+			{Start: "00:08.5", End: "00:09", Speaker: "A", Text: "dup"},
+		}}), nil
+	}
+	c := newTestClient(t, model, WithWindow(10*time.Second, 2*time.Second), WithMaxInlineBytes(32))
+
+	got, err := c.Transcribe(context.Background(), "m", Request{Audio: mp3(64), Duration: 25 * time.Second})
+	if err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+
+	if len(model.uploaded) != 1 || len(model.deleted) != 1 {
+		t.Errorf("uploaded %d, deleted %d; want one shared upload", len(model.uploaded), len(model.deleted))
+	}
+	for i, call := range model.calls {
+		if call.attachments[0].URI != "https://example.test/files/1" {
+			t.Errorf("window %d attachment = %+v, want shared upload", i, call.attachments[0])
+		}
+	}
+	if !strings.Contains(model.calls[1].prompt, "between 00:00:08 and 00:00:18") {
+		t.Errorf("second window prompt lacks range:\n%s", model.calls[1].prompt)
+	}
+
+	var texts []string
+	for _, segment := range got.Segments {
+		texts = append(texts, segment.Text)
+	}
+	if want := "00:01 dup 00:09.5 00:20"; strings.Join(texts, " ") != want {
+		t.Errorf("stitched = %q, want %q", strings.Join(texts, " "), want)
+	}
+}
+
+func TestTranscribe_InvalidResponses(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"empty", ""},
+		{"not json", "sorry, I cannot"},
+		{"bad timestamp", `{"segments":[{"start":"soon","end":"1","speaker":"A","text":"x"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := &fakeGenerator{respond: func(int, fakeCall) (*gemini.Response, error) {
+				return &gemini.Response{Text: tt.text}, nil
+			}}
+			_, err := newTestClient(t, gen).Transcribe(context.Background(), "m", Request{Audio: mp3(8)})
+			if !errors.Is(err, ErrInvalidResponse) {
+				t.Fatalf("error = %v, want ErrInvalidResponse", err)
+			}
+		})
+	}
+}
+
+func TestTranscribe_InputErrors(t *testing.T) {
+	gen := &fakeGenerator{}
+	c := newTestClient(t, gen)
+
+	if _, err := c.Transcribe(context.Background(), "m", Request{}); !errors.Is(err, ErrEmptyAudio) {
+		t.Errorf("empty audio error = %v, want ErrEmptyAudio", err)
+	}
+	image := gemini.Attachment{MIMEType: "image/png", Data: bytes.Repeat([]byte{1}, 4)}
+	if _, err := c.Transcribe(context.Background(), "m", Request{Audio: image}); !errors.Is(err, gemini.ErrUnsupportedMIMEType) {
+		t.Errorf("image input error = %v, want ErrUnsupportedMIMEType", err)
+	}
+	if len(gen.calls) != 0 {
+		t.Error("generator should not be called for invalid input")
+	}
+}
@@ -0,0 +1,81 @@
+package transcribe
+
+import (
+	"strings"
+	"time"
+
+	"github.com/shouni/go-gemini-client/gemini"
+)
+
+// Request は文字起こし1回分の入力です。
+type Request struct {
+	// Audio は文字起こしする音声です。Data（インライン）と URI（File API / gs://）の
+	// どちらでも構いません。大きな Data は送信前に File API へアップロードします。
+	Audio gemini.Attachment
+	// Duration は音声の長さです。WAV の Data はヘッダから求めるため省略できます。
+	// それ以外の形式で省略した場合は長さが分からないため、窓に分割せず1回で送ります。
+	Duration time.Duration
+	// Speakers は既知の話者名です（任意）。声から判別できた場合はこの名前で
+	// ラベル付けするようモデルに伝えます。
+	Speakers []string
+	// Language は主な言語のヒントです（"ja" など。任意）。
+	Language string
+	// Instructions はプロンプトに付け足す追加の指示です（用語集・表記ルールなど。任意）。
+	Instructions string
+}
+
+// Segment は、1人の話者による連続した発話1つです。
+type Segment struct {
+	// Start と End は、録音全体の先頭からの時刻です。
+	Start time.Duration
+	End   time.Duration
+	// Speaker は話者ラベルです。既知の話者名か "Speaker 1" のような連番です。
+	Speaker string
+	// Text は発話の本文です。
+	Text string
+	// Language はこの発話の言語コード（BCP 47）です。判定できなかった場合は空です。
+	Language string
+}
+
+// Transcript は、窓ごとの結果をつなぎ合わせた録音全体の文字起こしです。
+type Transcript struct {
+	// Segments は時刻順に並んだ発話です。
+	Segments []Segment
+	// Language は録音全体の主な言語です。発話時間が最も長い言語を採ります。
+	Language string
+	// Duration は音声の長さです。分からなかった場合は 0 です。
+	Duration time.Duration
+	// Usage は全窓分を合算したトークン使用量です。モデルが返さなかった場合は nil です。
+	Usage *gemini.TokenUsage
+}
+
+// Text は、発話を "話者: 本文" の行にして連結した文字列を返します。
+// 同じ話者の発話が続く場合も行は分けます。
+func (t *Transcript) Text() string {
+	var b strings.Builder
+	for i, segment := range t.Segments {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		if segment.Speaker != "" {
+			b.WriteString(segment.Speaker)
+			b.WriteString(": ")
+		}
+		b.WriteString(segment.Text)
+	}
+	return b.String()
+}
+
+// Speakers は、登場した話者ラベルを登場順に重複なく返します。
+func (t *Transcript) Speakers() []string {
+	var speakers []string
+	seen := make(map[string]bool)
+	for _, segment := range t.Segments {
+		if segment.Speaker == "" || seen[segment.Speaker] {
+			continue
+		}
+		seen[segment.Speaker] = true
+		speakers = append(speakers, segment.Speaker)
+	}
+	return speakers
+}
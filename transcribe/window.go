@@ -0,0 +1,93 @@
+package transcribe
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// window は、長い録音を分割して送る1区間です。
+type window struct {
+	// start と end は録音全体での範囲です。
+	start, end time.Duration
+	// keepFrom と keepTo は、この窓の結果から採用する発話の開始時刻の範囲です。
+	//
+	// 隣り合う窓は overlap だけ重なっていて、重なりの中の発話は両方の窓に現れます。
+	// 重なりの中点を境にどちらか一方だけを採ることで、重複も欠落も無くつなげます。
+	// 中点で分けるのは、窓の端ほど発話が途中で切れて精度が落ちるためです。
+	keepFrom, keepTo time.Duration
+}
+
+// planWindows は、長さ total の録音を size ごとの窓に分けます。
+// total が分からない（0）か size 以下の場合は、全体を1つの窓にします。
+func planWindows(total, size, overlap time.Duration) []window {
+	if total <= size {
+		return []window{{start: 0, end: total, keepFrom: 0, keepTo: math.MaxInt64}}
+	}
+	var windows []window
+	step := size - overlap
+	for start := time.Duration(0); ; start += step {
+		end := min(start+size, total)
+		w := window{start: start, end: end, keepFrom: start + overlap/2, keepTo: end - overlap/2}
+		if start == 0 {
+			w.keepFrom = 0
+		}
+		if end >= total {
+			w.keepTo = math.MaxInt64
+			return append(windows, w)
+		}
+		windows = append(windows, w)
+	}
+}
+
+// keeps は、開始時刻 start の発話をこの窓の結果から採用するかを返します。
+func (w window) keeps(start time.Duration) bool {
+	return start >= w.keepFrom && start < w.keepTo
+}
+
+// stitch は、窓ごとに採用した発話を時刻順に並べます。
+// 同時刻の発話は窓の順（＝元の並び）を保ちます。
+func stitch(segments []Segment) []Segment {
+	slices.SortStableFunc(segments, func(a, b Segment) int {
+		return cmp.Compare(a.Start, b.Start)
+	})
+	return segments
+}
+
+// errInvalidTimestamp は、モデルが返した時刻表記を解釈できない場合の内部エラーです。
+// 呼び出し側へは ErrInvalidResponse として包んで返します。
+var errInvalidTimestamp = errors.New("invalid timestamp")
+
+// parseTimestamp は、"SS"、"MM:SS"、"HH:MM:SS" の形の時刻を読みます。
+// 秒には小数（"05.250"、字幕形式の "05,250"）を付けられます。
+func parseTimestamp(s string) (time.Duration, error) {
+	s = strings.TrimSpace(strings.ReplaceAll(s, ",", "."))
+	parts := strings.Split(s, ":")
+	if s == "" || len(parts) > 3 {
+		return 0, fmt.Errorf("%w: %q", errInvalidTimestamp, s)
+	}
+	var seconds float64
+	for i, part := range parts {
+		// 小数を持てるのは最後の秒の欄だけ。
+		if i < len(parts)-1 && strings.Contains(part, ".") {
+			return 0, fmt.Errorf("%w: %q", errInvalidTimestamp, s)
+		}
+		value, err := strconv.ParseFloat(part, 64)
+		if err != nil || value < 0 || math.IsInf(value, 0) || math.IsNaN(value) {
+			return 0, fmt.Errorf("%w: %q", errInvalidTimestamp, s)
+		}
+		seconds = seconds*60 + value
+	}
+	return time.Duration(seconds * float64(time.Second)).Round(time.Millisecond), nil
+}
+
+// formatTimestamp は、プロンプトで範囲を伝えるための "HH:MM:SS" 表記を返します。
+func formatTimestamp(d time.Duration) string {
+	total := int(d.Round(time.Second) / time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", total/3600, total/60%60, total%60)
+}
@@ -0,0 +1,86 @@
+package transcribe
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPlanWindows(t *testing.T) {
+	const s = time.Second
+	tests := []struct {
+		name  string
+		total time.Duration
+		want  []window
+	}{
+		{"unknown length", 0, []window{{0, 0, 0, math.MaxInt64}}},
+		{"fits in one window", 10 * s, []window{{0, 10 * s, 0, math.MaxInt64}}},
+		{"three windows", 25 * s, []window{
+			{0, 10 * s, 0, 9 * s},
+			{8 * s, 18 * s, 9 * s, 17 * s},
+			{16 * s, 25 * s, 17 * s, math.MaxInt64},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := planWindows(tt.total, 10*s, 2*s)
+			if len(got) != len(tt.want) {
+				t.Fatalf("planWindows() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("window[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// TestPlanWindowsKeepRangesTile verifies that the keep ranges cover the whole recording without
+// gaps or overlaps, so every utterance is taken from exactly one window.
+func TestPlanWindowsKeepRangesTile(t *testing.T) {
+	windows := planWindows(95*time.Minute, DefaultWindowSize, DefaultWindowOverlap)
+	if windows[0].keepFrom != 0 || windows[len(windows)-1].keepTo != math.MaxInt64 {
+		t.Fatalf("keep ranges do not reach both ends: %+v", windows)
+	}
+	for i := 1; i < len(windows); i++ {
+		if windows[i].keepFrom != windows[i-1].keepTo {
+			t.Errorf("gap between window %d and %d: %v != %v", i-1, i, windows[i-1].keepTo, windows[i].keepFrom)
+		}
+		if windows[i].start >= windows[i-1].end {
+			t.Errorf("windows %d and %d do not overlap", i-1, i)
+		}
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"5", 5 * time.Second},
+		{"05.250", 5250 * time.Millisecond},
+		{"01:05", 65 * time.Second},
+		{"01:05,5", 65500 * time.Millisecond},
+		{" 1:02:03.004 ", time.Hour + 2*time.Minute + 3004*time.Millisecond},
+	}
+	for _, tt := range tests {
+		got, err := parseTimestamp(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("parseTimestamp(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "abc", "1:2:3:4", "1.5:00", "-3", "NaN"} {
+		if _, err := parseTimestamp(in); !errors.Is(err, errInvalidTimestamp) {
+			t.Errorf("parseTimestamp(%q) error = %v, want errInvalidTimestamp", in, err)
+		}
+	}
+}
+
+func TestFormatTimestamp(t *testing.T) {
+	if got := formatTimestamp(time.Hour + 2*time.Minute + 3400*time.Millisecond); got != "01:02:03" {
+		t.Errorf("formatTimestamp() = %q, want 01:02:03", got)
+	}
+}
@@ -0,0 +1,34 @@
+package tts
+
+// Option は Client の設定を適用する関数型です。
+// 不正な値（空文字列・ゼロ以下）は「指定なし」として無視し、既定値のままにします。
+type Option func(*Client)
+
+// WithVoice は、Request.Voice を省略したときに使うプリセット音声の名前を設定します。
+// 未設定の場合はモデルのデフォルトの声になります。
+func WithVoice(name string) Option {
+	return func(c *Client) {
+		if name != "" {
+			c.voice = name
+		}
+	}
+}
+
+// WithLanguageCode は、読み上げる言語のコードの既定値を設定します（"ja-JP" など）。
+func WithLanguageCode(code string) Option {
+	return func(c *Client) {
+		if code != "" {
+			c.languageCode = code
+		}
+	}
+}
+
+// WithSampleRate は、モデルが返す PCM の MIME type にサンプリング周波数が
+// 含まれていない場合に仮定する値を設定します。既定は DefaultSampleRate です。
+func WithSampleRate(hz int) Option {
+	return func(c *Client) {
+		if hz > 0 {
+			c.sampleRate = hz
+		}
+	}
+}
@@ -0,0 +1,246 @@
+// Package tts は、Gemini の音声合成（TTS）モデルによる読み上げを扱うクライアントを
+// 提供します。
+//
+// gemini パッケージが持つのは「声の設定を付けて生成し、音声のバイト列を受け取る」
+// 1往復で、読み上げとしての扱い——単一話者と複数話者の台本の組み立て、話者と声の
+// 対応の検証、モデルが返す生の PCM を再生可能な WAV に包む処理——を
+// このパッケージが受け持ちます。
+//
+// 依存は gemini.Generator の注入だけで、genai SDK には触れません。
+package tts
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/shouni/go-gemini-client/gemini"
+	"github.com/shouni/go-gemini-client/internal/wav"
+)
+
+// DefaultSampleRate は、MIME type にサンプリング周波数が無い場合に仮定する値（Hz）です。
+// Gemini の TTS モデルは 24kHz・16bit・モノラルの PCM を返します。
+const DefaultSampleRate = 24000
+
+// maxSpeakers は、複数話者の音声合成で API が受け付ける話者の上限です。
+const maxSpeakers = 2
+
+// 入力・結果に関するセンチネルエラーです。
+var (
+	// ErrGeneratorRequired は、New に nil の生成クライアントが渡された場合に返されます。
+	ErrGeneratorRequired = errors.New("tts: generator is required")
+	// ErrEmptyText は、読み上げる本文（または台本の行）が無い場合に返されます。
+	ErrEmptyText = errors.New("tts: text is required")
+	// ErrInvalidScript は、対話台本が API の制約を満たさない場合に返されます
+	// （話者名が空・声の割り当てが無い・話者が3人以上など）。
+	ErrInvalidScript = errors.New("tts: invalid dialogue script")
+	// ErrNoAudio は、呼び出しは成功したのに音声が返らなかった場合を示します。
+	ErrNoAudio = errors.New("tts: model returned no audio")
+	// ErrUnsupportedFormat は、モデルが WAV に包めない形式の音声を返した場合を示します。
+	ErrUnsupportedFormat = errors.New("tts: unsupported audio format")
+)
+
+// Client は音声合成を扱うクライアントです。
+type Client struct {
+	generator    gemini.Generator
+	voice        string
+	languageCode string
+	sampleRate   int
+}
+
+// New は、生成クライアントを注入して Client を初期化します。
+//
+// generator には *gemini.Client をそのまま渡せます。
+//
+//	gc, err := gemini.NewClient(ctx, cfg)
+//	tc, err := tts.New(gc, tts.WithVoice("Kore"), tts.WithLanguageCode("ja-JP"))
+func New(generator gemini.Generator, opts ...Option) (*Client, error) {
+	if generator == nil {
+		return nil, ErrGeneratorRequired
+	}
+	c := &Client{
+		generator:  generator,
+		sampleRate: DefaultSampleRate,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Synthesize は本文を単一話者で読み上げ、WAV に包んで返します。
+func (c *Client) Synthesize(ctx context.Context, modelName string, req Request) (*Audio, error) {
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return nil, ErrEmptyText
+	}
+	prompt := text
+	if style := strings.TrimSpace(req.Style); style != "" {
+		prompt = style + ": " + text
+	}
+	speech := &gemini.SpeechOptions{
+		Voice:        cmp.Or(strings.TrimSpace(req.Voice), c.voice),
+		LanguageCode: cmp.Or(strings.TrimSpace(req.LanguageCode), c.languageCode),
+	}
+	return c.synthesize(ctx, modelName, prompt, speech)
+}
+
+// SynthesizeDialogue は対話台本を話者ごとの声で読み上げ、1本の WAV に包んで返します。
+//
+// 台本は "話者名: セリフ" の行に組み立てて送ります。モデルはこの話者名を手掛かりに
+// 声を切り替えるため、話者名に改行やコロンは使えません。台本の話者が1人だけの場合は
+// 単一話者の設定で送ります（複数話者の設定は2人ちょうどが必要なため）。
+func (c *Client) SynthesizeDialogue(ctx context.Context, modelName string, dialogue Dialogue) (*Audio, error) {
+	speakers, err := dialogueSpeakers(dialogue)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	if style := strings.TrimSpace(dialogue.Style); style != "" {
+		b.WriteString(style)
+	} else {
+		fmt.Fprintf(&b, "TTS the following conversation between %s:", strings.Join(speakers, " and "))
+	}
+	for _, line := range dialogue.Lines {
+		if text := strings.TrimSpace(line.Text); text != "" {
+			fmt.Fprintf(&b, "\n%s: %s", strings.TrimSpace(line.Speaker), text)
+		}
+	}
+
+	speech := &gemini.SpeechOptions{LanguageCode: cmp.Or(strings.TrimSpace(dialogue.LanguageCode), c.languageCode)}
+	if len(speakers) == 1 {
+		speech.Voice = dialogue.Voices[speakers[0]]
+	} else {
+		for _, speaker := range speakers {
+			speech.Speakers = append(speech.Speakers, gemini.SpeakerVoice{Speaker: speaker, Voice: dialogue.Voices[speaker]})
+		}
+	}
+	return c.synthesize(ctx, modelName, b.String(), speech)
+}
+
+// dialogueSpeakers は台本を検証し、登場順に重複を除いた話者名を返します。
+// 本文が空の行は読み上げないため、話者の数にも数えません。
+func dialogueSpeakers(dialogue Dialogue) ([]string, error) {
+	var speakers []string
+	for i, line := range dialogue.Lines {
+		if strings.TrimSpace(line.Text) == "" {
+			continue
+		}
+		speaker := strings.TrimSpace(line.Speaker)
+		if speaker == "" || strings.ContainsAny(speaker, ":\n") {
+			return nil, fmt.Errorf("%w: Lines[%d] の話者名 %q は使えません", ErrInvalidScript, i, line.Speaker)
+		}
+		if slices.Contains(speakers, speaker) {
+			continue
+		}
+		if strings.TrimSpace(dialogue.Voices[speaker]) == "" {
+			return nil, fmt.Errorf("%w: 話者 %q に声が割り当てられていません", ErrInvalidScript, speaker)
+		}
+		speakers = append(speakers, speaker)
+	}
+	if len(speakers) == 0 {
+		return nil, ErrEmptyText
+	}
+	if len(speakers) > maxSpeakers {
+		return nil, fmt.Errorf("%w: 話者は %d 人までです（%d 人: %s）", ErrInvalidScript, maxSpeakers, len(speakers), strings.Join(speakers, ", "))
+	}
+	return speakers, nil
+}
+
+// synthesize は生成を1回呼び出し、返った音声を WAV に包みます。
+func (c *Client) synthesize(ctx context.Context, modelName, prompt string, speech *gemini.SpeechOptions) (*Audio, error) {
+	resp, err := c.generator.GenerateWithAttachments(ctx, modelName, prompt, nil, gemini.GenerateOptions{Speech: speech})
+	if err != nil {
+		return nil, fmt.Errorf("音声合成に失敗しました（モデル: %s）: %w", modelName, err)
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("音声合成に失敗しました（モデル: %s）: %w", modelName, gemini.ErrEmptyResponse)
+	}
+	audio, err := c.packageAudio(resp.Attachments)
+	if err != nil {
+		return nil, fmt.Errorf("音声合成の結果を処理できません（モデル: %s）: %w", modelName, err)
+	}
+	audio.Usage = resp.Usage
+	return audio, nil
+}
+
+// packageAudio は、レスポンスの音声を1本の WAV にまとめます。
+//
+// モデルは長い読み上げを複数のパートに分けて返すことがあるため、PCM のパートは
+// 順に連結します。形式の異なるパートを連結すると雑音になるので、その場合はエラーです。
+// 既に WAV で返った場合はヘッダから形式を読み、そのまま使います。
+func (c *Client) packageAudio(attachments []gemini.Attachment) (*Audio, error) {
+	var (
+		pcm    []byte
+		format wav.Format
+		found  bool
+	)
+	for _, attachment := range attachments {
+		if len(attachment.Data) == 0 || !strings.HasPrefix(strings.ToLower(attachment.MIMEType), "audio/") {
+			continue
+		}
+		partFormat, partPCM, err := c.decodePart(attachment)
+		if err != nil {
+			return nil, err
+		}
+		if found && partFormat != format {
+			return nil, fmt.Errorf("%w: パートごとに形式が異なります（%+v と %+v）", ErrUnsupportedFormat, format, partFormat)
+		}
+		format, found = partFormat, true
+		pcm = append(pcm, partPCM...)
+	}
+	if !found {
+		return nil, ErrNoAudio
+	}
+
+	data, err := wav.Encode(pcm, format)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnsupportedFormat, err)
+	}
+	// Encode はフレーム境界に切り詰めるため、PCM は包んだ後のデータから取り直す。
+	_, pcm, err = wav.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnsupportedFormat, err)
+	}
+	return &Audio{
+		MIMEType:      "audio/wav",
+		Data:          data,
+		PCM:           pcm,
+		SampleRate:    format.SampleRate,
+		Channels:      format.Channels,
+		BitsPerSample: format.BitsPerSample,
+		Duration:      format.Duration(len(pcm)),
+	}, nil
+}
+
+// decodePart は音声パート1つの形式と PCM 本体を返します。
+func (c *Client) decodePart(attachment gemini.Attachment) (wav.Format, []byte, error) {
+	mediaType, params, err := mime.ParseMediaType(attachment.MIMEType)
+	if err != nil {
+		return wav.Format{}, nil, fmt.Errorf("%w: MIME type %q を解釈できません: %w", ErrUnsupportedFormat, attachment.MIMEType, err)
+	}
+	switch mediaType {
+	case "audio/wav", "audio/x-wav", "audio/wave", "audio/vnd.wave":
+		format, pcm, err := wav.Decode(attachment.Data)
+		if err != nil {
+			return wav.Format{}, nil, fmt.Errorf("%w: %w", ErrUnsupportedFormat, err)
+		}
+		return format, pcm, nil
+	case "audio/l16", "audio/pcm":
+		format := wav.Format{SampleRate: c.sampleRate, Channels: 1, BitsPerSample: 16}
+		if rate, err := strconv.Atoi(params["rate"]); err == nil && rate > 0 {
+			format.SampleRate = rate
+		}
+		if channels, err := strconv.Atoi(params["channels"]); err == nil && channels > 0 {
+			format.Channels = channels
+		}
+		return format, attachment.Data, nil
+	}
+	return wav.Format{}, nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, attachment.MIMEType)
+}
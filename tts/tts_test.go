@@ -0,0 +1,296 @@
+package tts
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shouni/go-gemini-client/gemini"
+	"github.com/shouni/go-gemini-client/internal/wav"
+)
+
+// fakeGenerator は gemini.Generator のテストダブルです。
+type fakeGenerator struct {
+	calls []fakeCall
+	// respond は応答を決めます。nil の場合は 24kHz の PCM を 0.5 秒分返します。
+	respond func(call fakeCall) (*gemini.Response, error)
+}
+
+type fakeCall struct {
+	model  string
+	prompt string
+	opts   gemini.GenerateOptions
+}
+
+func (f *fakeGenerator) GenerateWithAttachments(_ context.Context, modelName string, prompt string, _ []gemini.Attachment, opts gemini.GenerateOptions) (*gemini.Response, error) {
+	call := fakeCall{model: modelName, prompt: prompt, opts: opts}
+	f.calls = append(f.calls, call)
+	if f.respond != nil {
+		return f.respond(call)
+	}
+	return &gemini.Response{
+		Attachments: []gemini.Attachment{{MIMEType: "audio/L16;codec=pcm;rate=24000", Data: make([]byte, 24000)}},
+		Usage:       &gemini.TokenUsage{TotalTokenCount: 7},
+	}, nil
+}
+
+func newTestClient(t *testing.T, generator gemini.Generator, opts ...Option) *Client {
+	t.Helper()
+	c, err := New(generator, opts...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return c
+}
+
+func TestNewRequiresGenerator(t *testing.T) {
+	if _, err := New(nil); !errors.Is(err, ErrGeneratorRequired) {
+		t.Fatalf("New(nil) error = %v, want ErrGeneratorRequired", err)
+	}
+}
+
+func TestSynthesize_WrapsPCMInWAV(t *testing.T) {
+	gen := &fakeGenerator{}
+	c := newTestClient(t, gen, WithVoice("Kore"), WithLanguageCode("ja-JP"))
+
+	audio, err := c.Synthesize(context.Background(), "tts-model", Request{Text: "こんにちは", Style: "Say cheerfully"})
+	if err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+
+	call := gen.calls[0]
+	if call.prompt != "Say cheerfully: こんにちは" {
+		t.Errorf("prompt = %q", call.prompt)
+	}
+	if got := call.opts.Speech; got == nil || got.Voice != "Kore" || got.LanguageCode != "ja-JP" || len(got.Speakers) != 0 {
+		t.Errorf("Speech = %+v, want default voice and language", got)
+	}
+
+	if audio.MIMEType != "audio/wav" || audio.SampleRate != 24000 || audio.Channels != 1 || audio.BitsPerSample != 16 {
+		t.Errorf("audio format = %s %d/%d/%d", audio.MIMEType, audio.SampleRate, audio.Channels, audio.BitsPerSample)
+	}
+	if audio.Duration != 500*time.Millisecond {
+		t.Errorf("Duration = %v, want 500ms", audio.Duration)
+	}
+	format, pcm, err := wav.Decode(audio.Data)
+	if err != nil || format.SampleRate != 24000 || len(pcm) != 24000 || len(audio.PCM) != 24000 {
+		t.Errorf("wav.Decode(Data) = %+v, %d bytes, %v (PCM %d bytes)", format, len(pcm), err, len(audio.PCM))
+	}
+	if audio.Usage == nil || audio.Usage.TotalTokenCount != 7 {
+		t.Errorf("Usage = %+v", audio.Usage)
+	}
+}
+
+func TestSynthesize_RequestOverridesDefaults(t *testing.T) {
+	gen := &fakeGenerator{}
+	c := newTestClient(t, gen, WithVoice("Kore"), WithLanguageCode("ja-JP"))
+
+	if _, err := c.Synthesize(context.Background(), "m", Request{Text: "hi", Voice: "Puck", LanguageCode: "en-US"}); err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+	if got := gen.calls[0].opts.Speech; got.Voice != "Puck" || got.LanguageCode != "en-US" {
+		t.Errorf("Speech = %+v, want request values", got)
+	}
+	if gen.calls[0].prompt != "hi" {
+		t.Errorf("prompt = %q, want text only without style", gen.calls[0].prompt)
+	}
+}
+
+func TestSynthesize_EmptyText(t *testing.T) {
+	gen := &fakeGenerator{}
+	c := newTestClient(t, gen)
+	if _, err := c.Synthesize(context.Background(), "m", Request{Text: "  "}); !errors.Is(err, ErrEmptyText) {
+		t.Fatalf("error = %v, want ErrEmptyText", err)
+	}
+	if len(gen.calls) != 0 {
+		t.Error("generator should not be called for empty text")
+	}
+}
+
+func TestSynthesize_ConcatenatesPCMParts(t *testing.T) {
+	gen := &fakeGenerator{respond: func(fakeCall) (*gemini.Response, error) {
+		return &gemini.Response{Attachments: []gemini.Attachment{
+			{MIMEType: "audio/L16;rate=16000", Data: make([]byte, 16000)},
+			{MIMEType: "text/plain", Data: []byte("ignored")},
+			{MIMEType: "audio/L16;rate=16000", Data: make([]byte, 16000)},
+		}}, nil
+	}}
+	c := newTestClient(t, gen)
+
+	audio, err := c.Synthesize(context.Background(), "m", Request{Text: "long"})
+	if err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+	if audio.SampleRate != 16000 || len(audio.PCM) != 32000 || audio.Duration != time.Second {
+		t.Errorf("audio = %d Hz, %d bytes, %v; want 16000 Hz, 32000 bytes, 1s", audio.SampleRate, len(audio.PCM), audio.Duration)
+	}
+}
+
+func TestSynthesize_PassesThroughWAV(t *testing.T) {
+	stereo := wav.Format{SampleRate: 48000, Channels: 2, BitsPerSample: 16}
+	data, err := wav.Encode(make([]byte, 192000), stereo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gen := &fakeGenerator{respond: func(fakeCall) (*gemini.Response, error) {
+		return &gemini.Response{Attachments: []gemini.Attachment{{MIMEType: "audio/wav", Data: data}}}, nil
+	}}
+	c := newTestClient(t, gen)
+
+	audio, err := c.Synthesize(context.Background(), "m", Request{Text: "x"})
+	if err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+	if audio.SampleRate != 48000 || audio.Channels != 2 || audio.Duration != time.Second {
+		t.Errorf("audio = %d Hz, %d ch, %v", audio.SampleRate, audio.Channels, audio.Duration)
+	}
+}
+
+func TestSynthesize_ResultErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		resp    *gemini.Response
+		genErr  error
+		wantErr error
+	}{
+		{"no audio", &gemini.Response{Text: "sorry"}, nil, ErrNoAudio},
+		{"nil response", nil, nil, gemini.ErrEmptyResponse},
+		{"unsupported", &gemini.Response{Attachments: []gemini.Attachment{{MIMEType: "audio/mpeg", Data: []byte{1}}}}, nil, ErrUnsupportedFormat},
+		{"mixed formats", &gemini.Response{Attachments: []gemini.Attachment{
+			{MIMEType: "audio/L16;rate=24000", Data: make([]byte, 4)},
+			{MIMEType: "audio/L16;rate=16000", Data: make([]byte, 4)},
+		}}, nil, ErrUnsupportedFormat},
+		{"generator error", nil, gemini.ErrBlocked, gemini.ErrBlocked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := &fakeGenerator{respond: func(fakeCall) (*gemini.Response, error) { return tt.resp, tt.genErr }}
+			c := newTestClient(t, gen)
+			if _, err := c.Synthesize(context.Background(), "m", Request{Text: "x"}); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSynthesizeDialogue_BuildsScriptAndSpeakers(t *testing.T) {
+	gen := &fakeGenerator{}
+	c := newTestClient(t, gen, WithLanguageCode("ja-JP"))
+
+	_, err := c.SynthesizeDialogue(context.Background(), "m", Dialogue{
+		Lines: []Line{
+			{Speaker: "Joe", Text: "How's it going?"},
+			{Speaker: "Jane", Text: "Not too bad."},
+			{Speaker: "Joe", Text: ""},
+			{Speaker: "Joe", Text: "Great."},
+		},
+		Voices: map[string]string{"Joe": "Kore", "Jane": "Puck"},
+	})
+	if err != nil {
+		t.Fatalf("SynthesizeDialogue() error = %v", err)
+	}
+
+	want := "TTS the following conversation between Joe and Jane:\nJoe: How's it going?\nJane: Not too bad.\nJoe: Great."
+	if gen.calls[0].prompt != want {
+		t.Errorf("prompt =\n%s\nwant\n%s", gen.calls[0].prompt, want)
+	}
+	speech := gen.calls[0].opts.Speech
+	if speech.Voice != "" || speech.LanguageCode != "ja-JP" {
+		t.Errorf("Speech = %+v", speech)
+	}
+	wantSpeakers := []gemini.SpeakerVoice{{Speaker: "Joe", Voice: "Kore"}, {Speaker: "Jane", Voice: "Puck"}}
+	if len(speech.Speakers) != 2 || speech.Speakers[0] != wantSpeakers[0] || speech.Speakers[1] != wantSpeakers[1] {
+		t.Errorf("Speakers = %+v, want %+v", speech.Speakers, wantSpeakers)
+	}
+}
+
+func TestSynthesizeDialogue_SingleSpeakerUsesVoice(t *testing.T) {
+	gen := &fakeGenerator{}
+	c := newTestClient(t, gen)
+
+	_, err := c.SynthesizeDialogue(context.Background(), "m", Dialogue{
+		Lines:  []Line{{Speaker: "語り手", Text: "むかしむかし"}},
+		Voices: map[string]string{"語り手": "Charon"},
+		Style:  "Read slowly like a bedtime story:",
+	})
+	if err != nil {
+		t.Fatalf("SynthesizeDialogue() error = %v", err)
+	}
+	if speech := gen.calls[0].opts.Speech; speech.Voice != "Charon" || len(speech.Speakers) != 0 {
+		t.Errorf("Speech = %+v, want single voice", speech)
+	}
+	if !strings.HasPrefix(gen.calls[0].prompt, "Read slowly like a bedtime story:\n語り手: ") {
+		t.Errorf("prompt = %q, want custom style header", gen.calls[0].prompt)
+	}
+}
+
+func TestSynthesizeDialogue_InvalidScripts(t *testing.T) {
+	voices := map[string]string{"A": "Kore", "B": "Puck", "C": "Fenrir"}
+	tests := []struct {
+		name    string
+		lines   []Line
+		wantErr error
+	}{
+		{"empty", nil, ErrEmptyText},
+		{"blank lines only", []Line{{Speaker: "A", Text: " "}}, ErrEmptyText},
+		{"missing speaker", []Line{{Text: "hi"}}, ErrInvalidScript},
+		{"colon in speaker", []Line{{Speaker: "A: B", Text: "hi"}}, ErrInvalidScript},
+		{"missing voice", []Line{{Speaker: "A", Text: "hi"}, {Speaker: "Z", Text: "yo"}}, ErrInvalidScript},
+		{"too many speakers", []Line{{Speaker: "A", Text: "1"}, {Speaker: "B", Text: "2"}, {Speaker: "C", Text: "3"}}, ErrInvalidScript},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := &fakeGenerator{}
+			c := newTestClient(t, gen)
+			if _, err := c.SynthesizeDialogue(context.Background(), "m", Dialogue{Lines: tt.lines, Voices: voices}); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if len(gen.calls) != 0 {
+				t.Error("generator should not be called for an invalid script")
+			}
+		})
+	}
+}
+
+// TestSynthesize_DefaultVoiceRequestsAudio は、声も言語も指定しない読み上げが、実際の
+// gemini.Client を通して AUDIO のレスポンスモダリティを要求することを確かめます。
+func TestSynthesize_DefaultVoiceRequestsAudio(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("request body: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"candidates":[{"content":{"role":"model","parts":[{"inlineData":{"mimeType":"audio/L16;codec=pcm;rate=24000","data":%q}}]},"finishReason":"STOP"}]}`,
+			base64.StdEncoding.EncodeToString(make([]byte, 480)))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("GOOGLE_GEMINI_BASE_URL", server.URL)
+
+	gc, err := gemini.NewClient(context.Background(), gemini.Config{APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("gemini.NewClient() error = %v", err)
+	}
+	audio, err := newTestClient(t, gc).Synthesize(context.Background(), "tts-model", Request{Text: "こんにちは"})
+	if err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+	if audio.Duration != 10*time.Millisecond {
+		t.Errorf("Duration = %v, want 10ms", audio.Duration)
+	}
+
+	config, _ := body["generationConfig"].(map[string]any)
+	if modalities, _ := config["responseModalities"].([]any); len(modalities) != 1 || modalities[0] != "AUDIO" {
+		t.Errorf("responseModalities = %v, want [AUDIO]", config["responseModalities"])
+	}
+	if _, ok := config["speechConfig"]; ok {
+		t.Errorf("speechConfig = %v, want none for the default voice", config["speechConfig"])
+	}
+}
@@ -0,0 +1,66 @@
+package tts
+
+import (
+	"time"
+
+	"github.com/shouni/go-gemini-client/gemini"
+)
+
+// Request は単一話者の読み上げ1回分の入力です。
+type Request struct {
+	// Text は読み上げる本文です。必須です。
+	Text string
+	// Style は読み上げ方の指示です（"Say cheerfully" など）。空の場合は Text をそのまま読みます。
+	// TTS モデルはプロンプト全体を読むのではなく演出の指示として解釈するため、
+	// 本文の前に "Style: Text" の形で付けます。
+	Style string
+	// Voice はプリセット音声の名前です。空の場合は WithVoice の既定値を使います。
+	Voice string
+	// LanguageCode は読み上げる言語のコードです。空の場合は WithLanguageCode の既定値を使います。
+	LanguageCode string
+}
+
+// Line は対話台本の1行です。
+type Line struct {
+	// Speaker は話者名です。Dialogue.Voices のキーと一致させます。
+	Speaker string
+	// Text はその話者のセリフです。
+	Text string
+}
+
+// Dialogue は複数話者の対話台本です。
+//
+// Gemini の複数話者 TTS は話者2人までしか扱えません。3人以上の台本は
+// ErrInvalidScript になるため、分割して読み上げてください。
+type Dialogue struct {
+	// Lines は台本の行です。この順に読み上げられます。
+	Lines []Line
+	// Voices は話者名からプリセット音声名への対応です。台本に登場する全員分が必要です。
+	Voices map[string]string
+	// Style は対話全体の演出の指示です。空の場合は既定の前置き
+	// （"TTS the following conversation between A and B:"）を使います。
+	Style string
+	// LanguageCode は読み上げる言語のコードです。空の場合は WithLanguageCode の既定値を使います。
+	LanguageCode string
+}
+
+// Audio は再生可能な形に包んだ合成音声です。
+type Audio struct {
+	// MIMEType は常に "audio/wav" です。
+	MIMEType string
+	// Data は WAV（RIFF）形式のバイト列です。そのままファイルへ書き出して再生できます。
+	Data []byte
+	// PCM はヘッダを除いたリトルエンディアンの PCM 本体です。Data の部分スライスで、
+	// 同じメモリを共有します。
+	PCM []byte
+	// SampleRate はサンプリング周波数（Hz）です。
+	SampleRate int
+	// Channels はチャンネル数です。
+	Channels int
+	// BitsPerSample は1サンプルあたりのビット数です。
+	BitsPerSample int
+	// Duration は再生時間です。
+	Duration time.Duration
+	// Usage はトークン使用量です。モデルが返さなかった場合は nil です。
+	Usage *gemini.TokenUsage
+}
@@ -0,0 +1,295 @@
+package veo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shouni/go-gemini-client/gemini"
+)
+
+// DefaultConcurrency は、GenerateAll が同時に生成させる既定の本数です。
+// Veo はプロジェクトごとに同時に実行できるリクエスト数の上限があるため、控えめにしています。
+const DefaultConcurrency = 4
+
+// BatchResult は GenerateAll の1件分の結果です。
+type BatchResult struct {
+	// OperationName は投函したオペレーションの識別子です。投函前に終わった場合は空です。
+	OperationName string
+	// Result は完了した生成の結果です。Err が非 nil の場合は nil です。
+	Result *Result
+	// Err はこの1件が失敗した理由です。
+	Err error
+}
+
+// GenerateAll は複数の動画生成をまとめて行い、入力と同じ順に結果を返します。
+//
+// 同時に生成させるのは WithConcurrency の本数（既定 DefaultConcurrency）までで、
+// 1件終わるごとに次を投函します。完了の確認は1件ごとにループを持たず、投函済みの
+// オペレーションすべてを1つのループで確認します。確認の間隔・想定時間による最初の
+// 遅延・タイムアウト・連続失敗の許容は、1件ごとに Generate と同じ規則で効きます。
+//
+// 1件の失敗は他を止めず、その件の BatchResult.Err に入ります。ctx がキャンセル
+// されると、まだ投函していない分は投函せず、待っている分は待ちをやめます
+// （投函済みの生成はサーバー側で続き、WithJobStore を設定していれば Resume で拾えます）。
+func (c *Client) GenerateAll(ctx context.Context, modelName string, reqs []Request) []BatchResult {
+	results := make([]BatchResult, len(reqs))
+	if len(reqs) == 0 {
+		return results
+	}
+
+	// slots は同時に生成させている本数を数える。投函の前に取り、完了を確認したら返す。
+	slots := make(chan struct{}, c.concurrency)
+	submitted := make(chan batchSubmission)
+	go c.submitAll(ctx, modelName, reqs, slots, submitted)
+
+	var pending []*batchJob
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for submitted != nil || len(pending) > 0 {
+		var due <-chan time.Time
+		if len(pending) > 0 {
+			timer.Reset(time.Until(earliestPoll(pending)))
+			due = timer.C
+		}
+
+		select {
+		case s, ok := <-submitted:
+			if !ok {
+				submitted = nil
+				continue
+			}
+			results[s.index].OperationName = s.operationName()
+			switch {
+			case s.err != nil:
+				results[s.index].Err = s.err
+				if s.acquired {
+					<-slots
+				}
+			case s.op.Done:
+				results[s.index].Result, results[s.index].Err = c.complete(ctx, s.op)
+				<-slots
+			default:
+				now := time.Now()
+				job := &batchJob{
+					index:    s.index,
+					started:  now,
+					deadline: now.Add(c.pollTimeout),
+					status:   Status{OperationName: s.op.Name},
+				}
+				job.schedule(c.expectedDuration(modelName))
+				pending = append(pending, job)
+			}
+
+		case <-due:
+			pending = c.pollDue(ctx, pending, func(job *batchJob, result *Result, err error) {
+				results[job.index].Result, results[job.index].Err = result, err
+				<-slots
+			})
+
+		case <-ctx.Done():
+			for _, job := range pending {
+				results[job.index].Err = c.waitDeadlineError(ctx, job.status.OperationName, ctx.Err())
+			}
+			pending = nil
+			// 投函側は ctx を見て残りを打ち切るので、閉じるまで受け取り切る。
+			for s := range submitted {
+				results[s.index].OperationName = s.operationName()
+				results[s.index].Err = s.err
+				if s.err == nil {
+					results[s.index].Err = c.waitDeadlineError(ctx, s.op.Name, ctx.Err())
+				}
+			}
+			submitted = nil
+		}
+	}
+	return results
+}
+
+// batchSubmission は投函1件分の結果です。
+type batchSubmission struct {
+	index int
+	op    *gemini.VideoOperation
+	err   error
+	// acquired は、この件が slots の空きを取ったかどうかです。空きを待つ間に ctx が
+	// キャンセルされた件は取っていないため、受け取った側は空きを返しません。
+	acquired bool
+}
+
+func (s batchSubmission) operationName() string {
+	if s.op == nil {
+		return ""
+	}
+	return s.op.Name
+}
+
+// batchJob は GenerateAll が完了を待っている1件です。
+type batchJob struct {
+	index    int
+	started  time.Time
+	deadline time.Time
+	nextPoll time.Time
+	status   Status
+}
+
+// submitAll は、空きができるのを待ちながら順に投函し、結果を out へ送ります。
+// ctx がキャンセルされたら、残りは投函せずにキャンセルとして送ります。
+func (c *Client) submitAll(ctx context.Context, modelName string, reqs []Request, slots chan struct{}, out chan<- batchSubmission) {
+	defer close(out)
+	for i, req := range reqs {
+		acquired := false
+		select {
+		case slots <- struct{}{}:
+			acquired = true
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			out <- batchSubmission{index: i, err: fmt.Errorf("リクエスト %d は投函前に中断されました: %w", i, ctx.Err()), acquired: acquired}
+			continue
+		}
+
+		op, err := c.start(ctx, modelName, req)
+		if err == nil && !op.Done && strings.TrimSpace(op.Name) == "" {
+			err = ErrMissingOperationName
+		}
+		out <- batchSubmission{index: i, op: op, err: err, acquired: true}
+	}
+}
+
+// schedule は次の確認を d 後に予定します。上限時刻を越える場合は上限時刻に
+// 合わせ、そこでタイムアウトとして打ち切れるようにします。
+func (j *batchJob) schedule(d time.Duration) {
+	j.nextPoll = time.Now().Add(d)
+	if j.nextPoll.After(j.deadline) {
+		j.nextPoll = j.deadline
+	}
+}
+
+// pollOnce は1件の状況を1回確認し、待ちが終わったか（finished）とその結末を返します。
+// 規則は wait と同じで、状況は WithProgress のコールバックへ通知します。
+func (c *Client) pollOnce(ctx context.Context, job *batchJob) (result *Result, finished bool, err error) {
+	status := &job.status
+	name := status.OperationName
+	notify := func() {
+		status.Elapsed = time.Since(job.started)
+		if c.progress != nil {
+			c.progress(*status)
+		}
+	}
+	finish := func(r *Result, e error) (*Result, bool, error) {
+		status.Result, status.Err = r, e
+		notify()
+		return r, true, e
+	}
+
+	if !time.Now().Before(job.deadline) {
+		return finish(nil, c.waitDeadlineError(ctx, name, context.DeadlineExceeded))
+	}
+
+	pollCtx, cancel := context.WithDeadline(ctx, job.deadline)
+	defer cancel()
+
+	status.Attempt++
+	status.PollError = nil
+	op, pollErr := c.generator.PollVideo(pollCtx, name)
+	switch {
+	case pollErr != nil && pollCtx.Err() != nil:
+		return finish(nil, c.waitDeadlineError(ctx, name, pollCtx.Err()))
+	case pollErr != nil:
+		status.ConsecutiveErrors++
+		status.PollError = pollErr
+		if status.ConsecutiveErrors >= c.maxPollErrors {
+			return finish(nil, fmt.Errorf("%w: オペレーション %q の確認が %d 回連続で失敗しました: %w",
+				ErrPollFailed, name, status.ConsecutiveErrors, pollErr))
+		}
+		c.logger.WarnContext(ctx, "動画生成の状況確認に失敗しました。再確認します",
+			"operation", name, "consecutive_errors", status.ConsecutiveErrors, "error", pollErr)
+	default:
+		status.ConsecutiveErrors = 0
+		status.Done, status.ProgressPercent = op.Done, op.ProgressPercent
+		if op.Done {
+			r, e := c.complete(ctx, op)
+			return finish(r, e)
+		}
+	}
+	notify()
+	job.schedule(c.poll.Next(status.Attempt))
+	return nil, false, nil
+}
+
+// waitAll は、投函済みのオペレーションすべての完了を GenerateAll と同じ1つのループで
+// 待ち、names と同じ順に結果を返します。Resume の本体です。
+//
+// 問い合わせは1件ずつ順に行うので、同時に PollVideo を呼ぶのは常に1件だけです。
+// 確認の間隔・想定時間による最初の遅延・タイムアウト・連続失敗の許容は、1件ごとに
+// Wait と同じ規則で効きます。ctx がキャンセルされると、残りはすべて待ちをやめます。
+func (c *Client) waitAll(ctx context.Context, names []string) []BatchResult {
+	results := make([]BatchResult, len(names))
+	pending := make([]*batchJob, 0, len(names))
+	for i, name := range names {
+		results[i].OperationName = name
+		if strings.TrimSpace(name) == "" {
+			results[i].Err = ErrMissingOperationName
+			continue
+		}
+		now := time.Now()
+		job := &batchJob{
+			index:    i,
+			started:  now,
+			deadline: now.Add(c.pollTimeout),
+			status:   Status{OperationName: name},
+		}
+		job.schedule(c.remainingExpected(ctx, name))
+		pending = append(pending, job)
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for len(pending) > 0 {
+		timer.Reset(time.Until(earliestPoll(pending)))
+		select {
+		case <-timer.C:
+			pending = c.pollDue(ctx, pending, func(job *batchJob, result *Result, err error) {
+				results[job.index].Result, results[job.index].Err = result, err
+			})
+		case <-ctx.Done():
+			for _, job := range pending {
+				results[job.index].Err = c.waitDeadlineError(ctx, job.status.OperationName, ctx.Err())
+			}
+			pending = nil
+		}
+	}
+	return results
+}
+
+// pollDue は、pending のうち確認の時刻が来たものを1件ずつ確認し、待ちが終わったものを
+// finished へ渡して、まだ待つものだけを返します。GenerateAll・Resume・Watcher の
+// 確認のループが共有します。
+func (c *Client) pollDue(ctx context.Context, pending []*batchJob, finished func(job *batchJob, result *Result, err error)) []*batchJob {
+	kept := pending[:0]
+	for _, job := range pending {
+		if time.Now().Before(job.nextPoll) {
+			kept = append(kept, job)
+			continue
+		}
+		if result, done, err := c.pollOnce(ctx, job); done {
+			finished(job, result, err)
+			continue
+		}
+		kept = append(kept, job)
+	}
+	return kept
+}
+
+// earliestPoll は、待っている中で最も早く確認すべき時刻を返します。
+func earliestPoll(jobs []*batchJob) time.Time {
+	earliest := jobs[0].nextPoll
+	for _, job := range jobs[1:] {
+		if job.nextPoll.Before(earliest) {
+			earliest = job.nextPoll
+		}
+	}
+	return earliest
+}
@@ -0,0 +1,204 @@
+package veo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shouni/go-gemini-client/gemini"
+)
+
+// batchGenerator は GenerateAll 用のテストダブルです。オペレーション名はプロンプトから
+// 作り、各オペレーションは pollsToFinish 回目の確認で完了します。同時に生成中の本数の
+// 最大値を記録します。
+type batchGenerator struct {
+	pollsToFinish int
+
+	mu          sync.Mutex
+	polls       map[string]int
+	inFlight    int
+	maxInFlight int
+	started     []string
+}
+
+func newBatchGenerator(pollsToFinish int) *batchGenerator {
+	return &batchGenerator{pollsToFinish: pollsToFinish, polls: make(map[string]int)}
+}
+
+func (g *batchGenerator) StartVideo(_ context.Context, _ string, req gemini.VideoRequest) (*gemini.VideoOperation, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if req.Prompt == "reject" {
+		return nil, errors.New("quota exceeded")
+	}
+	g.started = append(g.started, req.Prompt)
+	g.inFlight++
+	g.maxInFlight = max(g.maxInFlight, g.inFlight)
+	return running("operations/" + req.Prompt), nil
+}
+
+func (g *batchGenerator) PollVideo(_ context.Context, operationName string) (*gemini.VideoOperation, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.polls[operationName]++
+	if operationName == "operations/stuck" || g.polls[operationName] < g.pollsToFinish {
+		return running(operationName), nil
+	}
+	g.inFlight--
+	if operationName == "operations/filtered" {
+		return &gemini.VideoOperation{Name: operationName, Done: true, FilteredCount: 1}, nil
+	}
+	return finished(operationName, "gs://bucket/"+operationName+".mp4"), nil
+}
+
+func (g *batchGenerator) CancelVideo(context.Context, string) error {
+	return gemini.ErrUnsupported
+}
+
+func (g *batchGenerator) DeleteVideo(context.Context, string) error {
+	return gemini.ErrUnsupported
+}
+
+func requests(prompts ...string) []Request {
+	reqs := make([]Request, len(prompts))
+	for i, prompt := range prompts {
+		reqs[i] = Request{Prompt: prompt}
+	}
+	return reqs
+}
+
+func TestGenerateAllKeepsInputOrderWithPerItemErrors(t *testing.T) {
+	generator := newBatchGenerator(3)
+	client := newTestClient(t, generator, WithConcurrency(2))
+
+	results := client.GenerateAll(context.Background(), "veo-test", requests("a", "reject", "filtered", "b", "c"))
+	if len(results) != 5 {
+		t.Fatalf("results = %d, want 5", len(results))
+	}
+	for _, i := range []int{0, 3, 4} {
+		video, ok := results[i].Result.First()
+		if results[i].Err != nil || !ok || video.URI != "gs://bucket/"+results[i].OperationName+".mp4" {
+			t.Errorf("results[%d] = %+v", i, results[i])
+		}
+	}
+	if results[0].OperationName != "operations/a" || results[4].OperationName != "operations/c" {
+		t.Errorf("operation names out of order: %q, %q", results[0].OperationName, results[4].OperationName)
+	}
+	if results[1].Err == nil || results[1].OperationName != "" {
+		t.Errorf("rejected submission = %+v, want an error and no operation", results[1])
+	}
+	if !errors.Is(results[2].Err, ErrNoVideoGenerated) {
+		t.Errorf("filtered result error = %v, want ErrNoVideoGenerated", results[2].Err)
+	}
+	if generator.maxInFlight > 2 {
+		t.Errorf("max in flight = %d, want at most the concurrency limit 2", generator.maxInFlight)
+	}
+}
+
+func TestGenerateAllTimesOutPerItem(t *testing.T) {
+	client := newTestClient(t, newBatchGenerator(1), WithPollTimeout(30*time.Millisecond))
+
+	results := client.GenerateAll(context.Background(), "veo-test", requests("stuck", "quick"))
+	if !errors.Is(results[0].Err, context.DeadlineExceeded) {
+		t.Errorf("stuck item error = %v, want a deadline error", results[0].Err)
+	}
+	if results[1].Err != nil {
+		t.Errorf("quick item error = %v, want success despite the other timing out", results[1].Err)
+	}
+}
+
+func TestGenerateAllStopsOnCancel(t *testing.T) {
+	generator := newBatchGenerator(1)
+	client := newTestClient(t, generator, WithConcurrency(1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(30*time.Millisecond, cancel)
+
+	done := make(chan []BatchResult)
+	go func() { done <- client.GenerateAll(ctx, "veo-test", requests("stuck", "a", "b")) }()
+
+	var results []BatchResult
+	select {
+	case results = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("GenerateAll did not return after cancellation")
+	}
+	for i, result := range results {
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("results[%d].Err = %v, want context.Canceled", i, result.Err)
+		}
+	}
+	generator.mu.Lock()
+	defer generator.mu.Unlock()
+	if len(generator.started) != 1 {
+		t.Errorf("started = %v, want only the first request submitted", generator.started)
+	}
+}
+
+// cancelOnGetStore は、Get のたびに cancel を呼ぶ JobStore です。キャンセルの後の Put は
+// 少し待たせ、その間に投函側が中断を送り終えるようにします。
+type cancelOnGetStore struct {
+	*MemoryStore
+	cancel context.CancelFunc
+}
+
+func (s cancelOnGetStore) Get(ctx context.Context, operationName string) (Job, error) {
+	s.cancel()
+	return s.MemoryStore.Get(ctx, operationName)
+}
+
+func (s cancelOnGetStore) Put(ctx context.Context, job Job) error {
+	if ctx.Err() != nil {
+		time.Sleep(10 * time.Millisecond)
+	}
+	return s.MemoryStore.Put(ctx, job)
+}
+
+func TestGenerateAllCancelWhileWaitingForSlot(t *testing.T) {
+	// 1件目が投函の応答で完了し、その記録を読むときに ctx がキャンセルされる。投函側は
+	// 2件目の空きを待っている最中にキャンセルを受け、空きを取らずに中断を送る。
+	// 主ループがどちらの select の分岐を選んでも止まらないよう、何度か繰り返す。
+	for range 20 {
+		ctx, cancel := context.WithCancel(context.Background())
+		generator := &fakeGenerator{startOp: finished("operations/a", "gs://bucket/a.mp4")}
+		client := newTestClient(t, generator, WithConcurrency(1), WithJobStore(cancelOnGetStore{MemoryStore: NewMemoryStore(), cancel: cancel}))
+
+		done := make(chan []BatchResult)
+		go func() { done <- client.GenerateAll(ctx, "veo-test", requests("a", "b")) }()
+		select {
+		case results := <-done:
+			if !errors.Is(results[1].Err, context.Canceled) {
+				t.Errorf("results[1].Err = %v, want context.Canceled", results[1].Err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("GenerateAll deadlocked after a cancellation while waiting for a slot")
+		}
+		cancel()
+	}
+}
+
+func TestGenerateAllReportsProgressPerOperation(t *testing.T) {
+	var mu sync.Mutex
+	finals := map[string]bool{}
+	client := newTestClient(t, newBatchGenerator(2), WithProgress(func(s Status) {
+		mu.Lock()
+		defer mu.Unlock()
+		if s.Final() {
+			finals[s.OperationName] = true
+		}
+	}))
+
+	client.GenerateAll(context.Background(), "veo-test", requests("a", "b"))
+	if !finals["operations/a"] || !finals["operations/b"] {
+		t.Errorf("final events = %v, want one per operation", finals)
+	}
+}
+
+func TestGenerateAllEmpty(t *testing.T) {
+	client := newTestClient(t, newBatchGenerator(1))
+	if results := client.GenerateAll(context.Background(), "veo-test", nil); len(results) != 0 {
+		t.Errorf("results = %v, want none", results)
+	}
+}
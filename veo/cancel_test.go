@@ -0,0 +1,110 @@
+package veo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shouni/go-gemini-client/gemini"
+)
+
+// cancellingGenerator は取り消しと削除に対応する fakeGenerator です。
+// 取り消しを受けると、以降の確認で取り消されて完了したオペレーションを返します。
+// 削除を受けると、以降の確認では見つからなくなります。
+type cancellingGenerator struct {
+	fakeGenerator
+	cancelErr error
+	cancelled []string
+}
+
+func (g *cancellingGenerator) DeleteVideo(_ context.Context, operationName string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.byName, operationName)
+	return nil
+}
+
+func (g *cancellingGenerator) CancelVideo(_ context.Context, operationName string) error {
+	if g.cancelErr != nil {
+		return g.cancelErr
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.cancelled = append(g.cancelled, operationName)
+	g.byName[operationName] = &gemini.VideoOperation{
+		Name:    operationName,
+		Done:    true,
+		Failure: errors.Join(gemini.ErrVideoCancelled, errors.New("code=1")),
+	}
+	return nil
+}
+
+func TestCancelEndsWaitWithErrCancelled(t *testing.T) {
+	generator := &cancellingGenerator{fakeGenerator: fakeGenerator{
+		startOp: running("operations/abc"),
+		byName:  map[string]*gemini.VideoOperation{"operations/abc": running("operations/abc")},
+	}}
+	store := NewMemoryStore()
+	client := newTestClient(t, generator, WithJobStore(store))
+	ctx := context.Background()
+
+	name, err := client.Submit(ctx, "veo-test", Request{Prompt: "p"})
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if err := client.Cancel(ctx, name); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	_, err = client.Wait(ctx, name)
+	if !errors.Is(err, ErrCancelled) {
+		t.Fatalf("Wait() error = %v, want ErrCancelled", err)
+	}
+	if errors.Is(err, gemini.ErrVideoGenerationFailed) {
+		t.Error("a cancelled operation should not be reported as a generation failure")
+	}
+	job, err := store.Get(ctx, name)
+	if err != nil || job.Status != JobCancelled {
+		t.Errorf("stored job = %+v, %v; want JobCancelled", job, err)
+	}
+}
+
+func TestCancelUnsupported(t *testing.T) {
+	client := newTestClient(t, &fakeGenerator{})
+	if err := client.Cancel(context.Background(), "operations/abc"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Cancel() on a generator without cancellation error = %v, want ErrUnsupported", err)
+	}
+
+	backend := &cancellingGenerator{cancelErr: gemini.ErrUnsupported}
+	client = newTestClient(t, backend)
+	if err := client.Cancel(context.Background(), "operations/abc"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Cancel() on an unsupported backend error = %v, want ErrUnsupported", err)
+	}
+	if err := client.Cancel(context.Background(), " "); !errors.Is(err, ErrMissingOperationName) {
+		t.Errorf("Cancel(blank) error = %v, want ErrMissingOperationName", err)
+	}
+}
+
+func TestDeleteRemovesOperation(t *testing.T) {
+	generator := &cancellingGenerator{fakeGenerator: fakeGenerator{
+		byName: map[string]*gemini.VideoOperation{"operations/abc": finished("operations/abc", "gs://bucket/a.mp4")},
+	}}
+	client := newTestClient(t, generator)
+	ctx := context.Background()
+
+	if _, err := client.Wait(ctx, "operations/abc"); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if err := client.Delete(ctx, "operations/abc"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, ok := generator.byName["operations/abc"]; ok {
+		t.Error("Delete() did not reach the generator")
+	}
+
+	if err := newTestClient(t, &fakeGenerator{}).Delete(ctx, "operations/abc"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Delete() on a generator without deletion error = %v, want ErrUnsupported", err)
+	}
+	if err := client.Delete(ctx, ""); !errors.Is(err, ErrMissingOperationName) {
+		t.Errorf("Delete(blank) error = %v, want ErrMissingOperationName", err)
+	}
+}
@@ -0,0 +1,148 @@
+package veo
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shouni/go-gemini-client/gemini"
+)
+
+// 動画の取り出しに関するセンチネルエラーです。
+var (
+	// ErrDownloaderRequired は、URI で返った動画を取り出そうとしたのに、取得に使う
+	// gemini.MediaDownloader が無い場合に返されます。
+	ErrDownloaderRequired = errors.New("veo: media downloader is required to fetch video URIs")
+	// ErrNotVideo は、取り出した中身が動画ではなかった場合に返されます
+	// （エラーページや JSON が返った場合、空だった場合など）。
+	ErrNotVideo = errors.New("veo: content is not a video")
+	// ErrEmptyVideo は、動画にバイト列も URI も無い場合に返されます。
+	ErrEmptyVideo = errors.New("veo: video has neither data nor URI")
+)
+
+// sniffLen は中身の形式の判定に読む先頭のバイト数です。
+const sniffLen = 512
+
+// Download は動画の中身をバイト列で返します。
+//
+// Result.Videos の動画はバイト列（Data）で返る場合と URI で返る場合があり、どちらでも
+// 同じように扱えるようにするための入口です。URI の場合は gemini.MediaDownloader で
+// 取得します。長い動画をメモリに載せたくない場合は DownloadTo か Save を使ってください。
+func (c *Client) Download(ctx context.Context, video gemini.Attachment) ([]byte, error) {
+	if len(video.Data) > 0 {
+		if err := checkVideo(video.Data, video.MIMEType); err != nil {
+			return nil, err
+		}
+		return video.Data, nil
+	}
+	var buf bytes.Buffer
+	if _, err := c.DownloadTo(ctx, video, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DownloadTo は動画の中身を w へ書き出し、書いたバイト数を返します。
+//
+// URI の動画は読みながら書き出すため、全体をメモリに載せません。先頭のバイト列で
+// 動画であることを確かめてから書き始めるので、動画でなかった場合（ErrNotVideo）は
+// w に何も書きません。
+func (c *Client) DownloadTo(ctx context.Context, video gemini.Attachment, w io.Writer) (int64, error) {
+	r, declared, err := c.open(ctx, video)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	br := bufio.NewReaderSize(r, sniffLen)
+	head, err := br.Peek(sniffLen)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return 0, fmt.Errorf("veo: 動画 %s の読み込みに失敗しました: %w", describe(video), err)
+	}
+	if err := checkVideo(head, declared); err != nil {
+		return 0, fmt.Errorf("%w（%s）", err, describe(video))
+	}
+
+	n, err := io.Copy(w, br)
+	if err != nil {
+		return n, fmt.Errorf("veo: 動画 %s の書き出しに失敗しました: %w", describe(video), err)
+	}
+	return n, nil
+}
+
+// Save は動画の中身を path のファイルへ書き出します。
+//
+// 同じディレクトリの一時ファイルへ書いてから置き換えるため、途中で失敗しても
+// 書きかけのファイルは残らず、既存のファイルも壊しません。
+func (c *Client) Save(ctx context.Context, video gemini.Attachment, path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("veo: 動画の保存先 %s を作成できません: %w", path, err)
+	}
+	defer os.Remove(tmp.Name()) // rename 済みなら何もしない
+
+	if _, err := c.DownloadTo(ctx, video, tmp); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("veo: 動画の保存先 %s への書き込みに失敗しました: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("veo: 動画の保存先 %s への書き込みに失敗しました: %w", path, err)
+	}
+	return nil
+}
+
+// open は動画の中身を読み出す Reader と、申告された MIME type を返します。
+func (c *Client) open(ctx context.Context, video gemini.Attachment) (io.ReadCloser, string, error) {
+	switch {
+	case len(video.Data) > 0:
+		return io.NopCloser(bytes.NewReader(video.Data)), video.MIMEType, nil
+	case video.URI == "":
+		return nil, "", ErrEmptyVideo
+	case c.downloader == nil:
+		return nil, "", fmt.Errorf("%w: %s", ErrDownloaderRequired, video.URI)
+	}
+	stream, err := c.downloader.DownloadMedia(ctx, video.URI)
+	if err != nil {
+		return nil, "", fmt.Errorf("veo: 動画 %s の取得に失敗しました: %w", video.URI, err)
+	}
+	// 結果に載っていた申告を優先する。配信側の Content-Type は
+	// application/octet-stream のような汎用の値であることが多い。
+	return stream.Body, cmp.Or(video.MIMEType, stream.MIMEType), nil
+}
+
+// checkVideo は、先頭のバイト列と申告から中身が動画であることを確かめます。
+//
+// 形式を判定できた場合はそれを、判定できなかった場合は申告を根拠にします。
+// どちらも手掛かりにならない場合は確かめようがないため受け入れます。
+func checkVideo(head []byte, declared string) error {
+	if len(head) == 0 {
+		return fmt.Errorf("%w: 中身が空です", ErrNotVideo)
+	}
+	detected := gemini.DetectMIMEType(head)
+	switch {
+	case detected != "" && !strings.HasPrefix(detected, "video/"):
+		return fmt.Errorf("%w: 中身は %s でした（申告: %s）", ErrNotVideo, detected, cmp.Or(declared, "なし"))
+	case detected == "" && declared != "" && !strings.HasPrefix(declared, "video/") &&
+		declared != "application/octet-stream":
+		return fmt.Errorf("%w: 申告された形式は %s です", ErrNotVideo, declared)
+	}
+	return nil
+}
+
+// describe はエラーメッセージで動画を指す文字列を返します。
+func describe(video gemini.Attachment) string {
+	if video.URI != "" {
+		return video.URI
+	}
+	return fmt.Sprintf("（インライン %d バイト）", len(video.Data))
+}
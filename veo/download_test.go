@@ -0,0 +1,122 @@
+package veo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shouni/go-gemini-client/gemini"
+)
+
+// mp4Bytes は ftyp ボックスで始まる、MP4 と判定されるバイト列です。
+var mp4Bytes = append([]byte("\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom"), bytes.Repeat([]byte{0x00, 0xFF}, 600)...)
+
+// downloadingGenerator は gemini.MediaDownloader も満たす fakeGenerator です。
+type downloadingGenerator struct {
+	fakeGenerator
+	media map[string][]byte
+	uris  []string
+}
+
+func (g *downloadingGenerator) DownloadMedia(_ context.Context, uri string) (*gemini.MediaStream, error) {
+	g.uris = append(g.uris, uri)
+	data, ok := g.media[uri]
+	if !ok {
+		return nil, errors.New("HTTP 404")
+	}
+	return &gemini.MediaStream{Body: io.NopCloser(bytes.NewReader(data)), MIMEType: "application/octet-stream", Size: int64(len(data))}, nil
+}
+
+func TestDownload_URIAndInline(t *testing.T) {
+	generator := &downloadingGenerator{media: map[string][]byte{"files/abc": mp4Bytes}}
+	client := newTestClient(t, generator)
+	ctx := context.Background()
+
+	data, err := client.Download(ctx, gemini.Attachment{URI: "files/abc", MIMEType: "video/mp4"})
+	if err != nil || !bytes.Equal(data, mp4Bytes) {
+		t.Fatalf("Download(URI) = %d bytes, %v", len(data), err)
+	}
+	if len(generator.uris) != 1 || generator.uris[0] != "files/abc" {
+		t.Errorf("downloaded URIs = %v, want the generator to be used as the downloader", generator.uris)
+	}
+
+	data, err = client.Download(ctx, gemini.Attachment{Data: mp4Bytes, MIMEType: "video/mp4"})
+	if err != nil || !bytes.Equal(data, mp4Bytes) {
+		t.Fatalf("Download(inline) = %d bytes, %v", len(data), err)
+	}
+	if len(generator.uris) != 1 {
+		t.Errorf("inline video should not be downloaded, got %v", generator.uris)
+	}
+}
+
+func TestDownload_RejectsNonVideo(t *testing.T) {
+	generator := &downloadingGenerator{media: map[string][]byte{
+		"files/error": []byte(`{"error": {"code": 403, "message": "denied"}}`),
+		"files/empty": {},
+	}}
+	client := newTestClient(t, generator)
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	if _, err := client.DownloadTo(ctx, gemini.Attachment{URI: "files/error"}, &buf); !errors.Is(err, ErrNotVideo) {
+		t.Errorf("DownloadTo(JSON body) error = %v, want ErrNotVideo", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("wrote %d bytes, want nothing written for a non-video body", buf.Len())
+	}
+	if _, err := client.Download(ctx, gemini.Attachment{URI: "files/empty"}); !errors.Is(err, ErrNotVideo) {
+		t.Errorf("Download(empty body) error = %v, want ErrNotVideo", err)
+	}
+	if _, err := client.Download(ctx, gemini.Attachment{Data: []byte("\x89PNG\r\n\x1a\n...."), MIMEType: "video/mp4"}); !errors.Is(err, ErrNotVideo) {
+		t.Errorf("Download(PNG declared as video) error = %v, want ErrNotVideo", err)
+	}
+	if _, err := client.Download(ctx, gemini.Attachment{}); !errors.Is(err, ErrEmptyVideo) {
+		t.Errorf("Download(empty attachment) error = %v, want ErrEmptyVideo", err)
+	}
+}
+
+func TestDownload_RequiresDownloaderForURIs(t *testing.T) {
+	client := newTestClient(t, &fakeGenerator{})
+	_, err := client.Download(context.Background(), gemini.Attachment{URI: "gs://bucket/v.mp4"})
+	if !errors.Is(err, ErrDownloaderRequired) {
+		t.Fatalf("Download() error = %v, want ErrDownloaderRequired", err)
+	}
+
+	downloader := &downloadingGenerator{media: map[string][]byte{"gs://bucket/v.mp4": mp4Bytes}}
+	client = newTestClient(t, &fakeGenerator{}, WithDownloader(downloader))
+	if _, err := client.Download(context.Background(), gemini.Attachment{URI: "gs://bucket/v.mp4"}); err != nil {
+		t.Fatalf("Download() with WithDownloader error = %v", err)
+	}
+}
+
+func TestSave_WritesAtomically(t *testing.T) {
+	generator := &downloadingGenerator{media: map[string][]byte{
+		"files/ok":  mp4Bytes,
+		"files/bad": []byte("<html>Service Unavailable</html>"),
+	}}
+	client := newTestClient(t, generator)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.mp4")
+
+	if err := client.Save(context.Background(), gemini.Attachment{URI: "files/ok"}, path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if got, _ := os.ReadFile(path); !bytes.Equal(got, mp4Bytes) {
+		t.Errorf("saved %d bytes, want the video", len(got))
+	}
+
+	if err := client.Save(context.Background(), gemini.Attachment{URI: "files/bad"}, path); !errors.Is(err, ErrNotVideo) {
+		t.Fatalf("Save(bad) error = %v, want ErrNotVideo", err)
+	}
+	if got, _ := os.ReadFile(path); !bytes.Equal(got, mp4Bytes) {
+		t.Error("a failed Save must leave the existing file untouched")
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries, want no temporary files left behind", len(entries))
+	}
+}
@@ -0,0 +1,229 @@
+package veo
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Outcome は、待っていた動画生成がどう終わったかです。
+type Outcome string
+
+const (
+	// OutcomeSucceeded は、動画が生成されたことを表します。
+	OutcomeSucceeded Outcome = "succeeded"
+	// OutcomeFiltered は、安全性ポリシーで除外されて1本も生成されなかったことを表します。
+	OutcomeFiltered Outcome = "filtered"
+	// OutcomeCancelled は、オペレーションが取り消されて完了したことを表します。
+	OutcomeCancelled Outcome = "cancelled"
+	// OutcomeFailed は、生成が失敗したか、完了を待てなかった（タイムアウト・
+	// ErrPollFailed）ことを表します。
+	OutcomeFailed Outcome = "failed"
+)
+
+// Event は、Watcher が通知する動画生成1件の結末です。
+type Event struct {
+	// OperationName は結末を迎えたオペレーションの識別子です。
+	OperationName string
+	// Outcome は結末の分類です。
+	Outcome Outcome
+	// Result は OutcomeSucceeded の場合の生成結果です。それ以外は nil です。
+	Result *Result
+	// Err は OutcomeSucceeded 以外の場合の理由です。
+	Err error
+	// CompletedAt は結末を確認した時刻です。
+	CompletedAt time.Time
+}
+
+// newEvent は、待ちの結末から Event を組み立てます。
+func newEvent(operationName string, result *Result, err error) Event {
+	event := Event{OperationName: operationName, Result: result, Err: err, CompletedAt: time.Now()}
+	switch _, filtered := errors.AsType[*FilteredError](err); {
+	case err == nil:
+		event.Outcome = OutcomeSucceeded
+	case filtered:
+		event.Outcome = OutcomeFiltered
+	case errors.Is(err, ErrCancelled):
+		event.Outcome = OutcomeCancelled
+	default:
+		event.Outcome = OutcomeFailed
+	}
+	return event
+}
+
+// Notifier は、動画生成の結末を知らせる先です。
+//
+// Watcher は結末を確認するたびに、登録された Notifier を順に呼びます。返したエラーは
+// ログに残すだけで、他の Notifier や監視は止めません。
+type Notifier interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+// NotifierFunc は、関数を Notifier として使うためのアダプタです。
+// 同じプロセス内のチャネルへ流す、DB の状態を書き換える、といった用途に使えます。
+type NotifierFunc func(ctx context.Context, event Event) error
+
+// Notify は f(ctx, event) を呼びます。
+func (f NotifierFunc) Notify(ctx context.Context, event Event) error {
+	return f(ctx, event)
+}
+
+const (
+	// WebhookSignatureHeader は、Webhook が本文の署名を載せるヘッダです。
+	// 値は "sha256=" に続く16進の HMAC-SHA256 です。
+	WebhookSignatureHeader = "X-Veo-Signature"
+	// WebhookTimestampHeader は、Webhook が送信時刻（Unix 秒）を載せるヘッダです。
+	// 署名の対象に含まれるため、受信側で古い時刻を拒否すればリプレイを防げます。
+	WebhookTimestampHeader = "X-Veo-Timestamp"
+
+	defaultWebhookAttempts   = 3
+	defaultWebhookRetryDelay = time.Second
+)
+
+// WebhookPayload は Webhook が送る JSON の本文です。受信側はこの型へ読み込めます。
+type WebhookPayload struct {
+	OperationName   string    `json:"operation_name"`
+	Outcome         Outcome   `json:"outcome"`
+	VideoURIs       []string  `json:"video_uris,omitempty"`
+	FilteredCount   int32     `json:"filtered_count,omitempty"`
+	FilteredReasons []string  `json:"filtered_reasons,omitempty"`
+	Error           string    `json:"error,omitempty"`
+	CompletedAt     time.Time `json:"completed_at"`
+}
+
+// newWebhookPayload は Event を送信用の本文へ変換します。バイト列で返った動画は
+// 送りません（URI だけを載せます）。
+func newWebhookPayload(event Event) WebhookPayload {
+	payload := WebhookPayload{
+		OperationName: event.OperationName,
+		Outcome:       event.Outcome,
+		CompletedAt:   event.CompletedAt,
+	}
+	if event.Result != nil {
+		for _, video := range event.Result.Videos {
+			if video.URI != "" {
+				payload.VideoURIs = append(payload.VideoURIs, video.URI)
+			}
+		}
+		payload.FilteredCount, payload.FilteredReasons = event.Result.FilteredCount, event.Result.FilteredReasons
+	}
+	if filtered, ok := errors.AsType[*FilteredError](event.Err); ok {
+		payload.FilteredCount, payload.FilteredReasons = filtered.FilteredCount, filtered.Reasons
+	}
+	if event.Err != nil {
+		payload.Error = event.Err.Error()
+	}
+	return payload
+}
+
+// Webhook は、結末を HTTP POST で知らせる Notifier です。
+//
+// 本文は WebhookPayload の JSON で、Secret を設定すると送信時刻と本文の HMAC-SHA256 を
+// WebhookSignatureHeader に載せます。受信側は VerifyWebhook で検証してください。
+// 接続エラー・429・5xx は間隔を倍にしながら MaxAttempts 回まで送り直します。それ以外の
+// 4xx は受信側が拒否したものとして送り直しません。
+type Webhook struct {
+	// URL は送信先です。
+	URL string
+	// Secret は署名の鍵です。空の場合は署名しません。
+	Secret []byte
+	// HTTPClient は送信に使うクライアントです。nil の場合は http.DefaultClient です。
+	HTTPClient *http.Client
+	// MaxAttempts は送信の最大回数です。0 以下の場合は 3 回です。
+	MaxAttempts int
+	// RetryDelay は最初の送り直しまでの間隔です。0 以下の場合は 1 秒です。
+	RetryDelay time.Duration
+}
+
+// Notify は event を URL へ送ります。
+func (h *Webhook) Notify(ctx context.Context, event Event) error {
+	body, err := json.Marshal(newWebhookPayload(event))
+	if err != nil {
+		return fmt.Errorf("veo: Webhook の本文を作れません: %w", err)
+	}
+
+	attempts := h.MaxAttempts
+	if attempts <= 0 {
+		attempts = defaultWebhookAttempts
+	}
+	delay := h.RetryDelay
+	if delay <= 0 {
+		delay = defaultWebhookRetryDelay
+	}
+
+	var lastErr error
+	for attempt := range attempts {
+		if attempt > 0 {
+			select {
+			case <-time.After(delay):
+				delay *= 2
+			case <-ctx.Done():
+				return fmt.Errorf("veo: Webhook %s への送信を中断しました: %w（直前の失敗: %w）", h.URL, ctx.Err(), lastErr)
+			}
+		}
+		retryable, err := h.send(ctx, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retryable {
+			break
+		}
+	}
+	return lastErr
+}
+
+// send は1回だけ送信し、失敗した場合は送り直す価値があるかを返します。
+func (h *Webhook) send(ctx context.Context, body []byte) (retryable bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("veo: Webhook %s へのリクエストを作れません: %w", h.URL, err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	if len(h.Secret) > 0 {
+		req.Header.Set(WebhookSignatureHeader, SignWebhook(h.Secret, timestamp, body))
+	}
+
+	resp, err := cmp.Or(h.HTTPClient, http.DefaultClient).Do(req)
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("veo: Webhook %s への送信に失敗しました: %w", h.URL, err)
+	}
+	defer resp.Body.Close()
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retryable = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retryable, fmt.Errorf("veo: Webhook %s が HTTP %d を返しました: %s",
+		h.URL, resp.StatusCode, strings.TrimSpace(string(detail)))
+}
+
+// SignWebhook は、送信時刻と本文に対する署名（"sha256=" + 16進の HMAC-SHA256）を返します。
+// 署名の対象は timestamp + "." + body です。
+func SignWebhook(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhook は、受信した Webhook の署名を検証します。受信側の HTTP ハンドラで、
+// WebhookTimestampHeader と WebhookSignatureHeader の値、読み込んだ本文を渡してください。
+// 比較は定数時間で行います。
+func VerifyWebhook(secret []byte, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(SignWebhook(secret, timestamp, body)), []byte(signature))
+}
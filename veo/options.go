@@ -2,7 +2,10 @@ package veo
 
 import (
 	"log/slog"
+	"maps"
 	"time"
+
+	"github.com/shouni/go-gemini-client/gemini"
 )
 
 // Option は Client の設定を適用する関数型です。
@@ -10,10 +13,38 @@ import (
 type Option func(*Client)
 
 // WithPollInterval は、生成完了を確認する間隔を設定します。
+// WithPollStrategy(gemini.FixedPolling(d)) の短縮形です。
 func WithPollInterval(d time.Duration) Option {
 	return func(c *Client) {
 		if d > 0 {
-			c.pollInterval = d
+			c.poll = gemini.FixedPolling(d)
+		}
+	}
+}
+
+// WithPollStrategy は、生成完了を確認する間隔の決め方を設定します。
+//
+// 既定は DefaultPollInterval の固定間隔です。長尺の生成で問い合わせを減らすなら
+// gemini.ExponentialPolling を、多数のジョブを同時に待つなら gemini.JitteredPolling で
+// 確認の時刻をばらします。WithPollInterval と両方指定した場合は後に書いた方が効きます。
+func WithPollStrategy(strategy gemini.PollStrategy) Option {
+	return func(c *Client) {
+		if strategy != nil {
+			c.poll = strategy
+		}
+	}
+}
+
+// WithExpectedDurations は、モデルごとの「生成に最低限かかる時間」の表を設定します。
+//
+// キーはモデル名の接頭辞で、最も長く一致したものが使われます。投函してからこの時間が
+// 経つまでは、完了しているはずがないので最初の確認を遅らせます。ただし WithPollTimeout の
+// 半分を超える分は待たず、上限までに少なくとも1回は確認します。既定は空の表で、
+// 最初の確認を遅らせません。目安の表は DefaultExpectedDurations で得られます。
+func WithExpectedDurations(durations map[string]time.Duration) Option {
+	return func(c *Client) {
+		if durations != nil {
+			c.expected = maps.Clone(durations)
 		}
 	}
 }
@@ -39,6 +70,52 @@ func WithMaxPollErrors(n int) Option {
 	}
 }
 
+// WithConcurrency は、GenerateAll が同時に生成させる本数の上限を設定します。
+func WithConcurrency(n int) Option {
+	return func(c *Client) {
+		if n > 0 {
+			c.concurrency = n
+		}
+	}
+}
+
+// WithJobStore は、投函したジョブの記録先を設定します。
+//
+// 設定すると Submit / Generate が投函の時点でジョブを JobPending として記録し、
+// 完了を確認した時点で結果を書き戻します。プロセスが再起動した後は Resume で
+// 未完了のジョブの待ちを再開できます。
+func WithJobStore(store JobStore) Option {
+	return func(c *Client) {
+		if store != nil {
+			c.store = store
+		}
+	}
+}
+
+// WithProgress は、完了待ちの間に問い合わせるたびに呼ばれるコールバックを設定します。
+//
+// 経過時間や連続失敗の回数、（バックエンドが返せば）進捗の割合を受け取れるので、
+// 「生成中… 3分経過」のような表示に使えます。最後の呼び出しは待ちの結末です
+// （Status.Final 参照）。コールバックはポーリングのゴルーチンで同期的に呼ばれるため、
+// 重い処理は避けてください。
+func WithProgress(fn func(Status)) Option {
+	return func(c *Client) {
+		if fn != nil {
+			c.progress = fn
+		}
+	}
+}
+
+// WithDownloader は、URI で返った動画の取得に使うクライアントを設定します。
+// 未指定の場合、New に渡した generator が gemini.MediaDownloader を満たしていればそれを使います。
+func WithDownloader(downloader gemini.MediaDownloader) Option {
+	return func(c *Client) {
+		if downloader != nil {
+			c.downloader = downloader
+		}
+	}
+}
+
 // WithLogger は、このクライアントが出すログの出力先を設定します。
 // 未指定（または nil）の場合は slog.Default() を使います。ジョブ ID などの属性を
 // 付けたロガーを渡すと、ポーリングの警告ログにもその属性が乗ります。
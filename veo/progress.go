@@ -0,0 +1,60 @@
+package veo
+
+import (
+	"context"
+	"iter"
+	"time"
+)
+
+// Status は、完了待ちの間に1回の問い合わせごとに通知される状況です。
+//
+// 最後の通知は待ちの結末を表し、完了していれば Done と Result（または Err）を、
+// 完了を待てずに打ち切った場合（タイムアウト・中断・ErrPollFailed）は Err を持ちます。
+type Status struct {
+	// OperationName は待っているオペレーションの識別子です。
+	OperationName string
+	// Attempt は問い合わせの通し番号（1始まり）です。
+	Attempt int
+	// Elapsed は完了待ちを始めてからの経過時間です。
+	Elapsed time.Duration
+	// ConsecutiveErrors は、この時点で問い合わせが連続して失敗している回数です。
+	ConsecutiveErrors int
+	// PollError は、この回の問い合わせが失敗した場合の原因です。
+	// 許容回数の内であれば待ちは続きます。
+	PollError error
+	// Done はオペレーションが完了（成功・失敗を問わず）したかです。
+	Done bool
+	// ProgressPercent は、オペレーションのメタデータに含まれていた進捗（0〜100）です。
+	// バックエンドが返さない場合は nil です。
+	ProgressPercent *float64
+
+	// Result は最後の通知で、生成に成功した場合の結果です。
+	Result *Result
+	// Err は最後の通知で、待ちの結末がエラーだった場合の理由です。
+	Err error
+}
+
+// Final は、この通知が待ちの結末（最後の通知）かを返します。
+func (s Status) Final() bool {
+	return s.Result != nil || s.Err != nil
+}
+
+// Watch は、Wait と同じ手順で完了を待ちながら、問い合わせごとの状況を順に返す
+// イテレータです。最後の要素が待ちの結末（Status.Final が true）です。
+//
+// 進捗を UI に出しつつ結果も受け取る、という使い方を1つのループで書けます。
+// ループを途中で抜けると、その時点で待ちをやめます（ジョブの記録は JobPending の
+// まま残ります）。WithProgress のコールバックも併せて呼ばれます。
+//
+//	for status := range vc.Watch(ctx, name) {
+//	    if status.Final() {
+//	        result, err = status.Result, status.Err
+//	        break
+//	    }
+//	    fmt.Printf("rendering... %s elapsed\n", status.Elapsed.Round(time.Second))
+//	}
+func (c *Client) Watch(ctx context.Context, operationName string) iter.Seq[Status] {
+	return func(yield func(Status) bool) {
+		_, _ = c.wait(ctx, operationName, c.remainingExpected(ctx, operationName), yield)
+	}
+}
@@ -0,0 +1,288 @@
+package veo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/shouni/go-gemini-client/gemini"
+)
+
+// シーケンスの組み立てに関するセンチネルエラーです。
+var (
+	// ErrInvalidSequence は、Sequence の指定が生成を始める前に不正と分かる場合に返されます
+	// （ショットが無い、つなぎ方と入力が両立しない、など）。
+	ErrInvalidSequence = errors.New("veo: invalid sequence")
+	// ErrSequenceTooLong は、ショットを足すと Sequence.MaxTotalSec を超える場合に返されます。
+	ErrSequenceTooLong = errors.New("veo: sequence exceeds the maximum duration")
+)
+
+// Continuation は、ショットを直前のショットにどうつなぐかです。
+// 最初のショットでは無視されます。
+type Continuation int
+
+const (
+	// ExtendVideo は、直前のショットの動画を Video に渡して継続生成（video extension）します。
+	// 返る動画はそれまでの全体を含むため、最後のショットの動画がシーケンス全体になります。
+	ExtendVideo Continuation = iota
+	// StartFromLastFrame は、直前のショットの終了フレームを Image に渡して、そこから始まる
+	// 別のクリップを生成します。終了フレームには直前のショットの Shot.LastFrame を使い、
+	// 無ければ Sequence.ExtractLastFrame で生成された動画から取り出します。
+	StartFromLastFrame
+	// NewScene は、直前のショットとつながない独立したクリップを生成します（カット）。
+	NewScene
+)
+
+// String は Continuation の名前を返します。ログやエラーメッセージ用です。
+func (c Continuation) String() string {
+	switch c {
+	case ExtendVideo:
+		return "extend_video"
+	case StartFromLastFrame:
+		return "start_from_last_frame"
+	case NewScene:
+		return "new_scene"
+	default:
+		return fmt.Sprintf("Continuation(%d)", int(c))
+	}
+}
+
+// Shot はシーケンスの1ショットです。
+type Shot struct {
+	// Prompt はこのショットの生成指示です。
+	Prompt string
+	// Continue は直前のショットへのつなぎ方です。ゼロ値は ExtendVideo です。
+	Continue Continuation
+	// DurationSec はこのショットで生成する秒数です。0 は Sequence.Base.DurationSec を使います。
+	DurationSec int
+	// Image はこのショットの開始フレームです。最初のショットと NewScene でだけ指定できます。
+	Image *Media
+	// LastFrame はこのショットの終了フレームです（first/last frame 補間）。開始フレームが
+	// あるショットでだけ指定でき、次のショットが StartFromLastFrame ならその開始フレームにも
+	// なります。
+	LastFrame *Media
+}
+
+// Sequence は、複数のショットを順に生成して1本の流れにつなぐ指定です。
+type Sequence struct {
+	// Base は全ショットに共通の生成パラメータです（AspectRatio / Resolution /
+	// NegativePrompt / GenerateAudio / Seed / OutputGCSURI など）。Prompt と入力画像・動画は
+	// ショットごとに上書きし、NumberOfVideos は 1本に固定します。
+	Base Request
+	// Shots は生成するショットを順に並べたものです。
+	Shots []Shot
+	// MaxTotalSec は合計秒数の上限です。0 は上限なしです。上限を指定する場合、
+	// 秒数を数えられるよう全ショットに DurationSec（または Base.DurationSec）が必要です。
+	MaxTotalSec int
+	// ExtractLastFrame は、生成された動画から終了フレームを取り出します。
+	// StartFromLastFrame のショットの直前に Shot.LastFrame が無い場合に使います。
+	// 動画のデコードはこのパッケージの範囲外のため、ffmpeg などを呼ぶ関数を渡してください。
+	ExtractLastFrame func(ctx context.Context, video gemini.Attachment) (*Media, error)
+	// OnShot は、各ショットが完了するたびに呼ばれます。途中経過の保存などに使えます。
+	OnShot func(ShotResult)
+}
+
+// ShotResult は完了した1ショット分の結果です。
+type ShotResult struct {
+	// Index は Sequence.Shots の添字です。
+	Index int
+	// Continue は実際に使ったつなぎ方です（最初のショットは NewScene になります）。
+	Continue Continuation
+	// Result はこのショットの生成結果です。
+	Result *Result
+	// Video はこのショットで生成された動画です。ExtendVideo の場合はそれまでの全体を含みます。
+	Video gemini.Attachment
+	// DurationSec はこのショットで要求した秒数です。API のデフォルトに委ねた場合は 0 です。
+	DurationSec int
+	// CumulativeSec はこのショットまでの要求秒数の合計です。
+	CumulativeSec int
+}
+
+// SequenceResult は GenerateSequence の結果です。
+type SequenceResult struct {
+	// Shots は完了したショットを順に並べたものです。途中で失敗した場合も、
+	// それまでに完了した分が入ります。
+	Shots []ShotResult
+	// TotalSec は完了したショットの要求秒数の合計です。
+	TotalSec int
+}
+
+// Clips は、つなぎ合わせるべきクリップを順に返します。
+//
+// ExtendVideo の動画はそれまでの全体を含むため、継続生成が続く間は最後の1本だけを残し、
+// StartFromLastFrame / NewScene で区切られるごとに1本ずつ並べます。
+func (r *SequenceResult) Clips() []gemini.Attachment {
+	if r == nil {
+		return nil
+	}
+	var clips []gemini.Attachment
+	for _, shot := range r.Shots {
+		if shot.Continue == ExtendVideo && len(clips) > 0 {
+			clips[len(clips)-1] = shot.Video
+			continue
+		}
+		clips = append(clips, shot.Video)
+	}
+	return clips
+}
+
+// ShotError は、シーケンスの途中のショットが失敗したことを表します。
+// errors.Is で原因（ErrNoVideoGenerated や gemini.ErrVideoGenerationFailed など）を
+// 判定できます。
+type ShotError struct {
+	// Index は失敗したショットの Sequence.Shots での添字です。
+	Index int
+	// Prompt は失敗したショットの生成指示です。
+	Prompt string
+	// Err は失敗の原因です。
+	Err error
+}
+
+func (e *ShotError) Error() string {
+	return fmt.Sprintf("veo: ショット %d（%q）の生成に失敗しました: %v", e.Index, e.Prompt, e.Err)
+}
+
+func (e *ShotError) Unwrap() error {
+	return e.Err
+}
+
+// GenerateSequence は、ショットを順に生成して直前の出力から続けていきます。
+//
+// 各ショットは Generate と同じ規則で完了まで待ちます。あるショットが失敗する
+// （安全性ポリシーで除外された場合を含む）と、後続はそれに依存するためそこで止め、
+// *ShotError を返します。それまでに完了したショットは SequenceResult に残るので、
+// 失敗したショットの指示を変えて続きから組み直せます。
+//
+// つなぎ方と入力が両立しない指定や、MaxTotalSec を超える指定は、生成を始める前に
+// ErrInvalidSequence / ErrSequenceTooLong で返します。
+func (c *Client) GenerateSequence(ctx context.Context, modelName string, seq Sequence) (*SequenceResult, error) {
+	if err := seq.validate(); err != nil {
+		return nil, err
+	}
+
+	result := &SequenceResult{}
+	var prev *ShotResult
+	for i, shot := range seq.Shots {
+		req, continuation, err := seq.request(ctx, i, prev)
+		if err != nil {
+			return result, &ShotError{Index: i, Prompt: shot.Prompt, Err: err}
+		}
+		c.logger.InfoContext(ctx, "シーケンスのショットを生成します",
+			"shot", i, "of", len(seq.Shots), "continue", continuation.String())
+
+		generated, err := c.Generate(ctx, modelName, req)
+		if err != nil {
+			return result, &ShotError{Index: i, Prompt: shot.Prompt, Err: err}
+		}
+		video, _ := generated.First()
+
+		duration := seq.durationOf(shot)
+		result.TotalSec += duration
+		result.Shots = append(result.Shots, ShotResult{
+			Index:         i,
+			Continue:      continuation,
+			Result:        generated,
+			Video:         video,
+			DurationSec:   duration,
+			CumulativeSec: result.TotalSec,
+		})
+		prev = &result.Shots[len(result.Shots)-1]
+		if seq.OnShot != nil {
+			seq.OnShot(*prev)
+		}
+	}
+	return result, nil
+}
+
+// validate は、生成を始める前に分かる指定の誤りを検出します。
+func (seq Sequence) validate() error {
+	if len(seq.Shots) == 0 {
+		return fmt.Errorf("%w: ショットがありません", ErrInvalidSequence)
+	}
+	total := 0
+	for i, shot := range seq.Shots {
+		continuation := shot.Continue
+		if i == 0 {
+			continuation = NewScene
+		}
+		switch continuation {
+		case ExtendVideo:
+			if shot.Image != nil || shot.LastFrame != nil {
+				return fmt.Errorf("%w: ショット %d は直前の動画を継続するため Image / LastFrame を指定できません", ErrInvalidSequence, i)
+			}
+		case StartFromLastFrame:
+			if shot.Image != nil {
+				return fmt.Errorf("%w: ショット %d の開始フレームは直前のショットの終了フレームです。Image は指定できません", ErrInvalidSequence, i)
+			}
+			if seq.Shots[i-1].LastFrame == nil && seq.ExtractLastFrame == nil {
+				return fmt.Errorf("%w: ショット %d の開始フレームを得るには、ショット %d の LastFrame か ExtractLastFrame が必要です", ErrInvalidSequence, i, i-1)
+			}
+		case NewScene:
+			if shot.LastFrame != nil && shot.Image == nil {
+				return fmt.Errorf("%w: ショット %d の LastFrame は Image とセットでのみ指定できます", ErrInvalidSequence, i)
+			}
+		default:
+			return fmt.Errorf("%w: ショット %d のつなぎ方 %v は不明です", ErrInvalidSequence, i, shot.Continue)
+		}
+
+		if seq.MaxTotalSec > 0 {
+			duration := seq.durationOf(shot)
+			if duration == 0 {
+				return fmt.Errorf("%w: MaxTotalSec を指定する場合、ショット %d に DurationSec が必要です", ErrInvalidSequence, i)
+			}
+			total += duration
+			if total > seq.MaxTotalSec {
+				return fmt.Errorf("%w: ショット %d までで %d 秒になります（上限 %d 秒）", ErrSequenceTooLong, i, total, seq.MaxTotalSec)
+			}
+		}
+	}
+	return nil
+}
+
+// request は i 番目のショットのリクエストと、実際に使うつなぎ方を組み立てます。
+func (seq Sequence) request(ctx context.Context, i int, prev *ShotResult) (Request, Continuation, error) {
+	shot := seq.Shots[i]
+	req := seq.Base
+	req.Prompt = shot.Prompt
+	req.DurationSec = seq.durationOf(shot)
+	req.NumberOfVideos = 0
+	req.Image, req.Video, req.LastFrame, req.References = nil, nil, nil, nil
+
+	continuation := shot.Continue
+	if prev == nil {
+		continuation = NewScene
+	}
+	switch continuation {
+	case ExtendVideo:
+		video := prev.Video
+		req.Video = &video
+	case StartFromLastFrame:
+		frame := seq.Shots[prev.Index].LastFrame
+		if frame == nil {
+			extracted, err := seq.ExtractLastFrame(ctx, prev.Video)
+			if err != nil {
+				return Request{}, continuation, fmt.Errorf("ショット %d の終了フレームを取り出せません: %w", prev.Index, err)
+			}
+			if extracted == nil {
+				return Request{}, continuation, fmt.Errorf("ショット %d の終了フレームを取り出せません: %w", prev.Index, ErrInvalidSequence)
+			}
+			frame = extracted
+		}
+		req.Image, req.LastFrame = frame, shot.LastFrame
+	case NewScene:
+		req.Image, req.LastFrame = shot.Image, shot.LastFrame
+		if prev == nil && req.Image == nil {
+			// 最初のショットは Base の入力（参照画像など）をそのまま使えるようにする。
+			req.Image, req.Video, req.LastFrame, req.References = seq.Base.Image, seq.Base.Video, seq.Base.LastFrame, seq.Base.References
+		}
+	}
+	return req, continuation, nil
+}
+
+// durationOf はショットで要求する秒数を返します。
+func (seq Sequence) durationOf(shot Shot) int {
+	if shot.DurationSec > 0 {
+		return shot.DurationSec
+	}
+	return seq.Base.DurationSec
+}
@@ -0,0 +1,156 @@
+package veo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shouni/go-gemini-client/gemini"
+)
+
+// sequenceGenerator は、受け取ったリクエストを記録し、投函の応答で完了を返すテストダブルです。
+// 動画の URI はショットの通し番号から作ります。プロンプトが "filtered" のショットは
+// 安全性ポリシーで除外されたものとして返します。
+type sequenceGenerator struct {
+	requests []gemini.VideoRequest
+}
+
+func (g *sequenceGenerator) StartVideo(_ context.Context, _ string, req gemini.VideoRequest) (*gemini.VideoOperation, error) {
+	g.requests = append(g.requests, req)
+	name := "operations/" + string(rune('a'+len(g.requests)-1))
+	if req.Prompt == "filtered" {
+		return &gemini.VideoOperation{Name: name, Done: true, FilteredCount: 1, FilteredReasons: []string{"celebrity"}}, nil
+	}
+	return finished(name, "gs://bucket/"+name+".mp4"), nil
+}
+
+func (g *sequenceGenerator) PollVideo(context.Context, string) (*gemini.VideoOperation, error) {
+	return nil, errors.New("unexpected poll")
+}
+
+func (g *sequenceGenerator) CancelVideo(context.Context, string) error {
+	return gemini.ErrUnsupported
+}
+
+func (g *sequenceGenerator) DeleteVideo(context.Context, string) error {
+	return gemini.ErrUnsupported
+}
+
+func TestGenerateSequenceChainsShots(t *testing.T) {
+	generator := &sequenceGenerator{}
+	client := newTestClient(t, generator)
+	end := &Media{URI: "gs://bucket/end.png", MIMEType: "image/png"}
+
+	var seen []int
+	result, err := client.GenerateSequence(context.Background(), "veo-test", Sequence{
+		Base: Request{AspectRatio: "16:9", DurationSec: 8, NumberOfVideos: 2},
+		Shots: []Shot{
+			{Prompt: "opening", Image: &Media{URI: "gs://bucket/start.png"}},
+			{Prompt: "extend", Continue: ExtendVideo, DurationSec: 7},
+			{Prompt: "cut", Continue: NewScene, Image: &Media{URI: "gs://bucket/scene2.png"}, LastFrame: end},
+			{Prompt: "follow", Continue: StartFromLastFrame},
+		},
+		MaxTotalSec: 60,
+		OnShot:      func(s ShotResult) { seen = append(seen, s.Index) },
+	})
+	if err != nil {
+		t.Fatalf("GenerateSequence() error = %v", err)
+	}
+
+	reqs := generator.requests
+	if len(reqs) != 4 {
+		t.Fatalf("requests = %d, want 4", len(reqs))
+	}
+	if reqs[0].Image == nil || reqs[0].Video != nil || reqs[0].NumberOfVideos != 0 || reqs[0].AspectRatio != "16:9" {
+		t.Errorf("first request = %+v, want the shot image with base parameters and one video", reqs[0])
+	}
+	if reqs[1].Video == nil || reqs[1].Video.URI != "gs://bucket/operations/a.mp4" || reqs[1].Image != nil || reqs[1].DurationSec != 7 {
+		t.Errorf("extend request = %+v, want the previous video as input", reqs[1])
+	}
+	if reqs[2].Video != nil || reqs[2].Image.URI != "gs://bucket/scene2.png" || reqs[2].LastFrame != end {
+		t.Errorf("new scene request = %+v, want the shot's own frames", reqs[2])
+	}
+	if reqs[3].Image != end || reqs[3].Video != nil {
+		t.Errorf("start-from-last-frame request = %+v, want the previous shot's last frame", reqs[3])
+	}
+
+	if result.TotalSec != 31 || result.Shots[3].CumulativeSec != 31 || result.Shots[1].CumulativeSec != 15 {
+		t.Errorf("durations = total %d, shots %+v", result.TotalSec, result.Shots)
+	}
+	if result.Shots[0].Continue != NewScene {
+		t.Errorf("first shot continuation = %v, want new_scene", result.Shots[0].Continue)
+	}
+	clips := result.Clips()
+	if len(clips) != 3 || clips[0].URI != "gs://bucket/operations/b.mp4" {
+		t.Errorf("clips = %+v, want the extended video in place of its source plus two more", clips)
+	}
+	if len(seen) != 4 {
+		t.Errorf("OnShot calls = %v, want one per shot", seen)
+	}
+}
+
+func TestGenerateSequenceStopsOnFilteredShot(t *testing.T) {
+	generator := &sequenceGenerator{}
+	client := newTestClient(t, generator)
+
+	result, err := client.GenerateSequence(context.Background(), "veo-test", Sequence{
+		Shots: []Shot{{Prompt: "opening"}, {Prompt: "filtered"}, {Prompt: "never"}},
+	})
+	shotErr, ok := errors.AsType[*ShotError](err)
+	if !ok || shotErr.Index != 1 || shotErr.Prompt != "filtered" {
+		t.Fatalf("error = %v, want a ShotError for shot 1", err)
+	}
+	if !errors.Is(err, ErrNoVideoGenerated) {
+		t.Errorf("error = %v, want it to wrap ErrNoVideoGenerated", err)
+	}
+	if len(result.Shots) != 1 || len(generator.requests) != 2 {
+		t.Errorf("completed = %d, submitted = %d; want to stop right after the filtered shot", len(result.Shots), len(generator.requests))
+	}
+}
+
+func TestGenerateSequenceExtractsLastFrame(t *testing.T) {
+	generator := &sequenceGenerator{}
+	client := newTestClient(t, generator)
+	frame := &Media{URI: "gs://bucket/extracted.png", MIMEType: "image/png"}
+
+	var extractedFrom string
+	_, err := client.GenerateSequence(context.Background(), "veo-test", Sequence{
+		Shots: []Shot{{Prompt: "opening"}, {Prompt: "follow", Continue: StartFromLastFrame}},
+		ExtractLastFrame: func(_ context.Context, video gemini.Attachment) (*Media, error) {
+			extractedFrom = video.URI
+			return frame, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("GenerateSequence() error = %v", err)
+	}
+	if extractedFrom != "gs://bucket/operations/a.mp4" || generator.requests[1].Image != frame {
+		t.Errorf("extracted from %q, second image = %+v", extractedFrom, generator.requests[1].Image)
+	}
+}
+
+func TestGenerateSequenceValidatesBeforeGenerating(t *testing.T) {
+	tests := []struct {
+		name string
+		seq  Sequence
+		want error
+	}{
+		{"no shots", Sequence{}, ErrInvalidSequence},
+		{"extend with image", Sequence{Shots: []Shot{{Prompt: "a"}, {Prompt: "b", Image: &Media{URI: "gs://x.png"}}}}, ErrInvalidSequence},
+		{"last frame without source", Sequence{Shots: []Shot{{Prompt: "a"}, {Prompt: "b", Continue: StartFromLastFrame}}}, ErrInvalidSequence},
+		{"unknown duration", Sequence{Shots: []Shot{{Prompt: "a"}}, MaxTotalSec: 30}, ErrInvalidSequence},
+		{"too long", Sequence{Base: Request{DurationSec: 8}, Shots: []Shot{{Prompt: "a"}, {Prompt: "b"}}, MaxTotalSec: 10}, ErrSequenceTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := &sequenceGenerator{}
+			client := newTestClient(t, generator)
+			if _, err := client.GenerateSequence(context.Background(), "veo-test", tt.seq); !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+			if len(generator.requests) != 0 {
+				t.Errorf("submitted %d requests, want none", len(generator.requests))
+			}
+		})
+	}
+}
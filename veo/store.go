@@ -0,0 +1,250 @@
+package veo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrJobNotFound は、JobStore に指定したオペレーションの記録が無い場合に返されます。
+var ErrJobNotFound = errors.New("veo: job not found")
+
+// JobStatus は記録されたジョブの状態です。
+type JobStatus string
+
+const (
+	// JobPending は、投函済みで完了をまだ確認していない状態です。Resume の対象になります。
+	// 完了待ちがタイムアウトや中断で終わった場合も、この状態のまま残ります。
+	JobPending JobStatus = "pending"
+	// JobSucceeded は、動画が生成されたことを確認した状態です。
+	JobSucceeded JobStatus = "succeeded"
+	// JobFailed は、生成が失敗として完了したことを確認した状態です
+	// （安全性ポリシーで1本も生成されなかった場合を含みます）。
+	JobFailed JobStatus = "failed"
+	// JobCancelled は、オペレーションが取り消されて完了したことを確認した状態です。
+	JobCancelled JobStatus = "cancelled"
+)
+
+// Job は投函した動画生成1件の記録です。
+//
+// プロセスが再起動しても「何を、どのモデルで、いつ投函したか」を失わないために、
+// Submit / Generate が投函の時点で JobStore へ書き込みます。
+type Job struct {
+	OperationName string         `json:"operation_name"`
+	Model         string         `json:"model"`
+	Request       RequestSummary `json:"request"`
+	SubmittedAt   time.Time      `json:"submitted_at"`
+	Status        JobStatus      `json:"status"`
+	// UpdatedAt は Status を最後に書き換えた時刻です。
+	UpdatedAt time.Time `json:"updated_at"`
+	// VideoURIs は生成された動画の URI です。バイト列で返った動画は記録しません。
+	VideoURIs []string `json:"video_uris,omitempty"`
+	// Error は JobFailed / JobCancelled の理由です。
+	Error string `json:"error,omitempty"`
+}
+
+// RequestSummary は、ジョブの記録に残す Request の要約です。
+//
+// Request そのものは画像や動画のバイト列を抱えうるため、記録には入力の種類と
+// 生成パラメータだけを残します。
+type RequestSummary struct {
+	Prompt         string `json:"prompt,omitempty"`
+	HasImage       bool   `json:"has_image,omitempty"`
+	HasVideo       bool   `json:"has_video,omitempty"`
+	HasLastFrame   bool   `json:"has_last_frame,omitempty"`
+	References     int    `json:"references,omitempty"`
+	DurationSec    int    `json:"duration_sec,omitempty"`
+	AspectRatio    string `json:"aspect_ratio,omitempty"`
+	Resolution     string `json:"resolution,omitempty"`
+	NumberOfVideos int    `json:"number_of_videos,omitempty"`
+	OutputGCSURI   string `json:"output_gcs_uri,omitempty"`
+}
+
+// summarize は Request から記録用の要約を作ります。
+func summarize(req Request) RequestSummary {
+	return RequestSummary{
+		Prompt:         req.Prompt,
+		HasImage:       req.Image != nil && !req.Image.IsEmpty(),
+		HasVideo:       req.Video != nil && !req.Video.IsEmpty(),
+		HasLastFrame:   req.LastFrame != nil && !req.LastFrame.IsEmpty(),
+		References:     len(req.References),
+		DurationSec:    req.DurationSec,
+		AspectRatio:    req.AspectRatio,
+		Resolution:     req.Resolution,
+		NumberOfVideos: req.NumberOfVideos,
+		OutputGCSURI:   req.OutputGCSURI,
+	}
+}
+
+// JobStore はジョブの記録の保存先です。
+//
+// 同じ OperationName の Put は上書きです。実装は並行に呼び出されても安全である
+// 必要があります（Resume は複数のジョブを並行して待ちます）。
+type JobStore interface {
+	// Put はジョブを記録します。既に同じ名前の記録があれば置き換えます。
+	Put(ctx context.Context, job Job) error
+	// Get は記録を返します。無い場合は ErrJobNotFound を返します。
+	Get(ctx context.Context, operationName string) (Job, error)
+	// List はすべての記録を投函時刻の順に返します。
+	List(ctx context.Context) ([]Job, error)
+}
+
+// MemoryStore はプロセス内だけに記録を持つ JobStore です。
+// 再起動をまたげないため、テストや、投函と待ちが同じプロセスで完結する用途向けです。
+type MemoryStore struct {
+	mu   sync.Mutex
+	jobs map[string]Job
+}
+
+// NewMemoryStore は空の MemoryStore を返します。
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{jobs: make(map[string]Job)}
+}
+
+// Put はジョブを記録します。
+func (s *MemoryStore) Put(_ context.Context, job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.OperationName] = job
+	return nil
+}
+
+// Get は記録を返します。
+func (s *MemoryStore) Get(_ context.Context, operationName string) (Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[operationName]
+	if !ok {
+		return Job{}, fmt.Errorf("%w: %q", ErrJobNotFound, operationName)
+	}
+	return job, nil
+}
+
+// List はすべての記録を投函時刻の順に返します。
+func (s *MemoryStore) List(_ context.Context) ([]Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sortedJobs(s.jobs), nil
+}
+
+// FileStore は記録を1つの JSON ファイルに持つ JobStore です。
+//
+// 書き込みのたびにファイル全体を書き直します（一時ファイルへ書いてから rename する
+// ため、途中で落ちても壊れたファイルは残りません）。1プロセスが数十〜数百件の
+// ジョブを持つ程度を想定しており、複数のプロセスから同じファイルを同時に
+// 書き換える用途には向きません。
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileStore は path を保存先とする FileStore を返します。
+// ファイルが無ければ最初の Put で作成します（親ディレクトリは作りません）。
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Put はジョブを記録します。
+func (s *FileStore) Put(_ context.Context, job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs, err := s.load()
+	if err != nil {
+		return err
+	}
+	jobs[job.OperationName] = job
+	return s.save(jobs)
+}
+
+// Get は記録を返します。
+func (s *FileStore) Get(_ context.Context, operationName string) (Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs, err := s.load()
+	if err != nil {
+		return Job{}, err
+	}
+	job, ok := jobs[operationName]
+	if !ok {
+		return Job{}, fmt.Errorf("%w: %q", ErrJobNotFound, operationName)
+	}
+	return job, nil
+}
+
+// List はすべての記録を投函時刻の順に返します。
+func (s *FileStore) List(_ context.Context) ([]Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return sortedJobs(jobs), nil
+}
+
+// load はファイルを読み込みます。ファイルがまだ無い場合は空として扱います。
+func (s *FileStore) load() (map[string]Job, error) {
+	jobs := make(map[string]Job)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return jobs, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("veo: ジョブの記録 %s の読み込みに失敗しました: %w", s.path, err)
+	}
+	var list []Job
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("veo: ジョブの記録 %s を解釈できません: %w", s.path, err)
+	}
+	for _, job := range list {
+		jobs[job.OperationName] = job
+	}
+	return jobs, nil
+}
+
+// save は記録をファイルへ書き出します。
+func (s *FileStore) save(jobs map[string]Job) error {
+	data, err := json.MarshalIndent(sortedJobs(jobs), "", "  ")
+	if err != nil {
+		return fmt.Errorf("veo: ジョブの記録の変換に失敗しました: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("veo: ジョブの記録 %s の書き込みに失敗しました: %w", s.path, err)
+	}
+	defer os.Remove(tmp.Name()) // rename 済みなら何もしない
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("veo: ジョブの記録 %s の書き込みに失敗しました: %w", s.path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("veo: ジョブの記録 %s の書き込みに失敗しました: %w", s.path, err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("veo: ジョブの記録 %s の書き込みに失敗しました: %w", s.path, err)
+	}
+	return nil
+}
+
+// sortedJobs は記録を投函時刻（同時刻なら名前）の順に並べます。
+func sortedJobs(jobs map[string]Job) []Job {
+	list := make([]Job, 0, len(jobs))
+	for _, job := range jobs {
+		list = append(list, job)
+	}
+	slices.SortFunc(list, func(a, b Job) int {
+		if c := a.SubmittedAt.Compare(b.SubmittedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.OperationName, b.OperationName)
+	})
+	return list
+}
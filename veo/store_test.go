@@ -0,0 +1,227 @@
+package veo
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shouni/go-gemini-client/gemini"
+)
+
+func TestFileStore_PersistsAcrossInstances(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.json")
+	ctx := context.Background()
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	first := NewFileStore(path)
+	if _, err := first.Get(ctx, "operations/a"); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("Get() on a missing file error = %v, want ErrJobNotFound", err)
+	}
+	for i, name := range []string{"operations/b", "operations/a"} {
+		job := Job{OperationName: name, Model: "veo", Status: JobPending, SubmittedAt: base.Add(time.Duration(i) * time.Minute)}
+		if err := first.Put(ctx, job); err != nil {
+			t.Fatalf("Put(%s) error = %v", name, err)
+		}
+	}
+	if err := first.Put(ctx, Job{OperationName: "operations/b", Status: JobSucceeded, SubmittedAt: base}); err != nil {
+		t.Fatalf("Put() overwrite error = %v", err)
+	}
+
+	// 別のインスタンス（再起動後のプロセス）から読めること。
+	second := NewFileStore(path)
+	jobs, err := second.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(jobs) != 2 || jobs[0].OperationName != "operations/b" || jobs[1].OperationName != "operations/a" {
+		t.Fatalf("List() = %+v, want both jobs in submission order", jobs)
+	}
+	if jobs[0].Status != JobSucceeded {
+		t.Errorf("overwritten status = %s, want succeeded", jobs[0].Status)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries, want only the store file (no leftover temp files)", len(entries))
+	}
+}
+
+func TestFileStore_RejectsCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileStore(path).List(context.Background()); err == nil {
+		t.Fatal("List() should fail on a corrupt file")
+	}
+}
+
+// TestSubmitRecordsJobAndWaitWritesBack verifies the job lifecycle: Submit records it as pending
+// with a request summary, and Wait records the outcome once the operation completes.
+func TestSubmitRecordsJobAndWaitWritesBack(t *testing.T) {
+	store := NewMemoryStore()
+	fake := &fakeGenerator{
+		startOp: running("operations/abc"),
+		polls:   []pollResponse{{op: finished("operations/abc", "gs://bucket/out.mp4")}},
+	}
+	client := newTestClient(t, fake, WithJobStore(store))
+	ctx := context.Background()
+
+	name, err := client.Submit(ctx, "veo-test", Request{
+		Prompt:      "a cat",
+		Image:       &gemini.Attachment{MIMEType: "image/png", Data: []byte{1}},
+		DurationSec: 8,
+	})
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	job, err := store.Get(ctx, name)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if job.Status != JobPending || job.Model != "veo-test" || job.SubmittedAt.IsZero() {
+		t.Errorf("recorded job = %+v", job)
+	}
+	if job.Request.Prompt != "a cat" || !job.Request.HasImage || job.Request.DurationSec != 8 {
+		t.Errorf("request summary = %+v", job.Request)
+	}
+
+	if _, err := client.Wait(ctx, name); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	job, _ = store.Get(ctx, name)
+	if job.Status != JobSucceeded || len(job.VideoURIs) != 1 || job.VideoURIs[0] != "gs://bucket/out.mp4" {
+		t.Errorf("job after Wait = %+v", job)
+	}
+}
+
+func TestWaitRecordsFailureButNotTimeout(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	for _, name := range []string{"operations/failed", "operations/slow"} {
+		_ = store.Put(ctx, Job{OperationName: name, Status: JobPending})
+	}
+	fake := &fakeGenerator{byName: map[string]*gemini.VideoOperation{
+		"operations/failed": {Name: "operations/failed", Done: true, FilteredCount: 1, FilteredReasons: []string{"violence"}},
+		"operations/slow":   running("operations/slow"),
+	}}
+	client := newTestClient(t, fake, WithJobStore(store), WithPollTimeout(20*time.Millisecond))
+
+	if _, err := client.Wait(ctx, "operations/failed"); !errors.Is(err, ErrNoVideoGenerated) {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if job, _ := store.Get(ctx, "operations/failed"); job.Status != JobFailed || job.Error == "" {
+		t.Errorf("failed job = %+v, want failed with a reason", job)
+	}
+
+	if _, err := client.Wait(ctx, "operations/slow"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if job, _ := store.Get(ctx, "operations/slow"); job.Status != JobPending {
+		t.Errorf("timed-out job status = %s, want it left pending for Resume", job.Status)
+	}
+}
+
+func TestResumeWaitsForPendingJobs(t *testing.T) {
+	ctx := context.Background()
+	store := NewFileStore(filepath.Join(t.TempDir(), "jobs.json"))
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	jobs := []Job{
+		{OperationName: "operations/1", Status: JobPending, SubmittedAt: base},
+		{OperationName: "operations/2", Status: JobSucceeded, SubmittedAt: base.Add(time.Minute)},
+		{OperationName: "operations/3", Status: JobPending, SubmittedAt: base.Add(2 * time.Minute)},
+	}
+	for _, job := range jobs {
+		if err := store.Put(ctx, job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fake := &fakeGenerator{byName: map[string]*gemini.VideoOperation{
+		"operations/1": finished("operations/1", "gs://bucket/1.mp4"),
+		"operations/3": {Name: "operations/3", Done: true, Failure: gemini.ErrVideoGenerationFailed},
+	}}
+	client := newTestClient(t, fake, WithJobStore(store))
+
+	results, err := client.Resume(ctx)
+	if err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("results = %d, want only the 2 pending jobs", len(results))
+	}
+	if results[0].Job.OperationName != "operations/1" || results[0].Err != nil || results[0].Result == nil {
+		t.Errorf("results[0] = %+v", results[0])
+	}
+	if results[1].Job.OperationName != "operations/3" || !errors.Is(results[1].Err, gemini.ErrVideoGenerationFailed) {
+		t.Errorf("results[1] = %+v", results[1])
+	}
+
+	// 2回目の Resume では待つものが残っていないこと。
+	again, err := client.Resume(ctx)
+	if err != nil || len(again) != 0 {
+		t.Errorf("second Resume() = %d results, %v; want none", len(again), err)
+	}
+}
+
+// countingPollGenerator は、同時に走っている PollVideo の数の最大値を記録する fakeGenerator です。
+type countingPollGenerator struct {
+	fakeGenerator
+	countMu       sync.Mutex
+	inFlight, max int
+}
+
+func (g *countingPollGenerator) PollVideo(ctx context.Context, operationName string) (*gemini.VideoOperation, error) {
+	g.countMu.Lock()
+	g.inFlight++
+	g.max = max(g.max, g.inFlight)
+	g.countMu.Unlock()
+	defer func() {
+		g.countMu.Lock()
+		g.inFlight--
+		g.countMu.Unlock()
+	}()
+	time.Sleep(time.Millisecond)
+	return g.fakeGenerator.PollVideo(ctx, operationName)
+}
+
+func TestResumePollsPendingJobsInOneLoop(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	generator := &countingPollGenerator{fakeGenerator: fakeGenerator{byName: map[string]*gemini.VideoOperation{}}}
+	for i := range 50 {
+		name := "operations/" + strconv.Itoa(i)
+		generator.byName[name] = finished(name, "gs://bucket/"+strconv.Itoa(i)+".mp4")
+		if err := store.Put(ctx, Job{OperationName: name, Status: JobPending, SubmittedAt: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client := newTestClient(t, generator, WithJobStore(store))
+
+	results, err := client.Resume(ctx)
+	if err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	for i, result := range results {
+		if result.Err != nil || result.Result == nil {
+			t.Errorf("results[%d] = %+v", i, result)
+		}
+	}
+	if generator.max != 1 {
+		t.Errorf("concurrent PollVideo calls = %d, want 1 (one shared poll loop)", generator.max)
+	}
+}
+
+func TestResumeRequiresStore(t *testing.T) {
+	client := newTestClient(t, &fakeGenerator{})
+	if _, err := client.Resume(context.Background()); !errors.Is(err, ErrJobStoreRequired) {
+		t.Fatalf("Resume() error = %v, want ErrJobStoreRequired", err)
+	}
+}
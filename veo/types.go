@@ -27,6 +27,23 @@ const (
 	DefaultMaxPollErrors = 10
 )
 
+// DefaultExpectedDurations は、モデル名の接頭辞ごとの「生成に最低限かかる時間」の
+// 目安です。投函直後の確認は空振りが確実なため、WithExpectedDurations に渡すと、
+// この時間が経つまで最初の確認を遅らせます。混雑時はこれより大幅に長くかかるため、
+// 値は速い側に寄せてあります。
+//
+// Client の既定ではこの表を使わず、最初の確認を遅らせません。使う場合は
+// veo.WithExpectedDurations(veo.DefaultExpectedDurations()) のように明示します。
+// 返す値は呼び出しのたびに作り直すので、書き換えて渡しても構いません。
+func DefaultExpectedDurations() map[string]time.Duration {
+	return map[string]time.Duration{
+		"veo-2":        30 * time.Second,
+		"veo-3":        45 * time.Second,
+		"veo-3.0-fast": 25 * time.Second,
+		"veo-3.1-fast": 25 * time.Second,
+	}
+}
+
 // Request は動画生成1本分の入力です。
 //
 // gemini.VideoRequest の別名で、入力の組み立てにこのパッケージ独自の型を覚え直す
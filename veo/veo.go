@@ -29,20 +29,38 @@ var (
 	// ErrPollFailed は、生成状況の確認が続けて失敗し、完了を待てなくなった場合に
 	// 返されます。最後に発生した原因が Unwrap で辿れます。
 	ErrPollFailed = errors.New("veo: polling for completion failed")
+
+	// ErrJobStoreRequired は、JobStore を設定していない Client で Resume を呼んだ
+	// 場合に返されます。
+	ErrJobStoreRequired = errors.New("veo: job store is required")
+
+	// ErrCancelled は、オペレーションが取り消されて完了した場合に Wait / Generate が
+	// 返すエラーに含まれます。gemini.ErrVideoCancelled と同じ値です。
+	ErrCancelled = gemini.ErrVideoCancelled
+
+	// ErrUnsupported は、バックエンドが取り消し・削除に対応していない場合に
+	// Cancel / Delete が返します。gemini.ErrUnsupported と同じ値です。
+	ErrUnsupported = gemini.ErrUnsupported
 )
 
 // Client は動画生成の投函から完了待ちまでを扱うクライアントです。
 type Client struct {
 	generator     gemini.VideoGenerator
-	pollInterval  time.Duration
+	poll          gemini.PollStrategy
+	expected      map[string]time.Duration
 	pollTimeout   time.Duration
 	maxPollErrors int
+	concurrency   int
+	store         JobStore
+	progress      func(Status)
+	downloader    gemini.MediaDownloader
 	logger        *slog.Logger
 }
 
 // New は、動画生成クライアントを注入して Client を初期化します。
 //
-// generator には *gemini.Client をそのまま渡せます。
+// generator には *gemini.Client をそのまま渡せます。generator が gemini.MediaDownloader も
+// 満たす場合（*gemini.Client がそうです）は、Download・Save での動画の取得にも使います。
 //
 //	gc, err := gemini.NewClient(ctx, cfg)
 //	vc, err := veo.New(gc, veo.WithPollInterval(10*time.Second))
@@ -52,11 +70,15 @@ func New(generator gemini.VideoGenerator, opts ...Option) (*Client, error) {
 	}
 	c := &Client{
 		generator:     generator,
-		pollInterval:  DefaultPollInterval,
+		poll:          gemini.FixedPolling(DefaultPollInterval),
 		pollTimeout:   DefaultPollTimeout,
 		maxPollErrors: DefaultMaxPollErrors,
+		concurrency:   DefaultConcurrency,
 		logger:        slog.Default(),
 	}
+	if downloader, ok := generator.(gemini.MediaDownloader); ok {
+		c.downloader = downloader
+	}
 	for _, opt := range opts {
 		opt(c)
 	}
@@ -68,6 +90,10 @@ func New(generator gemini.VideoGenerator, opts ...Option) (*Client, error) {
 // 生成そのものが失敗した場合（安全性ポリシーによるブロックなど）は
 // gemini.ErrVideoGenerationFailed を含むエラーになります。完了を待てなかった場合は
 // ポーリングの打ち切り理由（タイムアウトまたは ErrPollFailed）を返します。
+//
+// WithExpectedDurations でモデルの想定時間を設定している場合は、投函から想定時間が
+// 経つまで最初の確認を遅らせます。設定が無ければ（既定）間隔を待たずに確認します。
+// どちらの場合も、WithPollTimeout の上限までに少なくとも1回は確認します。
 func (c *Client) Generate(ctx context.Context, modelName string, req Request) (*Result, error) {
 	op, err := c.start(ctx, modelName, req)
 	if err != nil {
@@ -76,12 +102,12 @@ func (c *Client) Generate(ctx context.Context, modelName string, req Request) (*
 
 	// 投函の応答が既に完了しているなら、1回分のポーリングを省いてそのまま返す。
 	if op.Done {
-		return resultFrom(op)
+		return c.complete(ctx, op)
 	}
 	if strings.TrimSpace(op.Name) == "" {
 		return nil, ErrMissingOperationName
 	}
-	return c.Wait(ctx, op.Name)
+	return c.wait(ctx, op.Name, c.expectedDuration(modelName), nil)
 }
 
 // Submit は動画生成を開始し、完了を待たずにオペレーション名を返します。
@@ -114,6 +140,9 @@ func (c *Client) start(ctx context.Context, modelName string, req Request) (*gem
 		return nil, fmt.Errorf("veo: %w", gemini.ErrEmptyResponse)
 	}
 	c.logger.InfoContext(ctx, "動画生成オペレーションを開始しました", "operation", op.Name, "model", modelName)
+	if strings.TrimSpace(op.Name) != "" {
+		c.recordSubmission(ctx, modelName, req, op.Name)
+	}
 	return op, nil
 }
 
@@ -125,48 +154,158 @@ func (c *Client) start(ctx context.Context, modelName string, req Request) (*gem
 //
 // 最初の問い合わせは間隔を待たずに直ちに行います。別実行からの再開では
 // オペレーションが既に完了していることが多く、そこで 1 interval 分（既定 10 秒）
-// 待ってから確認するのは純粋な死に時間になるためです。
+// 待ってから確認するのは純粋な死に時間になるためです。例外は WithExpectedDurations と
+// WithJobStore を両方設定していて、記録の投函時刻から想定時間がまだ経っていない場合で、
+// その残りの分だけ（WithPollTimeout の上限までに少なくとも1回は確認できる範囲で）待ちます。
 //
 // 1回ごとの問い合わせにはリトライを掛けません。一時的な失敗はこのループが
 // maxPollErrors 回まで受け流し、それを超えた時点で ErrPollFailed として打ち切ります
 // （gemini.PollVideo のコメント参照）。
+//
+// オペレーションが取り消されて完了した場合（Cancel 参照）は ErrCancelled を含むエラーを返します。
 func (c *Client) Wait(ctx context.Context, operationName string) (*Result, error) {
+	return c.wait(ctx, operationName, c.remainingExpected(ctx, operationName), nil)
+}
+
+// Cancel は、投函済みの動画生成の取り消しをサーバーへ要求します。
+//
+// ctx のキャンセルは待つのをやめるだけで、サーバー側の生成（と課金）は続きます。
+// 利用者が画面を離れた場合など、生成そのものを止めたいときはこちらを呼んでください。
+// 取り消しは非同期で、待っている Wait / Generate は次の確認で ErrCancelled を受け取ります。
+// WithJobStore を設定していれば、その時点で記録も JobCancelled になります。
+//
+// バックエンドが取り消しに対応していない場合は ErrUnsupported を返します。
+func (c *Client) Cancel(ctx context.Context, operationName string) error {
 	if strings.TrimSpace(operationName) == "" {
-		return nil, ErrMissingOperationName
+		return ErrMissingOperationName
+	}
+	if err := c.generator.CancelVideo(ctx, operationName); err != nil {
+		return err
+	}
+	c.logger.InfoContext(ctx, "動画生成オペレーションの取り消しを要求しました", "operation", operationName)
+	return nil
+}
+
+// Delete は、動画生成オペレーションの記録をサーバーから削除します。
+//
+// 削除した後は Wait で結果を取り出せなくなるため、動画を Download / Save し終えてから
+// 呼んでください。実行中のオペレーションを削除しても生成は止まりません（止めるには Cancel を
+// 使います）。生成された動画のファイルや、WithJobStore の記録は消しません。
+//
+// バックエンドが削除に対応していない場合は ErrUnsupported を返します。
+func (c *Client) Delete(ctx context.Context, operationName string) error {
+	if strings.TrimSpace(operationName) == "" {
+		return ErrMissingOperationName
+	}
+	if err := c.generator.DeleteVideo(ctx, operationName); err != nil {
+		return err
+	}
+	c.logger.InfoContext(ctx, "動画生成オペレーションを削除しました", "operation", operationName)
+	return nil
+}
+
+// expectedDuration は、モデル名に最も長く一致する接頭辞の想定時間を返します。
+// 表に無いモデルは 0（直ちに確認）です。
+//
+// 想定時間が pollTimeout 以上あると、最初の確認の前に待ちが打ち切られて一度も
+// 確認しないままタイムアウトになるため、pollTimeout の半分で頭打ちにします。
+func (c *Client) expectedDuration(modelName string) time.Duration {
+	var matched string
+	var d time.Duration
+	for prefix, expected := range c.expected {
+		if strings.HasPrefix(modelName, prefix) && len(prefix) > len(matched) {
+			matched, d = prefix, expected
+		}
+	}
+	return min(d, c.pollTimeout/2)
+}
+
+// remainingExpected は、JobStore の記録からモデルと投函時刻が分かる場合に、想定時間の
+// うちまだ経っていない分を返します。記録が無い、あるいは既に経っている場合は 0 です
+// （別実行からの再開では完了済みのことが多く、待つのは死に時間になるため）。
+func (c *Client) remainingExpected(ctx context.Context, operationName string) time.Duration {
+	if c.store == nil || strings.TrimSpace(operationName) == "" {
+		return 0
+	}
+	job, err := c.store.Get(ctx, operationName)
+	if err != nil || job.SubmittedAt.IsZero() {
+		return 0
+	}
+	return max(c.expectedDuration(job.Model)-time.Since(job.SubmittedAt), 0)
+}
+
+// errWatchStopped は、Watch の利用側がループを抜けて待ちをやめたことを表します。
+// 利用側へは返りません。
+var errWatchStopped = errors.New("veo: watch stopped")
+
+// wait は Wait と Watch の本体です。最初の確認を initialDelay だけ遅らせてから
+// c.poll の間隔で確認を繰り返し、問い合わせごとの状況を WithProgress のコールバックと
+// observe（nil 可）へ通知します。observe が false を返したら待ちをやめます。
+func (c *Client) wait(ctx context.Context, operationName string, initialDelay time.Duration, observe func(Status) bool) (*Result, error) {
+	started := time.Now()
+	status := Status{OperationName: operationName}
+	notify := func() bool {
+		status.Elapsed = time.Since(started)
+		if c.progress != nil {
+			c.progress(status)
+		}
+		return observe == nil || observe(status)
+	}
+	// finish は待ちの結末を最後の通知として送ってから返す。
+	finish := func(result *Result, err error) (*Result, error) {
+		status.Result, status.Err = result, err
+		notify()
+		return result, err
+	}
+
+	if strings.TrimSpace(operationName) == "" {
+		return finish(nil, ErrMissingOperationName)
 	}
 
 	waitCtx, cancel := context.WithTimeout(ctx, c.pollTimeout)
 	defer cancel()
 
-	ticker := time.NewTicker(c.pollInterval)
-	defer ticker.Stop()
+	next := time.NewTimer(initialDelay)
+	defer next.Stop()
+	select {
+	case <-waitCtx.Done():
+		return finish(nil, c.waitDeadlineError(ctx, operationName, waitCtx.Err()))
+	case <-next.C:
+	}
 
-	consecutiveErrors := 0
 	for {
+		status.Attempt++
+		status.PollError = nil
 		op, err := c.generator.PollVideo(waitCtx, operationName)
 		switch {
 		case err != nil && waitCtx.Err() != nil:
 			// 待ち時間の上限に達したことによる失敗は「一時的な失敗」ではない。
-			return nil, c.waitDeadlineError(ctx, operationName, waitCtx.Err())
+			return finish(nil, c.waitDeadlineError(ctx, operationName, waitCtx.Err()))
 		case err != nil:
-			consecutiveErrors++
-			if consecutiveErrors >= c.maxPollErrors {
-				return nil, fmt.Errorf("%w: オペレーション %q の確認が %d 回連続で失敗しました: %w",
-					ErrPollFailed, operationName, consecutiveErrors, err)
+			status.ConsecutiveErrors++
+			status.PollError = err
+			if status.ConsecutiveErrors >= c.maxPollErrors {
+				return finish(nil, fmt.Errorf("%w: オペレーション %q の確認が %d 回連続で失敗しました: %w",
+					ErrPollFailed, operationName, status.ConsecutiveErrors, err))
 			}
 			c.logger.WarnContext(ctx, "動画生成の状況確認に失敗しました。再確認します",
-				"operation", operationName, "consecutive_errors", consecutiveErrors, "error", err)
+				"operation", operationName, "consecutive_errors", status.ConsecutiveErrors, "error", err)
 		default:
-			consecutiveErrors = 0
+			status.ConsecutiveErrors = 0
+			status.Done, status.ProgressPercent = op.Done, op.ProgressPercent
 			if op.Done {
-				return resultFrom(op)
+				return finish(c.complete(ctx, op))
 			}
 		}
+		if !notify() {
+			return nil, errWatchStopped
+		}
 
+		next.Reset(c.poll.Next(status.Attempt))
 		select {
 		case <-waitCtx.Done():
-			return nil, c.waitDeadlineError(ctx, operationName, waitCtx.Err())
-		case <-ticker.C:
+			return finish(nil, c.waitDeadlineError(ctx, operationName, waitCtx.Err()))
+		case <-next.C:
 		}
 	}
 }
@@ -183,6 +322,112 @@ func (c *Client) waitDeadlineError(ctx context.Context, operationName string, wa
 		operationName, c.pollTimeout, waitErr)
 }
 
+// JobResult は Resume で完了を待ち直したジョブ1件分の結果です。
+type JobResult struct {
+	// Job は待ち直す前の（JobPending の）記録です。
+	Job Job
+	// Result は完了した生成の結果です。Err が非 nil の場合は nil です。
+	Result *Result
+	// Err は完了待ちのエラーです（Wait が返すものと同じ分類です）。
+	Err error
+}
+
+// Resume は、JobStore に JobPending のまま残っているジョブすべての完了を待ち直します。
+//
+// 投函した後、完了を確認する前にプロセスが再起動した場合の入口です。ジョブごとに
+// Wait を走らせるのではなく、GenerateAll と同じ1つの確認のループで全件を待つため、
+// 記録が何百件あっても PollVideo を同時に呼ぶのは1件だけです。確認の間隔・タイムアウト・
+// 連続失敗の許容は1件ごとに Wait と同じ規則で効きます。結果は投函時刻の順に返します。
+// 個々のジョブの失敗は JobResult.Err に入り、Resume 自体のエラーは記録を読めなかった
+// 場合だけです。待ち終えたジョブの状態は JobStore へ書き戻されます。
+func (c *Client) Resume(ctx context.Context) ([]JobResult, error) {
+	if c.store == nil {
+		return nil, ErrJobStoreRequired
+	}
+	jobs, err := c.store.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("veo: ジョブの記録の取得に失敗しました: %w", err)
+	}
+
+	var pending []Job
+	for _, job := range jobs {
+		if job.Status == JobPending {
+			pending = append(pending, job)
+		}
+	}
+
+	names := make([]string, len(pending))
+	for i, job := range pending {
+		names[i] = job.OperationName
+	}
+	results := make([]JobResult, len(pending))
+	for i, waited := range c.waitAll(ctx, names) {
+		results[i] = JobResult{Job: pending[i], Result: waited.Result, Err: waited.Err}
+	}
+	return results, nil
+}
+
+// recordSubmission は投函したジョブを JobStore へ記録します。
+//
+// 記録に失敗しても投函は取り消せない（課金も生成も進んでいる）ため、エラーには
+// せず警告ログに留めます。
+func (c *Client) recordSubmission(ctx context.Context, modelName string, req Request, operationName string) {
+	if c.store == nil {
+		return
+	}
+	now := time.Now()
+	job := Job{
+		OperationName: operationName,
+		Model:         modelName,
+		Request:       summarize(req),
+		SubmittedAt:   now,
+		Status:        JobPending,
+		UpdatedAt:     now,
+	}
+	if err := c.store.Put(ctx, job); err != nil {
+		c.logger.WarnContext(ctx, "投函したジョブの記録に失敗しました。再起動後に再開できません",
+			"operation", operationName, "error", err)
+	}
+}
+
+// complete は完了したオペレーションを結果へ変換し、その成否を JobStore へ書き戻します。
+func (c *Client) complete(ctx context.Context, op *gemini.VideoOperation) (*Result, error) {
+	result, err := resultFrom(op)
+	if c.store == nil {
+		return result, err
+	}
+
+	job, getErr := c.store.Get(ctx, op.Name)
+	switch {
+	case errors.Is(getErr, ErrJobNotFound):
+		// このクライアントが投函していない（記録の無い）オペレーションは書き足さない。
+		return result, err
+	case getErr != nil:
+		c.logger.WarnContext(ctx, "ジョブの記録の取得に失敗しました", "operation", op.Name, "error", getErr)
+		return result, err
+	}
+
+	job.UpdatedAt = time.Now()
+	switch {
+	case errors.Is(err, ErrCancelled):
+		job.Status, job.Error = JobCancelled, err.Error()
+	case err != nil:
+		job.Status, job.Error = JobFailed, err.Error()
+	default:
+		job.Status, job.Error = JobSucceeded, ""
+		job.VideoURIs = nil
+		for _, video := range result.Videos {
+			if video.URI != "" {
+				job.VideoURIs = append(job.VideoURIs, video.URI)
+			}
+		}
+	}
+	if putErr := c.store.Put(ctx, job); putErr != nil {
+		c.logger.WarnContext(ctx, "ジョブの状態の記録に失敗しました", "operation", op.Name, "error", putErr)
+	}
+	return result, err
+}
+
 // resultFrom は、完了したオペレーションを結果へ変換します。
 func resultFrom(op *gemini.VideoOperation) (*Result, error) {
 	if op.Failure != nil {
@@ -201,11 +446,38 @@ func resultFrom(op *gemini.VideoOperation) (*Result, error) {
 
 // noVideoError は、成功したのに動画が返らなかった場合のエラーを組み立てます。
 // 安全性ポリシーによる除外がこの経路の大半を占めるため、理由が分かっている
-// ときはメッセージに含めます（プロンプトを直すのに必要な情報です）。
+// ときは *FilteredError として件数と理由を持たせます（プロンプトを直すのに必要な情報です）。
 func noVideoError(op *gemini.VideoOperation) error {
 	if op.FilteredCount > 0 || len(op.FilteredReasons) > 0 {
-		return fmt.Errorf("%w: 安全性ポリシーにより除外されました（件数=%d, 理由=%s）",
-			ErrNoVideoGenerated, op.FilteredCount, strings.Join(op.FilteredReasons, "; "))
+		return &FilteredError{
+			OperationName: op.Name,
+			FilteredCount: op.FilteredCount,
+			Reasons:       op.FilteredReasons,
+		}
 	}
 	return fmt.Errorf("%w: オペレーション %q", ErrNoVideoGenerated, op.Name)
 }
+
+// FilteredError は、安全性ポリシーによる除外で動画が1本も生成されなかったことを表します。
+// errors.Is で ErrNoVideoGenerated と一致します。
+//
+//	if filtered, ok := errors.AsType[*veo.FilteredError](err); ok {
+//	    log.Printf("%d 本除外: %v", filtered.FilteredCount, filtered.Reasons)
+//	}
+type FilteredError struct {
+	// OperationName は除外が起きたオペレーションの識別子です。
+	OperationName string
+	// FilteredCount は除外された本数です。
+	FilteredCount int32
+	// Reasons はバックエンドが返した除外の理由です。
+	Reasons []string
+}
+
+func (e *FilteredError) Error() string {
+	return fmt.Sprintf("%v: 安全性ポリシーにより除外されました（件数=%d, 理由=%s）",
+		ErrNoVideoGenerated, e.FilteredCount, strings.Join(e.Reasons, "; "))
+}
+
+func (e *FilteredError) Unwrap() error {
+	return ErrNoVideoGenerated
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -22,6 +23,11 @@ type fakeGenerator struct {
 	polls     []pollResponse
 	pollCalls int
 	lastName  string
+
+	// byName が設定されていれば、PollVideo は polls の代わりに名前ごとの応答を返します。
+	// 複数のオペレーションを並行して待つテスト用です。
+	byName map[string]*gemini.VideoOperation
+	mu     sync.Mutex
 }
 
 type pollResponse struct {
@@ -37,7 +43,17 @@ func (f *fakeGenerator) StartVideo(_ context.Context, _ string, _ gemini.VideoRe
 }
 
 func (f *fakeGenerator) PollVideo(_ context.Context, operationName string) (*gemini.VideoOperation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	f.lastName = operationName
+	if f.byName != nil {
+		f.pollCalls++
+		op, ok := f.byName[operationName]
+		if !ok {
+			return nil, fmt.Errorf("unknown operation %q", operationName)
+		}
+		return op, nil
+	}
 	i := f.pollCalls
 	f.pollCalls++
 	if i >= len(f.polls) {
@@ -46,6 +62,16 @@ func (f *fakeGenerator) PollVideo(_ context.Context, operationName string) (*gem
 	return f.polls[i].op, f.polls[i].err
 }
 
+// CancelVideo は取り消しに対応しないバックエンドとして振る舞います（cancellingGenerator 参照）。
+func (f *fakeGenerator) CancelVideo(context.Context, string) error {
+	return gemini.ErrUnsupported
+}
+
+// DeleteVideo は削除に対応しないバックエンドとして振る舞います（cancellingGenerator 参照）。
+func (f *fakeGenerator) DeleteVideo(context.Context, string) error {
+	return gemini.ErrUnsupported
+}
+
 // newTestClient は、テストが待たされないよう極小のポーリング間隔で Client を作ります。
 func newTestClient(t *testing.T, generator gemini.VideoGenerator, opts ...Option) *Client {
 	t.Helper()
@@ -247,6 +273,20 @@ func TestWaitReportsSafetyFiltering(t *testing.T) {
 	if got := err.Error(); !strings.Contains(got, "violence") {
 		t.Errorf("error = %q, want it to name the filter reason", got)
 	}
+	filteredErr, ok := errors.AsType[*FilteredError](err)
+	if !ok || filteredErr.FilteredCount != 1 || len(filteredErr.Reasons) != 1 || filteredErr.OperationName != "operations/abc" {
+		t.Errorf("error = %#v, want a FilteredError with the count and reasons", err)
+	}
+}
+
+func TestWaitWithoutFilteringIsNotFilteredError(t *testing.T) {
+	generator := &fakeGenerator{polls: []pollResponse{{op: finished("operations/abc")}}}
+	client := newTestClient(t, generator)
+
+	_, err := client.Wait(context.Background(), "operations/abc")
+	if _, ok := errors.AsType[*FilteredError](err); ok || !errors.Is(err, ErrNoVideoGenerated) {
+		t.Errorf("error = %v, want ErrNoVideoGenerated without a FilteredError", err)
+	}
 }
 
 func TestWaitRequiresOperationName(t *testing.T) {
@@ -331,3 +371,197 @@ func TestWaitPollsImmediately(t *testing.T) {
 		t.Errorf("PollVideo calls = %d, want 1", fake.pollCalls)
 	}
 }
+
+// TestWaitReportsProgress verifies the progress callback sees every poll, including transient
+// errors and metadata progress, and that the last event carries the outcome.
+func TestWaitReportsProgress(t *testing.T) {
+	half := 50.0
+	midway := running("operations/abc")
+	midway.ProgressPercent = &half
+	generator := &fakeGenerator{
+		polls: []pollResponse{
+			{op: running("operations/abc")},
+			{err: errors.New("temporary failure")},
+			{op: midway},
+			{op: finished("operations/abc", "gs://bucket/out.mp4")},
+		},
+	}
+	var events []Status
+	client := newTestClient(t, generator, WithProgress(func(s Status) { events = append(events, s) }))
+
+	if _, err := client.Wait(context.Background(), "operations/abc"); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if len(events) != 4 {
+		t.Fatalf("events = %d, want one per poll", len(events))
+	}
+	for i, event := range events {
+		if event.Attempt != i+1 || event.OperationName != "operations/abc" {
+			t.Errorf("events[%d] = %+v", i, event)
+		}
+	}
+	if events[1].ConsecutiveErrors != 1 || events[1].PollError == nil || events[1].Final() {
+		t.Errorf("error event = %+v", events[1])
+	}
+	if events[2].ProgressPercent == nil || *events[2].ProgressPercent != 50 || events[2].ConsecutiveErrors != 0 {
+		t.Errorf("progress event = %+v", events[2])
+	}
+	last := events[3]
+	if !last.Done || !last.Final() || last.Result == nil || last.Err != nil {
+		t.Errorf("final event = %+v", last)
+	}
+	if last.Elapsed < events[0].Elapsed {
+		t.Errorf("elapsed went backwards: %v < %v", last.Elapsed, events[0].Elapsed)
+	}
+}
+
+func TestWatchYieldsUntilCompletion(t *testing.T) {
+	generator := &fakeGenerator{
+		polls: []pollResponse{
+			{op: running("operations/abc")},
+			{op: running("operations/abc")},
+			{op: &gemini.VideoOperation{Name: "operations/abc", Done: true, Failure: gemini.ErrVideoGenerationFailed}},
+		},
+	}
+	client := newTestClient(t, generator)
+
+	var statuses []Status
+	for status := range client.Watch(context.Background(), "operations/abc") {
+		statuses = append(statuses, status)
+	}
+	if len(statuses) != 3 {
+		t.Fatalf("statuses = %d, want 3", len(statuses))
+	}
+	last := statuses[2]
+	if !last.Final() || !last.Done || !errors.Is(last.Err, gemini.ErrVideoGenerationFailed) {
+		t.Errorf("final status = %+v", last)
+	}
+}
+
+func TestWatchStopsWhenLoopBreaks(t *testing.T) {
+	generator := &fakeGenerator{polls: []pollResponse{{op: running("operations/abc")}}}
+	client := newTestClient(t, generator)
+
+	for status := range client.Watch(context.Background(), "operations/abc") {
+		if status.Attempt == 2 {
+			break
+		}
+	}
+	if generator.pollCalls != 2 {
+		t.Errorf("poll calls = %d, want polling to stop with the loop", generator.pollCalls)
+	}
+}
+
+func TestWatchReportsGivingUp(t *testing.T) {
+	client := newTestClient(t, &fakeGenerator{polls: []pollResponse{{op: running("operations/abc")}}},
+		WithPollTimeout(20*time.Millisecond))
+
+	var last Status
+	for status := range client.Watch(context.Background(), "operations/abc") {
+		last = status
+	}
+	if !last.Final() || last.Done || !errors.Is(last.Err, context.DeadlineExceeded) {
+		t.Errorf("final status = %+v, want the timeout", last)
+	}
+
+	for status := range client.Watch(context.Background(), "") {
+		if !errors.Is(status.Err, ErrMissingOperationName) {
+			t.Errorf("status for blank name = %+v", status)
+		}
+	}
+}
+
+// TestGenerateDelaysFirstPollByExpectedDuration verifies a fresh submission is not polled before
+// the model could possibly have finished, and that the longest matching prefix wins.
+func TestGenerateDelaysFirstPollByExpectedDuration(t *testing.T) {
+	generator := &fakeGenerator{
+		startOp: running("operations/abc"),
+		polls:   []pollResponse{{op: finished("operations/abc", "gs://bucket/out.mp4")}},
+	}
+	client := newTestClient(t, generator, WithExpectedDurations(map[string]time.Duration{
+		"veo-3":      time.Hour,
+		"veo-3-fast": 30 * time.Millisecond,
+	}))
+
+	start := time.Now()
+	if _, err := client.Generate(context.Background(), "veo-3-fast-generate", Request{Prompt: "a cat"}); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("first poll after %v, want it delayed by the expected duration", elapsed)
+	}
+	if generator.pollCalls != 1 {
+		t.Errorf("poll calls = %d, want 1", generator.pollCalls)
+	}
+}
+
+// TestWaitDelaysOnlyForRemainingExpectedDuration verifies Wait uses the job record to skip the
+// delay for jobs submitted long ago (the usual resume case), and that a delay longer than the
+// poll timeout is clamped so the job is still polled once before giving up.
+func TestWaitDelaysOnlyForRemainingExpectedDuration(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	_ = store.Put(ctx, Job{OperationName: "operations/old", Model: "veo-3", Status: JobPending, SubmittedAt: time.Now().Add(-2 * time.Hour)})
+	_ = store.Put(ctx, Job{OperationName: "operations/new", Model: "veo-3", Status: JobPending, SubmittedAt: time.Now()})
+	generator := &fakeGenerator{byName: map[string]*gemini.VideoOperation{
+		"operations/old": finished("operations/old", "gs://bucket/old.mp4"),
+		"operations/new": finished("operations/new", "gs://bucket/new.mp4"),
+	}}
+	client := newTestClient(t, generator, WithJobStore(store),
+		WithExpectedDurations(map[string]time.Duration{"veo-3": time.Hour}),
+		WithPollTimeout(50*time.Millisecond))
+
+	start := time.Now()
+	if _, err := client.Wait(ctx, "operations/old"); err != nil {
+		t.Fatalf("Wait(old) error = %v, want an immediate poll", err)
+	}
+	if elapsed := time.Since(start); elapsed >= 25*time.Millisecond {
+		t.Errorf("Wait(old) took %v, want no delay", elapsed)
+	}
+
+	start = time.Now()
+	if _, err := client.Wait(ctx, "operations/new"); err != nil {
+		t.Fatalf("Wait(new) error = %v, want a poll before the timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed < 25*time.Millisecond {
+		t.Errorf("Wait(new) took %v, want the delay clamped to half the poll timeout", elapsed)
+	}
+}
+
+func TestNewDoesNotDelayFirstPollByDefault(t *testing.T) {
+	generator := &fakeGenerator{
+		startOp: running("operations/abc"),
+		polls:   []pollResponse{{op: finished("operations/abc", "gs://bucket/out.mp4")}},
+	}
+	client := newTestClient(t, generator)
+
+	start := time.Now()
+	if _, err := client.Generate(context.Background(), "veo-3.0-generate-001", Request{Prompt: "a cat"}); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("first poll after %v, want no expected-duration delay by default", elapsed)
+	}
+}
+
+func TestWaitFollowsPollStrategy(t *testing.T) {
+	generator := &fakeGenerator{
+		polls: []pollResponse{
+			{op: running("operations/abc")},
+			{op: running("operations/abc")},
+			{op: finished("operations/abc", "gs://bucket/out.mp4")},
+		},
+	}
+	var attempts []int
+	client := newTestClient(t, generator, WithPollStrategy(gemini.PollStrategyFunc(func(attempt int) time.Duration {
+		attempts = append(attempts, attempt)
+		return time.Millisecond
+	})))
+
+	if _, err := client.Wait(context.Background(), "operations/abc"); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if len(attempts) != 2 || attempts[0] != 1 || attempts[1] != 2 {
+		t.Errorf("strategy attempts = %v, want [1 2]", attempts)
+	}
+}
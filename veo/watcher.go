@@ -0,0 +1,217 @@
+package veo
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrWatcherClosed は、Shutdown を呼んだ後の Watcher に監視を頼んだ場合に返されます。
+var ErrWatcherClosed = errors.New("veo: watcher is closed")
+
+// Watcher は、投函済みのオペレーションをバックグラウンドで見張り、結末を Notifier へ
+// 知らせます。
+//
+// HTTP ハンドラから Submit して即座に応答を返し、完了は後から利用者へ知らせる、という
+// 構成のためのものです。見張っているオペレーションすべてを GenerateAll と同じ1つの
+// ループで確認し、間隔・タイムアウト・連続失敗の許容は Client の設定に従います。
+// Notifier の呼び出しは確認のループとは別のゴルーチンで行うため、遅い送信先が
+// 他のオペレーションの確認を遅らせることはありません。
+type Watcher struct {
+	client    *Client
+	notifiers []Notifier
+
+	// queued は Track が加え、見張りのループが引き取るまでのオペレーション名です。
+	// ループが確認の途中でも Track を待たせないよう、チャネルではなく mu で守った
+	// 列に積み、wake で知らせます。
+	mu     sync.Mutex
+	queued []string
+	closed bool
+	wake   chan struct{}
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+
+	// ctx は確認と通知に使う context で、Shutdown の期限が切れたときだけキャンセルします。
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	notifying sync.WaitGroup
+}
+
+// NewWatcher は、結末を notifiers へ知らせる Watcher を作り、見張りを始めます。
+// 使い終わったら Shutdown を呼んでください。
+//
+//	watcher := videoClient.NewWatcher(&veo.Webhook{URL: hookURL, Secret: secret})
+//	defer watcher.Shutdown(context.Background())
+//
+//	name, err := videoClient.Submit(ctx, model, req)
+//	err = watcher.Track(name)
+func (c *Client) NewWatcher(notifiers ...Notifier) *Watcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &Watcher{
+		client:    c,
+		notifiers: slices.DeleteFunc(slices.Clone(notifiers), func(n Notifier) bool { return n == nil }),
+		wake:      make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	go w.run()
+	return w
+}
+
+// Track は、投函済みのオペレーションを見張りに加えます。既に見張っている名前は無視します。
+// 見張りのループが確認や通知の途中でも待たずに戻ります。Shutdown の後は
+// ErrWatcherClosed を返します。
+func (w *Watcher) Track(operationName string) error {
+	if strings.TrimSpace(operationName) == "" {
+		return ErrMissingOperationName
+	}
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return ErrWatcherClosed
+	}
+	w.queued = append(w.queued, operationName)
+	w.mu.Unlock()
+
+	// 知らせが既に溜まっていれば、ループはそれで列ごと引き取る。
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// takeQueued は Track が積んだオペレーション名を引き取ります。
+func (w *Watcher) takeQueued() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	names := w.queued
+	w.queued = nil
+	return names
+}
+
+// TrackPending は、JobStore に JobPending で残っているジョブをすべて見張りに加え、
+// 加えた件数を返します。再起動後に、前のプロセスが見張っていた分を引き継ぐのに使います。
+// WithJobStore を設定していない場合は ErrJobStoreRequired を返します。
+func (w *Watcher) TrackPending(ctx context.Context) (int, error) {
+	if w.client.store == nil {
+		return 0, ErrJobStoreRequired
+	}
+	jobs, err := w.client.store.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	tracked := 0
+	for _, job := range jobs {
+		if job.Status != JobPending {
+			continue
+		}
+		if err := w.Track(job.OperationName); err != nil {
+			return tracked, err
+		}
+		tracked++
+	}
+	return tracked, nil
+}
+
+// Shutdown は見張りを止め、送信中の通知が終わるのを待ちます。
+//
+// 見張り中で結末を迎えていないオペレーションは通知せずに手放します（サーバー側の
+// 生成は続きます。WithJobStore を設定していれば JobPending のまま残るので、次の
+// プロセスで TrackPending か Resume で拾えます）。ctx の期限が切れた場合は、送信中の
+// 通知を中断して ctx.Err() を返します。
+func (w *Watcher) Shutdown(ctx context.Context) error {
+	w.stopOnce.Do(func() {
+		w.mu.Lock()
+		w.closed = true
+		w.mu.Unlock()
+		close(w.stop)
+	})
+	defer w.cancel()
+
+	select {
+	case <-w.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	notified := make(chan struct{})
+	go func() {
+		w.notifying.Wait()
+		close(notified)
+	}()
+	select {
+	case <-notified:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run は見張りのループです。GenerateAll の確認ループと同じく、最も早く確認すべき
+// 時刻までタイマーで眠り、期限の来たものだけを確認します。
+func (w *Watcher) run() {
+	defer close(w.done)
+	c := w.client
+
+	var pending []*batchJob
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		var due <-chan time.Time
+		if len(pending) > 0 {
+			timer.Reset(time.Until(earliestPoll(pending)))
+			due = timer.C
+		}
+
+		select {
+		case <-w.wake:
+			for _, name := range w.takeQueued() {
+				if slices.ContainsFunc(pending, func(j *batchJob) bool { return j.status.OperationName == name }) {
+					continue
+				}
+				now := time.Now()
+				job := &batchJob{
+					started:  now,
+					deadline: now.Add(c.pollTimeout),
+					status:   Status{OperationName: name},
+				}
+				job.schedule(c.remainingExpected(w.ctx, name))
+				pending = append(pending, job)
+			}
+
+		case <-due:
+			pending = c.pollDue(w.ctx, pending, func(job *batchJob, result *Result, err error) {
+				w.dispatch(newEvent(job.status.OperationName, result, err))
+			})
+
+		case <-w.stop:
+			if len(pending) > 0 {
+				c.logger.InfoContext(w.ctx, "未完了のオペレーションを手放して見張りを終了します", "pending", len(pending))
+			}
+			return
+		}
+	}
+}
+
+// dispatch は event を登録された Notifier へ順に知らせます。確認のループを止めないよう
+// 別のゴルーチンで行います。
+func (w *Watcher) dispatch(event Event) {
+	w.notifying.Go(func() {
+		for _, notifier := range w.notifiers {
+			if err := notifier.Notify(w.ctx, event); err != nil {
+				w.client.logger.WarnContext(w.ctx, "動画生成の結末の通知に失敗しました",
+					"operation", event.OperationName, "outcome", event.Outcome, "error", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,266 @@
+package veo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shouni/go-gemini-client/gemini"
+)
+
+// collectEvents は、受け取った Event をチャネルへ流す Notifier を返します。
+func collectEvents() (Notifier, <-chan Event) {
+	events := make(chan Event, 16)
+	return NotifierFunc(func(_ context.Context, event Event) error {
+		events <- event
+		return nil
+	}), events
+}
+
+func receiveEvent(t *testing.T, events <-chan Event) Event {
+	t.Helper()
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event was notified")
+		return Event{}
+	}
+}
+
+func TestWatcherNotifiesOutcomes(t *testing.T) {
+	generator := &fakeGenerator{byName: map[string]*gemini.VideoOperation{
+		"operations/ok":       finished("operations/ok", "gs://bucket/ok.mp4"),
+		"operations/filtered": {Name: "operations/filtered", Done: true, FilteredCount: 2, FilteredReasons: []string{"violence"}},
+		"operations/failed":   {Name: "operations/failed", Done: true, Failure: &gemini.VideoFailureError{Code: 3}},
+	}}
+	client := newTestClient(t, generator)
+	notifier, events := collectEvents()
+	watcher := client.NewWatcher(notifier)
+	t.Cleanup(func() { _ = watcher.Shutdown(context.Background()) })
+
+	for _, name := range []string{"operations/ok", "operations/filtered", "operations/failed"} {
+		if err := watcher.Track(name); err != nil {
+			t.Fatalf("Track(%s) error = %v", name, err)
+		}
+	}
+
+	got := map[string]Event{}
+	for range 3 {
+		event := receiveEvent(t, events)
+		got[event.OperationName] = event
+	}
+	if e := got["operations/ok"]; e.Outcome != OutcomeSucceeded || e.Result == nil || e.Err != nil {
+		t.Errorf("ok event = %+v", e)
+	}
+	if e := got["operations/filtered"]; e.Outcome != OutcomeFiltered || !errors.Is(e.Err, ErrNoVideoGenerated) {
+		t.Errorf("filtered event = %+v", e)
+	}
+	if e := got["operations/failed"]; e.Outcome != OutcomeFailed || !errors.Is(e.Err, gemini.ErrVideoGenerationFailed) {
+		t.Errorf("failed event = %+v", e)
+	}
+}
+
+// slowPollGenerator は、"operations/slow" の確認を release が閉じられるまで止める fakeGenerator です。
+type slowPollGenerator struct {
+	fakeGenerator
+	polling, release chan struct{}
+	once             sync.Once
+}
+
+func (g *slowPollGenerator) PollVideo(ctx context.Context, operationName string) (*gemini.VideoOperation, error) {
+	if operationName == "operations/slow" {
+		g.once.Do(func() { close(g.polling) })
+		<-g.release
+	}
+	return g.fakeGenerator.PollVideo(ctx, operationName)
+}
+
+func TestWatcherTrackDoesNotWaitForPolling(t *testing.T) {
+	generator := &slowPollGenerator{
+		fakeGenerator: fakeGenerator{byName: map[string]*gemini.VideoOperation{
+			"operations/slow": finished("operations/slow", "gs://bucket/slow.mp4"),
+			"operations/ok":   finished("operations/ok", "gs://bucket/ok.mp4"),
+		}},
+		polling: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	client := newTestClient(t, generator)
+	notifier, events := collectEvents()
+	watcher := client.NewWatcher(notifier)
+	t.Cleanup(func() { _ = watcher.Shutdown(context.Background()) })
+	var releaseOnce sync.Once
+	release := func() { releaseOnce.Do(func() { close(generator.release) }) }
+	t.Cleanup(release) // 失敗しても Shutdown の前に確認を終わらせる
+
+	if err := watcher.Track("operations/slow"); err != nil {
+		t.Fatalf("Track(slow) error = %v", err)
+	}
+	<-generator.polling
+
+	// ループが確認で止まっている間も Track はすぐに戻る。
+	tracked := make(chan error)
+	go func() { tracked <- watcher.Track("operations/ok") }()
+	select {
+	case err := <-tracked:
+		if err != nil {
+			t.Fatalf("Track(ok) error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Track() blocked while the watcher was polling")
+	}
+
+	release()
+	got := map[string]bool{}
+	for range 2 {
+		got[receiveEvent(t, events).OperationName] = true
+	}
+	if !got["operations/slow"] || !got["operations/ok"] {
+		t.Errorf("notified = %v, want both operations", got)
+	}
+}
+
+func TestWatcherShutdownWaitsForNotifications(t *testing.T) {
+	generator := &fakeGenerator{byName: map[string]*gemini.VideoOperation{
+		"operations/ok":    finished("operations/ok", "gs://bucket/ok.mp4"),
+		"operations/stuck": running("operations/stuck"),
+	}}
+	client := newTestClient(t, generator)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	var delivered bool
+	watcher := client.NewWatcher(NotifierFunc(func(context.Context, Event) error {
+		close(started)
+		<-release
+		delivered = true
+		return nil
+	}))
+	_ = watcher.Track("operations/stuck")
+	_ = watcher.Track("operations/ok")
+	<-started
+
+	shutdown := make(chan error)
+	go func() { shutdown <- watcher.Shutdown(context.Background()) }()
+	select {
+	case err := <-shutdown:
+		t.Fatalf("Shutdown() returned %v before the notification finished", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	if err := <-shutdown; err != nil || !delivered {
+		t.Fatalf("Shutdown() = %v, delivered = %v", err, delivered)
+	}
+	if err := watcher.Track("operations/late"); !errors.Is(err, ErrWatcherClosed) {
+		t.Errorf("Track() after Shutdown error = %v, want ErrWatcherClosed", err)
+	}
+}
+
+func TestWatcherShutdownHonoursDeadline(t *testing.T) {
+	generator := &fakeGenerator{byName: map[string]*gemini.VideoOperation{
+		"operations/ok": finished("operations/ok", "gs://bucket/ok.mp4"),
+	}}
+	client := newTestClient(t, generator)
+	started := make(chan struct{})
+	watcher := client.NewWatcher(NotifierFunc(func(ctx context.Context, _ Event) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+	_ = watcher.Track("operations/ok")
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := watcher.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want the deadline", err)
+	}
+}
+
+func TestWatcherTrackPending(t *testing.T) {
+	generator := &fakeGenerator{byName: map[string]*gemini.VideoOperation{
+		"operations/pending": finished("operations/pending", "gs://bucket/p.mp4"),
+	}}
+	store := NewMemoryStore()
+	ctx := context.Background()
+	_ = store.Put(ctx, Job{OperationName: "operations/pending", Status: JobPending})
+	_ = store.Put(ctx, Job{OperationName: "operations/done", Status: JobSucceeded})
+
+	notifier, events := collectEvents()
+	watcher := newTestClient(t, generator, WithJobStore(store)).NewWatcher(notifier)
+	t.Cleanup(func() { _ = watcher.Shutdown(context.Background()) })
+
+	n, err := watcher.TrackPending(ctx)
+	if err != nil || n != 1 {
+		t.Fatalf("TrackPending() = %d, %v; want 1", n, err)
+	}
+	if event := receiveEvent(t, events); event.OperationName != "operations/pending" {
+		t.Errorf("event = %+v", event)
+	}
+
+	bare := newTestClient(t, generator).NewWatcher()
+	defer bare.Shutdown(ctx)
+	if _, err := bare.TrackPending(ctx); !errors.Is(err, ErrJobStoreRequired) {
+		t.Errorf("TrackPending() without a store error = %v, want ErrJobStoreRequired", err)
+	}
+}
+
+func TestWebhookSignsAndRetries(t *testing.T) {
+	secret := []byte("s3cret")
+	var (
+		mu       sync.Mutex
+		attempts int
+		payload  WebhookPayload
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		body, _ := io.ReadAll(r.Body)
+		if !VerifyWebhook(secret, r.Header.Get(WebhookTimestampHeader), body, r.Header.Get(WebhookSignatureHeader)) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		if attempts == 1 {
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.Unmarshal(body, &payload)
+	}))
+	defer server.Close()
+
+	hook := &Webhook{URL: server.URL, Secret: secret, RetryDelay: time.Millisecond}
+	event := newEvent("operations/abc", &Result{Videos: []gemini.Attachment{{URI: "gs://bucket/v.mp4"}}}, nil)
+	if err := hook.Notify(context.Background(), event); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("attempts = %d, want a retry after the 503", attempts)
+	}
+	if payload.OperationName != "operations/abc" || payload.Outcome != OutcomeSucceeded || len(payload.VideoURIs) != 1 {
+		t.Errorf("payload = %+v", payload)
+	}
+}
+
+func TestWebhookDoesNotRetryClientErrors(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+		http.Error(w, "gone", http.StatusGone)
+	}))
+	defer server.Close()
+
+	hook := &Webhook{URL: server.URL, RetryDelay: time.Millisecond}
+	err := hook.Notify(context.Background(), newEvent("operations/abc", nil, &FilteredError{FilteredCount: 1}))
+	if err == nil || attempts != 1 {
+		t.Errorf("Notify() = %v after %d attempts, want one failed attempt", err, attempts)
+	}
+	if VerifyWebhook([]byte("k"), "1", []byte("body"), SignWebhook([]byte("other"), "1", []byte("body"))) {
+		t.Error("a signature made with another secret must not verify")
+	}
+}